@@ -3,6 +3,11 @@ package agent
 import (
 	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
 	"sync"
 	"time"
 
@@ -16,99 +21,405 @@ import (
 
 const trentoAgentCheckId = "trentoAgent"
 
+const bootIDPath = "/proc/sys/kernel/random/boot_id"
+
+const (
+	// tickJitter spreads the discoveries and heartbeats of the agents started at the same time,
+	// so the collector doesn't get synchronized bursts
+	tickJitter = 0.1
+	// maxStartupJitter caps the delay of the first discovery runs, so that a started or reloaded
+	// agent publishes its data soon even for discoveries running hourly
+	maxStartupJitter = 30 * time.Second
+	// maxDiscoveryBackoff caps the interval between the runs of a repeatedly failing discovery
+	maxDiscoveryBackoff = 30 * time.Minute
+	// maxHeartbeatBackoff caps the interval between the heartbeats while the server is unreachable
+	maxHeartbeatBackoff = time.Minute
+)
+
 type Agent struct {
-	config          *Config
+	config *Config
+	// configMutex guards the configuration read by the running loops, which is replaced on reload
+	configMutex     sync.RWMutex
+	client          *reloadableClient
 	collectorClient collector.Client
 	discoveries     []discovery.Discovery
+	loops           map[string]*discoveryLoop
+	loopsMutex      sync.Mutex
+	started         bool
+	wg              sync.WaitGroup
+	status          *statusTracker
+	bootID          string
 	ctx             context.Context
 	ctxCancel       context.CancelFunc
 }
 
+// discoveryLoop is the running loop of a discovery, which can be stopped on its own on reload
+type discoveryLoop struct {
+	discovery discovery.Discovery
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
 type Config struct {
+	// InstanceName is the hostname reported in the heartbeats
 	InstanceName      string
 	DiscoveriesConfig *discovery.DiscoveriesConfig
+	// StatusListenAddress is the address where the status and metrics endpoints are served.
+	// They are disabled if empty
+	StatusListenAddress string
 }
 
 // NewAgent returns a new instance of Agent with the given configuration
 func NewAgent(config *Config) (*Agent, error) {
-	collectorClient, err := collector.NewCollectorClient(config.DiscoveriesConfig.CollectorConfig)
+	client, err := collector.NewCollectorClient(config.DiscoveriesConfig.CollectorConfig)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not create a collector client")
 	}
 
-	discoveries := []discovery.Discovery{
-		discovery.NewClusterDiscovery(collectorClient, *config.DiscoveriesConfig),
-		discovery.NewSAPSystemsDiscovery(collectorClient, *config.DiscoveriesConfig),
-		discovery.NewCloudDiscovery(collectorClient, *config.DiscoveriesConfig),
-		discovery.NewSubscriptionDiscovery(collectorClient, *config.DiscoveriesConfig),
-		discovery.NewHostDiscovery(collectorClient, *config.DiscoveriesConfig),
+	status := newStatusTracker()
+	reloadable := &reloadableClient{client: client}
+	collectorClient := &statusClient{Client: reloadable, status: status}
+
+	discoveries, err := NewDiscoveries(collectorClient, *config.DiscoveriesConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range discoveries {
+		status.addDiscovery(d.GetId())
+	}
+
+	bootID, err := getBootID()
+	if err != nil {
+		log.Warnf("Could not read the boot id, hosts sharing the agent id and hostname can not be told apart: %s", err)
 	}
 
 	ctx, ctxCancel := context.WithCancel(context.Background())
 
 	agent := &Agent{
 		config:          config,
+		client:          reloadable,
 		collectorClient: collectorClient,
+		bootID:          bootID,
 		ctx:             ctx,
 		ctxCancel:       ctxCancel,
 		discoveries:     discoveries,
+		loops:           make(map[string]*discoveryLoop),
+		status:          status,
 	}
 	return agent, nil
 }
 
+// NewDiscoveries returns the built-in discoveries along with the configured plugins,
+// all of them publishing through the given collector client
+func NewDiscoveries(collectorClient collector.Client, config discovery.DiscoveriesConfig) ([]discovery.Discovery, error) {
+	discoveries := []discovery.Discovery{
+		discovery.NewClusterDiscovery(collectorClient, config),
+		discovery.NewSAPSystemsDiscovery(collectorClient, config),
+		discovery.NewCloudDiscovery(collectorClient, config),
+		discovery.NewSubscriptionDiscovery(collectorClient, config),
+		discovery.NewHostDiscovery(collectorClient, config),
+		discovery.NewPackagesDiscovery(collectorClient, config),
+		discovery.NewTuningDiscovery(collectorClient, config),
+	}
+
+	plugins, err := discovery.LoadPlugins(collectorClient, config)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load the discovery plugins")
+	}
+
+	return append(discoveries, plugins...), nil
+}
+
+// RunDiscovery executes a discovery once, aborting it if it exceeds its timeout or the context is done
+func RunDiscovery(ctx context.Context, d discovery.Discovery) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.GetTimeout())
+	defer cancel()
+
+	result, err := d.Discover(ctx)
+	if ctx.Err() == context.DeadlineExceeded {
+		err = errors.Errorf("discovery timed out after %s", d.GetTimeout())
+	}
+
+	return result, err
+}
+
 // Start the Agent. This will start the discovery ticker and the heartbeat ticker
 func (a *Agent) Start() error {
-	var wg sync.WaitGroup
-
+	a.loopsMutex.Lock()
 	for _, d := range a.discoveries {
-		wg.Add(1)
-		go func(wg *sync.WaitGroup, d discovery.Discovery) {
-			log.Infof("Starting %s loop...", d.GetId())
-			defer wg.Done()
-			a.startDiscoverTicker(d)
-			log.Infof("%s discover loop stopped.", d.GetId())
-		}(&wg, d)
+		a.startDiscoveryLoop(d)
 	}
+	a.started = true
+	a.loopsMutex.Unlock()
 
-	wg.Add(1)
-	go func(wg *sync.WaitGroup) {
+	a.wg.Add(1)
+	go func() {
 		log.Info("Starting heartbeat loop...")
-		defer wg.Done()
+		defer a.wg.Done()
 		a.startHeartbeatTicker()
 		log.Info("heartbeat loop stopped.")
-	}(&wg)
+	}()
+
+	if a.config.StatusListenAddress != "" {
+		a.wg.Add(1)
+		go func() {
+			log.Infof("Starting the status server on %s...", a.config.StatusListenAddress)
+			defer a.wg.Done()
+			a.serveStatus()
+			log.Info("status server stopped.")
+		}()
+	}
 
-	wg.Wait()
+	a.wg.Wait()
 
 	return nil
 }
 
+// startDiscoveryLoop runs a discovery loop until it is stopped on its own or the agent is stopped.
+// It must be called holding the loops mutex
+func (a *Agent) startDiscoveryLoop(d discovery.Discovery) {
+	ctx, cancel := context.WithCancel(a.ctx)
+	loop := &discoveryLoop{
+		discovery: d,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+	a.loops[d.GetId()] = loop
+
+	a.wg.Add(1)
+	go func() {
+		log.Infof("Starting %s loop...", d.GetId())
+		defer a.wg.Done()
+		defer close(loop.done)
+		a.startDiscoverTicker(ctx, d)
+		log.Infof("%s discover loop stopped.", d.GetId())
+	}()
+}
+
+// Reload applies a new configuration to the running agent. The following heartbeats report the new instance name.
+// The collector client is replaced and closed once the in-flight publications complete, if its configuration changed
+// or if mTLS is enabled, as the certificate files might have been replaced. Only the discovery loops whose
+// configuration changed are restarted, along with the ones of the plugins added or removed.
+// The running configuration is kept if the new one can not be applied
+func (a *Agent) Reload(config *Config) error {
+	a.loopsMutex.Lock()
+	defer a.loopsMutex.Unlock()
+
+	if a.ctx.Err() != nil {
+		return errors.New("the agent is stopped")
+	}
+
+	if config.StatusListenAddress != a.config.StatusListenAddress {
+		log.Warn("The status listen address can not be changed without restarting the agent")
+	}
+
+	discoveries, err := NewDiscoveries(a.collectorClient, *config.DiscoveriesConfig)
+	if err != nil {
+		return err
+	}
+
+	collectorConfig := config.DiscoveriesConfig.CollectorConfig
+	if collectorConfig.EnablemTLS || !reflect.DeepEqual(collectorConfig, a.config.DiscoveriesConfig.CollectorConfig) {
+		client, err := collector.NewCollectorClient(collectorConfig)
+		if err != nil {
+			return errors.Wrap(err, "could not create a collector client")
+		}
+
+		a.client.replace(client)
+		log.Info("Collector client reloaded")
+	}
+
+	a.reloadDiscoveries(discoveries)
+
+	a.configMutex.Lock()
+	if config.InstanceName != a.config.InstanceName {
+		log.Infof("Instance name changed from %s to %s", a.config.InstanceName, config.InstanceName)
+	}
+	a.config.InstanceName = config.InstanceName
+	a.config.DiscoveriesConfig = config.DiscoveriesConfig
+	a.configMutex.Unlock()
+
+	return nil
+}
+
+// reloadDiscoveries restarts the loops of the discoveries differing from the running ones, stops the ones
+// no longer present and starts the new ones. A stopped loop completes its current execution, publication included.
+// It must be called holding the loops mutex
+func (a *Agent) reloadDiscoveries(discoveries []discovery.Discovery) {
+	reloaded := make(map[string]discovery.Discovery)
+	for _, d := range discoveries {
+		reloaded[d.GetId()] = d
+	}
+
+	unchanged := make(map[string]discovery.Discovery)
+	var stopped []*discoveryLoop
+	for _, current := range a.discoveries {
+		// Discoveries are plain values holding their configuration, so any configuration change makes them differ
+		d, found := reloaded[current.GetId()]
+		if found && reflect.DeepEqual(d, current) {
+			unchanged[current.GetId()] = current
+			continue
+		}
+
+		if !found {
+			a.status.removeDiscovery(current.GetId())
+		}
+
+		if loop, running := a.loops[current.GetId()]; running {
+			log.Infof("Stopping the %s loop to reload it...", current.GetId())
+			loop.cancel()
+			stopped = append(stopped, loop)
+			delete(a.loops, current.GetId())
+		}
+	}
+
+	for _, loop := range stopped {
+		<-loop.done
+	}
+
+	a.discoveries = nil
+	for _, d := range discoveries {
+		if current, found := unchanged[d.GetId()]; found {
+			a.discoveries = append(a.discoveries, current)
+			continue
+		}
+
+		a.status.addDiscovery(d.GetId())
+		a.discoveries = append(a.discoveries, d)
+		// The loops of an agent not started yet are started along with it
+		if a.started {
+			a.startDiscoveryLoop(d)
+		}
+	}
+}
+
 func (a *Agent) Stop() {
 	a.ctxCancel()
 }
 
 // Start a Ticker loop that will iterate over the hardcoded list of Discovery backends and execute them.
-func (a *Agent) startDiscoverTicker(d discovery.Discovery) {
+// Each execution is aborted if it exceeds the discovery timeout or if the agent is stopped,
+// while the loop stops once the given context is done
+func (a *Agent) startDiscoverTicker(ctx context.Context, d discovery.Discovery) {
 
-	tick := func() {
-		result, err := d.Discover()
+	tick := func() error {
+		startedAt := time.Now()
+		result, err := RunDiscovery(a.ctx, d)
+		a.status.discoveryRun(d.GetId(), startedAt, time.Since(startedAt), err)
 		if err != nil {
 			result = fmt.Sprintf("Error while running discovery '%s': %s", d.GetId(), err)
 			log.Errorln(result)
 		}
 		log.Infof("%s discovery tick output: %s", d.GetId(), result)
+		return err
 	}
-	internal.Repeat(d.GetId(), tick, time.Duration(d.GetInterval()), a.ctx)
 
+	interval := time.Duration(d.GetInterval())
+	startupJitter := interval
+	if startupJitter > maxStartupJitter {
+		startupJitter = maxStartupJitter
+	}
+
+	internal.Repeat(
+		d.GetId(),
+		tick,
+		interval,
+		ctx,
+		internal.WithStartupJitter(startupJitter),
+		internal.WithJitter(tickJitter),
+		internal.WithBackoff(maxDiscoveryBackoff),
+	)
+}
+
+// serveStatus serves the status and metrics endpoints until the agent is stopped
+func (a *Agent) serveStatus() {
+	server := &http.Server{
+		Addr:    a.config.StatusListenAddress,
+		Handler: a.status.handler(),
+	}
+
+	go func() {
+		<-a.ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+
+	err := server.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
+		log.Errorf("Error while serving the agent status: %s", err)
+	}
 }
 
 func (a *Agent) startHeartbeatTicker() {
-	tick := func() {
-		err := a.collectorClient.Heartbeat()
-		if err != nil {
-			log.Errorf("Error while sending the heartbeat to the server: %s", err)
+	internal.Repeat(
+		"agent.heartbeat",
+		a.heartbeat,
+		internal.HeartbeatInterval,
+		a.ctx,
+		internal.WithStartupJitter(internal.HeartbeatInterval),
+		internal.WithJitter(tickJitter),
+		internal.WithBackoff(maxHeartbeatBackoff),
+	)
+}
+
+// heartbeat sends the status summary of the agent to the server
+func (a *Agent) heartbeat() error {
+	summary := a.status.summary()
+	// The server detects agents sharing the same id, cloned from the same machine, by their hostnames
+	// and boot ids, as the clones often keep the same hostname
+	a.configMutex.RLock()
+	summary.Hostname = a.config.InstanceName
+	a.configMutex.RUnlock()
+	summary.BootID = a.bootID
+
+	err := a.collectorClient.Heartbeat(summary)
+	if err != nil {
+		log.Errorf("Error while sending the heartbeat to the server: %s", err)
+	}
+	return err
+}
+
+// getBootID returns the random id the kernel generates on each boot, telling apart the hosts cloned from the same image
+func getBootID() (string, error) {
+	bootID, err := ioutil.ReadFile(bootIDPath)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(bootID)), nil
+}
+
+// reloadableClient is a collector client which can be replaced while the agent runs
+type reloadableClient struct {
+	sync.RWMutex
+	client collector.Client
+}
+
+func (c *reloadableClient) Publish(discoveryType string, payload interface{}) error {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.client.Publish(discoveryType, payload)
+}
+
+func (c *reloadableClient) Heartbeat(status interface{}) error {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.client.Heartbeat(status)
+}
+
+// replace swaps the client once the in-flight publications and heartbeats complete, closing the previous one.
+// The following ones wait for the replacement
+func (c *reloadableClient) replace(client collector.Client) {
+	c.Lock()
+	defer c.Unlock()
+
+	if closer, ok := c.client.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Warnf("Error while closing the previous collector client: %s", err)
 		}
 	}
 
-	internal.Repeat("agent.heartbeat", tick, internal.HeartbeatInterval, a.ctx)
+	c.client = client
 }
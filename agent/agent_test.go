@@ -0,0 +1,238 @@
+package agent
+
+import (
+	"context"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trento-project/trento/agent/discovery"
+	"github.com/trento-project/trento/agent/discovery/collector"
+)
+
+type dummyDiscovery struct {
+	id       string
+	interval time.Duration
+}
+
+func (d dummyDiscovery) GetId() string {
+	return d.id
+}
+
+func (d dummyDiscovery) Discover(ctx context.Context) (string, error) {
+	return "", nil
+}
+
+func (d dummyDiscovery) GetInterval() time.Duration {
+	return d.interval
+}
+
+func (d dummyDiscovery) GetTimeout() time.Duration {
+	return time.Minute
+}
+
+func newTestAgent(discoveries ...discovery.Discovery) *Agent {
+	ctx, ctxCancel := context.WithCancel(context.Background())
+	status := newStatusTracker()
+	for _, d := range discoveries {
+		status.addDiscovery(d.GetId())
+	}
+
+	client := &reloadableClient{client: &dummyCollectorClient{}}
+
+	return &Agent{
+		config:          &Config{},
+		client:          client,
+		collectorClient: &statusClient{Client: client, status: status},
+		discoveries:     discoveries,
+		loops:           make(map[string]*discoveryLoop),
+		status:          status,
+		ctx:             ctx,
+		ctxCancel:       ctxCancel,
+	}
+}
+
+func TestAgentReloadDiscoveries(t *testing.T) {
+	a := newTestAgent(
+		dummyDiscovery{id: "changed", interval: time.Hour},
+		dummyDiscovery{id: "unchanged", interval: time.Hour},
+		dummyDiscovery{id: "removed", interval: time.Hour},
+	)
+
+	stopped := make(chan struct{})
+	go func() {
+		a.Start()
+		close(stopped)
+	}()
+
+	assert.Eventually(t, func() bool {
+		a.loopsMutex.Lock()
+		defer a.loopsMutex.Unlock()
+		return a.started
+	}, time.Second, 10*time.Millisecond)
+
+	changedLoop := a.loops["changed"]
+	unchangedLoop := a.loops["unchanged"]
+	removedLoop := a.loops["removed"]
+
+	a.loopsMutex.Lock()
+	a.reloadDiscoveries([]discovery.Discovery{
+		dummyDiscovery{id: "changed", interval: 2 * time.Hour},
+		dummyDiscovery{id: "unchanged", interval: time.Hour},
+		dummyDiscovery{id: "added", interval: time.Hour},
+	})
+	a.loopsMutex.Unlock()
+
+	assert.Len(t, a.loops, 3)
+	assert.Same(t, unchangedLoop, a.loops["unchanged"])
+	assert.NotSame(t, changedLoop, a.loops["changed"])
+	assert.Equal(t, 2*time.Hour, a.loops["changed"].discovery.GetInterval())
+	assert.Contains(t, a.loops, "added")
+	assert.NotContains(t, a.loops, "removed")
+	assert.Len(t, a.discoveries, 3)
+
+	for _, loop := range []*discoveryLoop{changedLoop, removedLoop} {
+		select {
+		case <-loop.done:
+		default:
+			t.Error("the loop was not stopped")
+		}
+	}
+
+	s := getStatus(t, a.status)
+	assert.Contains(t, s.Discoveries, "added")
+	assert.NotContains(t, s.Discoveries, "removed")
+
+	a.Stop()
+	<-stopped
+}
+
+func TestAgentReloadStopped(t *testing.T) {
+	a := newTestAgent()
+	a.Stop()
+
+	err := a.Reload(&Config{})
+	assert.EqualError(t, err, "the agent is stopped")
+}
+
+type closingCollectorClient struct {
+	dummyCollectorClient
+	closed bool
+}
+
+func (c *closingCollectorClient) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestAgentReloadClosesClient(t *testing.T) {
+	a := newTestAgent()
+	defer a.Stop()
+
+	// The certificate of the running client was requested and is waiting for its approval
+	pending := &closingCollectorClient{}
+	a.client.client = pending
+
+	certsDir := t.TempDir()
+	err := a.Reload(&Config{
+		DiscoveriesConfig: &discovery.DiscoveriesConfig{
+			DiscoveriesPeriodsConfig:  &discovery.DiscoveriesPeriodConfig{},
+			DiscoveriesTimeoutsConfig: &discovery.DiscoveriesTimeoutConfig{},
+			CollectorConfig: &collector.Config{
+				CollectorHost: "localhost",
+				CollectorPort: 1,
+				EnablemTLS:    true,
+				AutoCert:      true,
+				CA:            "../test/certs/ca-cert.pem",
+				Cert:          path.Join(certsDir, "agent.pem"),
+				Key:           path.Join(certsDir, "agent-key.pem"),
+				AgentID:       "some-agent",
+			},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, pending.closed)
+	assert.NotEqual(t, pending, a.client.client)
+
+	// Stops waiting for the certificate of the reloaded client
+	a.client.replace(&dummyCollectorClient{})
+}
+
+type heartbeatsCollectorClient struct {
+	dummyCollectorClient
+	summary *StatusSummary
+}
+
+func (c *heartbeatsCollectorClient) Heartbeat(status interface{}) error {
+	c.summary = status.(*StatusSummary)
+	return nil
+}
+
+func TestAgentReloadInstanceName(t *testing.T) {
+	a := newTestAgent()
+	defer a.Stop()
+
+	discoveriesConfig := &discovery.DiscoveriesConfig{
+		DiscoveriesPeriodsConfig:  &discovery.DiscoveriesPeriodConfig{},
+		DiscoveriesTimeoutsConfig: &discovery.DiscoveriesTimeoutConfig{},
+		CollectorConfig: &collector.Config{
+			CollectorHost: "localhost",
+			CollectorPort: 8081,
+		},
+	}
+	a.config = &Config{InstanceName: "host1", DiscoveriesConfig: discoveriesConfig}
+
+	heartbeats := &heartbeatsCollectorClient{}
+	a.client.client = heartbeats
+
+	err := a.Reload(&Config{InstanceName: "host1-renamed", DiscoveriesConfig: discoveriesConfig})
+	assert.NoError(t, err)
+	assert.Same(t, heartbeats, a.client.client)
+
+	assert.NoError(t, a.heartbeat())
+	assert.Equal(t, "host1-renamed", heartbeats.summary.Hostname)
+}
+
+type blockingCollectorClient struct {
+	dummyCollectorClient
+	release chan struct{}
+}
+
+func (c *blockingCollectorClient) Publish(discoveryType string, payload interface{}) error {
+	<-c.release
+	return c.err
+}
+
+func TestReloadableClientReplace(t *testing.T) {
+	current := &blockingCollectorClient{release: make(chan struct{})}
+	client := &reloadableClient{client: current}
+
+	published := make(chan error)
+	go func() {
+		published <- client.Publish("host_discovery", nil)
+	}()
+
+	// Give the publication the time to start before replacing the client
+	time.Sleep(50 * time.Millisecond)
+
+	replaced := make(chan struct{})
+	go func() {
+		client.replace(&dummyCollectorClient{err: assert.AnError})
+		close(replaced)
+	}()
+
+	select {
+	case <-replaced:
+		t.Fatal("the client was replaced during an in-flight publication")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(current.release)
+	assert.NoError(t, <-published)
+	<-replaced
+
+	assert.Equal(t, assert.AnError, client.Publish("host_discovery", nil))
+}
@@ -1,6 +1,7 @@
 package discovery
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -16,6 +17,7 @@ type CloudDiscovery struct {
 	id              string
 	collectorClient collector.Client
 	interval        time.Duration
+	timeout         time.Duration
 }
 
 func NewCloudDiscovery(collectorClient collector.Client, config DiscoveriesConfig) Discovery {
@@ -23,6 +25,7 @@ func NewCloudDiscovery(collectorClient collector.Client, config DiscoveriesConfi
 	d.collectorClient = collectorClient
 	d.id = CloudDiscoveryId
 	d.interval = config.DiscoveriesPeriodsConfig.Cloud
+	d.timeout = config.DiscoveriesTimeoutsConfig.Cloud
 
 	return d
 }
@@ -35,8 +38,12 @@ func (d CloudDiscovery) GetInterval() time.Duration {
 	return d.interval
 }
 
-func (d CloudDiscovery) Discover() (string, error) {
-	cloudData, err := cloud.NewCloudInstance()
+func (d CloudDiscovery) GetTimeout() time.Duration {
+	return d.timeout
+}
+
+func (d CloudDiscovery) Discover(ctx context.Context) (string, error) {
+	cloudData, err := cloud.NewCloudInstance(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -1,6 +1,7 @@
 package discovery
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -17,6 +18,7 @@ type ClusterDiscovery struct {
 	id              string
 	collectorClient collector.Client
 	interval        time.Duration
+	timeout         time.Duration
 }
 
 func NewClusterDiscovery(collectorClient collector.Client, config DiscoveriesConfig) Discovery {
@@ -24,6 +26,7 @@ func NewClusterDiscovery(collectorClient collector.Client, config DiscoveriesCon
 	d.collectorClient = collectorClient
 	d.id = ClusterDiscoveryId
 	d.interval = config.DiscoveriesPeriodsConfig.Cluster
+	d.timeout = config.DiscoveriesTimeoutsConfig.Cluster
 
 	return d
 }
@@ -36,9 +39,13 @@ func (d ClusterDiscovery) GetInterval() time.Duration {
 	return d.interval
 }
 
+func (d ClusterDiscovery) GetTimeout() time.Duration {
+	return d.timeout
+}
+
 // Execute one iteration of a discovery and publish the results to the collector
-func (d ClusterDiscovery) Discover() (string, error) {
-	cluster, err := cluster.NewCluster()
+func (d ClusterDiscovery) Discover(ctx context.Context) (string, error) {
+	cluster, err := cluster.NewCluster(ctx)
 	if err != nil {
 		return "No HA cluster discovered on this host", nil
 	}
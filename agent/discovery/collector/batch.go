@@ -0,0 +1,71 @@
+package collector
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+type batchItem struct {
+	discoveryType string
+	body          []byte
+	done          chan error
+}
+
+// batcher groups the request bodies published within a time window, so they are sent
+// to the collector in a single request. Publishing blocks until the batch is flushed,
+// so the callers still get the delivery result of their own payload
+type batcher struct {
+	sync.Mutex
+	window  time.Duration
+	pending []*batchItem
+	flush   func(items []*batchItem)
+}
+
+func newBatcher(window time.Duration, flush func(items []*batchItem)) *batcher {
+	return &batcher{
+		window: window,
+		flush:  flush,
+	}
+}
+
+// add queues a request body in the current batch, starting a new one if needed,
+// and waits for the batch to be flushed
+func (b *batcher) add(discoveryType string, body []byte) error {
+	item := &batchItem{
+		discoveryType: discoveryType,
+		body:          body,
+		done:          make(chan error, 1),
+	}
+
+	b.Lock()
+	b.pending = append(b.pending, item)
+	if len(b.pending) == 1 {
+		time.AfterFunc(b.window, b.flushPending)
+	}
+	b.Unlock()
+
+	return <-item.done
+}
+
+func (b *batcher) flushPending() {
+	b.Lock()
+	items := b.pending
+	b.pending = nil
+	b.Unlock()
+
+	b.flush(items)
+}
+
+// newBatchRequestBody builds the envelope posted to the collector for several request bodies
+func newBatchRequestBody(agentID string, items []*batchItem) ([]byte, error) {
+	bodies := make([]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		bodies = append(bodies, json.RawMessage(item.body))
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"agent_id": agentID,
+		"batch":    bodies,
+	})
+}
@@ -0,0 +1,161 @@
+package collector
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+	_ "github.com/trento-project/trento/test"
+	"github.com/trento-project/trento/test/helpers"
+)
+
+type BatchTestSuite struct {
+	suite.Suite
+}
+
+func TestBatchTestSuite(t *testing.T) {
+	suite.Run(t, new(BatchTestSuite))
+}
+
+func (suite *BatchTestSuite) SetupTest() {
+	fileSystem = afero.NewMemMapFs()
+
+	afero.WriteFile(fileSystem, machineIdPath, []byte(DummyMachineID), 0644)
+}
+
+// publishAll publishes concurrently a payload for each discovery type, returning the results by type
+func (suite *BatchTestSuite) publishAll(collectorClient *client, payloads map[string]string) map[string]error {
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	results := make(map[string]error)
+
+	for discoveryType, payload := range payloads {
+		wg.Add(1)
+		go func(discoveryType string, payload string) {
+			defer wg.Done()
+			err := collectorClient.Publish(discoveryType, payload)
+
+			lock.Lock()
+			results[discoveryType] = err
+			lock.Unlock()
+		}(discoveryType, payload)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func (suite *BatchTestSuite) TestBatch_PublishedInASingleRequest() {
+	collectorClient, err := NewCollectorClient(&Config{
+		CollectorHost: "localhost",
+		CollectorPort: 8081,
+		BatchWindow:   100 * time.Millisecond,
+	})
+	suite.NoError(err)
+
+	var requests []map[string]interface{}
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		bodyBytes, _ := ioutil.ReadAll(req.Body)
+
+		var body map[string]interface{}
+		json.Unmarshal(bodyBytes, &body)
+		requests = append(requests, body)
+
+		return &http.Response{
+			StatusCode: 202,
+		}
+	})
+
+	results := suite.publishAll(collectorClient, map[string]string{
+		"host_discovery":       "host-1",
+		"ha_cluster_discovery": "cluster-1",
+	})
+
+	suite.NoError(results["host_discovery"])
+	suite.NoError(results["ha_cluster_discovery"])
+
+	suite.Len(requests, 1)
+	suite.Equal(DummyAgentID, requests[0]["agent_id"])
+
+	batch := requests[0]["batch"].([]interface{})
+	suite.Len(batch, 2)
+
+	received := make(map[string]interface{})
+	for _, event := range batch {
+		event := event.(map[string]interface{})
+		suite.Equal(DummyAgentID, event["agent_id"])
+		received[event["discovery_type"].(string)] = event["payload"]
+	}
+	suite.Equal(map[string]interface{}{
+		"host_discovery":       "host-1",
+		"ha_cluster_discovery": "cluster-1",
+	}, received)
+}
+
+func (suite *BatchTestSuite) TestBatch_SinglePayloadNotEnveloped() {
+	collectorClient, err := NewCollectorClient(&Config{
+		CollectorHost: "localhost",
+		CollectorPort: 8081,
+		BatchWindow:   10 * time.Millisecond,
+	})
+	suite.NoError(err)
+
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		bodyBytes, _ := ioutil.ReadAll(req.Body)
+
+		expectedBody, _ := json.Marshal(map[string]interface{}{
+			"agent_id":       DummyAgentID,
+			"discovery_type": "host_discovery",
+			"payload":        "host-1",
+		})
+		suite.EqualValues(expectedBody, bodyBytes)
+
+		return &http.Response{
+			StatusCode: 202,
+		}
+	})
+
+	suite.NoError(collectorClient.Publish("host_discovery", "host-1"))
+}
+
+func (suite *BatchTestSuite) TestBatch_SpooledPerDiscoveryType() {
+	collectorClient, err := NewCollectorClient(&Config{
+		CollectorHost: "localhost",
+		CollectorPort: 8081,
+		BatchWindow:   100 * time.Millisecond,
+		Outbox: &OutboxConfig{
+			Path:       dummyOutboxPath,
+			Policy:     OutboxPolicyOrdered,
+			MaxEntries: 10,
+		},
+	})
+	suite.NoError(err)
+
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+		}
+	})
+
+	results := suite.publishAll(collectorClient, map[string]string{
+		"host_discovery":       "host-1",
+		"ha_cluster_discovery": "cluster-1",
+	})
+
+	suite.ErrorIs(results["host_discovery"], errSpooled)
+	suite.ErrorIs(results["ha_cluster_discovery"], errSpooled)
+
+	entries, _ := collectorClient.outbox.entries()
+	suite.Len(entries, 2)
+
+	var spooledTypes []string
+	for _, entry := range entries {
+		spooledTypes = append(spooledTypes, entry.discoveryType)
+	}
+	suite.ElementsMatch([]string{"host_discovery", "ha_cluster_discovery"}, spooledTypes)
+}
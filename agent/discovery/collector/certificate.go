@@ -0,0 +1,361 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/trento-project/trento/internal/pki"
+)
+
+// certificatePollInterval is the time between checks of the approval of a certificate request,
+// and between renewal attempts after a failed one
+var certificatePollInterval = 30 * time.Second
+
+const certificateStatusIssued = "issued"
+
+// certificateTmpExtension is appended to the certificate and key files while they are written
+const certificateTmpExtension = ".tmp"
+
+type issuedCertificate struct {
+	Status      string `json:"status"`
+	Certificate string `json:"certificate"`
+}
+
+// clientCertificate holds the client certificate issued by the internal CA of the server.
+// It is replaced in place when renewed, so the following TLS handshakes use the new one without restarting the agent
+type clientCertificate struct {
+	sync.RWMutex
+	certificate  *tls.Certificate
+	leaf         *x509.Certificate
+	renewing     sync.Mutex
+	nextRenewal  time.Time
+	renewalRetry time.Time
+}
+
+// get is the tls.Config GetClientCertificate callback. No certificate is sent until one is issued
+func (cc *clientCertificate) get(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	cc.RLock()
+	defer cc.RUnlock()
+
+	if cc.certificate == nil {
+		return &tls.Certificate{}, nil
+	}
+
+	return cc.certificate, nil
+}
+
+func (cc *clientCertificate) set(certPEM []byte, keyPEM []byte) error {
+	certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return err
+	}
+
+	leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+	if err != nil {
+		return err
+	}
+
+	cc.Lock()
+	defer cc.Unlock()
+
+	cc.certificate = &certificate
+	cc.leaf = leaf
+	// Renew once two thirds of the certificate lifetime have passed
+	cc.nextRenewal = leaf.NotBefore.Add(leaf.NotAfter.Sub(leaf.NotBefore) * 2 / 3)
+	cc.renewalRetry = time.Time{}
+
+	return nil
+}
+
+func (cc *clientCertificate) getLeaf() *x509.Certificate {
+	cc.RLock()
+	defer cc.RUnlock()
+
+	return cc.leaf
+}
+
+func (cc *clientCertificate) renewalDue(now time.Time) bool {
+	cc.RLock()
+	defer cc.RUnlock()
+
+	return cc.leaf != nil && now.After(cc.nextRenewal) && now.After(cc.renewalRetry)
+}
+
+func (cc *clientCertificate) retryRenewalLater(now time.Time) {
+	cc.Lock()
+	defer cc.Unlock()
+
+	cc.renewalRetry = now.Add(certificatePollInterval)
+}
+
+// loadCertificate reads the client certificate previously issued by the internal CA. It tells whether
+// a new one has to be requested, as well as when the stored one expired, since it can not be renewed
+func (c *client) loadCertificate() (bool, error) {
+	certPEM, err := afero.ReadFile(fileSystem, c.config.Cert)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "could not read the agent certificate")
+	}
+
+	keyPEM, err := afero.ReadFile(fileSystem, c.config.Key)
+	if err != nil {
+		return false, errors.Wrap(err, "could not read the agent key")
+	}
+
+	err = c.certificate.set(certPEM, keyPEM)
+	if err != nil {
+		return false, errors.Wrap(err, "could not load the agent certificate")
+	}
+
+	leaf := c.certificate.getLeaf()
+	switch {
+	case leaf.Subject.CommonName != c.agentID:
+		log.Warnf("The stored certificate belongs to agent %s, not to this agent", leaf.Subject.CommonName)
+	case time.Now().After(leaf.NotAfter):
+		log.Warnf("The agent certificate expired on %s", leaf.NotAfter)
+	default:
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// obtainCertificate requests a new client certificate and waits for its approval, retrying the request
+// if the collector rejects it. It only returns once the certificate is stored or the context is done
+func (c *client) obtainCertificate(ctx context.Context) error {
+	csrPEM, keyPEM, err := pki.NewCertificateRequest(c.agentID)
+	if err != nil {
+		return err
+	}
+
+	for {
+		err = c.requestCertificate(csrPEM)
+		if err == nil {
+			break
+		}
+
+		log.Warnf("Could not request the agent certificate: %s", err)
+
+		if err := sleepContext(ctx, certificatePollInterval); err != nil {
+			return err
+		}
+	}
+
+	log.Infof("Certificate requested for agent %s, waiting for its approval on the server", c.agentID)
+
+	certPEM, err := c.waitCertificate(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = c.storeCertificate(certPEM, keyPEM)
+	if err != nil {
+		return err
+	}
+
+	// Kept alive connections were established without a client certificate
+	c.httpClient.CloseIdleConnections()
+
+	log.Infof("Agent certificate issued, stored in %s", c.config.Cert)
+
+	return nil
+}
+
+// requestCertificate submits a certificate signing request, to be approved on the server. The request
+// is authenticated with the agent credentials if enrolled, as only then the server replaces a previous one
+func (c *client) requestCertificate(csrPEM []byte) error {
+	requestBody, err := json.Marshal(map[string]string{
+		"agent_id": c.agentID,
+		"csr":      string(csrPEM),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := c.newRequest("/api/certificates", bytes.NewBuffer(requestBody))
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusConflict {
+		return fmt.Errorf("the collector already holds a certificate request or certificate of agent %s, "+
+			"which must be deleted by an administrator", c.agentID)
+	}
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("the certificate request was rejected by the collector. Status: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// waitCertificate polls the collector until the certificate request is approved or the context is done
+func (c *client) waitCertificate(ctx context.Context) ([]byte, error) {
+	url := fmt.Sprintf("%s/api/certificates/%s", c.getBaseURL(), c.agentID)
+
+	for {
+		certificate, err := c.getCertificate(url)
+		if err != nil {
+			log.Warnf("Could not check the certificate request status: %s", err)
+		} else if certificate.Status == certificateStatusIssued {
+			return []byte(certificate.Certificate), nil
+		}
+
+		if err := sleepContext(ctx, certificatePollInterval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sleepContext waits for the given duration, returning earlier with the context error once it is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (c *client) getCertificate(url string) (*issuedCertificate, error) {
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var certificate issuedCertificate
+	err = json.NewDecoder(resp.Body).Decode(&certificate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &certificate, nil
+}
+
+// renewCertificateIfDue renews the client certificate once most of its lifetime has passed,
+// authenticating the renewal with the current certificate. Failed renewals are retried later
+func (c *client) renewCertificateIfDue() {
+	if c.certificate == nil || !c.certificate.renewalDue(time.Now()) {
+		return
+	}
+
+	c.certificate.renewing.Lock()
+	defer c.certificate.renewing.Unlock()
+
+	// Already renewed by a concurrent request
+	if !c.certificate.renewalDue(time.Now()) {
+		return
+	}
+
+	err := c.renewCertificate()
+	if err != nil {
+		log.Warnf("Could not renew the agent certificate, expiring on %s: %s", c.certificate.getLeaf().NotAfter, err)
+		c.certificate.retryRenewalLater(time.Now())
+		return
+	}
+
+	log.Infof("Agent certificate renewed, now expiring on %s", c.certificate.getLeaf().NotAfter)
+}
+
+func (c *client) renewCertificate() error {
+	csrPEM, keyPEM, err := pki.NewCertificateRequest(c.agentID)
+	if err != nil {
+		return err
+	}
+
+	requestBody, err := json.Marshal(map[string]string{"csr": string(csrPEM)})
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/api/certificates/%s/renew", c.getBaseURL(), c.agentID)
+	resp, err := c.httpClient.Post(url, "application/json", bytes.NewBuffer(requestBody))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("the certificate renewal was rejected by the collector. Status: %d", resp.StatusCode)
+	}
+
+	var certificate issuedCertificate
+	err = json.NewDecoder(resp.Body).Decode(&certificate)
+	if err != nil {
+		return err
+	}
+
+	err = c.storeCertificate([]byte(certificate.Certificate), keyPEM)
+	if err != nil {
+		return err
+	}
+
+	// Kept alive connections were authenticated with the previous certificate
+	c.httpClient.CloseIdleConnections()
+
+	return nil
+}
+
+// storeCertificate stores the client certificate to be loaded on the next start, and then replaces the one in use.
+// Both files are written aside and renamed into place, so that a failed write doesn't leave a key not matching the certificate
+func (c *client) storeCertificate(certPEM []byte, keyPEM []byte) error {
+	_, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return errors.Wrap(err, "the collector returned an invalid certificate")
+	}
+
+	files := []struct {
+		path    string
+		content []byte
+	}{
+		{c.config.Key, keyPEM},
+		{c.config.Cert, certPEM},
+	}
+
+	for _, file := range files {
+		err = fileSystem.MkdirAll(path.Dir(file.path), 0700)
+		if err == nil {
+			err = afero.WriteFile(fileSystem, file.path+certificateTmpExtension, file.content, 0600)
+		}
+		if err != nil {
+			return errors.Wrap(err, "could not store the agent certificate")
+		}
+	}
+
+	for _, file := range files {
+		err = fileSystem.Rename(file.path+certificateTmpExtension, file.path)
+		if err != nil {
+			return errors.Wrap(err, "could not store the agent certificate")
+		}
+	}
+
+	return c.certificate.set(certPEM, keyPEM)
+}
@@ -0,0 +1,282 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/internal/pki"
+	_ "github.com/trento-project/trento/test"
+	"github.com/trento-project/trento/test/helpers"
+)
+
+const (
+	dummyCertPath = "/etc/trento/certs/agent.pem"
+	dummyKeyPath  = "/etc/trento/certs/agent-key.pem"
+)
+
+type CertificateTestSuite struct {
+	suite.Suite
+	server        *httptest.Server
+	ca            *pki.CertificateAuthority
+	csr           string
+	polls         int
+	requests      int
+	renewals      int
+	validity      time.Duration
+	rejectRenewal bool
+	neverApprove  bool
+}
+
+func TestCertificateTestSuite(t *testing.T) {
+	suite.Run(t, new(CertificateTestSuite))
+}
+
+func (suite *CertificateTestSuite) SetupTest() {
+	fileSystem = afero.NewMemMapFs()
+	certificatePollInterval = 10 * time.Millisecond
+
+	afero.WriteFile(fileSystem, machineIdPath, []byte(DummyMachineID), 0644)
+
+	ca, err := pki.NewCertificateAuthority(helpers.NewCertificateAuthorityPEM(suite.T()))
+	suite.NoError(err)
+
+	suite.ca = ca
+	suite.csr = ""
+	suite.polls = 0
+	suite.requests = 0
+	suite.renewals = 0
+	suite.validity = time.Hour
+	suite.rejectRenewal = false
+	suite.neverApprove = false
+	suite.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		certificatesPath := "/api/certificates/" + DummyAgentID
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/certificates":
+			var request map[string]string
+			json.NewDecoder(r.Body).Decode(&request)
+			suite.requests++
+			suite.csr = request["csr"]
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodGet && r.URL.Path == certificatesPath:
+			// Approved on the second poll
+			suite.polls++
+			if suite.polls == 1 || suite.neverApprove {
+				fmt.Fprint(w, `{"status":"pending"}`)
+				return
+			}
+			certPEM, _, _ := suite.ca.Sign([]byte(suite.csr), DummyAgentID, suite.validity)
+			json.NewEncoder(w).Encode(map[string]string{"status": "issued", "certificate": string(certPEM)})
+		case r.URL.Path == certificatesPath+"/renew":
+			suite.renewals++
+			if suite.rejectRenewal {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			var request map[string]string
+			json.NewDecoder(r.Body).Decode(&request)
+			certPEM, _, _ := suite.ca.Sign([]byte(request["csr"]), DummyAgentID, time.Hour)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]string{"status": "issued", "certificate": string(certPEM)})
+		case strings.HasSuffix(r.URL.Path, "/heartbeat"):
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func (suite *CertificateTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+// newClient builds a client over plain HTTP, so the certificate handling is tested without the TLS handshakes
+func (suite *CertificateTestSuite) newClient() *client {
+	host, port, _ := net.SplitHostPort(suite.server.Listener.Addr().String())
+	collectorPort, _ := strconv.Atoi(port)
+
+	c, err := NewCollectorClient(&Config{
+		CollectorHost: host,
+		CollectorPort: collectorPort,
+		Cert:          dummyCertPath,
+		Key:           dummyKeyPath,
+	})
+	suite.NoError(err)
+
+	c.certificate = &clientCertificate{}
+
+	return c
+}
+
+func (suite *CertificateTestSuite) storeCertificate(commonName string, validity time.Duration) {
+	csrPEM, keyPEM, err := pki.NewCertificateRequest(commonName)
+	suite.NoError(err)
+
+	certPEM, _, err := suite.ca.Sign(csrPEM, commonName, validity)
+	suite.NoError(err)
+
+	afero.WriteFile(fileSystem, dummyCertPath, certPEM, 0600)
+	afero.WriteFile(fileSystem, dummyKeyPath, keyPEM, 0600)
+}
+
+func (suite *CertificateTestSuite) TestCertificate_RequestOnFirstStart() {
+	c := suite.newClient()
+
+	loaded, err := c.loadCertificate()
+	suite.NoError(err)
+	suite.False(loaded)
+
+	err = c.obtainCertificate(context.Background())
+	suite.NoError(err)
+	suite.Equal(1, suite.requests)
+	suite.Equal(2, suite.polls)
+
+	certificate, err := c.certificate.get(nil)
+	suite.NoError(err)
+	suite.NotEmpty(certificate.Certificate)
+	suite.Equal(DummyAgentID, c.certificate.getLeaf().Subject.CommonName)
+
+	info, _ := fileSystem.Stat(dummyKeyPath)
+	suite.Equal("-rw-------", info.Mode().String())
+
+	// The stored certificate is used on the next start
+	c = suite.newClient()
+	loaded, err = c.loadCertificate()
+	suite.NoError(err)
+	suite.True(loaded)
+	suite.Equal(1, suite.requests)
+}
+
+func (suite *CertificateTestSuite) TestCertificate_StopWaitingApproval() {
+	suite.neverApprove = true
+
+	c := suite.newClient()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := c.obtainCertificate(ctx)
+	suite.ErrorIs(err, context.DeadlineExceeded)
+	suite.Equal(1, suite.requests)
+	suite.Greater(suite.polls, 1)
+	suite.Nil(c.certificate.getLeaf())
+}
+
+func (suite *CertificateTestSuite) TestCertificate_StopWaitingApprovalOnClose() {
+	suite.neverApprove = true
+
+	c := suite.newClient()
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	done := make(chan error)
+	go func() {
+		done <- c.obtainCertificate(ctx)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	suite.NoError(c.Close())
+
+	select {
+	case err := <-done:
+		suite.ErrorIs(err, context.Canceled)
+	case <-time.After(time.Second):
+		suite.Fail("still waiting for the certificate approval after closing the client")
+	}
+	suite.Nil(c.certificate.getLeaf())
+}
+
+func (suite *CertificateTestSuite) TestCertificate_NoCertificateBeforeIssued() {
+	certificate, err := (&clientCertificate{}).get(nil)
+
+	suite.NoError(err)
+	suite.Empty(certificate.Certificate)
+}
+
+func (suite *CertificateTestSuite) TestCertificate_StoredForOtherAgent() {
+	suite.storeCertificate("other-agent", time.Hour)
+
+	c := suite.newClient()
+
+	loaded, err := c.loadCertificate()
+	suite.NoError(err)
+	suite.False(loaded)
+
+	suite.NoError(c.obtainCertificate(context.Background()))
+	suite.Equal(1, suite.requests)
+	suite.Equal(DummyAgentID, c.certificate.getLeaf().Subject.CommonName)
+}
+
+func (suite *CertificateTestSuite) TestCertificate_RenewBeforeExpiry() {
+	// The certificates are valid since a few minutes ago, so a short validity makes the renewal due
+	suite.storeCertificate(DummyAgentID, time.Minute)
+
+	c := suite.newClient()
+	loaded, err := c.loadCertificate()
+	suite.NoError(err)
+	suite.True(loaded)
+	expiring := c.certificate.getLeaf().NotAfter
+
+	suite.NoError(c.Heartbeat(nil))
+	suite.Equal(1, suite.renewals)
+	suite.True(c.certificate.getLeaf().NotAfter.After(expiring))
+
+	stored, _ := afero.ReadFile(fileSystem, dummyCertPath)
+	block, _ := pem.Decode(stored)
+	suite.Equal(c.certificate.getLeaf().Raw, block.Bytes)
+
+	suite.NoError(c.Heartbeat(nil))
+	suite.Equal(1, suite.renewals)
+}
+
+func (suite *CertificateTestSuite) TestCertificate_RenewalFailure() {
+	suite.storeCertificate(DummyAgentID, time.Minute)
+	suite.rejectRenewal = true
+	certificatePollInterval = time.Hour
+
+	c := suite.newClient()
+	loaded, err := c.loadCertificate()
+	suite.NoError(err)
+	suite.True(loaded)
+	expiring := c.certificate.getLeaf().NotAfter
+
+	suite.NoError(c.Heartbeat(nil))
+	suite.NoError(c.Heartbeat(nil))
+	suite.Equal(1, suite.renewals)
+	suite.Equal(expiring, c.certificate.getLeaf().NotAfter)
+}
+
+func (suite *CertificateTestSuite) TestCertificate_RenewalNotStored() {
+	suite.storeCertificate(DummyAgentID, time.Minute)
+	certificatePollInterval = time.Hour
+
+	c := suite.newClient()
+	loaded, err := c.loadCertificate()
+	suite.NoError(err)
+	suite.True(loaded)
+	expiring := c.certificate.getLeaf()
+	storedCert, _ := afero.ReadFile(fileSystem, dummyCertPath)
+	storedKey, _ := afero.ReadFile(fileSystem, dummyKeyPath)
+
+	// The renewed certificate can not be written, so both the stored and the in use ones are kept
+	fileSystem = afero.NewReadOnlyFs(fileSystem)
+
+	suite.NoError(c.Heartbeat(nil))
+	suite.Equal(1, suite.renewals)
+	suite.Equal(expiring, c.certificate.getLeaf())
+
+	cert, _ := afero.ReadFile(fileSystem, dummyCertPath)
+	key, _ := afero.ReadFile(fileSystem, dummyKeyPath)
+	suite.Equal(storedCert, cert)
+	suite.Equal(storedKey, key)
+}
@@ -0,0 +1,57 @@
+package collector
+
+import (
+	"sync"
+	"time"
+)
+
+type publishedPayload struct {
+	hash       string
+	fullSentAt time.Time
+}
+
+// payloadTracker keeps track of the last payload published for each discovery type,
+// so that unchanged payloads are not sent again until the resync period expires
+type payloadTracker struct {
+	sync.Mutex
+	resyncPeriod time.Duration
+	published    map[string]publishedPayload
+}
+
+func newPayloadTracker(resyncPeriod time.Duration) *payloadTracker {
+	return &payloadTracker{
+		resyncPeriod: resyncPeriod,
+		published:    make(map[string]publishedPayload),
+	}
+}
+
+// isUnchanged tells whether the payload hash matches the last published one,
+// and a full resync is not due yet
+func (t *payloadTracker) isUnchanged(discoveryType string, hash string) bool {
+	t.Lock()
+	defer t.Unlock()
+
+	published, ok := t.published[discoveryType]
+	if !ok {
+		return false
+	}
+
+	return published.hash == hash && time.Since(published.fullSentAt) < t.resyncPeriod
+}
+
+func (t *payloadTracker) track(discoveryType string, hash string) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.published[discoveryType] = publishedPayload{
+		hash:       hash,
+		fullSentAt: time.Now(),
+	}
+}
+
+func (t *payloadTracker) forget(discoveryType string) {
+	t.Lock()
+	defer t.Unlock()
+
+	delete(t.published, discoveryType)
+}
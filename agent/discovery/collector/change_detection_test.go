@@ -0,0 +1,115 @@
+package collector
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+	_ "github.com/trento-project/trento/test"
+	"github.com/trento-project/trento/test/helpers"
+)
+
+type ChangeDetectionTestSuite struct {
+	suite.Suite
+	collectorClient *client
+	requests        []string
+	unchangedStatus int
+}
+
+func TestChangeDetectionTestSuite(t *testing.T) {
+	suite.Run(t, new(ChangeDetectionTestSuite))
+}
+
+func (suite *ChangeDetectionTestSuite) SetupTest() {
+	fileSystem = afero.NewMemMapFs()
+
+	afero.WriteFile(fileSystem, machineIdPath, []byte(DummyMachineID), 0644)
+
+	collectorClient, err := NewCollectorClient(&Config{
+		CollectorHost: "localhost",
+		CollectorPort: 8081,
+		ResyncPeriod:  time.Hour,
+	})
+	suite.NoError(err)
+
+	suite.requests = nil
+	suite.unchangedStatus = http.StatusAccepted
+
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		suite.requests = append(suite.requests, req.URL.Path)
+
+		if req.URL.Path == "/api/collect/unchanged" {
+			return &http.Response{
+				StatusCode: suite.unchangedStatus,
+			}
+		}
+
+		return &http.Response{
+			StatusCode: 202,
+		}
+	})
+
+	suite.collectorClient = collectorClient
+}
+
+func (suite *ChangeDetectionTestSuite) TestChangeDetection_UnchangedPayloadIsNotSent() {
+	suite.NoError(suite.collectorClient.Publish("host_discovery", "host"))
+	suite.NoError(suite.collectorClient.Publish("host_discovery", "host"))
+	suite.NoError(suite.collectorClient.Publish("ha_cluster_discovery", "host"))
+	suite.NoError(suite.collectorClient.Publish("host_discovery", "changed host"))
+
+	suite.Equal([]string{
+		"/api/collect",
+		"/api/collect/unchanged",
+		"/api/collect",
+		"/api/collect",
+	}, suite.requests)
+}
+
+func (suite *ChangeDetectionTestSuite) TestChangeDetection_ResyncRequestedByTheCollector() {
+	suite.unchangedStatus = http.StatusConflict
+
+	suite.NoError(suite.collectorClient.Publish("host_discovery", "host"))
+	suite.NoError(suite.collectorClient.Publish("host_discovery", "host"))
+
+	suite.Equal([]string{
+		"/api/collect",
+		"/api/collect/unchanged",
+		"/api/collect",
+	}, suite.requests)
+}
+
+func (suite *ChangeDetectionTestSuite) TestChangeDetection_ResyncPeriodExpired() {
+	suite.NoError(suite.collectorClient.Publish("host_discovery", "host"))
+
+	suite.collectorClient.tracker.published["host_discovery"] = publishedPayload{
+		hash:       suite.collectorClient.tracker.published["host_discovery"].hash,
+		fullSentAt: time.Now().Add(-2 * time.Hour),
+	}
+
+	suite.NoError(suite.collectorClient.Publish("host_discovery", "host"))
+
+	suite.Equal([]string{
+		"/api/collect",
+		"/api/collect",
+	}, suite.requests)
+}
+
+func (suite *ChangeDetectionTestSuite) TestChangeDetection_FailedPublishIsNotTracked() {
+	suite.collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		suite.requests = append(suite.requests, req.URL.Path)
+		return &http.Response{
+			StatusCode: 500,
+		}
+	})
+
+	suite.Error(suite.collectorClient.Publish("host_discovery", "host"))
+	suite.Error(suite.collectorClient.Publish("host_discovery", "host"))
+
+	suite.Equal([]string{
+		"/api/collect",
+		"/api/collect",
+	}, suite.requests)
+}
@@ -2,15 +2,21 @@ package collector
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/trento-project/trento/internal"
 
@@ -19,13 +25,22 @@ import (
 
 type Client interface {
 	Publish(discoveryType string, payload interface{}) error
-	Heartbeat() error
+	Heartbeat(status interface{}) error
 }
 
 type client struct {
 	config     *Config
 	agentID    string
 	httpClient *http.Client
+	outbox     *outbox
+	tracker    *payloadTracker
+	batcher    *batcher
+	secret     string
+	// enrolling serializes the enrollment of the agent by the concurrent requests
+	enrolling   sync.Mutex
+	certificate *clientCertificate
+	// cancel stops the background work of the client, as waiting for the approval of its certificate
+	cancel context.CancelFunc
 }
 
 type Config struct {
@@ -35,17 +50,54 @@ type Config struct {
 	Cert          string
 	Key           string
 	CA            string
+	Outbox        *OutboxConfig
+	// ResyncPeriod is the period after which unchanged payloads are fully sent again.
+	// Change detection is disabled if zero
+	ResyncPeriod time.Duration
+	// Compression gzips the discovered data sent to the collector
+	Compression bool
+	// BatchWindow is the time window during which the discovered data is gathered
+	// to be sent in a single request. Batching is disabled if zero
+	BatchWindow time.Duration
+	// EnrollmentToken is the one-time token exchanged for the agent credentials on the first start
+	EnrollmentToken string
+	// CredentialsPath is the file where the agent credentials are stored once enrolled
+	CredentialsPath string
+	// AgentID overrides the agent identifier derived from the machine id, telling apart
+	// the hosts cloned from the same image
+	AgentID string
+	// AutoCert requests the mTLS client certificate from the internal CA of the server on the first start,
+	// storing it in Cert and Key, and renews it before it expires
+	AutoCert bool
+}
+
+type unexpectedStatusError struct {
+	statusCode int
+	message    string
+}
+
+func (e *unexpectedStatusError) Error() string {
+	return e.message
 }
 
 const machineIdPath = "/etc/machine-id"
 
+var errResyncRequested = errors.New("the collector requested a full resync")
+
 var fileSystem = afero.NewOsFs()
 
 func NewCollectorClient(config *Config) (*client, error) {
 	var tlsConfig *tls.Config
+	var certificate *clientCertificate
 	var err error
 
-	if config.EnablemTLS {
+	if config.EnablemTLS && config.AutoCert {
+		certificate = &clientCertificate{}
+		tlsConfig, err = getAutoCertTLSConfig(config.CA, certificate)
+		if err != nil {
+			return nil, err
+		}
+	} else if config.EnablemTLS {
 		tlsConfig, err = getTLSConfig(config.Cert, config.Key, config.CA)
 		if err != nil {
 			return nil, err
@@ -58,54 +110,258 @@ func NewCollectorClient(config *Config) (*client, error) {
 		},
 	}
 
-	machineIDBytes, err := afero.ReadFile(fileSystem, machineIdPath)
+	agentID, err := getAgentID(config.AgentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var outbox *outbox
+	if config.Outbox != nil && config.Outbox.Path != "" {
+		outbox, err = newOutbox(config.Outbox)
+		if err != nil {
+			return nil, err
+		}
+	}
 
+	var tracker *payloadTracker
+	if config.ResyncPeriod > 0 {
+		tracker = newPayloadTracker(config.ResyncPeriod)
+	}
+
+	c := &client{
+		config:      config,
+		httpClient:  httpClient,
+		agentID:     agentID,
+		outbox:      outbox,
+		tracker:     tracker,
+		certificate: certificate,
+	}
+
+	if config.BatchWindow > 0 {
+		c.batcher = newBatcher(config.BatchWindow, c.flushBatch)
+	}
+
+	err = c.loadCredentials()
 	if err != nil {
 		return nil, err
 	}
 
-	machineID := strings.TrimSpace(string(machineIDBytes))
+	if certificate != nil {
+		loaded, err := c.loadCertificate()
+		if err != nil {
+			return nil, err
+		}
 
-	agentID := uuid.NewSHA1(internal.TrentoNamespace, []byte(machineID))
+		// The requests are not authenticated with a certificate until the new one is approved
+		if !loaded {
+			ctx, cancel := context.WithCancel(context.Background())
+			c.cancel = cancel
 
-	return &client{
-		config:     config,
-		httpClient: httpClient,
-		agentID:    agentID.String(),
-	}, nil
+			go func() {
+				err := c.obtainCertificate(ctx)
+				if err != nil && ctx.Err() == nil {
+					log.Errorf("Could not get the agent certificate: %s", err)
+				}
+			}()
+		}
+	}
+
+	return c, nil
+}
+
+// Close stops the background work of the client, as waiting for the approval of its certificate,
+// and closes its idle connections. It must not be used afterwards
+func (c *client) Close() error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+
+	c.httpClient.CloseIdleConnections()
+
+	return nil
 }
 
 func (c *client) Publish(discoveryType string, payload interface{}) error {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	// The collector hashes the payload exactly as it is received
+	payloadHash := internal.Md5sum(string(payloadBytes))
+
+	if c.tracker != nil && c.tracker.isUnchanged(discoveryType, payloadHash) {
+		log.Debugf("Notifying unchanged %s to data collector", discoveryType)
+
+		err = c.notifyUnchanged(discoveryType, payloadHash)
+		if !errors.Is(err, errResyncRequested) {
+			return err
+		}
+
+		log.Infof("Data collector requested a full resync of %s", discoveryType)
+		c.tracker.forget(discoveryType)
+	}
+
 	log.Debugf("Sending %s to data collector", discoveryType)
 
-	requestBody, err := json.Marshal(map[string]interface{}{
-		"agent_id":       c.agentID,
-		"discovery_type": discoveryType,
-		"payload":        payload,
-	})
+	requestBody, err := newRequestBody(c.agentID, discoveryType, payloadBytes)
 	if err != nil {
 		return err
 	}
 
-	url := fmt.Sprintf("%s/api/collect", c.getBaseURL())
-	resp, err := c.httpClient.Post(url, "application/json", bytes.NewBuffer(requestBody))
+	if c.batcher == nil {
+		err = c.deliver(discoveryType, requestBody)
+	} else {
+		err = c.batcher.add(discoveryType, requestBody)
+	}
+
+	// A spooled payload is delivered as soon as the collector is back, so it counts as published
+	if c.tracker != nil && (err == nil || errors.Is(err, errSpooled)) {
+		c.tracker.track(discoveryType, payloadHash)
+	}
+
+	return err
+}
+
+// notifyUnchanged tells the collector that the payload of a discovery type did not change
+// since the last one published. The collector may ask for a full resync if it does not know about it
+func (c *client) notifyUnchanged(discoveryType string, payloadHash string) error {
+	notify := func() error {
+		requestBody, err := json.Marshal(map[string]interface{}{
+			"agent_id":       c.agentID,
+			"discovery_type": discoveryType,
+			"payload_hash":   payloadHash,
+		})
+		if err != nil {
+			return err
+		}
+
+		req, err := c.newRequest("/api/collect/unchanged", bytes.NewBuffer(requestBody))
+		if err != nil {
+			return err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		switch resp.StatusCode {
+		case http.StatusAccepted:
+			return nil
+		case http.StatusConflict, http.StatusNotFound:
+			// Collectors not supporting change detection answer with not found
+			return errResyncRequested
+		default:
+			return fmt.Errorf(
+				"something wrong happened while notifying unchanged data to the collector. Status: %d, Agent: %s, discovery: %s",
+				resp.StatusCode, c.agentID, discoveryType)
+		}
+	}
+
+	if c.outbox == nil {
+		return notify()
+	}
+
+	return c.outbox.afterReplay(c.postCollectedData, notify)
+}
+
+// deliver sends a request body to the collector, through the outbox if enabled
+func (c *client) deliver(discoveryType string, requestBody []byte) error {
+	if c.outbox == nil {
+		return c.postCollectedData(discoveryType, requestBody)
+	}
+
+	return c.outbox.deliver(discoveryType, requestBody, c.postCollectedData)
+}
+
+// flushBatch sends the request bodies gathered by the batcher in a single request
+func (c *client) flushBatch(items []*batchItem) {
+	if len(items) == 1 {
+		items[0].done <- c.deliver(items[0].discoveryType, items[0].body)
+		return
+	}
+
+	requestBody, err := newBatchRequestBody(c.agentID, items)
+	if err != nil {
+		for _, item := range items {
+			item.done <- err
+		}
+		return
+	}
+
+	log.Debugf("Sending a batch of %d payloads to data collector", len(items))
+
+	sendBatch := func() error {
+		return c.postCollectedData("batch", requestBody)
+	}
+
+	if c.outbox != nil {
+		c.outbox.deliverBatch(items, sendBatch, c.postCollectedData)
+		return
+	}
+
+	err = sendBatch()
+	for _, item := range items {
+		item.done <- err
+	}
+}
+
+func (c *client) postCollectedData(discoveryType string, requestBody []byte) error {
+	var body bytes.Buffer
+	if c.config.Compression {
+		writer := gzip.NewWriter(&body)
+		_, err := writer.Write(requestBody)
+		if err == nil {
+			err = writer.Close()
+		}
+		if err != nil {
+			return err
+		}
+	} else {
+		body.Write(requestBody)
+	}
+
+	req, err := c.newRequest("/api/collect", &body)
+	if err != nil {
+		return err
+	}
+	if c.config.Compression {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusAccepted {
-		return fmt.Errorf(
-			"something wrong happened while publishing data to the collector. Status: %d, Agent: %s, discovery: %s",
-			resp.StatusCode, c.agentID, discoveryType)
+		return &unexpectedStatusError{
+			statusCode: resp.StatusCode,
+			message: fmt.Sprintf(
+				"something wrong happened while publishing data to the collector. Status: %d, Agent: %s, discovery: %s",
+				resp.StatusCode, c.agentID, discoveryType),
+		}
 	}
 
 	return nil
 }
 
-func (c *client) Heartbeat() error {
-	url := fmt.Sprintf("%s/api/hosts/%s/heartbeat", c.getBaseURL(), c.agentID)
-	resp, err := c.httpClient.Post(url, "application/json", nil)
+// Heartbeat tells the server that the agent is alive, reporting the given agent status
+func (c *client) Heartbeat(status interface{}) error {
+	requestBody, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+
+	req, err := c.newRequest(fmt.Sprintf("/api/hosts/%s/heartbeat", c.agentID), bytes.NewBuffer(requestBody))
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return err
 	}
@@ -118,6 +374,54 @@ func (c *client) Heartbeat() error {
 	return nil
 }
 
+// getAgentID derives the agent identifier from the host machine id, unless explicitly overridden
+func getAgentID(override string) (string, error) {
+	if override != "" {
+		return override, nil
+	}
+
+	machineIDBytes, err := afero.ReadFile(fileSystem, machineIdPath)
+	if err != nil {
+		return "", err
+	}
+
+	machineID := strings.TrimSpace(string(machineIDBytes))
+
+	return uuid.NewSHA1(internal.TrentoNamespace, []byte(machineID)).String(), nil
+}
+
+// newRequestBody builds the body posted to the collector for an already marshaled payload
+func newRequestBody(agentID string, discoveryType string, payloadBytes []byte) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"agent_id":       agentID,
+		"discovery_type": discoveryType,
+		"payload":        json.RawMessage(payloadBytes),
+	})
+}
+
+// newRequest builds a JSON request to the collector, authenticated with the agent credentials.
+// The agent is enrolled on the first request if an enrollment token is configured
+func (c *client) newRequest(path string, body io.Reader) (*http.Request, error) {
+	c.renewCertificateIfDue()
+
+	req, err := http.NewRequest(http.MethodPost, c.getBaseURL()+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := c.getSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		req.SetBasicAuth(c.agentID, secret)
+	}
+
+	return req, nil
+}
+
 func (c *client) getBaseURL() string {
 	protocol := "http"
 	if c.config.EnablemTLS {
@@ -127,12 +431,10 @@ func (c *client) getBaseURL() string {
 }
 
 func getTLSConfig(cert, key, ca string) (*tls.Config, error) {
-	caCert, err := ioutil.ReadFile(ca)
+	caCertPool, err := getCACertPool(ca)
 	if err != nil {
 		return nil, err
 	}
-	caCertPool := x509.NewCertPool()
-	caCertPool.AppendCertsFromPEM(caCert)
 
 	certificate, err := tls.LoadX509KeyPair(cert, key)
 	if err != nil {
@@ -144,3 +446,28 @@ func getTLSConfig(cert, key, ca string) (*tls.Config, error) {
 		Certificates: []tls.Certificate{certificate},
 	}, nil
 }
+
+// getAutoCertTLSConfig builds a TLS configuration using the client certificate issued by the internal CA,
+// which can be replaced without restarting the agent
+func getAutoCertTLSConfig(ca string, certificate *clientCertificate) (*tls.Config, error) {
+	caCertPool, err := getCACertPool(ca)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		RootCAs:              caCertPool,
+		GetClientCertificate: certificate.get,
+	}, nil
+}
+
+func getCACertPool(ca string) (*x509.CertPool, error) {
+	caCert, err := ioutil.ReadFile(ca)
+	if err != nil {
+		return nil, err
+	}
+	caCertPool := x509.NewCertPool()
+	caCertPool.AppendCertsFromPEM(caCert)
+
+	return caCertPool, nil
+}
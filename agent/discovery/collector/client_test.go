@@ -1,6 +1,7 @@
 package collector
 
 import (
+	"compress/gzip"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
@@ -130,6 +131,39 @@ func (suite *CollectorClientTestSuite) TestCollectorClient_PublishingFailure() {
 	suite.Error(err)
 }
 
+func (suite *CollectorClientTestSuite) TestCollectorClient_PublishingCompressed() {
+	collectorClient, err := NewCollectorClient(&Config{
+		CollectorHost: "localhost",
+		CollectorPort: 8081,
+		Compression:   true,
+	})
+
+	suite.NoError(err)
+
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		suite.Equal("gzip", req.Header.Get("Content-Encoding"))
+
+		reader, err := gzip.NewReader(req.Body)
+		suite.NoError(err)
+		bodyBytes, _ := ioutil.ReadAll(reader)
+
+		expectedBody, _ := json.Marshal(map[string]interface{}{
+			"agent_id":       DummyAgentID,
+			"discovery_type": "some_discovery_type",
+			"payload":        "some discovered data",
+		})
+		suite.EqualValues(expectedBody, bodyBytes)
+
+		return &http.Response{
+			StatusCode: 202,
+		}
+	})
+
+	err = collectorClient.Publish("some_discovery_type", "some discovered data")
+
+	suite.NoError(err)
+}
+
 func (suite *CollectorClientTestSuite) TestCollectorClient_Heartbeat() {
 	collectorClient, err := NewCollectorClient(&Config{
 		EnablemTLS:    true,
@@ -144,11 +178,15 @@ func (suite *CollectorClientTestSuite) TestCollectorClient_Heartbeat() {
 
 	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
 		suite.Equal(req.URL.String(), fmt.Sprintf("https://localhost:8081/api/hosts/%s/heartbeat", DummyAgentID))
+
+		bodyBytes, _ := ioutil.ReadAll(req.Body)
+		suite.JSONEq(`{"version":"1.0.0"}`, string(bodyBytes))
+
 		return &http.Response{
 			StatusCode: 204,
 		}
 	})
-	err = collectorClient.Heartbeat()
+	err = collectorClient.Heartbeat(map[string]string{"version": "1.0.0"})
 
 	suite.NoError(err)
 }
@@ -0,0 +1,123 @@
+package collector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+type credentials struct {
+	AgentID string `json:"agent_id"`
+	Secret  string `json:"secret"`
+}
+
+// loadCredentials reads the credentials stored by a previous enrollment. Without credentials,
+// requests are not authenticated until the agent is enrolled with the enrollment token
+func (c *client) loadCredentials() error {
+	if c.config.CredentialsPath == "" {
+		return nil
+	}
+
+	content, err := afero.ReadFile(fileSystem, c.config.CredentialsPath)
+	if os.IsNotExist(err) {
+		if c.config.EnrollmentToken == "" {
+			log.Warn("The agent is not enrolled, its requests are not authenticated")
+		}
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "could not read the agent credentials")
+	}
+
+	var stored credentials
+	err = json.Unmarshal(content, &stored)
+	if err != nil {
+		return errors.Wrap(err, "could not parse the agent credentials")
+	}
+
+	if stored.AgentID != c.agentID {
+		log.Warnf("The stored credentials belong to agent %s, not to this agent", stored.AgentID)
+		return nil
+	}
+
+	c.secret = stored.Secret
+
+	return nil
+}
+
+// getSecret returns the agent secret, enrolling the agent with the enrollment token first if
+// it is not enrolled yet. A failed enrollment is retried on the next request
+func (c *client) getSecret() (string, error) {
+	c.enrolling.Lock()
+	defer c.enrolling.Unlock()
+
+	if c.secret != "" || c.config.CredentialsPath == "" || c.config.EnrollmentToken == "" {
+		return c.secret, nil
+	}
+
+	secret, err := c.enroll()
+	if err != nil {
+		return "", errors.Wrap(err, "could not enroll the agent")
+	}
+
+	content, err := json.Marshal(&credentials{AgentID: c.agentID, Secret: secret})
+	if err != nil {
+		return "", err
+	}
+
+	err = fileSystem.MkdirAll(path.Dir(c.config.CredentialsPath), 0700)
+	if err != nil {
+		return "", errors.Wrap(err, "could not store the agent credentials")
+	}
+
+	err = afero.WriteFile(fileSystem, c.config.CredentialsPath, content, 0600)
+	if err != nil {
+		return "", errors.Wrap(err, "could not store the agent credentials")
+	}
+
+	c.secret = secret
+	log.Infof("Agent successfully enrolled, credentials stored in %s", c.config.CredentialsPath)
+
+	return secret, nil
+}
+
+// enroll exchanges the enrollment token for the agent secret
+func (c *client) enroll() (string, error) {
+	requestBody, err := json.Marshal(map[string]string{
+		"agent_id": c.agentID,
+		"token":    c.config.EnrollmentToken,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/api/enroll", c.getBaseURL())
+	resp, err := c.httpClient.Post(url, "application/json", bytes.NewBuffer(requestBody))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("the enrollment was rejected by the collector. Status: %d", resp.StatusCode)
+	}
+
+	var enrollment credentials
+	err = json.NewDecoder(resp.Body).Decode(&enrollment)
+	if err != nil {
+		return "", err
+	}
+
+	if enrollment.Secret == "" {
+		return "", fmt.Errorf("the collector did not return the agent secret")
+	}
+
+	return enrollment.Secret, nil
+}
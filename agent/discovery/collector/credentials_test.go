@@ -0,0 +1,155 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+	_ "github.com/trento-project/trento/test"
+)
+
+const dummyCredentialsPath = "/var/lib/trento/agent-credentials.json"
+
+type CredentialsTestSuite struct {
+	suite.Suite
+	server      *httptest.Server
+	enrollments int
+	authorized  []bool
+}
+
+func TestCredentialsTestSuite(t *testing.T) {
+	suite.Run(t, new(CredentialsTestSuite))
+}
+
+func (suite *CredentialsTestSuite) SetupTest() {
+	fileSystem = afero.NewMemMapFs()
+
+	afero.WriteFile(fileSystem, machineIdPath, []byte(DummyMachineID), 0644)
+
+	suite.enrollments = 0
+	suite.authorized = nil
+	suite.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/enroll":
+			var request map[string]string
+			json.NewDecoder(r.Body).Decode(&request)
+			if request["token"] != "valid-token" || request["agent_id"] != DummyAgentID {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			suite.enrollments++
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"secret":"some-secret"}`)
+		default:
+			agentID, secret, ok := r.BasicAuth()
+			suite.authorized = append(suite.authorized, ok && agentID == DummyAgentID && secret == "some-secret")
+			if strings.HasSuffix(r.URL.Path, "/heartbeat") {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+}
+
+func (suite *CredentialsTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *CredentialsTestSuite) newConfig(enrollmentToken string) *Config {
+	host, port, _ := net.SplitHostPort(suite.server.Listener.Addr().String())
+	collectorPort, _ := strconv.Atoi(port)
+
+	return &Config{
+		CollectorHost:   host,
+		CollectorPort:   collectorPort,
+		EnrollmentToken: enrollmentToken,
+		CredentialsPath: dummyCredentialsPath,
+	}
+}
+
+func (suite *CredentialsTestSuite) TestCredentials_EnrollOnFirstRequest() {
+	collectorClient, err := NewCollectorClient(suite.newConfig("valid-token"))
+	suite.NoError(err)
+	suite.Equal(0, suite.enrollments)
+
+	suite.NoError(collectorClient.Publish("host_discovery", "host-1"))
+	suite.Equal(1, suite.enrollments)
+	suite.Equal([]bool{true}, suite.authorized)
+
+	content, _ := afero.ReadFile(fileSystem, dummyCredentialsPath)
+	suite.JSONEq(fmt.Sprintf(`{"agent_id":"%s","secret":"some-secret"}`, DummyAgentID), string(content))
+
+	info, _ := fileSystem.Stat(dummyCredentialsPath)
+	suite.Equal("-rw-------", info.Mode().String())
+
+	suite.NoError(collectorClient.Heartbeat(nil))
+	suite.Equal(1, suite.enrollments)
+
+	// The token is not used anymore once enrolled
+	collectorClient, err = NewCollectorClient(suite.newConfig("valid-token"))
+	suite.NoError(err)
+	suite.NoError(collectorClient.Heartbeat(nil))
+	suite.Equal(1, suite.enrollments)
+	suite.Equal([]bool{true, true, true}, suite.authorized)
+}
+
+func (suite *CredentialsTestSuite) TestCredentials_EnrollmentRejected() {
+	collectorClient, err := NewCollectorClient(suite.newConfig("used-token"))
+	suite.NoError(err)
+
+	err = collectorClient.Heartbeat(nil)
+	suite.EqualError(err, "could not enroll the agent: the enrollment was rejected by the collector. Status: 401")
+	suite.Empty(suite.authorized)
+
+	exists, _ := afero.Exists(fileSystem, dummyCredentialsPath)
+	suite.False(exists)
+}
+
+func (suite *CredentialsTestSuite) TestCredentials_CollectorUnreachable() {
+	config := suite.newConfig("valid-token")
+	suite.server.Close()
+
+	collectorClient, err := NewCollectorClient(config)
+	suite.NoError(err)
+
+	err = collectorClient.Heartbeat(nil)
+	suite.Error(err)
+
+	exists, _ := afero.Exists(fileSystem, dummyCredentialsPath)
+	suite.False(exists)
+
+	// The enrollment is retried once the collector is back
+	suite.SetupTest()
+	collectorClient.config = suite.newConfig("valid-token")
+
+	suite.NoError(collectorClient.Heartbeat(nil))
+	suite.Equal(1, suite.enrollments)
+	suite.Equal([]bool{true}, suite.authorized)
+}
+
+func (suite *CredentialsTestSuite) TestCredentials_NotEnrolled() {
+	collectorClient, err := NewCollectorClient(suite.newConfig(""))
+	suite.NoError(err)
+
+	suite.NoError(collectorClient.Publish("host_discovery", "host-1"))
+	suite.Equal([]bool{false}, suite.authorized)
+}
+
+func (suite *CredentialsTestSuite) TestCredentials_OtherAgentCredentials() {
+	afero.WriteFile(fileSystem, dummyCredentialsPath, []byte(`{"agent_id":"other-agent","secret":"other-secret"}`), 0600)
+
+	collectorClient, err := NewCollectorClient(suite.newConfig("valid-token"))
+	suite.NoError(err)
+
+	suite.NoError(collectorClient.Heartbeat(nil))
+	suite.Equal(1, suite.enrollments)
+	suite.Equal([]bool{true}, suite.authorized)
+}
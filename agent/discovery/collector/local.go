@@ -0,0 +1,71 @@
+package collector
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// LocalData is a discovered payload kept by the local client, along with the
+// request body that would have been posted to the collector
+type LocalData struct {
+	DiscoveryType string
+	Payload       json.RawMessage
+	RequestBody   []byte
+}
+
+// LocalClient is a Client which does not send anything to the collector, but keeps
+// the published payloads exactly as they would be sent, so they can be inspected locally
+type LocalClient struct {
+	sync.Mutex
+	agentID   string
+	published []LocalData
+}
+
+// NewLocalClient creates a local client, the agent id is derived from the machine id unless overridden
+func NewLocalClient(agentIDOverride string) (*LocalClient, error) {
+	agentID, err := getAgentID(agentIDOverride)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LocalClient{agentID: agentID}, nil
+}
+
+func (c *LocalClient) AgentID() string {
+	return c.agentID
+}
+
+// Published returns the payloads published so far, in publishing order
+func (c *LocalClient) Published() []LocalData {
+	c.Lock()
+	defer c.Unlock()
+
+	return append([]LocalData(nil), c.published...)
+}
+
+func (c *LocalClient) Publish(discoveryType string, payload interface{}) error {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	requestBody, err := newRequestBody(c.agentID, discoveryType, payloadBytes)
+	if err != nil {
+		return err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	c.published = append(c.published, LocalData{
+		DiscoveryType: discoveryType,
+		Payload:       payloadBytes,
+		RequestBody:   requestBody,
+	})
+
+	return nil
+}
+
+func (c *LocalClient) Heartbeat(status interface{}) error {
+	return nil
+}
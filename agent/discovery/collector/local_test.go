@@ -0,0 +1,51 @@
+package collector
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	_ "github.com/trento-project/trento/test"
+)
+
+func TestLocalClient_Publish(t *testing.T) {
+	fileSystem = afero.NewMemMapFs()
+
+	afero.WriteFile(fileSystem, machineIdPath, []byte(DummyMachineID), 0644)
+
+	localClient, err := NewLocalClient("")
+	assert.NoError(t, err)
+	assert.Equal(t, DummyAgentID, localClient.AgentID())
+
+	discoveredDataPayload := struct {
+		FieldA string
+	}{
+		FieldA: "some discovered field",
+	}
+
+	assert.NoError(t, localClient.Publish("the_discovery_type", discoveredDataPayload))
+	assert.NoError(t, localClient.Heartbeat(nil))
+
+	requestBody, _ := json.Marshal(map[string]interface{}{
+		"agent_id":       DummyAgentID,
+		"discovery_type": "the_discovery_type",
+		"payload":        discoveredDataPayload,
+	})
+
+	published := localClient.Published()
+	assert.Len(t, published, 1)
+	assert.Equal(t, "the_discovery_type", published[0].DiscoveryType)
+	assert.JSONEq(t, `{"FieldA": "some discovered field"}`, string(published[0].Payload))
+	assert.Equal(t, requestBody, published[0].RequestBody)
+}
+
+func TestLocalClient_AgentIDOverride(t *testing.T) {
+	fileSystem = afero.NewMemMapFs()
+
+	afero.WriteFile(fileSystem, machineIdPath, []byte(DummyMachineID), 0644)
+
+	localClient, err := NewLocalClient("1f2e3d4c-5b6a-4978-8695-a4b3c2d1e0f9")
+	assert.NoError(t, err)
+	assert.Equal(t, "1f2e3d4c-5b6a-4978-8695-a4b3c2d1e0f9", localClient.AgentID())
+}
@@ -0,0 +1,339 @@
+package collector
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+const (
+	// OutboxPolicyOrdered keeps every spooled payload of a discovery type, up to the configured limit
+	OutboxPolicyOrdered = "ordered"
+	// OutboxPolicyLatest keeps only the most recent spooled payload of a discovery type
+	OutboxPolicyLatest = "latest"
+
+	DefaultOutboxMaxEntries = 100
+
+	outboxEntryExtension = ".json"
+	outboxTmpExtension   = ".tmp"
+)
+
+type OutboxConfig struct {
+	Path       string
+	Policy     string
+	MaxEntries int
+}
+
+type outboxEntry struct {
+	name          string
+	discoveryType string
+	spooledAt     time.Time
+}
+
+type sendFunc func(discoveryType string, body []byte) error
+
+var (
+	errSpooled   = errors.New("payload spooled in the outbox")
+	errReplaying = errors.New("the spooled payloads are being replayed")
+)
+
+// outbox is a bounded on-disk spool where the request bodies that could not be delivered
+// to the collector are stored, so they can be replayed in order once the collector is back.
+// Each payload is stored in its own file, named after the spooling time and the discovery type.
+// The lock only guards the spool files, the payloads are sent without holding it
+type outbox struct {
+	sync.Mutex
+	config    *OutboxConfig
+	lastStamp int64
+	// replaying tells whether the spooled payloads are being replayed, only one replay runs at a time
+	replaying bool
+}
+
+func newOutbox(config *OutboxConfig) (*outbox, error) {
+	if config.Policy != OutboxPolicyOrdered && config.Policy != OutboxPolicyLatest {
+		return nil, fmt.Errorf("unknown outbox policy: %s", config.Policy)
+	}
+
+	if config.MaxEntries < 1 {
+		return nil, fmt.Errorf("invalid outbox max entries: %d, should be at least 1", config.MaxEntries)
+	}
+
+	err := fileSystem.MkdirAll(config.Path, 0700)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create the outbox directory")
+	}
+
+	return &outbox{config: config}, nil
+}
+
+// deliver replays the spooled payloads and then sends the given one.
+// If the collector cannot be reached, the payload is spooled to be delivered later on,
+// as well as if other payloads are being replayed, so it is delivered after them
+func (o *outbox) deliver(discoveryType string, body []byte, send sendFunc) error {
+	err := o.replay(send)
+	if err == nil {
+		err = send(discoveryType, body)
+		if err == nil || !isRetriable(err) {
+			return err
+		}
+	}
+
+	return o.spool(discoveryType, body, err)
+}
+
+// deliverBatch replays the spooled payloads and then sends the given batch at once.
+// If the collector cannot be reached, each payload of the batch is spooled on its own,
+// so the outbox policy still applies per discovery type
+func (o *outbox) deliverBatch(items []*batchItem, sendBatch func() error, send sendFunc) {
+	err := o.replay(send)
+	if err == nil {
+		err = sendBatch()
+		if err == nil || !isRetriable(err) {
+			for _, item := range items {
+				item.done <- err
+			}
+			return
+		}
+	}
+
+	for _, item := range items {
+		item.done <- o.spool(item.discoveryType, item.body, err)
+	}
+}
+
+// spool stores a payload that could not be delivered because of the given error
+func (o *outbox) spool(discoveryType string, body []byte, err error) error {
+	o.Lock()
+	defer o.Unlock()
+
+	spoolErr := o.enqueue(discoveryType, body)
+	if spoolErr != nil {
+		return errors.Wrapf(err, "could not spool the %s payload: %s", discoveryType, spoolErr)
+	}
+
+	if errors.Is(err, errReplaying) {
+		log.Debugf("%s payload spooled behind the payloads being replayed", discoveryType)
+	} else {
+		count, oldest := o.stats()
+		log.Warnf("Could not reach the collector, %s payload spooled. Outbox: %d entries, oldest spooled %s ago",
+			discoveryType, count, time.Since(oldest).Round(time.Second))
+	}
+
+	return errors.Wrapf(errSpooled, "could not deliver the %s payload: %s", discoveryType, err)
+}
+
+// afterReplay replays the spooled payloads and then runs the given operation,
+// which is not spooled if failing. The operation doesn't wait for a replay run by another delivery
+func (o *outbox) afterReplay(send sendFunc, operation func() error) error {
+	err := o.replay(send)
+	if err != nil && !errors.Is(err, errReplaying) {
+		return err
+	}
+
+	return operation()
+}
+
+// replay sends the spooled payloads in the order they were spooled, removing each of them once delivered.
+// The payloads spooled meanwhile are replayed as well. It stops at the first payload that could not be delivered,
+// and returns errReplaying without waiting if another replay is in progress
+func (o *outbox) replay(send sendFunc) error {
+	o.Lock()
+	if o.replaying {
+		o.Unlock()
+		return errReplaying
+	}
+
+	entries, err := o.entries()
+	if err != nil || len(entries) == 0 {
+		o.Unlock()
+		return err
+	}
+
+	o.replaying = true
+	o.Unlock()
+
+	log.Infof("Replaying %d spooled payloads to the collector, oldest spooled %s ago",
+		len(entries), time.Since(entries[0].spooledAt).Round(time.Second))
+
+	for err == nil {
+		err = o.replayEntry(entries[0], send)
+		if err != nil {
+			if isRetriable(err) {
+				log.Warnf("Replay interrupted, %d spooled payloads left in the outbox: %s", len(entries), err)
+			}
+			break
+		}
+
+		// The replay only ends once no payload is left, so that none spooled behind it is forgotten
+		o.Lock()
+		entries, err = o.entries()
+		if err == nil && len(entries) == 0 {
+			o.replaying = false
+			o.Unlock()
+
+			log.Infof("Outbox successfully replayed")
+			return nil
+		}
+		o.Unlock()
+	}
+
+	o.Lock()
+	o.replaying = false
+	o.Unlock()
+
+	return err
+}
+
+// replayEntry sends a spooled payload, removing it once delivered or rejected by the collector
+func (o *outbox) replayEntry(entry outboxEntry, send sendFunc) error {
+	entryPath := path.Join(o.config.Path, entry.name)
+
+	body, err := afero.ReadFile(fileSystem, entryPath)
+	// Dropped by the outbox policy meanwhile
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	err = send(entry.discoveryType, body)
+	if err != nil && isRetriable(err) {
+		return err
+	}
+
+	if err != nil {
+		log.Errorf("Discarding spooled %s payload rejected by the collector: %s", entry.discoveryType, err)
+	}
+
+	o.Lock()
+	defer o.Unlock()
+
+	err = fileSystem.Remove(entryPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+func (o *outbox) enqueue(discoveryType string, body []byte) error {
+	stamp := time.Now().UnixNano()
+	if stamp <= o.lastStamp {
+		stamp = o.lastStamp + 1
+	}
+	o.lastStamp = stamp
+
+	name := fmt.Sprintf("%020d-%s%s", stamp, discoveryType, outboxEntryExtension)
+	tmpPath := path.Join(o.config.Path, name+outboxTmpExtension)
+
+	err := afero.WriteFile(fileSystem, tmpPath, body, 0600)
+	if err != nil {
+		return err
+	}
+
+	err = fileSystem.Rename(tmpPath, path.Join(o.config.Path, name))
+	if err != nil {
+		return err
+	}
+
+	return o.prune(discoveryType)
+}
+
+// prune drops the oldest payloads of a discovery type exceeding the limit set by the outbox policy
+func (o *outbox) prune(discoveryType string) error {
+	maxEntries := o.config.MaxEntries
+	if o.config.Policy == OutboxPolicyLatest {
+		maxEntries = 1
+	}
+
+	entries, err := o.entries()
+	if err != nil {
+		return err
+	}
+
+	var sameType []outboxEntry
+	for _, entry := range entries {
+		if entry.discoveryType == discoveryType {
+			sameType = append(sameType, entry)
+		}
+	}
+
+	for i := 0; i < len(sameType)-maxEntries; i++ {
+		log.Debugf("Dropping spooled %s payload %s from the outbox", discoveryType, sameType[i].name)
+		err := fileSystem.Remove(path.Join(o.config.Path, sameType[i].name))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// stats returns the number of spooled payloads and the spooling time of the oldest one
+func (o *outbox) stats() (int, time.Time) {
+	entries, err := o.entries()
+	if err != nil || len(entries) == 0 {
+		return 0, time.Now()
+	}
+
+	return len(entries), entries[0].spooledAt
+}
+
+// entries lists the spooled payloads, oldest first
+func (o *outbox) entries() ([]outboxEntry, error) {
+	files, err := afero.ReadDir(fileSystem, o.config.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []outboxEntry
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, outboxEntryExtension) {
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimSuffix(name, outboxEntryExtension), "-", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		stamp, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		entries = append(entries, outboxEntry{
+			name:          name,
+			discoveryType: parts[1],
+			spooledAt:     time.Unix(0, stamp),
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].name < entries[j].name
+	})
+
+	return entries, nil
+}
+
+// isRetriable tells whether a failed delivery is worth being retried later on.
+// Payloads rejected by the collector with a client error would be rejected again
+func isRetriable(err error) bool {
+	var statusErr *unexpectedStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.statusCode >= 500
+	}
+
+	return true
+}
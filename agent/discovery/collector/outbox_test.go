@@ -0,0 +1,201 @@
+package collector
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+	_ "github.com/trento-project/trento/test"
+	"github.com/trento-project/trento/test/helpers"
+)
+
+const dummyOutboxPath = "/var/lib/trento/outbox"
+
+type OutboxTestSuite struct {
+	suite.Suite
+}
+
+func TestOutboxTestSuite(t *testing.T) {
+	suite.Run(t, new(OutboxTestSuite))
+}
+
+func (suite *OutboxTestSuite) SetupTest() {
+	fileSystem = afero.NewMemMapFs()
+
+	afero.WriteFile(fileSystem, machineIdPath, []byte(DummyMachineID), 0644)
+}
+
+func (suite *OutboxTestSuite) newClient(policy string, maxEntries int) *client {
+	collectorClient, err := NewCollectorClient(&Config{
+		CollectorHost: "localhost",
+		CollectorPort: 8081,
+		Outbox: &OutboxConfig{
+			Path:       dummyOutboxPath,
+			Policy:     policy,
+			MaxEntries: maxEntries,
+		},
+	})
+	suite.NoError(err)
+
+	return collectorClient
+}
+
+// collectorStub answers with the given status code and records the payloads received by the collector
+func (suite *OutboxTestSuite) collectorStub(statusCode *int, received *[]string) http.RoundTripper {
+	return helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		if *statusCode == http.StatusAccepted {
+			bodyBytes, _ := ioutil.ReadAll(req.Body)
+
+			var body map[string]interface{}
+			json.Unmarshal(bodyBytes, &body)
+			*received = append(*received, body["payload"].(string))
+		}
+
+		return &http.Response{
+			StatusCode: *statusCode,
+		}
+	})
+}
+
+func (suite *OutboxTestSuite) TestOutbox_SpoolAndReplayInOrder() {
+	collectorClient := suite.newClient(OutboxPolicyOrdered, 10)
+
+	statusCode := http.StatusServiceUnavailable
+	var received []string
+	collectorClient.httpClient.Transport = suite.collectorStub(&statusCode, &received)
+
+	suite.Error(collectorClient.Publish("host_discovery", "host-1"))
+	suite.Error(collectorClient.Publish("ha_cluster_discovery", "cluster-1"))
+	suite.Error(collectorClient.Publish("host_discovery", "host-2"))
+
+	entries, _ := collectorClient.outbox.entries()
+	suite.Len(entries, 3)
+
+	statusCode = http.StatusAccepted
+	suite.NoError(collectorClient.Publish("host_discovery", "host-3"))
+
+	suite.Equal([]string{"host-1", "cluster-1", "host-2", "host-3"}, received)
+
+	entries, _ = collectorClient.outbox.entries()
+	suite.Len(entries, 0)
+}
+
+func (suite *OutboxTestSuite) TestOutbox_BoundedPerDiscoveryType() {
+	collectorClient := suite.newClient(OutboxPolicyOrdered, 2)
+
+	statusCode := http.StatusBadGateway
+	var received []string
+	collectorClient.httpClient.Transport = suite.collectorStub(&statusCode, &received)
+
+	collectorClient.Publish("host_discovery", "host-1")
+	collectorClient.Publish("host_discovery", "host-2")
+	collectorClient.Publish("ha_cluster_discovery", "cluster-1")
+	collectorClient.Publish("host_discovery", "host-3")
+
+	statusCode = http.StatusAccepted
+	suite.NoError(collectorClient.outbox.replay(collectorClient.postCollectedData))
+
+	suite.Equal([]string{"host-2", "cluster-1", "host-3"}, received)
+}
+
+func (suite *OutboxTestSuite) TestOutbox_LatestWins() {
+	collectorClient := suite.newClient(OutboxPolicyLatest, 10)
+
+	statusCode := http.StatusBadGateway
+	var received []string
+	collectorClient.httpClient.Transport = suite.collectorStub(&statusCode, &received)
+
+	collectorClient.Publish("host_discovery", "host-1")
+	collectorClient.Publish("ha_cluster_discovery", "cluster-1")
+	collectorClient.Publish("host_discovery", "host-2")
+
+	statusCode = http.StatusAccepted
+	suite.NoError(collectorClient.outbox.replay(collectorClient.postCollectedData))
+
+	suite.Equal([]string{"cluster-1", "host-2"}, received)
+}
+
+func (suite *OutboxTestSuite) TestOutbox_RejectedPayloadsAreNotSpooled() {
+	collectorClient := suite.newClient(OutboxPolicyOrdered, 10)
+
+	statusCode := http.StatusBadRequest
+	var received []string
+	collectorClient.httpClient.Transport = suite.collectorStub(&statusCode, &received)
+
+	suite.Error(collectorClient.Publish("host_discovery", "host-1"))
+
+	entries, _ := collectorClient.outbox.entries()
+	suite.Len(entries, 0)
+}
+
+func (suite *OutboxTestSuite) TestOutbox_ReplayDoesNotBlockOtherDeliveries() {
+	collectorClient := suite.newClient(OutboxPolicyOrdered, 10)
+
+	statusCode := http.StatusServiceUnavailable
+	var received []string
+	collectorClient.httpClient.Transport = suite.collectorStub(&statusCode, &received)
+
+	suite.Error(collectorClient.Publish("host_discovery", "host-1"))
+
+	// The collector is back, but slow to accept the replayed payload
+	var mu sync.Mutex
+	received = nil
+	replaying := make(chan struct{})
+	release := make(chan struct{})
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		if req.URL.Path == "/api/collect/unchanged" {
+			return &http.Response{StatusCode: http.StatusAccepted}
+		}
+
+		bodyBytes, _ := ioutil.ReadAll(req.Body)
+		var body map[string]interface{}
+		json.Unmarshal(bodyBytes, &body)
+
+		if body["payload"] == "host-1" {
+			close(replaying)
+			<-release
+		}
+
+		mu.Lock()
+		received = append(received, body["payload"].(string))
+		mu.Unlock()
+
+		return &http.Response{StatusCode: http.StatusAccepted}
+	})
+
+	published := make(chan error)
+	go func() {
+		published <- collectorClient.Publish("ha_cluster_discovery", "cluster-1")
+	}()
+	<-replaying
+
+	suite.NoError(collectorClient.notifyUnchanged("host_discovery", "some-hash"))
+	suite.ErrorIs(collectorClient.Publish("host_discovery", "host-2"), errSpooled)
+
+	close(release)
+	suite.NoError(<-published)
+
+	// The payload spooled during the replay is delivered by it, before the one that triggered it
+	suite.Equal([]string{"host-1", "host-2", "cluster-1"}, received)
+
+	entries, _ := collectorClient.outbox.entries()
+	suite.Len(entries, 0)
+}
+
+func (suite *OutboxTestSuite) TestOutbox_InvalidPolicy() {
+	_, err := NewCollectorClient(&Config{
+		CollectorHost: "localhost",
+		CollectorPort: 8081,
+		Outbox: &OutboxConfig{
+			Path:       dummyOutboxPath,
+			Policy:     "whatever",
+			MaxEntries: 10,
+		},
+	})
+
+	suite.Error(err)
+}
@@ -79,6 +79,24 @@ func (suite *PublishingTestSuite) TestCollectorClient_PublishingSubscriptionDisc
 	})
 }
 
+func (suite *PublishingTestSuite) TestCollectorClient_PublishingPackagesDiscovery() {
+	discoveryType := "packages_discovery"
+	discoveredPackages := mocks.NewDiscoveredPackagesMock()
+
+	suite.runDiscoveryScenario(discoveryType, discoveredPackages, func(requestBodyAgainstCollector string) {
+		suite.assertJsonMatchesJsonFileContent("./test/fixtures/discovery/packages/expected_published_packages_discovery.json", requestBodyAgainstCollector)
+	})
+}
+
+func (suite *PublishingTestSuite) TestCollectorClient_PublishingTuningDiscovery() {
+	discoveryType := "tuning_discovery"
+	discoveredTuning := mocks.NewDiscoveredTuningMock()
+
+	suite.runDiscoveryScenario(discoveryType, discoveredTuning, func(requestBodyAgainstCollector string) {
+		suite.assertJsonMatchesJsonFileContent("./test/fixtures/discovery/tuning/expected_published_tuning_discovery.json", requestBodyAgainstCollector)
+	})
+}
+
 func (suite *PublishingTestSuite) TestCollectorClient_PublishingSAPSystemDatabaseDiscovery() {
 	discoveryType := "sap_system_discovery"
 	discoveredSAPSystem := mocks.NewDiscoveredSAPSystemDatabaseMock()
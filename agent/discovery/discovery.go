@@ -1,6 +1,7 @@
 package discovery
 
 import (
+	"context"
 	"time"
 
 	"github.com/trento-project/trento/agent/discovery/collector"
@@ -12,19 +13,47 @@ type DiscoveriesPeriodConfig struct {
 	Cloud        time.Duration
 	Host         time.Duration
 	Subscription time.Duration
+	Packages     time.Duration
+	Tuning       time.Duration
+	Plugins      time.Duration
+}
+
+const DiscoveryMinTimeout time.Duration = 1 * time.Second
+
+type DiscoveriesTimeoutConfig struct {
+	Cluster      time.Duration
+	SAPSystem    time.Duration
+	Cloud        time.Duration
+	Host         time.Duration
+	Subscription time.Duration
+	Packages     time.Duration
+	Tuning       time.Duration
+	Plugins      time.Duration
+}
+
+// PluginConfig overrides the period and the timeout of a single plugin,
+// the plugins defaults apply to the ones left unset
+type PluginConfig struct {
+	Period  time.Duration `mapstructure:"period"`
+	Timeout time.Duration `mapstructure:"timeout"`
 }
 
 type DiscoveriesConfig struct {
-	SSHAddress               string
-	DiscoveriesPeriodsConfig *DiscoveriesPeriodConfig
-	CollectorConfig          *collector.Config
+	SSHAddress                string
+	PluginsDirectory          string
+	PluginsConfig             map[string]*PluginConfig
+	DiscoveriesPeriodsConfig  *DiscoveriesPeriodConfig
+	DiscoveriesTimeoutsConfig *DiscoveriesTimeoutConfig
+	CollectorConfig           *collector.Config
 }
 
 type Discovery interface {
 	// Returns an arbitrary unique string identifier of the discovery
 	GetId() string
-	// Execute the discovery mechanism
-	Discover() (string, error)
+	// Execute the discovery mechanism, which is aborted when the context is done
+	Discover(ctx context.Context) (string, error)
 	// Get interval
 	GetInterval() time.Duration
+	// Get the maximum duration of a discovery execution
+	GetTimeout() time.Duration
 }
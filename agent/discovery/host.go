@@ -1,6 +1,7 @@
 package discovery
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"os"
@@ -25,6 +26,7 @@ type HostDiscovery struct {
 	collectorClient collector.Client
 	host            string
 	interval        time.Duration
+	timeout         time.Duration
 }
 
 func NewHostDiscovery(collectorClient collector.Client, config DiscoveriesConfig) Discovery {
@@ -33,6 +35,7 @@ func NewHostDiscovery(collectorClient collector.Client, config DiscoveriesConfig
 	d.collectorClient = collectorClient
 	d.host, _ = os.Hostname()
 	d.interval = config.DiscoveriesPeriodsConfig.Host
+	d.timeout = config.DiscoveriesTimeoutsConfig.Host
 	d.sshAddress = config.SSHAddress
 	return d
 }
@@ -45,8 +48,12 @@ func (d HostDiscovery) GetInterval() time.Duration {
 	return d.interval
 }
 
+func (d HostDiscovery) GetTimeout() time.Duration {
+	return d.timeout
+}
+
 // Execute one iteration of a discovery and publish to the collector
-func (d HostDiscovery) Discover() (string, error) {
+func (d HostDiscovery) Discover(ctx context.Context) (string, error) {
 	ipAddresses, err := getHostIpAddresses()
 	if err != nil {
 		return "", err
@@ -54,12 +61,12 @@ func (d HostDiscovery) Discover() (string, error) {
 
 	host := hosts.DiscoveredHost{
 		SSHAddress:      d.sshAddress,
-		OSVersion:       getOSVersion(),
+		OSVersion:       getOSVersion(ctx),
 		HostIpAddresses: ipAddresses,
 		HostName:        d.host,
-		CPUCount:        getLogicalCPUs(),
-		SocketCount:     getCPUSocketCount(),
-		TotalMemoryMB:   getTotalMemoryMB(),
+		CPUCount:        getLogicalCPUs(ctx),
+		SocketCount:     getCPUSocketCount(ctx),
+		TotalMemoryMB:   getTotalMemoryMB(ctx),
 		AgentVersion:    version.Version,
 	}
 
@@ -95,32 +102,32 @@ func getHostIpAddresses() ([]string, error) {
 	return ipAddrList, nil
 }
 
-func getOSVersion() string {
-	infoStat, err := host.Info()
+func getOSVersion(ctx context.Context) string {
+	infoStat, err := host.InfoWithContext(ctx)
 	if err != nil {
 		log.Errorf("Error while getting host info: %s", err)
 	}
 	return infoStat.PlatformVersion
 }
 
-func getTotalMemoryMB() int {
-	v, err := mem.VirtualMemory()
+func getTotalMemoryMB(ctx context.Context) int {
+	v, err := mem.VirtualMemoryWithContext(ctx)
 	if err != nil {
 		log.Errorf("Error while getting memory info: %s", err)
 	}
 	return int(v.Total) / 1024 / 1024
 }
 
-func getLogicalCPUs() int {
-	logical, err := cpu.Counts(true)
+func getLogicalCPUs(ctx context.Context) int {
+	logical, err := cpu.CountsWithContext(ctx, true)
 	if err != nil {
 		log.Errorf("Error while getting logical CPU count: %s", err)
 	}
 	return logical
 }
 
-func getCPUSocketCount() int {
-	info, err := cpu.Info()
+func getCPUSocketCount(ctx context.Context) int {
+	info, err := cpu.InfoWithContext(ctx)
 
 	if err != nil {
 		log.Errorf("Error while getting CPU info: %s", err)
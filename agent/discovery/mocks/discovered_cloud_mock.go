@@ -25,3 +25,39 @@ func NewDiscoveredCloudMock() cloud.CloudInstance {
 		Metadata: metadata,
 	}
 }
+
+func NewDiscoveredAwsCloudMock() cloud.CloudInstance {
+	metadata := &cloud.AwsMetadata{}
+
+	jsonFile, err := os.Open("./test/fixtures/discovery/aws/aws_discovery.json")
+	if err != nil {
+		panic(err)
+	}
+	defer jsonFile.Close()
+	byteValue, _ := ioutil.ReadAll(jsonFile)
+
+	json.Unmarshal(byteValue, metadata)
+
+	return cloud.CloudInstance{
+		Provider: cloud.Aws,
+		Metadata: metadata,
+	}
+}
+
+func NewDiscoveredGcpCloudMock() cloud.CloudInstance {
+	metadata := &cloud.GcpMetadata{}
+
+	jsonFile, err := os.Open("./test/fixtures/discovery/gcp/gcp_discovery.json")
+	if err != nil {
+		panic(err)
+	}
+	defer jsonFile.Close()
+	byteValue, _ := ioutil.ReadAll(jsonFile)
+
+	json.Unmarshal(byteValue, metadata)
+
+	return cloud.CloudInstance{
+		Provider: cloud.Gcp,
+		Metadata: metadata,
+	}
+}
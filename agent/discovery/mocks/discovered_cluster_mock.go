@@ -1,14 +1,20 @@
 package mocks
 
-import "github.com/trento-project/trento/internal/cluster"
+import (
+	"context"
+
+	"github.com/trento-project/trento/internal/cluster"
+)
 
 func NewDiscoveredClusterMock() cluster.Cluster {
-	cluster, _ := cluster.NewClusterWithDiscoveryTools(&cluster.DiscoveryTools{
-		CibAdmPath:      "./test/fake_cibadmin.sh",
-		CrmmonAdmPath:   "./test/fake_crm_mon.sh",
-		CorosyncKeyPath: "./test/authkey",
-		SBDPath:         "./test/fake_sbd.sh",
-		SBDConfigPath:   "./test/sbd_config",
+	cluster, _ := cluster.NewClusterWithDiscoveryTools(context.Background(), &cluster.DiscoveryTools{
+		CibAdmPath:             "./test/fake_cibadmin.sh",
+		CrmmonAdmPath:          "./test/fake_crm_mon.sh",
+		CorosyncKeyPath:        "./test/authkey",
+		CorosyncConfigPath:     "./test/corosync.conf",
+		CorosyncQuorumtoolPath: "./test/fake_corosync_quorumtool.sh",
+		SBDPath:                "./test/fake_sbd.sh",
+		SBDConfigPath:          "./test/sbd_config",
 	})
 
 	return cluster
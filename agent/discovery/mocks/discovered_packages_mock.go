@@ -0,0 +1,24 @@
+package mocks
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/trento-project/trento/internal/packages"
+)
+
+func NewDiscoveredPackagesMock() packages.Packages {
+	var pkgs packages.Packages
+
+	jsonFile, err := os.Open("./test/fixtures/discovery/packages/packages_discovery.json")
+	if err != nil {
+		panic(err)
+	}
+	defer jsonFile.Close()
+	byteValue, _ := ioutil.ReadAll(jsonFile)
+
+	json.Unmarshal(byteValue, &pkgs)
+
+	return pkgs
+}
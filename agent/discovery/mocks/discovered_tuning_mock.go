@@ -0,0 +1,24 @@
+package mocks
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/trento-project/trento/internal/tuning"
+)
+
+func NewDiscoveredTuningMock() tuning.Tuning {
+	var t tuning.Tuning
+
+	jsonFile, err := os.Open("./test/fixtures/discovery/tuning/tuning_discovery.json")
+	if err != nil {
+		panic(err)
+	}
+	defer jsonFile.Close()
+	byteValue, _ := ioutil.ReadAll(jsonFile)
+
+	json.Unmarshal(byteValue, &t)
+
+	return t
+}
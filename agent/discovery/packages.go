@@ -0,0 +1,63 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/trento/agent/discovery/collector"
+	"github.com/trento-project/trento/internal/packages"
+)
+
+const PackagesDiscoveryId string = "packages_discovery"
+const PackagesDiscoveryMinPeriod time.Duration = 60 * time.Second
+
+type PackagesDiscovery struct {
+	id              string
+	collectorClient collector.Client
+	host            string
+	interval        time.Duration
+	timeout         time.Duration
+}
+
+func NewPackagesDiscovery(collectorClient collector.Client, config DiscoveriesConfig) Discovery {
+	d := PackagesDiscovery{}
+	d.id = PackagesDiscoveryId
+	d.collectorClient = collectorClient
+	d.host, _ = os.Hostname()
+	d.interval = config.DiscoveriesPeriodsConfig.Packages
+	d.timeout = config.DiscoveriesTimeoutsConfig.Packages
+
+	return d
+}
+
+func (d PackagesDiscovery) GetId() string {
+	return d.id
+}
+
+func (d PackagesDiscovery) GetInterval() time.Duration {
+	return d.interval
+}
+
+func (d PackagesDiscovery) GetTimeout() time.Duration {
+	return d.timeout
+}
+
+// Discover publishes the rpm packages installed in the host. The package list is sorted,
+// so the collector client only sends it again when something was installed, removed or updated
+func (d PackagesDiscovery) Discover(ctx context.Context) (string, error) {
+	packagesData, err := packages.NewPackages(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	err = d.collectorClient.Publish(d.id, packagesData)
+	if err != nil {
+		log.Debugf("Error while sending packages discovery to data collector: %s", err)
+		return "", err
+	}
+
+	return fmt.Sprintf("Packages (%d entries) discovered", len(packagesData)), nil
+}
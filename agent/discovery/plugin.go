@@ -0,0 +1,164 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/trento/agent/discovery/collector"
+	"github.com/trento-project/trento/internal"
+)
+
+const PluginDiscoveryMinPeriod time.Duration = 1 * time.Second
+
+var invalidPluginIdChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+var reservedDiscoveryIds = []string{
+	ClusterDiscoveryId,
+	SAPDiscoveryId,
+	CloudDiscoveryId,
+	SubscriptionDiscoveryId,
+	HostDiscoveryId,
+	PackagesDiscoveryId,
+	TuningDiscoveryId,
+}
+
+// PluginDiscovery runs an external executable and publishes the JSON document
+// it prints on the standard output, using the plugin id as discovery type
+type PluginDiscovery struct {
+	id              string
+	path            string
+	collectorClient collector.Client
+	interval        time.Duration
+	timeout         time.Duration
+}
+
+// LoadPlugins creates a discovery for each executable file found in the configured plugins directory
+func LoadPlugins(collectorClient collector.Client, config DiscoveriesConfig) ([]Discovery, error) {
+	var plugins []Discovery
+
+	if config.PluginsDirectory == "" {
+		return plugins, nil
+	}
+
+	entries, err := os.ReadDir(config.PluginsDirectory)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the plugins directory")
+	}
+
+	pluginFiles := make(map[string][]string)
+	var pluginIds []string
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+			log.Debugf("Skipping %s, not an executable file", entry.Name())
+			continue
+		}
+
+		id := pluginId(entry.Name())
+		if id == "" {
+			log.Errorf("Skipping plugin %s, its file name does not make a valid discovery type", entry.Name())
+			continue
+		}
+
+		if internal.Contains(reservedDiscoveryIds, id) {
+			log.Warnf("Skipping plugin %s, %s is a reserved discovery type", entry.Name(), id)
+			continue
+		}
+
+		if _, found := pluginFiles[id]; !found {
+			pluginIds = append(pluginIds, id)
+		}
+		pluginFiles[id] = append(pluginFiles[id], entry.Name())
+	}
+
+	for _, id := range pluginIds {
+		// None of the colliding plugins is loaded, as their payloads would overwrite each other
+		if len(pluginFiles[id]) > 1 {
+			log.Errorf("Skipping plugins %s, all of them have the %s discovery type", strings.Join(pluginFiles[id], ", "), id)
+			continue
+		}
+
+		plugin := NewPluginDiscovery(filepath.Join(config.PluginsDirectory, pluginFiles[id][0]), collectorClient, config)
+
+		log.Infof("Plugin %s loaded with discovery type %s", pluginFiles[id][0], id)
+		plugins = append(plugins, plugin)
+	}
+
+	return plugins, nil
+}
+
+func NewPluginDiscovery(path string, collectorClient collector.Client, config DiscoveriesConfig) Discovery {
+	d := PluginDiscovery{}
+	d.id = pluginId(path)
+	d.path = path
+	d.collectorClient = collectorClient
+	d.interval = config.DiscoveriesPeriodsConfig.Plugins
+	d.timeout = config.DiscoveriesTimeoutsConfig.Plugins
+
+	if pluginConfig, found := config.PluginsConfig[d.id]; found && pluginConfig != nil {
+		if pluginConfig.Period != 0 {
+			d.interval = pluginConfig.Period
+		}
+		if pluginConfig.Timeout != 0 {
+			d.timeout = pluginConfig.Timeout
+		}
+	}
+
+	return d
+}
+
+// pluginId builds the discovery type out of the plugin file name without extension,
+// e.g. /usr/etc/trento/plugins/Storage-Layout.sh becomes storage_layout
+func pluginId(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	name = invalidPluginIdChars.ReplaceAllString(strings.ToLower(name), "_")
+
+	return strings.Trim(name, "_")
+}
+
+func (d PluginDiscovery) GetId() string {
+	return d.id
+}
+
+func (d PluginDiscovery) GetInterval() time.Duration {
+	return d.interval
+}
+
+func (d PluginDiscovery) GetTimeout() time.Duration {
+	return d.timeout
+}
+
+func (d PluginDiscovery) Discover(ctx context.Context) (string, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.Command(d.path)
+	cmd.Stderr = &stderr
+
+	output, err := internal.CommandOutput(ctx, cmd)
+	if err != nil {
+		return "", errors.Wrapf(err, "plugin %s failed: %s", d.path, strings.TrimSpace(stderr.String()))
+	}
+
+	if !json.Valid(output) {
+		return "", fmt.Errorf("plugin %s did not print a valid JSON document", d.path)
+	}
+
+	err = d.collectorClient.Publish(d.id, json.RawMessage(output))
+	if err != nil {
+		log.Debugf("Error while sending %s plugin discovery to data collector: %s", d.id, err)
+		return "", err
+	}
+
+	return fmt.Sprintf("Plugin %s successfully discovered", d.id), nil
+}
@@ -0,0 +1,161 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type publishedPluginData struct {
+	discoveryType string
+	payload       interface{}
+}
+
+type fakeCollectorClient struct {
+	published []publishedPluginData
+}
+
+func (c *fakeCollectorClient) Publish(discoveryType string, payload interface{}) error {
+	c.published = append(c.published, publishedPluginData{discoveryType, payload})
+	return nil
+}
+
+func (c *fakeCollectorClient) Heartbeat(status interface{}) error {
+	return nil
+}
+
+type PluginDiscoveryTestSuite struct {
+	suite.Suite
+	pluginsDirectory string
+	collectorClient  *fakeCollectorClient
+	config           DiscoveriesConfig
+}
+
+func TestPluginDiscoveryTestSuite(t *testing.T) {
+	suite.Run(t, new(PluginDiscoveryTestSuite))
+}
+
+func (suite *PluginDiscoveryTestSuite) SetupTest() {
+	suite.pluginsDirectory = suite.T().TempDir()
+	suite.collectorClient = &fakeCollectorClient{}
+	suite.config = DiscoveriesConfig{
+		PluginsDirectory: suite.pluginsDirectory,
+		DiscoveriesPeriodsConfig: &DiscoveriesPeriodConfig{
+			Plugins: 60 * time.Second,
+		},
+		DiscoveriesTimeoutsConfig: &DiscoveriesTimeoutConfig{
+			Plugins: 30 * time.Second,
+		},
+	}
+}
+
+func (suite *PluginDiscoveryTestSuite) writePlugin(name string, script string, perm os.FileMode) string {
+	path := filepath.Join(suite.pluginsDirectory, name)
+	suite.NoError(os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), perm))
+
+	return path
+}
+
+func (suite *PluginDiscoveryTestSuite) TestLoadPlugins() {
+	suite.writePlugin("Storage-Layout.sh", "echo '{}'", 0755)
+	suite.writePlugin("README", "", 0644)
+	suite.writePlugin("host_discovery", "echo '{}'", 0755)
+	suite.NoError(os.Mkdir(filepath.Join(suite.pluginsDirectory, "subdir"), 0755))
+
+	plugins, err := LoadPlugins(suite.collectorClient, suite.config)
+	suite.NoError(err)
+
+	suite.Len(plugins, 1)
+	suite.Equal("storage_layout", plugins[0].GetId())
+	suite.Equal(60*time.Second, plugins[0].GetInterval())
+	suite.Equal(30*time.Second, plugins[0].GetTimeout())
+}
+
+func (suite *PluginDiscoveryTestSuite) TestLoadPlugins_OwnPeriod() {
+	suite.writePlugin("storage.sh", "echo '{}'", 0755)
+	suite.writePlugin("network.sh", "echo '{}'", 0755)
+	suite.writePlugin("multipath.sh", "echo '{}'", 0755)
+	suite.config.PluginsConfig = map[string]*PluginConfig{
+		"storage":   {Period: 10 * time.Minute, Timeout: 2 * time.Minute},
+		"multipath": {Timeout: 5 * time.Second},
+	}
+
+	plugins, err := LoadPlugins(suite.collectorClient, suite.config)
+	suite.NoError(err)
+
+	suite.Len(plugins, 3)
+	suite.Equal("multipath", plugins[0].GetId())
+	suite.Equal(60*time.Second, plugins[0].GetInterval())
+	suite.Equal(5*time.Second, plugins[0].GetTimeout())
+	suite.Equal("network", plugins[1].GetId())
+	suite.Equal(60*time.Second, plugins[1].GetInterval())
+	suite.Equal(30*time.Second, plugins[1].GetTimeout())
+	suite.Equal("storage", plugins[2].GetId())
+	suite.Equal(10*time.Minute, plugins[2].GetInterval())
+	suite.Equal(2*time.Minute, plugins[2].GetTimeout())
+}
+
+func (suite *PluginDiscoveryTestSuite) TestLoadPlugins_InvalidIds() {
+	suite.writePlugin("storage.sh", "echo '{}'", 0755)
+	suite.writePlugin("storage.py", "echo '{}'", 0755)
+	suite.writePlugin("___.sh", "echo '{}'", 0755)
+	suite.writePlugin("network.sh", "echo '{}'", 0755)
+	suite.writePlugin("tuning_discovery", "echo '{}'", 0755)
+
+	plugins, err := LoadPlugins(suite.collectorClient, suite.config)
+	suite.NoError(err)
+
+	suite.Len(plugins, 1)
+	suite.Equal("network", plugins[0].GetId())
+}
+
+func (suite *PluginDiscoveryTestSuite) TestLoadPlugins_Disabled() {
+	suite.config.PluginsDirectory = ""
+
+	plugins, err := LoadPlugins(suite.collectorClient, suite.config)
+	suite.NoError(err)
+	suite.Empty(plugins)
+}
+
+func (suite *PluginDiscoveryTestSuite) TestLoadPlugins_MissingDirectory() {
+	suite.config.PluginsDirectory = filepath.Join(suite.pluginsDirectory, "missing")
+
+	_, err := LoadPlugins(suite.collectorClient, suite.config)
+	suite.Error(err)
+}
+
+func (suite *PluginDiscoveryTestSuite) TestPluginDiscovery_Discover() {
+	path := suite.writePlugin("storage.sh", `echo '{"disks": ["sda", "sdb"]}'`, 0755)
+	plugin := NewPluginDiscovery(path, suite.collectorClient, suite.config)
+
+	result, err := plugin.Discover(context.Background())
+	suite.NoError(err)
+	suite.Equal("Plugin storage successfully discovered", result)
+
+	suite.Len(suite.collectorClient.published, 1)
+	suite.Equal("storage", suite.collectorClient.published[0].discoveryType)
+	suite.JSONEq(`{"disks": ["sda", "sdb"]}`, string(suite.collectorClient.published[0].payload.(json.RawMessage)))
+}
+
+func (suite *PluginDiscoveryTestSuite) TestPluginDiscovery_InvalidOutput() {
+	path := suite.writePlugin("storage.sh", "echo 'not json'", 0755)
+	plugin := NewPluginDiscovery(path, suite.collectorClient, suite.config)
+
+	_, err := plugin.Discover(context.Background())
+	suite.EqualError(err, "plugin "+path+" did not print a valid JSON document")
+	suite.Empty(suite.collectorClient.published)
+}
+
+func (suite *PluginDiscoveryTestSuite) TestPluginDiscovery_Failure() {
+	path := suite.writePlugin("storage.sh", "echo 'no disks' >&2; exit 1", 0755)
+	plugin := NewPluginDiscovery(path, suite.collectorClient, suite.config)
+
+	_, err := plugin.Discover(context.Background())
+	suite.EqualError(err, "plugin "+path+" failed: no disks: exit status 1")
+	suite.Empty(suite.collectorClient.published)
+}
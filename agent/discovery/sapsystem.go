@@ -1,6 +1,7 @@
 package discovery
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -16,6 +17,7 @@ type SAPSystemsDiscovery struct {
 	id              string
 	collectorClient collector.Client
 	interval        time.Duration
+	timeout         time.Duration
 }
 
 func NewSAPSystemsDiscovery(collectorClient collector.Client, config DiscoveriesConfig) Discovery {
@@ -23,6 +25,7 @@ func NewSAPSystemsDiscovery(collectorClient collector.Client, config Discoveries
 	d.id = SAPDiscoveryId
 	d.collectorClient = collectorClient
 	d.interval = config.DiscoveriesPeriodsConfig.SAPSystem
+	d.timeout = config.DiscoveriesTimeoutsConfig.SAPSystem
 
 	return d
 }
@@ -35,8 +38,12 @@ func (d SAPSystemsDiscovery) GetInterval() time.Duration {
 	return d.interval
 }
 
-func (d SAPSystemsDiscovery) Discover() (string, error) {
-	systems, err := sapsystem.NewSAPSystemsList()
+func (d SAPSystemsDiscovery) GetTimeout() time.Duration {
+	return d.timeout
+}
+
+func (d SAPSystemsDiscovery) Discover(ctx context.Context) (string, error) {
+	systems, err := sapsystem.NewSAPSystemsList(ctx)
 
 	if err != nil {
 		return "", err
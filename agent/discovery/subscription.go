@@ -1,6 +1,7 @@
 package discovery
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"time"
@@ -18,6 +19,7 @@ type SubscriptionDiscovery struct {
 	collectorClient collector.Client
 	host            string
 	interval        time.Duration
+	timeout         time.Duration
 }
 
 func NewSubscriptionDiscovery(collectorClient collector.Client, config DiscoveriesConfig) Discovery {
@@ -26,6 +28,7 @@ func NewSubscriptionDiscovery(collectorClient collector.Client, config Discoveri
 	d.collectorClient = collectorClient
 	d.host, _ = os.Hostname()
 	d.interval = config.DiscoveriesPeriodsConfig.Subscription
+	d.timeout = config.DiscoveriesTimeoutsConfig.Subscription
 
 	return d
 }
@@ -38,8 +41,12 @@ func (d SubscriptionDiscovery) GetInterval() time.Duration {
 	return d.interval
 }
 
-func (d SubscriptionDiscovery) Discover() (string, error) {
-	subsData, err := subscription.NewSubscriptions()
+func (d SubscriptionDiscovery) GetTimeout() time.Duration {
+	return d.timeout
+}
+
+func (d SubscriptionDiscovery) Discover(ctx context.Context) (string, error) {
+	subsData, err := subscription.NewSubscriptions(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,61 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/trento/agent/discovery/collector"
+	"github.com/trento-project/trento/internal/tuning"
+)
+
+const TuningDiscoveryId string = "tuning_discovery"
+const TuningDiscoveryMinPeriod time.Duration = 20 * time.Second
+
+type TuningDiscovery struct {
+	id              string
+	collectorClient collector.Client
+	host            string
+	interval        time.Duration
+	timeout         time.Duration
+}
+
+func NewTuningDiscovery(collectorClient collector.Client, config DiscoveriesConfig) Discovery {
+	d := TuningDiscovery{}
+	d.id = TuningDiscoveryId
+	d.collectorClient = collectorClient
+	d.host, _ = os.Hostname()
+	d.interval = config.DiscoveriesPeriodsConfig.Tuning
+	d.timeout = config.DiscoveriesTimeoutsConfig.Tuning
+
+	return d
+}
+
+func (d TuningDiscovery) GetId() string {
+	return d.id
+}
+
+func (d TuningDiscovery) GetInterval() time.Duration {
+	return d.interval
+}
+
+func (d TuningDiscovery) GetTimeout() time.Duration {
+	return d.timeout
+}
+
+func (d TuningDiscovery) Discover(ctx context.Context) (string, error) {
+	tuningData, err := tuning.NewTuning(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	err = d.collectorClient.Publish(d.id, tuningData)
+	if err != nil {
+		log.Debugf("Error while sending tuning discovery to data collector: %s", err)
+		return "", err
+	}
+
+	return fmt.Sprintf("Kernel tuning (%d parameters) discovered", len(tuningData.Sysctl)), nil
+}
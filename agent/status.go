@@ -0,0 +1,314 @@
+package agent
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/agent/discovery/collector"
+	"github.com/trento-project/trento/version"
+)
+
+const metricsNamespace = "trento_agent"
+
+type Status struct {
+	Version     string                      `json:"version"`
+	StartedAt   time.Time                   `json:"started_at"`
+	Heartbeat   HeartbeatStatus             `json:"heartbeat"`
+	Discoveries map[string]*DiscoveryStatus `json:"discoveries"`
+}
+
+type HeartbeatStatus struct {
+	LastSentAt    *time.Time `json:"last_sent_at,omitempty"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+}
+
+type DiscoveryStatus struct {
+	LastRunAt           *time.Time `json:"last_run_at,omitempty"`
+	LastDuration        float64    `json:"last_duration_seconds"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastPublishedAt     *time.Time `json:"last_published_at,omitempty"`
+	LastPublishError    string     `json:"last_publish_error,omitempty"`
+}
+
+// StatusSummary is the summary of the agent status sent along with each heartbeat
+type StatusSummary struct {
+	Hostname    string              `json:"hostname,omitempty"`
+	BootID      string              `json:"boot_id,omitempty"`
+	Version     string              `json:"version"`
+	Uptime      float64             `json:"uptime_seconds"`
+	Discoveries []*DiscoverySummary `json:"discoveries"`
+}
+
+type DiscoverySummary struct {
+	ID                  string     `json:"id"`
+	LastRunAt           *time.Time `json:"last_run_at,omitempty"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastPublishError    string     `json:"last_publish_error,omitempty"`
+}
+
+// statusTracker keeps the outcome of the discoveries, publications and heartbeats,
+// exposing it as a JSON status document and as Prometheus metrics
+type statusTracker struct {
+	sync.RWMutex
+	status Status
+
+	registry          *prometheus.Registry
+	discoveryRuns     *prometheus.CounterVec
+	discoveryDuration *prometheus.GaugeVec
+	discoveryLastOk   *prometheus.GaugeVec
+	discoveryFailures *prometheus.GaugeVec
+	publications      *prometheus.CounterVec
+	heartbeats        *prometheus.CounterVec
+	heartbeatLastOk   prometheus.Gauge
+}
+
+func newStatusTracker() *statusTracker {
+	s := &statusTracker{
+		status: Status{
+			Version:     version.Version,
+			StartedAt:   time.Now(),
+			Discoveries: make(map[string]*DiscoveryStatus),
+		},
+		registry: prometheus.NewRegistry(),
+		discoveryRuns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "discovery_runs_total",
+			Help:      "Number of discovery executions, by result",
+		}, []string{"discovery", "result"}),
+		discoveryDuration: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "discovery_last_duration_seconds",
+			Help:      "Duration of the last discovery execution",
+		}, []string{"discovery"}),
+		discoveryLastOk: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "discovery_last_success_timestamp_seconds",
+			Help:      "Unix timestamp of the last successful discovery execution",
+		}, []string{"discovery"}),
+		discoveryFailures: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "discovery_consecutive_failures",
+			Help:      "Number of discovery executions failed since the last successful one",
+		}, []string{"discovery"}),
+		publications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "publications_total",
+			Help:      "Number of discovered data publications to the collector, by result",
+		}, []string{"discovery", "result"}),
+		heartbeats: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "heartbeats_total",
+			Help:      "Number of heartbeats sent to the server, by result",
+		}, []string{"result"}),
+		heartbeatLastOk: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "heartbeat_last_success_timestamp_seconds",
+			Help:      "Unix timestamp of the last successful heartbeat",
+		}),
+	}
+
+	info := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace:   metricsNamespace,
+		Name:        "info",
+		Help:        "Information about the trento agent",
+		ConstLabels: prometheus.Labels{"version": version.Version},
+	})
+	info.Set(1)
+
+	s.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		info,
+		s.discoveryRuns,
+		s.discoveryDuration,
+		s.discoveryLastOk,
+		s.discoveryFailures,
+		s.publications,
+		s.heartbeats,
+		s.heartbeatLastOk,
+	)
+
+	return s
+}
+
+// addDiscovery lists a discovery in the status before its first execution
+func (s *statusTracker) addDiscovery(id string) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.discoveryStatus(id)
+	s.discoveryFailures.WithLabelValues(id).Set(0)
+}
+
+// removeDiscovery drops a discovery no longer executed, like a removed plugin, from the status
+func (s *statusTracker) removeDiscovery(id string) {
+	s.Lock()
+	defer s.Unlock()
+
+	delete(s.status.Discoveries, id)
+	s.discoveryDuration.DeleteLabelValues(id)
+	s.discoveryLastOk.DeleteLabelValues(id)
+	s.discoveryFailures.DeleteLabelValues(id)
+}
+
+func (s *statusTracker) discoveryStatus(id string) *DiscoveryStatus {
+	status, ok := s.status.Discoveries[id]
+	if !ok {
+		status = &DiscoveryStatus{}
+		s.status.Discoveries[id] = status
+	}
+
+	return status
+}
+
+func (s *statusTracker) discoveryRun(id string, startedAt time.Time, duration time.Duration, err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	status := s.discoveryStatus(id)
+	status.LastRunAt = &startedAt
+	status.LastDuration = duration.Seconds()
+	s.discoveryDuration.WithLabelValues(id).Set(duration.Seconds())
+
+	if err != nil {
+		status.LastError = err.Error()
+		status.ConsecutiveFailures++
+		s.discoveryRuns.WithLabelValues(id, "failure").Inc()
+		s.discoveryFailures.WithLabelValues(id).Set(float64(status.ConsecutiveFailures))
+		return
+	}
+
+	finishedAt := startedAt.Add(duration)
+	status.LastSuccessAt = &finishedAt
+	status.LastError = ""
+	status.ConsecutiveFailures = 0
+	s.discoveryRuns.WithLabelValues(id, "success").Inc()
+	s.discoveryLastOk.WithLabelValues(id).Set(float64(finishedAt.Unix()))
+	s.discoveryFailures.WithLabelValues(id).Set(0)
+}
+
+func (s *statusTracker) published(discoveryType string, err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	now := time.Now()
+	status := s.discoveryStatus(discoveryType)
+	status.LastPublishedAt = &now
+
+	if err != nil {
+		status.LastPublishError = err.Error()
+		s.publications.WithLabelValues(discoveryType, "failure").Inc()
+		return
+	}
+
+	status.LastPublishError = ""
+	s.publications.WithLabelValues(discoveryType, "success").Inc()
+}
+
+func (s *statusTracker) heartbeatSent(err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	now := time.Now()
+	s.status.Heartbeat.LastSentAt = &now
+
+	if err != nil {
+		s.status.Heartbeat.LastError = err.Error()
+		s.heartbeats.WithLabelValues("failure").Inc()
+		return
+	}
+
+	s.status.Heartbeat.LastSuccessAt = &now
+	s.status.Heartbeat.LastError = ""
+	s.heartbeats.WithLabelValues("success").Inc()
+	s.heartbeatLastOk.Set(float64(now.Unix()))
+}
+
+// summary returns the summary of the current status, with the discoveries sorted by id
+func (s *statusTracker) summary() *StatusSummary {
+	s.RLock()
+	defer s.RUnlock()
+
+	summary := &StatusSummary{
+		Version:     s.status.Version,
+		Uptime:      time.Since(s.status.StartedAt).Round(time.Second).Seconds(),
+		Discoveries: []*DiscoverySummary{},
+	}
+
+	for id, status := range s.status.Discoveries {
+		summary.Discoveries = append(summary.Discoveries, &DiscoverySummary{
+			ID:                  id,
+			LastRunAt:           status.LastRunAt,
+			LastSuccessAt:       status.LastSuccessAt,
+			LastError:           status.LastError,
+			ConsecutiveFailures: status.ConsecutiveFailures,
+			LastPublishError:    status.LastPublishError,
+		})
+	}
+
+	sort.Slice(summary.Discoveries, func(i, j int) bool {
+		return summary.Discoveries[i].ID < summary.Discoveries[j].ID
+	})
+
+	return summary
+}
+
+func (s *statusTracker) marshalStatus() ([]byte, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	return json.Marshal(s.status)
+}
+
+// handler serves the status document on /status and the Prometheus metrics on /metrics
+func (s *statusTracker) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		body, err := s.marshalStatus()
+		if err != nil {
+			log.Errorf("Error while marshaling the agent status: %s", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	})
+	mux.Handle("/metrics", promhttp.HandlerFor(s.registry, promhttp.HandlerOpts{}))
+
+	return mux
+}
+
+// statusClient is a collector client recording the outcome of each publication and heartbeat
+type statusClient struct {
+	collector.Client
+	status *statusTracker
+}
+
+func (c *statusClient) Publish(discoveryType string, payload interface{}) error {
+	err := c.Client.Publish(discoveryType, payload)
+	c.status.published(discoveryType, err)
+
+	return err
+}
+
+func (c *statusClient) Heartbeat(status interface{}) error {
+	err := c.Client.Heartbeat(status)
+	c.status.heartbeatSent(err)
+
+	return err
+}
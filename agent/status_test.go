@@ -0,0 +1,145 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trento-project/trento/version"
+)
+
+type dummyCollectorClient struct {
+	err error
+}
+
+func (c *dummyCollectorClient) Publish(discoveryType string, payload interface{}) error {
+	return c.err
+}
+
+func (c *dummyCollectorClient) Heartbeat(status interface{}) error {
+	return c.err
+}
+
+func getStatus(t *testing.T, status *statusTracker) Status {
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/status", nil)
+	status.handler().ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+
+	var s Status
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &s))
+
+	return s
+}
+
+func TestStatusDiscoveries(t *testing.T) {
+	status := newStatusTracker()
+	status.addDiscovery("host_discovery")
+	status.addDiscovery("cloud_discovery")
+
+	startedAt := time.Now()
+	status.discoveryRun("host_discovery", startedAt, 2*time.Second, errors.New("kaboom"))
+	status.discoveryRun("host_discovery", startedAt, 2*time.Second, errors.New("kaboom"))
+	status.discoveryRun("cloud_discovery", startedAt, time.Second, nil)
+
+	s := getStatus(t, status)
+
+	assert.Len(t, s.Discoveries, 2)
+
+	host := s.Discoveries["host_discovery"]
+	assert.Equal(t, "kaboom", host.LastError)
+	assert.Equal(t, 2, host.ConsecutiveFailures)
+	assert.Equal(t, 2.0, host.LastDuration)
+	assert.Nil(t, host.LastSuccessAt)
+
+	cloud := s.Discoveries["cloud_discovery"]
+	assert.Empty(t, cloud.LastError)
+	assert.Equal(t, 0, cloud.ConsecutiveFailures)
+	assert.WithinDuration(t, startedAt.Add(time.Second), *cloud.LastSuccessAt, time.Millisecond)
+}
+
+func TestStatusSummary(t *testing.T) {
+	status := newStatusTracker()
+	status.addDiscovery("host_discovery")
+	status.addDiscovery("cloud_discovery")
+
+	startedAt := time.Now()
+	status.discoveryRun("host_discovery", startedAt, time.Second, errors.New("kaboom"))
+	status.discoveryRun("cloud_discovery", startedAt, time.Second, nil)
+	status.published("cloud_discovery", errors.New("collector unreachable"))
+
+	summary := status.summary()
+
+	assert.Equal(t, version.Version, summary.Version)
+	assert.GreaterOrEqual(t, summary.Uptime, 0.0)
+	assert.Len(t, summary.Discoveries, 2)
+
+	cloud := summary.Discoveries[0]
+	assert.Equal(t, "cloud_discovery", cloud.ID)
+	assert.Empty(t, cloud.LastError)
+	assert.Equal(t, "collector unreachable", cloud.LastPublishError)
+	assert.NotNil(t, cloud.LastSuccessAt)
+
+	host := summary.Discoveries[1]
+	assert.Equal(t, "host_discovery", host.ID)
+	assert.Equal(t, "kaboom", host.LastError)
+	assert.Equal(t, 1, host.ConsecutiveFailures)
+	assert.Nil(t, host.LastSuccessAt)
+}
+
+func TestStatusClient(t *testing.T) {
+	status := newStatusTracker()
+	collectorClient := &dummyCollectorClient{}
+	client := &statusClient{Client: collectorClient, status: status}
+
+	assert.NoError(t, client.Publish("host_discovery", nil))
+	assert.NoError(t, client.Heartbeat(nil))
+
+	collectorClient.err = errors.New("collector unreachable")
+	assert.Error(t, client.Publish("cloud_discovery", nil))
+
+	s := getStatus(t, status)
+
+	assert.NotNil(t, s.Discoveries["host_discovery"].LastPublishedAt)
+	assert.Empty(t, s.Discoveries["host_discovery"].LastPublishError)
+	assert.Equal(t, "collector unreachable", s.Discoveries["cloud_discovery"].LastPublishError)
+	assert.NotNil(t, s.Heartbeat.LastSuccessAt)
+
+	assert.Error(t, client.Heartbeat(nil))
+
+	s = getStatus(t, status)
+	assert.Equal(t, "collector unreachable", s.Heartbeat.LastError)
+}
+
+func TestStatusMetrics(t *testing.T) {
+	status := newStatusTracker()
+	status.addDiscovery("host_discovery")
+	status.discoveryRun("host_discovery", time.Now(), time.Second, errors.New("kaboom"))
+	status.published("host_discovery", nil)
+	status.heartbeatSent(nil)
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	status.handler().ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	metrics := string(body)
+
+	assert.Contains(t, metrics, `trento_agent_discovery_runs_total{discovery="host_discovery",result="failure"} 1`)
+	assert.Contains(t, metrics, `trento_agent_discovery_consecutive_failures{discovery="host_discovery"} 1`)
+	assert.Contains(t, metrics, `trento_agent_discovery_last_duration_seconds{discovery="host_discovery"} 1`)
+	assert.Contains(t, metrics, `trento_agent_publications_total{discovery="host_discovery",result="success"} 1`)
+	assert.Contains(t, metrics, `trento_agent_heartbeats_total{result="success"} 1`)
+	assert.Contains(t, metrics, `trento_agent_info{version=""} 1`)
+	assert.Contains(t, metrics, "go_goroutines")
+}
@@ -13,26 +13,32 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/trento-project/trento/agent"
+	"github.com/trento-project/trento/agent/discovery/collector"
 	"github.com/trento-project/trento/internal"
 )
 
 func NewAgentCmd() *cobra.Command {
-	var sshAddress string
-
-	var clusterDiscoveryPeriod time.Duration
-	var sapSystemDiscoveryPeriod time.Duration
-	var cloudDiscoveryPeriod time.Duration
-	var hostDiscoveryPeriod time.Duration
-	var subscriptionDiscoveryPeriod time.Duration
-
 	var collectorHost string
 	var collectorPort int
+	var resyncPeriod time.Duration
+	var compression bool
+	var batchWindow time.Duration
 
 	var enablemTLS bool
 	var cert string
 	var key string
 	var ca string
 
+	var enrollmentToken string
+	var credentialsPath string
+	var autoCert bool
+
+	var outboxPath string
+	var outboxPolicy string
+	var outboxMaxEntries int
+
+	var statusListenAddress string
+
 	agentCmd := &cobra.Command{
 		Use:   "agent",
 		Short: "Command tree related to the agent component",
@@ -51,29 +57,94 @@ func NewAgentCmd() *cobra.Command {
 		Run:   start,
 	}
 
-	startCmd.Flags().StringVar(&sshAddress, "ssh-address", "", "The address to which the trento-agent should be reachable for ssh connection by the runner for check execution.")
-
-	startCmd.Flags().DurationVarP(&clusterDiscoveryPeriod, "cluster-discovery-period", "", 10*time.Second, "Cluster discovery mechanism loop period in seconds")
-	startCmd.Flags().DurationVarP(&sapSystemDiscoveryPeriod, "sapsystem-discovery-period", "", 10*time.Second, "SAP systems discovery mechanism loop period in seconds")
-	startCmd.Flags().DurationVarP(&cloudDiscoveryPeriod, "cloud-discovery-period", "", 10*time.Second, "Cloud discovery mechanism loop period in seconds")
-	startCmd.Flags().DurationVarP(&hostDiscoveryPeriod, "host-discovery-period", "", 10*time.Second, "Host discovery mechanism loop period in seconds")
-	startCmd.Flags().DurationVarP(&subscriptionDiscoveryPeriod, "subscription-discovery-period", "", 900*time.Second, "Subscription discovery mechanism loop period in seconds")
-
-	startCmd.Flags().MarkHidden("subscription-discovery-period")
+	addDiscoveriesFlags(startCmd)
 
 	startCmd.Flags().StringVar(&collectorHost, "collector-host", "localhost", "Data Collector host")
 	startCmd.Flags().IntVar(&collectorPort, "collector-port", 8081, "Data Collector port")
+	startCmd.Flags().DurationVar(&resyncPeriod, "resync-period", 1*time.Hour, "Period after which unchanged discovery data is fully sent again to the Data Collector. Set to 0 to always send it")
+	startCmd.Flags().BoolVar(&compression, "collector-compression", false, "Compress with gzip the discovery data sent to the Data Collector. The Data Collector must support compressed requests")
+	startCmd.Flags().DurationVar(&batchWindow, "collector-batch-window", 0, "Time window during which the discovery data is gathered to be sent to the Data Collector in a single request. Set to 0 to disable batching")
 
 	startCmd.Flags().BoolVar(&enablemTLS, "enable-mtls", false, "Enable mTLS authentication between server and agent")
 	startCmd.Flags().StringVar(&cert, "cert", "", "mTLS client certificate")
 	startCmd.Flags().StringVar(&key, "key", "", "mTLS client key")
 	startCmd.Flags().StringVar(&ca, "ca", "", "mTLS Certificate Authority")
+	startCmd.Flags().BoolVar(&autoCert, "auto-cert", false, "Request the mTLS client certificate from the server internal CA on the first start, storing it in the cert and key paths, and renew it before it expires")
+
+	startCmd.Flags().StringVar(&enrollmentToken, "enrollment-token", "", "One-time token exchanged for the agent credentials on the first start")
+	startCmd.Flags().StringVar(&credentialsPath, "credentials-path", "/var/lib/trento/agent-credentials.json", "File where the agent credentials are stored once enrolled")
+
+	startCmd.Flags().StringVar(&outboxPath, "outbox-path", "/var/lib/trento/outbox", "Directory where the discovery payloads are spooled while the collector is unreachable. Leave empty to disable spooling")
+	startCmd.Flags().StringVar(&outboxPolicy, "outbox-policy", collector.OutboxPolicyOrdered, "Spooling policy for each discovery type: 'ordered' keeps every payload, 'latest' keeps only the most recent one")
+	startCmd.Flags().IntVar(&outboxMaxEntries, "outbox-max-entries", collector.DefaultOutboxMaxEntries, "Maximum number of payloads spooled for each discovery type")
+
+	startCmd.Flags().StringVar(&statusListenAddress, "status-listen-address", "", "Local address where the agent status and Prometheus metrics are served, e.g. 127.0.0.1:8702. Leave empty to disable them")
 
 	agentCmd.AddCommand(startCmd)
+	addDiscoverCmd(agentCmd)
 
 	return agentCmd
 }
 
+// addDiscoveriesFlags adds the flags configuring the discoveries, shared by the commands running them
+func addDiscoveriesFlags(cmd *cobra.Command) {
+	var sshAddress string
+	var agentID string
+
+	var clusterDiscoveryPeriod time.Duration
+	var sapSystemDiscoveryPeriod time.Duration
+	var cloudDiscoveryPeriod time.Duration
+	var hostDiscoveryPeriod time.Duration
+	var subscriptionDiscoveryPeriod time.Duration
+	var packagesDiscoveryPeriod time.Duration
+	var tuningDiscoveryPeriod time.Duration
+
+	var clusterDiscoveryTimeout time.Duration
+	var sapSystemDiscoveryTimeout time.Duration
+	var cloudDiscoveryTimeout time.Duration
+	var hostDiscoveryTimeout time.Duration
+	var subscriptionDiscoveryTimeout time.Duration
+	var packagesDiscoveryTimeout time.Duration
+	var tuningDiscoveryTimeout time.Duration
+
+	var pluginsDirectory string
+	var pluginsDiscoveryPeriod time.Duration
+	var pluginsDiscoveryTimeout time.Duration
+
+	cmd.Flags().StringVar(&sshAddress, "ssh-address", "", "The address to which the trento-agent should be reachable for ssh connection by the runner for check execution.")
+	cmd.Flags().StringVar(&agentID, "agent-id", "", "Agent identifier (UUID) overriding the one derived from /etc/machine-id, to tell apart hosts cloned from the same image")
+
+	cmd.Flags().DurationVarP(&clusterDiscoveryPeriod, "cluster-discovery-period", "", 10*time.Second, "Cluster discovery mechanism loop period in seconds")
+	cmd.Flags().DurationVarP(&sapSystemDiscoveryPeriod, "sapsystem-discovery-period", "", 10*time.Second, "SAP systems discovery mechanism loop period in seconds")
+	cmd.Flags().DurationVarP(&cloudDiscoveryPeriod, "cloud-discovery-period", "", 10*time.Second, "Cloud discovery mechanism loop period in seconds")
+	cmd.Flags().DurationVarP(&hostDiscoveryPeriod, "host-discovery-period", "", 10*time.Second, "Host discovery mechanism loop period in seconds")
+	cmd.Flags().DurationVarP(&subscriptionDiscoveryPeriod, "subscription-discovery-period", "", 900*time.Second, "Subscription discovery mechanism loop period in seconds")
+
+	cmd.Flags().DurationVarP(&packagesDiscoveryPeriod, "packages-discovery-period", "", 3600*time.Second, "Packages discovery mechanism loop period in seconds")
+	cmd.Flags().DurationVarP(&tuningDiscoveryPeriod, "tuning-discovery-period", "", 300*time.Second, "Kernel tuning discovery mechanism loop period in seconds")
+
+	cmd.Flags().MarkHidden("subscription-discovery-period")
+	cmd.Flags().MarkHidden("packages-discovery-period")
+	cmd.Flags().MarkHidden("tuning-discovery-period")
+
+	cmd.Flags().DurationVarP(&clusterDiscoveryTimeout, "cluster-discovery-timeout", "", 30*time.Second, "Cluster discovery mechanism execution timeout")
+	cmd.Flags().DurationVarP(&sapSystemDiscoveryTimeout, "sapsystem-discovery-timeout", "", 30*time.Second, "SAP systems discovery mechanism execution timeout")
+	cmd.Flags().DurationVarP(&cloudDiscoveryTimeout, "cloud-discovery-timeout", "", 30*time.Second, "Cloud discovery mechanism execution timeout")
+	cmd.Flags().DurationVarP(&hostDiscoveryTimeout, "host-discovery-timeout", "", 30*time.Second, "Host discovery mechanism execution timeout")
+	cmd.Flags().DurationVarP(&subscriptionDiscoveryTimeout, "subscription-discovery-timeout", "", 60*time.Second, "Subscription discovery mechanism execution timeout")
+
+	cmd.Flags().DurationVarP(&packagesDiscoveryTimeout, "packages-discovery-timeout", "", 60*time.Second, "Packages discovery mechanism execution timeout")
+	cmd.Flags().DurationVarP(&tuningDiscoveryTimeout, "tuning-discovery-timeout", "", 30*time.Second, "Kernel tuning discovery mechanism execution timeout")
+
+	cmd.Flags().MarkHidden("subscription-discovery-timeout")
+	cmd.Flags().MarkHidden("packages-discovery-timeout")
+	cmd.Flags().MarkHidden("tuning-discovery-timeout")
+
+	cmd.Flags().StringVar(&pluginsDirectory, "plugins-directory", "", "Directory containing the discovery plugin executables. Leave empty to disable plugins")
+	cmd.Flags().DurationVarP(&pluginsDiscoveryPeriod, "plugins-discovery-period", "", 60*time.Second, "Plugins discovery mechanism loop period in seconds")
+	cmd.Flags().DurationVarP(&pluginsDiscoveryTimeout, "plugins-discovery-timeout", "", 30*time.Second, "Plugins discovery mechanism execution timeout")
+}
+
 func start(*cobra.Command, []string) {
 	var err error
 
@@ -98,6 +169,29 @@ func start(*cobra.Command, []string) {
 		a.Stop()
 	}()
 
+	reloads := make(chan os.Signal, 1)
+	signal.Notify(reloads, syscall.SIGHUP)
+
+	go func() {
+		for range reloads {
+			log.Println("Caught SIGHUP signal, reloading the agent configuration...")
+
+			config, err := ReloadConfig()
+			if err != nil {
+				log.Errorf("Invalid agent configuration, keeping the current one: %s", err)
+				continue
+			}
+
+			err = a.Reload(config)
+			if err != nil {
+				log.Errorf("Could not reload the agent configuration, keeping the current one: %s", err)
+				continue
+			}
+
+			log.Println("Agent configuration reloaded")
+		}
+	}()
+
 	log.Println("Starting the Console Agent...")
 	err = a.Start()
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 	"github.com/trento-project/trento/agent"
@@ -21,27 +22,63 @@ func validatePeriod(durationFlag string, minValue time.Duration) error {
 	return nil
 }
 
-func LoadConfig() (*agent.Config, error) {
-	enablemTLS := viper.GetBool("enable-mtls")
-	cert := viper.GetString("cert")
-	key := viper.GetString("key")
-	ca := viper.GetString("ca")
+// loadPluginsConfig loads the period and timeout overrides of single plugins, set in the configuration file
+func loadPluginsConfig() (map[string]*discovery.PluginConfig, error) {
+	if !viper.IsSet("plugins") {
+		return nil, nil
+	}
 
-	minPeriodValues := map[string]time.Duration{
-		"cluster-discovery-period":      discovery.ClusterDiscoveryMinPeriod,
-		"sapsystem-discovery-period":    discovery.SAPDiscoveryMinPeriod,
-		"cloud-discovery-period":        discovery.CloudDiscoveryMinPeriod,
-		"host-discovery-period":         discovery.HostDiscoveryMinPeriod,
-		"subscription-discovery-period": discovery.SubscriptionDiscoveryMinPeriod,
+	var pluginsConfig map[string]*discovery.PluginConfig
+	err := viper.UnmarshalKey("plugins", &pluginsConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the plugins configuration")
 	}
 
-	for flagName, minPeriodValue := range minPeriodValues {
-		err := validatePeriod(flagName, minPeriodValue)
-		if err != nil {
-			return nil, err
+	for id, pluginConfig := range pluginsConfig {
+		if pluginConfig == nil {
+			continue
+		}
+		if pluginConfig.Period != 0 && pluginConfig.Period < discovery.PluginDiscoveryMinPeriod {
+			return nil, errors.Errorf("plugins.%s.period: invalid interval %s, should be at least %s", id, pluginConfig.Period, discovery.PluginDiscoveryMinPeriod)
 		}
+		if pluginConfig.Timeout != 0 && pluginConfig.Timeout < discovery.DiscoveryMinTimeout {
+			return nil, errors.Errorf("plugins.%s.timeout: invalid interval %s, should be at least %s", id, pluginConfig.Timeout, discovery.DiscoveryMinTimeout)
+		}
+	}
+
+	return pluginsConfig, nil
+}
+
+// loadAgentID loads the agent id override, which must be a UUID like the ones derived from the machine id
+func loadAgentID() (string, error) {
+	agentID := viper.GetString("agent-id")
+	if agentID == "" {
+		return "", nil
 	}
 
+	if _, err := uuid.Parse(agentID); err != nil {
+		return "", errors.Errorf("agent-id: invalid identifier %s, should be a UUID", agentID)
+	}
+
+	return agentID, nil
+}
+
+func LoadConfig() (*agent.Config, error) {
+	discoveriesConfig, err := LoadDiscoveriesConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// The one-shot discover command does not need it, as no runner connects to it
+	if discoveriesConfig.SSHAddress == "" {
+		return nil, errors.New("ssh-address is required, cannot start agent")
+	}
+
+	enablemTLS := viper.GetBool("enable-mtls")
+	cert := viper.GetString("cert")
+	key := viper.GetString("key")
+	ca := viper.GetString("ca")
+
 	if enablemTLS {
 		var err error
 
@@ -59,14 +96,40 @@ func LoadConfig() (*agent.Config, error) {
 		}
 	}
 
-	hostname, err := os.Hostname()
+	autoCert := viper.GetBool("auto-cert")
+	if autoCert && !enablemTLS {
+		return nil, errors.New("auto-cert: the certificate can only be requested with mTLS enabled")
+	}
+
+	outboxPolicy := viper.GetString("outbox-policy")
+	if outboxPolicy != collector.OutboxPolicyOrdered && outboxPolicy != collector.OutboxPolicyLatest {
+		return nil, errors.Errorf("outbox-policy: unknown policy %s, should be one of: %s, %s",
+			outboxPolicy, collector.OutboxPolicyOrdered, collector.OutboxPolicyLatest)
+	}
+
+	outboxMaxEntries := viper.GetInt("outbox-max-entries")
+	if outboxMaxEntries < 1 {
+		return nil, errors.Errorf("outbox-max-entries: invalid value %d, should be at least 1", outboxMaxEntries)
+	}
+
+	resyncPeriod := viper.GetDuration("resync-period")
+	if resyncPeriod < 0 {
+		return nil, errors.Errorf("resync-period: invalid interval %s, should not be negative", resyncPeriod)
+	}
+
+	batchWindow := viper.GetDuration("collector-batch-window")
+	if batchWindow < 0 {
+		return nil, errors.Errorf("collector-batch-window: invalid interval %s, should not be negative", batchWindow)
+	}
+
+	agentID, err := loadAgentID()
 	if err != nil {
-		return nil, errors.Wrap(err, "could not read the hostname")
+		return nil, err
 	}
 
-	sshAddress := viper.GetString("ssh-address")
-	if sshAddress == "" {
-		return nil, errors.New("ssh-address is required, cannot start agent")
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the hostname")
 	}
 
 	collectorConfig := &collector.Config{
@@ -76,6 +139,81 @@ func LoadConfig() (*agent.Config, error) {
 		Cert:          cert,
 		Key:           key,
 		CA:            ca,
+		Outbox: &collector.OutboxConfig{
+			Path:       viper.GetString("outbox-path"),
+			Policy:     outboxPolicy,
+			MaxEntries: outboxMaxEntries,
+		},
+		ResyncPeriod:    resyncPeriod,
+		Compression:     viper.GetBool("collector-compression"),
+		BatchWindow:     batchWindow,
+		EnrollmentToken: viper.GetString("enrollment-token"),
+		CredentialsPath: viper.GetString("credentials-path"),
+		AutoCert:        autoCert,
+		AgentID:         agentID,
+	}
+
+	discoveriesConfig.CollectorConfig = collectorConfig
+
+	return &agent.Config{
+		InstanceName:        hostname,
+		DiscoveriesConfig:   discoveriesConfig,
+		StatusListenAddress: viper.GetString("status-listen-address"),
+	}, nil
+}
+
+// ReloadConfig reads the configuration file again and loads the resulting agent configuration,
+// validated as on start. The flags and environment variables keep overriding the file
+func ReloadConfig() (*agent.Config, error) {
+	err := viper.ReadInConfig()
+	if _, notFound := err.(viper.ConfigFileNotFoundError); err != nil && !notFound {
+		return nil, errors.Wrap(err, "could not read the configuration file")
+	}
+
+	return LoadConfig()
+}
+
+// LoadDiscoveriesConfig loads the discoveries configuration, without the collector one
+func LoadDiscoveriesConfig() (*discovery.DiscoveriesConfig, error) {
+	minPeriodValues := map[string]time.Duration{
+		"cluster-discovery-period":      discovery.ClusterDiscoveryMinPeriod,
+		"sapsystem-discovery-period":    discovery.SAPDiscoveryMinPeriod,
+		"cloud-discovery-period":        discovery.CloudDiscoveryMinPeriod,
+		"host-discovery-period":         discovery.HostDiscoveryMinPeriod,
+		"subscription-discovery-period": discovery.SubscriptionDiscoveryMinPeriod,
+		"packages-discovery-period":     discovery.PackagesDiscoveryMinPeriod,
+		"tuning-discovery-period":       discovery.TuningDiscoveryMinPeriod,
+		"plugins-discovery-period":      discovery.PluginDiscoveryMinPeriod,
+	}
+
+	for flagName, minPeriodValue := range minPeriodValues {
+		err := validatePeriod(flagName, minPeriodValue)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	timeoutFlags := []string{
+		"cluster-discovery-timeout",
+		"sapsystem-discovery-timeout",
+		"cloud-discovery-timeout",
+		"host-discovery-timeout",
+		"subscription-discovery-timeout",
+		"packages-discovery-timeout",
+		"tuning-discovery-timeout",
+		"plugins-discovery-timeout",
+	}
+
+	for _, flagName := range timeoutFlags {
+		err := validatePeriod(flagName, discovery.DiscoveryMinTimeout)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pluginsConfig, err := loadPluginsConfig()
+	if err != nil {
+		return nil, err
 	}
 
 	discoveryPeriodsConfig := &discovery.DiscoveriesPeriodConfig{
@@ -84,16 +222,29 @@ func LoadConfig() (*agent.Config, error) {
 		Cloud:        viper.GetDuration("cloud-discovery-period"),
 		Host:         viper.GetDuration("host-discovery-period"),
 		Subscription: viper.GetDuration("subscription-discovery-period"),
+		Packages:     viper.GetDuration("packages-discovery-period"),
+		Tuning:       viper.GetDuration("tuning-discovery-period"),
+		Plugins:      viper.GetDuration("plugins-discovery-period"),
+	}
+
+	discoveryTimeoutsConfig := &discovery.DiscoveriesTimeoutConfig{
+		Cluster:      viper.GetDuration("cluster-discovery-timeout"),
+		SAPSystem:    viper.GetDuration("sapsystem-discovery-timeout"),
+		Cloud:        viper.GetDuration("cloud-discovery-timeout"),
+		Host:         viper.GetDuration("host-discovery-timeout"),
+		Subscription: viper.GetDuration("subscription-discovery-timeout"),
+		Packages:     viper.GetDuration("packages-discovery-timeout"),
+		Tuning:       viper.GetDuration("tuning-discovery-timeout"),
+		Plugins:      viper.GetDuration("plugins-discovery-timeout"),
 	}
 
 	discoveriesConfig := &discovery.DiscoveriesConfig{
-		SSHAddress:               sshAddress,
-		CollectorConfig:          collectorConfig,
-		DiscoveriesPeriodsConfig: discoveryPeriodsConfig,
+		SSHAddress:                viper.GetString("ssh-address"),
+		PluginsDirectory:          viper.GetString("plugins-directory"),
+		PluginsConfig:             pluginsConfig,
+		DiscoveriesPeriodsConfig:  discoveryPeriodsConfig,
+		DiscoveriesTimeoutsConfig: discoveryTimeoutsConfig,
 	}
 
-	return &agent.Config{
-		InstanceName:      hostname,
-		DiscoveriesConfig: discoveriesConfig,
-	}, nil
+	return discoveriesConfig, nil
 }
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"github.com/trento-project/trento/agent"
 	"github.com/trento-project/trento/agent/discovery"
@@ -27,7 +29,8 @@ func (suite *AgentCmdTestSuite) SetupTest() {
 
 	cmd := NewAgentCmd()
 
-	cmd.Commands()[0].Run = func(cmd *cobra.Command, args []string) {
+	startCmd, _, _ := cmd.Find([]string{"start"})
+	startCmd.Run = func(cmd *cobra.Command, args []string) {
 		// do nothing
 	}
 
@@ -47,13 +50,27 @@ func (suite *AgentCmdTestSuite) TearDownTest() {
 	expectedConfig := &agent.Config{
 		InstanceName: "some-hostname",
 		DiscoveriesConfig: &discovery.DiscoveriesConfig{
-			SSHAddress: "some-ssh-address",
+			SSHAddress:       "some-ssh-address",
+			PluginsDirectory: "/some/plugins",
 			DiscoveriesPeriodsConfig: &discovery.DiscoveriesPeriodConfig{
 				Cluster:      10 * time.Second,
 				SAPSystem:    10 * time.Second,
 				Cloud:        10 * time.Second,
 				Host:         10 * time.Second,
 				Subscription: 900 * time.Second,
+				Packages:     3600 * time.Second,
+				Tuning:       300 * time.Second,
+				Plugins:      30 * time.Second,
+			},
+			DiscoveriesTimeoutsConfig: &discovery.DiscoveriesTimeoutConfig{
+				Cluster:      20 * time.Second,
+				SAPSystem:    20 * time.Second,
+				Cloud:        20 * time.Second,
+				Host:         20 * time.Second,
+				Subscription: 40 * time.Second,
+				Packages:     50 * time.Second,
+				Tuning:       20 * time.Second,
+				Plugins:      20 * time.Second,
 			},
 			CollectorConfig: &collector.Config{
 				CollectorHost: "localhost",
@@ -62,8 +79,21 @@ func (suite *AgentCmdTestSuite) TearDownTest() {
 				Cert:          "some-cert",
 				Key:           "some-key",
 				CA:            "some-ca",
+				Outbox: &collector.OutboxConfig{
+					Path:       "/some/outbox",
+					Policy:     "latest",
+					MaxEntries: 10,
+				},
+				ResyncPeriod:    30 * time.Minute,
+				Compression:     false,
+				BatchWindow:     5 * time.Second,
+				EnrollmentToken: "some-token",
+				CredentialsPath: "/some/credentials.json",
+				AutoCert:        true,
+				AgentID:         "1f2e3d4c-5b6a-4978-8695-a4b3c2d1e0f9",
 			},
 		},
+		StatusListenAddress: "127.0.0.1:8702",
 	}
 
 	config, err := LoadConfig()
@@ -77,35 +107,126 @@ func (suite *AgentCmdTestSuite) TestConfigFromFlags() {
 	suite.cmd.SetArgs([]string{
 		"start",
 		"--ssh-address=some-ssh-address",
+		"--agent-id=1f2e3d4c-5b6a-4978-8695-a4b3c2d1e0f9",
 		"--cloud-discovery-period=10s",
 		"--cluster-discovery-period=10s",
 		"--sapsystem-discovery-period=10s",
 		"--host-discovery-period=10s",
 		"--subscription-discovery-period=900s",
+		"--packages-discovery-period=3600s",
+		"--cloud-discovery-timeout=20s",
+		"--cluster-discovery-timeout=20s",
+		"--sapsystem-discovery-timeout=20s",
+		"--host-discovery-timeout=20s",
+		"--subscription-discovery-timeout=40s",
+		"--packages-discovery-timeout=50s",
+		"--tuning-discovery-timeout=20s",
+		"--plugins-directory=/some/plugins",
+		"--plugins-discovery-period=30s",
+		"--plugins-discovery-timeout=20s",
 		"--collector-host=localhost",
 		"--collector-port=1337",
+		"--resync-period=30m",
+		"--collector-compression=false",
+		"--collector-batch-window=5s",
 		"--enable-mtls",
 		"--cert=some-cert",
 		"--key=some-key",
 		"--ca=some-ca",
+		"--auto-cert",
+		"--enrollment-token=some-token",
+		"--credentials-path=/some/credentials.json",
+		"--outbox-path=/some/outbox",
+		"--outbox-policy=latest",
+		"--outbox-max-entries=10",
+		"--status-listen-address=127.0.0.1:8702",
 	})
 }
 
 func (suite *AgentCmdTestSuite) TestConfigFromEnv() {
 	os.Setenv("TRENTO_SSH_ADDRESS", "some-ssh-address")
+	os.Setenv("TRENTO_AGENT_ID", "1f2e3d4c-5b6a-4978-8695-a4b3c2d1e0f9")
 	os.Setenv("TRENTO_CLOUD_DISCOVERY_PERIOD", "10s")
 	os.Setenv("TRENTO_CLUSTER_DISCOVERY_PERIOD", "10s")
 	os.Setenv("TRENTO_SAPSYSTEM_DISCOVERY_PERIOD", "10s")
 	os.Setenv("TRENTO_HOST_DISCOVERY_PERIOD", "10s")
 	os.Setenv("TRENTO_SUBSCRIPTION_DISCOVERY_PERIOD", "900s")
+	os.Setenv("TRENTO_PACKAGES_DISCOVERY_PERIOD", "3600s")
+	os.Setenv("TRENTO_CLOUD_DISCOVERY_TIMEOUT", "20s")
+	os.Setenv("TRENTO_CLUSTER_DISCOVERY_TIMEOUT", "20s")
+	os.Setenv("TRENTO_SAPSYSTEM_DISCOVERY_TIMEOUT", "20s")
+	os.Setenv("TRENTO_HOST_DISCOVERY_TIMEOUT", "20s")
+	os.Setenv("TRENTO_SUBSCRIPTION_DISCOVERY_TIMEOUT", "40s")
+	os.Setenv("TRENTO_PACKAGES_DISCOVERY_TIMEOUT", "50s")
+	os.Setenv("TRENTO_TUNING_DISCOVERY_TIMEOUT", "20s")
+	os.Setenv("TRENTO_PLUGINS_DIRECTORY", "/some/plugins")
+	os.Setenv("TRENTO_PLUGINS_DISCOVERY_PERIOD", "30s")
+	os.Setenv("TRENTO_PLUGINS_DISCOVERY_TIMEOUT", "20s")
 	os.Setenv("TRENTO_COLLECTOR_HOST", "localhost")
 	os.Setenv("TRENTO_COLLECTOR_PORT", "1337")
+	os.Setenv("TRENTO_RESYNC_PERIOD", "30m")
+	os.Setenv("TRENTO_COLLECTOR_COMPRESSION", "false")
+	os.Setenv("TRENTO_COLLECTOR_BATCH_WINDOW", "5s")
 	os.Setenv("TRENTO_ENABLE_MTLS", "true")
 	os.Setenv("TRENTO_CERT", "some-cert")
 	os.Setenv("TRENTO_KEY", "some-key")
 	os.Setenv("TRENTO_CA", "some-ca")
+	os.Setenv("TRENTO_AUTO_CERT", "true")
+	os.Setenv("TRENTO_ENROLLMENT_TOKEN", "some-token")
+	os.Setenv("TRENTO_CREDENTIALS_PATH", "/some/credentials.json")
+	os.Setenv("TRENTO_OUTBOX_PATH", "/some/outbox")
+	os.Setenv("TRENTO_OUTBOX_POLICY", "latest")
+	os.Setenv("TRENTO_OUTBOX_MAX_ENTRIES", "10")
+	os.Setenv("TRENTO_STATUS_LISTEN_ADDRESS", "127.0.0.1:8702")
 }
 
 func (suite *AgentCmdTestSuite) TestConfigFromFile() {
 	os.Setenv("TRENTO_CONFIG", "../../test/fixtures/config/agent.yaml")
 }
+
+func TestPluginsConfigFromFile(t *testing.T) {
+	os.Clearenv()
+	viper.Reset()
+	os.Setenv("TRENTO_CONFIG", "../../test/fixtures/config/agent_plugins.yaml")
+
+	cmd := NewAgentCmd()
+	startCmd, _, _ := cmd.Find([]string{"start"})
+	startCmd.Run = func(cmd *cobra.Command, args []string) {
+		// do nothing
+	}
+	cmd.SetArgs([]string{"start"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.Execute()
+
+	config, err := LoadDiscoveriesConfig()
+	assert.NoError(t, err)
+
+	expectedPluginsConfig := map[string]*discovery.PluginConfig{
+		"storage_layout": {Period: 10 * time.Minute, Timeout: 2 * time.Minute},
+		"multipath":      {Timeout: 5 * time.Second},
+	}
+	assert.Equal(t, expectedPluginsConfig, config.PluginsConfig)
+	assert.Equal(t, 30*time.Second, config.DiscoveriesPeriodsConfig.Plugins)
+	assert.Equal(t, 20*time.Second, config.DiscoveriesTimeoutsConfig.Plugins)
+}
+
+func TestSSHAddressRequiredOnlyOnStart(t *testing.T) {
+	os.Clearenv()
+	viper.Reset()
+
+	cmd := NewAgentCmd()
+	discoverCmd, _, _ := cmd.Find([]string{"discover"})
+	discoverCmd.Run = func(cmd *cobra.Command, args []string) {
+		// do nothing
+	}
+	cmd.SetArgs([]string{"discover"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.Execute()
+
+	config, err := LoadDiscoveriesConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "", config.SSHAddress)
+
+	_, err = LoadConfig()
+	assert.EqualError(t, err, "ssh-address is required, cannot start agent")
+}
@@ -0,0 +1,167 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/trento-project/trento/agent"
+	"github.com/trento-project/trento/agent/discovery"
+	"github.com/trento-project/trento/agent/discovery/collector"
+)
+
+func addDiscoverCmd(agentCmd *cobra.Command) {
+	var outputPath string
+	var scenarioLayout bool
+
+	discoverCmd := &cobra.Command{
+		Use:   "discover [discovery type...]",
+		Short: "Run the discoveries once and print the data that would be sent to the collector",
+		Long: `Run the given discoveries, or all of them if none is given, only once.
+The data is not sent to the collector: the exact request bodies are printed to stdout,
+one per line, or written to the output path, one file per discovery type.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if scenarioLayout && outputPath == "" {
+				log.Fatal("--scenario-layout requires an output path")
+			}
+
+			discover(cmd.OutOrStdout(), args, outputPath, scenarioLayout)
+		},
+	}
+
+	addDiscoveriesFlags(discoverCmd)
+
+	discoverCmd.Flags().StringVar(&outputPath, "path", "", "Directory where the discovered data is written. Printed to stdout if empty")
+	discoverCmd.Flags().BoolVar(&scenarioLayout, "scenario-layout", false, "Write the discovered data with the same layout as 'trento ctl dump-scenario'")
+
+	agentCmd.AddCommand(discoverCmd)
+}
+
+func discover(out io.Writer, discoveryTypes []string, outputPath string, scenarioLayout bool) {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	config, err := LoadDiscoveriesConfig()
+	if err != nil {
+		log.Fatal("Failed to create the discoveries configuration: ", err)
+	}
+
+	agentID, err := loadAgentID()
+	if err != nil {
+		log.Fatal("Failed to create the discoveries configuration: ", err)
+	}
+
+	localClient, err := collector.NewLocalClient(agentID)
+	if err != nil {
+		log.Fatal("Failed to create the local collector client: ", err)
+	}
+
+	discoveries, err := agent.NewDiscoveries(localClient, *config)
+	if err != nil {
+		log.Fatal("Failed to create the discoveries: ", err)
+	}
+
+	discoveries, err = selectDiscoveries(discoveries, discoveryTypes)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	failed := 0
+	for _, d := range discoveries {
+		result, err := agent.RunDiscovery(ctx, d)
+		if err != nil {
+			log.Errorf("Error while running discovery '%s': %s", d.GetId(), err)
+			failed++
+			continue
+		}
+		log.Infof("%s discovery output: %s", d.GetId(), result)
+	}
+
+	err = writeDiscoveredData(out, outputPath, scenarioLayout, localClient.AgentID(), localClient.Published())
+	if err != nil {
+		log.Fatal("Error while writing the discovered data: ", err)
+	}
+
+	if failed > 0 {
+		log.Fatalf("%d discoveries failed", failed)
+	}
+}
+
+// selectDiscoveries keeps the discoveries with the given ids, or all of them if none is given
+func selectDiscoveries(discoveries []discovery.Discovery, ids []string) ([]discovery.Discovery, error) {
+	if len(ids) == 0 {
+		return discoveries, nil
+	}
+
+	available := make(map[string]discovery.Discovery)
+	var availableIds []string
+	for _, d := range discoveries {
+		available[d.GetId()] = d
+		availableIds = append(availableIds, d.GetId())
+	}
+
+	var selected []discovery.Discovery
+	for _, id := range ids {
+		d, ok := available[id]
+		if !ok {
+			return nil, errors.Errorf("unknown discovery type %s, should be one of: %s", id, strings.Join(availableIds, ", "))
+		}
+		selected = append(selected, d)
+	}
+
+	return selected, nil
+}
+
+// writeDiscoveredData prints the request bodies to out if no output path is given, otherwise it writes
+// one file per discovery type, either as the request body or with the dump-scenario layout
+func writeDiscoveredData(out io.Writer, outputPath string, scenarioLayout bool, agentID string, published []collector.LocalData) error {
+	if outputPath == "" {
+		for _, data := range published {
+			_, err := fmt.Fprintln(out, string(data.RequestBody))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	err := os.MkdirAll(outputPath, 0700)
+	if err != nil {
+		return errors.Wrap(err, "could not create the output directory")
+	}
+
+	for _, data := range published {
+		content := data.RequestBody
+		fileName := fmt.Sprintf("%s.json", data.DiscoveryType)
+
+		if scenarioLayout {
+			content, err = json.MarshalIndent(map[string]interface{}{
+				"agent_id":       agentID,
+				"discovery_type": data.DiscoveryType,
+				"payload":        data.Payload,
+			}, "", " ")
+			if err != nil {
+				return err
+			}
+			fileName = fmt.Sprintf("%s_%s.json", agentID, data.DiscoveryType)
+		}
+
+		err = os.WriteFile(filepath.Join(outputPath, fileName), content, 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/trento-project/trento/agent/discovery"
+	"github.com/trento-project/trento/agent/discovery/collector"
+)
+
+type dummyDiscovery struct {
+	id string
+}
+
+func (d dummyDiscovery) GetId() string {
+	return d.id
+}
+
+func (d dummyDiscovery) Discover(ctx context.Context) (string, error) {
+	return "", nil
+}
+
+func (d dummyDiscovery) GetInterval() time.Duration {
+	return time.Second
+}
+
+func (d dummyDiscovery) GetTimeout() time.Duration {
+	return time.Second
+}
+
+func TestSelectDiscoveries(t *testing.T) {
+	discoveries := []discovery.Discovery{
+		dummyDiscovery{id: "host_discovery"},
+		dummyDiscovery{id: "cloud_discovery"},
+	}
+
+	selected, err := selectDiscoveries(discoveries, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, discoveries, selected)
+
+	selected, err = selectDiscoveries(discoveries, []string{"cloud_discovery"})
+	assert.NoError(t, err)
+	assert.Equal(t, []discovery.Discovery{dummyDiscovery{id: "cloud_discovery"}}, selected)
+
+	_, err = selectDiscoveries(discoveries, []string{"other_discovery"})
+	assert.EqualError(t, err, "unknown discovery type other_discovery, should be one of: host_discovery, cloud_discovery")
+}
+
+var discoveredData = []collector.LocalData{
+	{
+		DiscoveryType: "host_discovery",
+		Payload:       json.RawMessage(`{"hostname":"host1"}`),
+		RequestBody:   []byte(`{"agent_id":"some-agent","discovery_type":"host_discovery","payload":{"hostname":"host1"}}`),
+	},
+	{
+		DiscoveryType: "cloud_discovery",
+		Payload:       json.RawMessage(`{"provider":""}`),
+		RequestBody:   []byte(`{"agent_id":"some-agent","discovery_type":"cloud_discovery","payload":{"provider":""}}`),
+	},
+}
+
+func TestWriteDiscoveredDataToStdout(t *testing.T) {
+	var out bytes.Buffer
+
+	err := writeDiscoveredData(&out, "", false, "some-agent", discoveredData)
+	assert.NoError(t, err)
+
+	assert.Equal(t,
+		`{"agent_id":"some-agent","discovery_type":"host_discovery","payload":{"hostname":"host1"}}`+"\n"+
+			`{"agent_id":"some-agent","discovery_type":"cloud_discovery","payload":{"provider":""}}`+"\n",
+		out.String())
+}
+
+func TestWriteDiscoveredDataToDirectory(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "discovered")
+
+	err := writeDiscoveredData(nil, outputPath, false, "some-agent", discoveredData)
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(outputPath, "host_discovery.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, discoveredData[0].RequestBody, content)
+
+	content, err = os.ReadFile(filepath.Join(outputPath, "cloud_discovery.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, discoveredData[1].RequestBody, content)
+}
+
+func TestWriteDiscoveredDataWithScenarioLayout(t *testing.T) {
+	outputPath := t.TempDir()
+
+	err := writeDiscoveredData(nil, outputPath, true, "some-agent", discoveredData)
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(outputPath, "some-agent_host_discovery.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, "{\n \"agent_id\": \"some-agent\",\n \"discovery_type\": \"host_discovery\",\n \"payload\": {\n  \"hostname\": \"host1\"\n }\n}", string(content))
+
+	_, err = os.Stat(filepath.Join(outputPath, "some-agent_cloud_discovery.json"))
+	assert.NoError(t, err)
+}
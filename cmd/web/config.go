@@ -33,6 +33,18 @@ func LoadConfig() (*web.Config, error) {
 		}
 	}
 
+	caKey := viper.GetString("ca-key")
+	agentCertValidity := viper.GetDuration("agent-cert-validity")
+
+	if caKey != "" {
+		if !enablemTLS {
+			return nil, fmt.Errorf("the internal CA requires mTLS to be enabled")
+		}
+		if agentCertValidity <= 0 {
+			return nil, fmt.Errorf("the agent certificates validity must be positive")
+		}
+	}
+
 	return &web.Config{
 		Host:          viper.GetString("host"),
 		Port:          viper.GetInt("port"),
@@ -48,6 +60,9 @@ func LoadConfig() (*web.Config, error) {
 			User:      viper.GetString("grafana-user"),
 			Password:  viper.GetString("grafana-password"),
 		},
-		PrometheusURL: viper.GetString("prometheus-url"),
+		PrometheusURL:     viper.GetString("prometheus-url"),
+		EnableAgentAuth:   viper.GetBool("enable-agent-auth"),
+		CAKey:             caKey,
+		AgentCertValidity: agentCertValidity,
 	}, nil
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/suite"
@@ -64,7 +65,10 @@ func (suite *WebCmdTestSuite) TearDownTest() {
 			User:      "adminuser",
 			Password:  "password",
 		},
-		PrometheusURL: "http://prometheus-host:9090",
+		PrometheusURL:     "http://prometheus-host:9090",
+		EnableAgentAuth:   true,
+		CAKey:             "some-ca-key",
+		AgentCertValidity: 720 * time.Hour,
 	}
 	config, err := LoadConfig()
 	suite.NoError(err)
@@ -82,6 +86,9 @@ func (suite *WebCmdTestSuite) TestConfigFromFlags() {
 		"--cert=some-cert",
 		"--key=some-key",
 		"--ca=some-ca",
+		"--enable-agent-auth",
+		"--ca-key=some-ca-key",
+		"--agent-cert-validity=720h",
 		"--db-host=some-db-host",
 		"--db-port=6543",
 		"--db-user=postgres",
@@ -103,6 +110,9 @@ func (suite *WebCmdTestSuite) TestConfigFromEnv() {
 	os.Setenv("TRENTO_CERT", "some-cert")
 	os.Setenv("TRENTO_KEY", "some-key")
 	os.Setenv("TRENTO_CA", "some-ca")
+	os.Setenv("TRENTO_ENABLE_AGENT_AUTH", "true")
+	os.Setenv("TRENTO_CA_KEY", "some-ca-key")
+	os.Setenv("TRENTO_AGENT_CERT_VALIDITY", "720h")
 	os.Setenv("TRENTO_DB_HOST", "some-db-host")
 	os.Setenv("TRENTO_DB_PORT", "6543")
 	os.Setenv("TRENTO_DB_USER", "postgres")
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -45,6 +46,9 @@ func addServeCmd(webCmd *cobra.Command) {
 	var cert string
 	var key string
 	var ca string
+	var caKey string
+	var agentCertValidity time.Duration
+	var enableAgentAuth bool
 
 	var grafanaPublicURL string
 	var grafanaApiURL string
@@ -67,6 +71,9 @@ func addServeCmd(webCmd *cobra.Command) {
 	serveCmd.Flags().StringVar(&cert, "cert", "", "mTLS server certificate")
 	serveCmd.Flags().StringVar(&key, "key", "", "mTLS server key")
 	serveCmd.Flags().StringVar(&ca, "ca", "", "mTLS Certificate Authority")
+	serveCmd.Flags().StringVar(&caKey, "ca-key", "", "Private key of the mTLS Certificate Authority, enabling the internal CA which issues the agent certificates on approval. The agents must then use a certificate issued by it")
+	serveCmd.Flags().DurationVar(&agentCertValidity, "agent-cert-validity", 90*24*time.Hour, "Validity of the agent certificates issued by the internal CA")
+	serveCmd.Flags().BoolVar(&enableAgentAuth, "enable-agent-auth", false, "Require the agents to authenticate with the credentials got by enrolling with a one-time token")
 
 	serveCmd.Flags().StringVar(&grafanaPublicURL, "grafana-public-url", "", "Browsable Grafana URL, if not provided, the API url will be used. This is the base url for iframes embedding.")
 	serveCmd.Flags().StringVar(&grafanaApiURL, "grafana-api-url", "http://localhost:3000", "Grafana API URL")
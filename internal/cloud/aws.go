@@ -0,0 +1,217 @@
+/*
+Based on https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/configuring-instance-metadata-service.html
+*/
+
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	awsTokenTTL = "21600"
+)
+
+var awsMetadataUrl = "http://169.254.169.254"
+
+type AwsMetadata struct {
+	AccountId         string                 `json:"accountId,omitempty" mapstructure:"accountid,omitempty"`
+	AmiId             string                 `json:"imageId,omitempty" mapstructure:"imageid,omitempty"`
+	Architecture      string                 `json:"architecture,omitempty" mapstructure:"architecture,omitempty"`
+	AvailabilityZone  string                 `json:"availabilityZone,omitempty" mapstructure:"availabilityzone,omitempty"`
+	InstanceId        string                 `json:"instanceId,omitempty" mapstructure:"instanceid,omitempty"`
+	InstanceType      string                 `json:"instanceType,omitempty" mapstructure:"instancetype,omitempty"`
+	PrivateIp         string                 `json:"privateIp,omitempty" mapstructure:"privateip,omitempty"`
+	Region            string                 `json:"region,omitempty" mapstructure:"region,omitempty"`
+	NetworkInterfaces []*AwsNetworkInterface `json:"networkInterfaces,omitempty" mapstructure:"networkinterfaces,omitempty"`
+	Tags              map[string]string      `json:"tags,omitempty" mapstructure:"tags,omitempty"`
+}
+
+type AwsNetworkInterface struct {
+	InterfaceId string   `json:"interfaceId,omitempty" mapstructure:"interfaceid,omitempty"`
+	MacAddress  string   `json:"macAddress,omitempty" mapstructure:"macaddress,omitempty"`
+	PrivateIps  []string `json:"privateIps,omitempty" mapstructure:"privateips,omitempty"`
+	PublicIps   []string `json:"publicIps,omitempty" mapstructure:"publicips,omitempty"`
+	SubnetId    string   `json:"subnetId,omitempty" mapstructure:"subnetid,omitempty"`
+	VpcId       string   `json:"vpcId,omitempty" mapstructure:"vpcid,omitempty"`
+}
+
+// awsMetadataClient queries the instance metadata service using a session token (IMDSv2)
+type awsMetadataClient struct {
+	ctx   context.Context
+	token string
+}
+
+// errAwsMetadataNotFound is returned for the metadata entries which are not available in the instance,
+// e.g. the public IPs of a private interface or the tags when they are not exposed in the metadata
+var errAwsMetadataNotFound = fmt.Errorf("aws metadata entry not found")
+
+func NewAwsMetadata(ctx context.Context) (*AwsMetadata, error) {
+	m := &AwsMetadata{}
+
+	log.Debug("Requesting Aws metadata...")
+
+	c, err := newAwsMetadataClient(ctx)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	identity, err := c.get("/latest/dynamic/instance-identity/document")
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	log.Debugln(identity)
+
+	err = json.Unmarshal([]byte(identity), m)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	m.NetworkInterfaces, err = c.getNetworkInterfaces()
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	m.Tags, err = c.getTags()
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func newAwsMetadataClient(ctx context.Context) (*awsMetadataClient, error) {
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPut, awsMetadataUrl+"/latest/api/token", nil)
+	req.Header.Add("X-aws-ec2-metadata-token-ttl-seconds", awsTokenTTL)
+
+	token, err := doAwsRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not get an aws metadata session token: %w", err)
+	}
+
+	return &awsMetadataClient{ctx: ctx, token: token}, nil
+}
+
+func (c *awsMetadataClient) get(path string) (string, error) {
+	req, _ := http.NewRequestWithContext(c.ctx, http.MethodGet, awsMetadataUrl+path, nil)
+	req.Header.Add("X-aws-ec2-metadata-token", c.token)
+
+	return doAwsRequest(req)
+}
+
+// list returns the entries of a metadata category, one per line
+func (c *awsMetadataClient) list(path string) ([]string, error) {
+	body, err := c.get(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []string
+	for _, entry := range strings.Split(body, "\n") {
+		entry = strings.TrimSpace(entry)
+		if entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// listOptional is like list, but returns no entries if the category is not available
+func (c *awsMetadataClient) listOptional(path string) ([]string, error) {
+	entries, err := c.list(path)
+	if err == errAwsMetadataNotFound {
+		return nil, nil
+	}
+
+	return entries, err
+}
+
+func (c *awsMetadataClient) getNetworkInterfaces() ([]*AwsNetworkInterface, error) {
+	macs, err := c.list("/latest/meta-data/network/interfaces/macs/")
+	if err != nil {
+		return nil, err
+	}
+
+	var interfaces []*AwsNetworkInterface
+	for _, mac := range macs {
+		mac = strings.TrimSuffix(mac, "/")
+		prefix := fmt.Sprintf("/latest/meta-data/network/interfaces/macs/%s/", mac)
+
+		nic := &AwsNetworkInterface{MacAddress: mac}
+
+		if nic.InterfaceId, err = c.get(prefix + "interface-id"); err != nil {
+			return nil, err
+		}
+		if nic.SubnetId, err = c.get(prefix + "subnet-id"); err != nil {
+			return nil, err
+		}
+		if nic.VpcId, err = c.get(prefix + "vpc-id"); err != nil {
+			return nil, err
+		}
+		if nic.PrivateIps, err = c.listOptional(prefix + "local-ipv4s"); err != nil {
+			return nil, err
+		}
+		if nic.PublicIps, err = c.listOptional(prefix + "public-ipv4s"); err != nil {
+			return nil, err
+		}
+
+		interfaces = append(interfaces, nic)
+	}
+
+	return interfaces, nil
+}
+
+// getTags returns the instance tags, only available if allowed in the instance metadata options
+func (c *awsMetadataClient) getTags() (map[string]string, error) {
+	keys, err := c.listOptional("/latest/meta-data/tags/instance")
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]string)
+	for _, key := range keys {
+		value, err := c.get("/latest/meta-data/tags/instance/" + key)
+		if err != nil {
+			return nil, err
+		}
+		tags[key] = value
+	}
+
+	return tags, nil
+}
+
+func doAwsRequest(req *http.Request) (string, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", errAwsMetadataNotFound
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d requesting %s: %s", resp.StatusCode, req.URL.Path, string(body))
+	}
+
+	return strings.TrimSpace(string(body)), nil
+}
@@ -0,0 +1,144 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const awsDummyToken = "dummy-token"
+
+// newImdsServer returns a stand-in of the instance metadata service, only answering requests with a session token
+func newImdsServer(entries map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/latest/api/token" {
+			if r.Method != http.MethodPut || r.Header.Get("X-aws-ec2-metadata-token-ttl-seconds") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, awsDummyToken)
+			return
+		}
+
+		if r.Header.Get("X-aws-ec2-metadata-token") != awsDummyToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		entry, ok := entries[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, entry)
+	}))
+}
+
+var imdsEntries = map[string]string{
+	"/latest/dynamic/instance-identity/document": `{
+		"accountId" : "123456789012",
+		"architecture" : "x86_64",
+		"availabilityZone" : "eu-central-1a",
+		"imageId" : "ami-0a1b2c3d4e5f6a7b8",
+		"instanceId" : "i-0123456789abcdef0",
+		"instanceType" : "r5.4xlarge",
+		"privateIp" : "10.0.1.10",
+		"region" : "eu-central-1"
+	}`,
+	"/latest/meta-data/network/interfaces/macs/":                               "0a:1b:2c:3d:4e:5f/\n0a:1b:2c:3d:4e:60/",
+	"/latest/meta-data/network/interfaces/macs/0a:1b:2c:3d:4e:5f/interface-id": "eni-0123456789abcdef0",
+	"/latest/meta-data/network/interfaces/macs/0a:1b:2c:3d:4e:5f/subnet-id":    "subnet-0123456789abcdef0",
+	"/latest/meta-data/network/interfaces/macs/0a:1b:2c:3d:4e:5f/vpc-id":       "vpc-0123456789abcdef0",
+	"/latest/meta-data/network/interfaces/macs/0a:1b:2c:3d:4e:5f/local-ipv4s":  "10.0.1.10\n10.0.1.11",
+	"/latest/meta-data/network/interfaces/macs/0a:1b:2c:3d:4e:5f/public-ipv4s": "3.120.1.10",
+	"/latest/meta-data/network/interfaces/macs/0a:1b:2c:3d:4e:60/interface-id": "eni-0123456789abcdef1",
+	"/latest/meta-data/network/interfaces/macs/0a:1b:2c:3d:4e:60/subnet-id":    "subnet-0123456789abcdef1",
+	"/latest/meta-data/network/interfaces/macs/0a:1b:2c:3d:4e:60/vpc-id":       "vpc-0123456789abcdef0",
+	"/latest/meta-data/network/interfaces/macs/0a:1b:2c:3d:4e:60/local-ipv4s":  "10.0.2.10",
+	"/latest/meta-data/tags/instance":                                          "Name\nworkload",
+	"/latest/meta-data/tags/instance/Name":                                     "vmhana01",
+	"/latest/meta-data/tags/instance/workload":                                 "sap",
+}
+
+func TestNewAwsMetadata(t *testing.T) {
+	server := newImdsServer(imdsEntries)
+	defer server.Close()
+
+	client = server.Client()
+	awsMetadataUrl = server.URL
+
+	m, err := NewAwsMetadata(context.Background())
+
+	expectedMeta := &AwsMetadata{
+		AccountId:        "123456789012",
+		AmiId:            "ami-0a1b2c3d4e5f6a7b8",
+		Architecture:     "x86_64",
+		AvailabilityZone: "eu-central-1a",
+		InstanceId:       "i-0123456789abcdef0",
+		InstanceType:     "r5.4xlarge",
+		PrivateIp:        "10.0.1.10",
+		Region:           "eu-central-1",
+		NetworkInterfaces: []*AwsNetworkInterface{
+			{
+				InterfaceId: "eni-0123456789abcdef0",
+				MacAddress:  "0a:1b:2c:3d:4e:5f",
+				PrivateIps:  []string{"10.0.1.10", "10.0.1.11"},
+				PublicIps:   []string{"3.120.1.10"},
+				SubnetId:    "subnet-0123456789abcdef0",
+				VpcId:       "vpc-0123456789abcdef0",
+			},
+			{
+				InterfaceId: "eni-0123456789abcdef1",
+				MacAddress:  "0a:1b:2c:3d:4e:60",
+				PrivateIps:  []string{"10.0.2.10"},
+				SubnetId:    "subnet-0123456789abcdef1",
+				VpcId:       "vpc-0123456789abcdef0",
+			},
+		},
+		Tags: map[string]string{
+			"Name":     "vmhana01",
+			"workload": "sap",
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedMeta, m)
+}
+
+func TestNewAwsMetadataWithoutTags(t *testing.T) {
+	entries := make(map[string]string)
+	for path, entry := range imdsEntries {
+		entries[path] = entry
+	}
+	delete(entries, "/latest/meta-data/tags/instance")
+
+	server := newImdsServer(entries)
+	defer server.Close()
+
+	client = server.Client()
+	awsMetadataUrl = server.URL
+
+	m, err := NewAwsMetadata(context.Background())
+
+	assert.NoError(t, err)
+	assert.Empty(t, m.Tags)
+	assert.Len(t, m.NetworkInterfaces, 2)
+}
+
+func TestNewAwsMetadataTokenError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client = server.Client()
+	awsMetadataUrl = server.URL
+
+	_, err := NewAwsMetadata(context.Background())
+
+	assert.EqualError(t, err, "could not get an aws metadata session token: unexpected status 403 requesting /latest/api/token: ")
+}
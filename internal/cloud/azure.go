@@ -6,6 +6,7 @@ package cloud
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -149,11 +150,11 @@ type HTTPClient interface {
 
 var client HTTPClient = &http.Client{Transport: &http.Transport{Proxy: nil}}
 
-func NewAzureMetadata() (*AzureMetadata, error) {
+func NewAzureMetadata(ctx context.Context) (*AzureMetadata, error) {
 	var err error
 	m := &AzureMetadata{}
 
-	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s/metadata/instance", azureApiAddress), nil)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://%s/metadata/instance", azureApiAddress), nil)
 	req.Header.Add("Metadata", "True")
 
 	q := req.URL.Query()
@@ -2,6 +2,7 @@ package cloud
 
 import (
 	"bytes"
+	"context"
 	"io/ioutil"
 	"net/http"
 	"os"
@@ -30,7 +31,7 @@ func TestNewAzureMetadata(t *testing.T) {
 
 	client = clientMock
 
-	m, err := NewAzureMetadata()
+	m, err := NewAzureMetadata(context.Background())
 
 	expectedMeta := &AzureMetadata{
 		Compute: Compute{
@@ -0,0 +1,111 @@
+/*
+Based on https://cloud.google.com/compute/docs/metadata/querying-metadata
+*/
+
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var gcpMetadataUrl = "http://metadata.google.internal"
+
+type GcpMetadata struct {
+	Instance GcpInstance `json:"instance,omitempty" mapstructure:"instance,omitempty"`
+	Project  GcpProject  `json:"project,omitempty" mapstructure:"project,omitempty"`
+}
+
+type GcpInstance struct {
+	CpuPlatform       string                 `json:"cpuPlatform,omitempty" mapstructure:"cpuplatform,omitempty"`
+	Disks             []*GcpDisk             `json:"disks,omitempty" mapstructure:"disks,omitempty"`
+	Hostname          string                 `json:"hostname,omitempty" mapstructure:"hostname,omitempty"`
+	Image             string                 `json:"image,omitempty" mapstructure:"image,omitempty"`
+	MachineType       string                 `json:"machineType,omitempty" mapstructure:"machinetype,omitempty"`
+	Name              string                 `json:"name,omitempty" mapstructure:"name,omitempty"`
+	NetworkInterfaces []*GcpNetworkInterface `json:"networkInterfaces,omitempty" mapstructure:"networkinterfaces,omitempty"`
+	Tags              []string               `json:"tags,omitempty" mapstructure:"tags,omitempty"`
+	Zone              string                 `json:"zone,omitempty" mapstructure:"zone,omitempty"`
+}
+
+type GcpDisk struct {
+	DeviceName string `json:"deviceName,omitempty" mapstructure:"devicename,omitempty"`
+	Index      int    `json:"index,omitempty" mapstructure:"index,omitempty"`
+	Interface  string `json:"interface,omitempty" mapstructure:"interface,omitempty"`
+	Mode       string `json:"mode,omitempty" mapstructure:"mode,omitempty"`
+	Type       string `json:"type,omitempty" mapstructure:"type,omitempty"`
+}
+
+type GcpNetworkInterface struct {
+	AccessConfigs []*GcpAccessConfig `json:"accessConfigs,omitempty" mapstructure:"accessconfigs,omitempty"`
+	Gateway       string             `json:"gateway,omitempty" mapstructure:"gateway,omitempty"`
+	Ip            string             `json:"ip,omitempty" mapstructure:"ip,omitempty"`
+	Mac           string             `json:"mac,omitempty" mapstructure:"mac,omitempty"`
+	Mtu           int                `json:"mtu,omitempty" mapstructure:"mtu,omitempty"`
+	Network       string             `json:"network,omitempty" mapstructure:"network,omitempty"`
+}
+
+type GcpAccessConfig struct {
+	ExternalIp string `json:"externalIp,omitempty" mapstructure:"externalip,omitempty"`
+	Type       string `json:"type,omitempty" mapstructure:"type,omitempty"`
+}
+
+type GcpProject struct {
+	NumericProjectId int64  `json:"numericProjectId,omitempty" mapstructure:"numericprojectid,omitempty"`
+	ProjectId        string `json:"projectId,omitempty" mapstructure:"projectid,omitempty"`
+}
+
+// NewGcpMetadata queries the instance and project metadata. Instance labels are not served
+// by the metadata server, so the instance network tags are collected instead
+func NewGcpMetadata(ctx context.Context) (*GcpMetadata, error) {
+	m := &GcpMetadata{}
+
+	log.Debug("Requesting Gcp metadata...")
+
+	err := getGcpMetadata(ctx, "/computeMetadata/v1/instance/", &m.Instance)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	err = getGcpMetadata(ctx, "/computeMetadata/v1/project/", &m.Project)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func getGcpMetadata(ctx context.Context, path string, value interface{}) error {
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, gcpMetadataUrl+path, nil)
+	req.Header.Add("Metadata-Flavor", "Google")
+
+	q := req.URL.Query()
+	q.Add("recursive", "true")
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d requesting %s: %s", resp.StatusCode, path, string(body))
+	}
+
+	log.Debugln(string(body))
+
+	return json.Unmarshal(body, value)
+}
@@ -0,0 +1,109 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newGcpMetadataServer returns a stand-in of the metadata server, only answering requests with the metadata flavor header
+func newGcpMetadataServer() *httptest.Server {
+	aFile, _ := os.Open("../../test/gcp_metadata")
+	instance, _ := ioutil.ReadAll(aFile)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" || r.URL.Query().Get("recursive") != "true" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/computeMetadata/v1/instance/":
+			w.Write(instance)
+		case "/computeMetadata/v1/project/":
+			fmt.Fprint(w, `{"attributes":{},"numericProjectId":123456789012,"projectId":"sap-landscape"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestNewGcpMetadata(t *testing.T) {
+	server := newGcpMetadataServer()
+	defer server.Close()
+
+	client = server.Client()
+	gcpMetadataUrl = server.URL
+
+	m, err := NewGcpMetadata(context.Background())
+
+	expectedMeta := &GcpMetadata{
+		Instance: GcpInstance{
+			CpuPlatform: "Intel Broadwell",
+			Disks: []*GcpDisk{
+				{
+					DeviceName: "persistent-disk-0",
+					Index:      0,
+					Interface:  "SCSI",
+					Mode:       "READ_WRITE",
+					Type:       "PERSISTENT",
+				},
+				{
+					DeviceName: "vmhana01-hana-data",
+					Index:      1,
+					Interface:  "SCSI",
+					Mode:       "READ_WRITE",
+					Type:       "PERSISTENT-SSD",
+				},
+			},
+			Hostname:    "vmhana01.europe-west1-b.c.sap-landscape.internal",
+			Image:       "projects/suse-sap-cloud/global/images/sles-15-sp2-sap-v20210604",
+			MachineType: "projects/123456789012/machineTypes/n1-highmem-32",
+			Name:        "vmhana01",
+			NetworkInterfaces: []*GcpNetworkInterface{
+				{
+					AccessConfigs: []*GcpAccessConfig{
+						{
+							ExternalIp: "34.77.10.10",
+							Type:       "ONE_TO_ONE_NAT",
+						},
+					},
+					Gateway: "10.0.0.1",
+					Ip:      "10.0.0.10",
+					Mac:     "42:01:0a:00:00:0a",
+					Mtu:     1460,
+					Network: "projects/123456789012/networks/sap-network",
+				},
+			},
+			Tags: []string{"hana", "sap"},
+			Zone: "projects/123456789012/zones/europe-west1-b",
+		},
+		Project: GcpProject{
+			NumericProjectId: 123456789012,
+			ProjectId:        "sap-landscape",
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedMeta, m)
+}
+
+func TestNewGcpMetadataError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client = server.Client()
+	gcpMetadataUrl = server.URL
+
+	_, err := NewGcpMetadata(context.Background())
+
+	assert.EqualError(t, err, "unexpected status 503 requesting /computeMetadata/v1/instance/: ")
+}
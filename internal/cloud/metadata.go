@@ -1,11 +1,13 @@
 package cloud
 
 import (
+	"context"
 	"os/exec"
 	"regexp"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/trento/internal"
 )
 
 const (
@@ -29,9 +31,9 @@ var customExecCommand CustomCommand = exec.Command
 // All these detection methods are based in crmsh code, which has been refined over the years
 // https://github.com/ClusterLabs/crmsh/blob/master/crmsh/utils.py#L2009
 
-func identifyAzure() (bool, error) {
+func identifyAzure(ctx context.Context) (bool, error) {
 	log.Debug("Checking if the VM is running on Azure...")
-	output, err := customExecCommand("dmidecode", "-s", "chassis-asset-tag").Output()
+	output, err := internal.CommandOutput(ctx, customExecCommand("dmidecode", "-s", "chassis-asset-tag"))
 	if err != nil {
 		return false, err
 	}
@@ -42,9 +44,9 @@ func identifyAzure() (bool, error) {
 	return provider == azureDmiTag, nil
 }
 
-func identifyAws() (bool, error) {
+func identifyAws(ctx context.Context) (bool, error) {
 	log.Debug("Checking if the VM is running on Aws...")
-	output, err := customExecCommand("dmidecode", "-s", "system-version").Output()
+	output, err := internal.CommandOutput(ctx, customExecCommand("dmidecode", "-s", "system-version"))
 	if err != nil {
 		return false, err
 	}
@@ -55,9 +57,9 @@ func identifyAws() (bool, error) {
 	return regexp.MatchString(".*amazon.*", provider)
 }
 
-func identifyGcp() (bool, error) {
+func identifyGcp(ctx context.Context) (bool, error) {
 	log.Debug("Checking if the VM is running on Gcp...")
-	output, err := customExecCommand("dmidecode", "-s", "bios-vendor").Output()
+	output, err := internal.CommandOutput(ctx, customExecCommand("dmidecode", "-s", "bios-vendor"))
 	if err != nil {
 		return false, err
 	}
@@ -68,24 +70,24 @@ func identifyGcp() (bool, error) {
 	return regexp.MatchString(".*Google.*", provider)
 }
 
-func IdentifyCloudProvider() (string, error) {
+func IdentifyCloudProvider(ctx context.Context) (string, error) {
 	log.Info("Identifying if the VM is running in a cloud environment...")
 
-	if result, err := identifyAzure(); err != nil {
+	if result, err := identifyAzure(ctx); err != nil {
 		return "", err
 	} else if result {
 		log.Infof("VM is running on %s", Azure)
 		return Azure, nil
 	}
 
-	if result, err := identifyAws(); err != nil {
+	if result, err := identifyAws(ctx); err != nil {
 		return "", err
 	} else if result {
 		log.Infof("VM is running on %s", Aws)
 		return Aws, nil
 	}
 
-	if result, err := identifyGcp(); err != nil {
+	if result, err := identifyGcp(ctx); err != nil {
 		return "", err
 	} else if result {
 		log.Infof("VM is running on %s", Gcp)
@@ -96,11 +98,11 @@ func IdentifyCloudProvider() (string, error) {
 	return "", nil
 }
 
-func NewCloudInstance() (*CloudInstance, error) {
+func NewCloudInstance(ctx context.Context) (*CloudInstance, error) {
 	var err error
 	var cloudMetadata interface{}
 
-	provider, err := IdentifyCloudProvider()
+	provider, err := IdentifyCloudProvider(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -111,7 +113,17 @@ func NewCloudInstance() (*CloudInstance, error) {
 
 	switch provider {
 	case Azure:
-		cloudMetadata, err = NewAzureMetadata()
+		cloudMetadata, err = NewAzureMetadata(ctx)
+		if err != nil {
+			return nil, err
+		}
+	case Aws:
+		cloudMetadata, err = NewAwsMetadata(ctx)
+		if err != nil {
+			return nil, err
+		}
+	case Gcp:
+		cloudMetadata, err = NewGcpMetadata(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -2,6 +2,7 @@ package cloud
 
 import (
 	"bytes"
+	"context"
 	"io/ioutil"
 	"net/http"
 	"os/exec"
@@ -25,7 +26,7 @@ func TestIdentifyCloudProviderErr(t *testing.T) {
 		mockDmidecodeErr(),
 	)
 
-	provider, err := IdentifyCloudProvider()
+	provider, err := IdentifyCloudProvider(context.Background())
 
 	assert.Equal(t, "", provider)
 	assert.EqualError(t, err, "exec: \"error\": executable file not found in $PATH")
@@ -44,7 +45,7 @@ func TestIdentifyCloudProviderAzure(t *testing.T) {
 		mockDmidecodeAzure(),
 	)
 
-	provider, err := IdentifyCloudProvider()
+	provider, err := IdentifyCloudProvider(context.Background())
 
 	assert.Equal(t, "azure", provider)
 	assert.NoError(t, err)
@@ -67,7 +68,7 @@ func TestIdentifyCloudProviderAws(t *testing.T) {
 		mockDmidecodeAws(),
 	)
 
-	provider, err := IdentifyCloudProvider()
+	provider, err := IdentifyCloudProvider(context.Background())
 
 	assert.Equal(t, "aws", provider)
 	assert.NoError(t, err)
@@ -94,7 +95,7 @@ func TestIdentifyCloudProviderGcp(t *testing.T) {
 		mockDmidecodeGcp(),
 	)
 
-	provider, err := IdentifyCloudProvider()
+	provider, err := IdentifyCloudProvider(context.Background())
 
 	assert.Equal(t, "gcp", provider)
 	assert.NoError(t, err)
@@ -121,7 +122,7 @@ func TestIdentifyCloudProviderNoCloud(t *testing.T) {
 		mockDmidecodeNoCloud(),
 	)
 
-	provider, err := IdentifyCloudProvider()
+	provider, err := IdentifyCloudProvider(context.Background())
 
 	assert.Equal(t, "", provider)
 	assert.NoError(t, err)
@@ -151,7 +152,7 @@ func TestNewCloudInstanceAzure(t *testing.T) {
 
 	client = clientMock
 
-	c, err := NewCloudInstance()
+	c, err := NewCloudInstance(context.Background())
 
 	assert.NoError(t, err)
 	assert.Equal(t, "azure", c.Provider)
@@ -159,6 +160,64 @@ func TestNewCloudInstanceAzure(t *testing.T) {
 	assert.Equal(t, "test", meta.Compute.Name)
 }
 
+func TestNewCloudInstanceAws(t *testing.T) {
+	mockCommand := new(mocks.CustomCommand)
+
+	customExecCommand = mockCommand.Execute
+
+	mockCommand.On("Execute", "dmidecode", "-s", "chassis-asset-tag").Return(
+		mockDmidecodeNoCloud(),
+	)
+
+	mockCommand.On("Execute", "dmidecode", "-s", "system-version").Return(
+		mockDmidecodeAws(),
+	)
+
+	server := newImdsServer(imdsEntries)
+	defer server.Close()
+
+	client = server.Client()
+	awsMetadataUrl = server.URL
+
+	c, err := NewCloudInstance(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "aws", c.Provider)
+	meta := c.Metadata.(*AwsMetadata)
+	assert.Equal(t, "i-0123456789abcdef0", meta.InstanceId)
+}
+
+func TestNewCloudInstanceGcp(t *testing.T) {
+	mockCommand := new(mocks.CustomCommand)
+
+	customExecCommand = mockCommand.Execute
+
+	mockCommand.On("Execute", "dmidecode", "-s", "chassis-asset-tag").Return(
+		mockDmidecodeNoCloud(),
+	)
+
+	mockCommand.On("Execute", "dmidecode", "-s", "system-version").Return(
+		mockDmidecodeNoCloud(),
+	)
+
+	mockCommand.On("Execute", "dmidecode", "-s", "bios-vendor").Return(
+		mockDmidecodeGcp(),
+	)
+
+	server := newGcpMetadataServer()
+	defer server.Close()
+
+	client = server.Client()
+	gcpMetadataUrl = server.URL
+
+	c, err := NewCloudInstance(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "gcp", c.Provider)
+	meta := c.Metadata.(*GcpMetadata)
+	assert.Equal(t, "vmhana01", meta.Instance.Name)
+}
+
 func TestNewCloudInstanceNoCloud(t *testing.T) {
 	mockCommand := new(mocks.CustomCommand)
 
@@ -176,7 +235,7 @@ func TestNewCloudInstanceNoCloud(t *testing.T) {
 		mockDmidecodeNoCloud(),
 	)
 
-	c, err := NewCloudInstance()
+	c, err := NewCloudInstance(context.Background())
 
 	assert.NoError(t, err)
 	assert.Equal(t, "", c.Provider)
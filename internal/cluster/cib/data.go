@@ -26,12 +26,42 @@ type Root struct {
 		} `xml:"resources"`
 		Constraints struct {
 			RscLocations []struct {
-				Id       string `xml:"id,attr"`
-				Node     string `xml:"node,attr"`
-				Resource string `xml:"rsc,attr"`
-				Role     string `xml:"role,attr"`
-				Score    string `xml:"score,attr"`
+				Id           string        `xml:"id,attr"`
+				Node         string        `xml:"node,attr"`
+				Resource     string        `xml:"rsc,attr"`
+				Role         string        `xml:"role,attr"`
+				Score        string        `xml:"score,attr"`
+				Rules        []Rule        `xml:"rule"`
+				ResourceSets []ResourceSet `xml:"resource_set"`
 			} `xml:"rsc_location"`
+			RscColocations []struct {
+				Id               string        `xml:"id,attr"`
+				Score            string        `xml:"score,attr"`
+				Resource         string        `xml:"rsc,attr"`
+				ResourceRole     string        `xml:"rsc-role,attr"`
+				WithResource     string        `xml:"with-rsc,attr"`
+				WithResourceRole string        `xml:"with-rsc-role,attr"`
+				ResourceSets     []ResourceSet `xml:"resource_set"`
+			} `xml:"rsc_colocation"`
+			RscOrders []struct {
+				Id           string        `xml:"id,attr"`
+				Kind         string        `xml:"kind,attr"`
+				Score        string        `xml:"score,attr"`
+				First        string        `xml:"first,attr"`
+				FirstAction  string        `xml:"first-action,attr"`
+				Then         string        `xml:"then,attr"`
+				ThenAction   string        `xml:"then-action,attr"`
+				Symmetrical  string        `xml:"symmetrical,attr"`
+				ResourceSets []ResourceSet `xml:"resource_set"`
+			} `xml:"rsc_order"`
+			RscTickets []struct {
+				Id           string        `xml:"id,attr"`
+				Ticket       string        `xml:"ticket,attr"`
+				Resource     string        `xml:"rsc,attr"`
+				ResourceRole string        `xml:"rsc-role,attr"`
+				LossPolicy   string        `xml:"loss-policy,attr"`
+				ResourceSets []ResourceSet `xml:"resource_set"`
+			} `xml:"rsc_ticket"`
 		} `xml:"constraints"`
 	} `xml:"configuration"`
 }
@@ -66,6 +96,36 @@ type Clone struct {
 }
 
 type Group struct {
-	Id         string      `xml:"id,attr"`
-	Primitives []Primitive `xml:"primitive"`
+	Id             string      `xml:"id,attr"`
+	MetaAttributes []Attribute `xml:"meta_attributes>nvpair"`
+	Primitives     []Primitive `xml:"primitive"`
+}
+
+// Rule is a rule of a constraint, e.g. the location rule moving the ASCS instance of an ENSA1 setup
+// to the node running the ERS instance
+type Rule struct {
+	Id          string `xml:"id,attr"`
+	Score       string `xml:"score,attr"`
+	Role        string `xml:"role,attr"`
+	BooleanOp   string `xml:"boolean-op,attr"`
+	Expressions []struct {
+		Id        string `xml:"id,attr"`
+		Attribute string `xml:"attribute,attr"`
+		Operation string `xml:"operation,attr"`
+		Value     string `xml:"value,attr"`
+	} `xml:"expression"`
+}
+
+// ResourceSet is a set of resources of a constraint, e.g. a colocation of several resources.
+// The resources of a set are ordered or colocated among them if the set is sequential, the default
+type ResourceSet struct {
+	Id           string `xml:"id,attr"`
+	Sequential   string `xml:"sequential,attr"`
+	RequireAll   string `xml:"require-all,attr"`
+	Action       string `xml:"action,attr"`
+	Role         string `xml:"role,attr"`
+	Score        string `xml:"score,attr"`
+	ResourceRefs []struct {
+		Id string `xml:"id,attr"`
+	} `xml:"resource_ref"`
 }
@@ -1,23 +1,26 @@
 package cib
 
 import (
+	"context"
 	"encoding/xml"
 	"os/exec"
 
 	"github.com/pkg/errors"
+
+	"github.com/trento-project/trento/internal"
 )
 
 type Parser interface {
-	Parse() (Root, error)
+	Parse(ctx context.Context) (Root, error)
 }
 
 type cibAdminParser struct {
 	cibAdminPath string
 }
 
-func (p *cibAdminParser) Parse() (Root, error) {
+func (p *cibAdminParser) Parse(ctx context.Context) (Root, error) {
 	var CIB Root
-	cibXML, err := exec.Command(p.cibAdminPath, "--query", "--local").Output()
+	cibXML, err := internal.CommandOutput(ctx, exec.Command(p.cibAdminPath, "--query", "--local"))
 	if err != nil {
 		return CIB, errors.Wrap(err, "error while executing cibadmin")
 	}
@@ -1,6 +1,7 @@
 package cib
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,7 +14,7 @@ func TestConstructor(t *testing.T) {
 
 func TestParse(t *testing.T) {
 	p := NewCibAdminParser("../../../test/fake_cibadmin.sh")
-	data, err := p.Parse()
+	data, err := p.Parse(context.Background())
 	assert.NoError(t, err)
 	assert.Equal(t, 2, len(data.Configuration.Nodes))
 	assert.Equal(t, "cib-bootstrap-options-cluster-name", data.Configuration.CrmConfig.ClusterProperties[3].Id)
@@ -71,4 +72,43 @@ func TestParse(t *testing.T) {
 	assert.Equal(t, "ocf", data.Configuration.Resources.Primitives[2].Class)
 	assert.Equal(t, "heartbeat", data.Configuration.Resources.Primitives[2].Provider)
 	assert.Equal(t, "Dummy", data.Configuration.Resources.Primitives[2].Type)
+	assert.Equal(t, 5, len(data.Configuration.Constraints.RscLocations))
+	assert.Equal(t, 0, len(data.Configuration.Constraints.RscLocations[3].Rules))
+	assert.Equal(t, "loc_test_pingd", data.Configuration.Constraints.RscLocations[4].Id)
+	assert.Equal(t, "test", data.Configuration.Constraints.RscLocations[4].Resource)
+	assert.Equal(t, 1, len(data.Configuration.Constraints.RscLocations[4].Rules))
+	assert.Equal(t, "-INFINITY", data.Configuration.Constraints.RscLocations[4].Rules[0].Score)
+	assert.Equal(t, "pingd", data.Configuration.Constraints.RscLocations[4].Rules[0].Expressions[0].Attribute)
+	assert.Equal(t, "not_defined", data.Configuration.Constraints.RscLocations[4].Rules[0].Expressions[0].Operation)
+}
+
+func TestParseConstraints(t *testing.T) {
+	p := NewCibAdminParser("../../../test/fake_cibadmin.sh")
+	data, err := p.Parse(context.Background())
+	assert.NoError(t, err)
+
+	constraints := data.Configuration.Constraints
+	assert.Equal(t, 2, len(constraints.RscColocations))
+	assert.Equal(t, "col_saphana_ip_PRD_HDB00", constraints.RscColocations[0].Id)
+	assert.Equal(t, "2000", constraints.RscColocations[0].Score)
+	assert.Equal(t, "rsc_ip_PRD_HDB00", constraints.RscColocations[0].Resource)
+	assert.Equal(t, "Started", constraints.RscColocations[0].ResourceRole)
+	assert.Equal(t, "msl_SAPHana_PRD_HDB00", constraints.RscColocations[0].WithResource)
+	assert.Equal(t, "Master", constraints.RscColocations[0].WithResourceRole)
+
+	assert.Equal(t, 2, len(constraints.RscOrders))
+	assert.Equal(t, "Optional", constraints.RscOrders[0].Kind)
+	assert.Equal(t, "cln_SAPHanaTopology_PRD_HDB00", constraints.RscOrders[0].First)
+	assert.Equal(t, "msl_SAPHana_PRD_HDB00", constraints.RscOrders[0].Then)
+	assert.Equal(t, "ord_test_stop", constraints.RscOrders[1].Id)
+	assert.Equal(t, 1, len(constraints.RscOrders[1].ResourceSets))
+	assert.Equal(t, "true", constraints.RscOrders[1].ResourceSets[0].Sequential)
+	assert.Equal(t, 2, len(constraints.RscOrders[1].ResourceSets[0].ResourceRefs))
+	assert.Equal(t, "test-stop", constraints.RscOrders[1].ResourceSets[0].ResourceRefs[0].Id)
+	assert.Equal(t, "test", constraints.RscOrders[1].ResourceSets[0].ResourceRefs[1].Id)
+
+	assert.Equal(t, 1, len(constraints.RscTickets))
+	assert.Equal(t, "ticket-A", constraints.RscTickets[0].Ticket)
+	assert.Equal(t, "test-stop", constraints.RscTickets[0].Resource)
+	assert.Equal(t, "stop", constraints.RscTickets[0].LossPolicy)
 }
@@ -1,10 +1,13 @@
 package cluster
 
 import (
+	"context"
 	"os"
 	"strconv"
 	"strings"
 
+	log "github.com/sirupsen/logrus"
+
 	"github.com/trento-project/trento/internal"
 
 	// These packages were originally imported from github.com/ClusterLabs/ha_cluster_exporter/collector/pacemaker
@@ -26,38 +29,45 @@ const (
 )
 
 type DiscoveryTools struct {
-	CibAdmPath      string
-	CrmmonAdmPath   string
-	CorosyncKeyPath string
-	SBDPath         string
-	SBDConfigPath   string
+	CibAdmPath             string
+	CrmmonAdmPath          string
+	CorosyncKeyPath        string
+	CorosyncConfigPath     string
+	CorosyncQuorumtoolPath string
+	SBDPath                string
+	SBDConfigPath          string
 }
 
 type Cluster struct {
-	Cib    cib.Root    `mapstructure:"cib,omitempty"`
-	Crmmon crmmon.Root `mapstructure:"crmmon,omitempty"`
-	SBD    SBD         `mapstructure:"sbd,omitempty"`
-	Id     string      `mapstructure:"id"`
-	Name   string      `mapstructure:"name"`
-	DC     bool        `mapstructure:"dc"`
+	Cib      cib.Root    `mapstructure:"cib,omitempty"`
+	Crmmon   crmmon.Root `mapstructure:"crmmon,omitempty"`
+	SBD      SBD         `mapstructure:"sbd,omitempty"`
+	Corosync Corosync    `mapstructure:"corosync,omitempty"`
+	Id       string      `mapstructure:"id"`
+	Name     string      `mapstructure:"name"`
+	DC       bool        `mapstructure:"dc"`
+	// SAPInstanceProfiles are the HA interface settings of the SAP instances managed by the cluster
+	SAPInstanceProfiles []*SAPInstanceProfile `mapstructure:"sapinstanceprofiles,omitempty"`
 }
 
-func NewCluster() (Cluster, error) {
-	return NewClusterWithDiscoveryTools(&DiscoveryTools{
-		CibAdmPath:      cibAdmPath,
-		CrmmonAdmPath:   crmmonAdmPath,
-		CorosyncKeyPath: corosyncKeyPath,
-		SBDPath:         SBDPath,
-		SBDConfigPath:   SBDConfigPath,
+func NewCluster(ctx context.Context) (Cluster, error) {
+	return NewClusterWithDiscoveryTools(ctx, &DiscoveryTools{
+		CibAdmPath:             cibAdmPath,
+		CrmmonAdmPath:          crmmonAdmPath,
+		CorosyncKeyPath:        corosyncKeyPath,
+		CorosyncConfigPath:     CorosyncConfigPath,
+		CorosyncQuorumtoolPath: CorosyncQuorumtoolPath,
+		SBDPath:                SBDPath,
+		SBDConfigPath:          SBDConfigPath,
 	})
 }
 
-func NewClusterWithDiscoveryTools(discoveryTools *DiscoveryTools) (Cluster, error) {
+func NewClusterWithDiscoveryTools(ctx context.Context, discoveryTools *DiscoveryTools) (Cluster, error) {
 	var cluster = Cluster{}
 
 	cibParser := cib.NewCibAdminParser(discoveryTools.CibAdmPath)
 
-	cibConfig, err := cibParser.Parse()
+	cibConfig, err := cibParser.Parse(ctx)
 	if err != nil {
 		return cluster, err
 	}
@@ -66,7 +76,7 @@ func NewClusterWithDiscoveryTools(discoveryTools *DiscoveryTools) (Cluster, erro
 
 	crmmonParser := crmmon.NewCrmMonParser(discoveryTools.CrmmonAdmPath)
 
-	crmmonConfig, err := crmmonParser.Parse()
+	crmmonConfig, err := crmmonParser.Parse(ctx)
 	if err != nil {
 		return cluster, err
 	}
@@ -81,8 +91,15 @@ func NewClusterWithDiscoveryTools(discoveryTools *DiscoveryTools) (Cluster, erro
 
 	cluster.Name = getName(cluster)
 
+	// The corosync data only enriches the cluster, so whatever could be discovered is kept,
+	// e.g. the configuration while corosync-quorumtool fails because the stack is starting
+	cluster.Corosync, err = NewCorosync(ctx, discoveryTools.CorosyncConfigPath, discoveryTools.CorosyncQuorumtoolPath)
+	if err != nil {
+		log.Warnf("Error getting corosync information: %s", err)
+	}
+
 	if cluster.IsFencingSBD() {
-		sbdData, err := NewSBD(cluster.Id, discoveryTools.SBDPath, discoveryTools.SBDConfigPath)
+		sbdData, err := NewSBD(ctx, cluster.Id, discoveryTools.SBDPath, discoveryTools.SBDConfigPath)
 		if err != nil {
 			return cluster, err
 		}
@@ -90,6 +107,8 @@ func NewClusterWithDiscoveryTools(discoveryTools *DiscoveryTools) (Cluster, erro
 		cluster.SBD = sbdData
 	}
 
+	cluster.SAPInstanceProfiles = getSAPInstanceProfiles(&cluster)
+
 	cluster.DC = isDC(&cluster)
 
 	return cluster, nil
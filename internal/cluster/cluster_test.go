@@ -1,6 +1,7 @@
 package cluster
 
 import (
+	"context"
 	"os"
 	"testing"
 
@@ -24,6 +25,27 @@ func TestClusterId(t *testing.T) {
 	assert.Equal(t, c.Id, authkey)
 }
 
+func TestNewClusterCorosyncError(t *testing.T) {
+	mockCorosyncQuorumtoolCommand(t, mockCorosyncQuorumtoolErr)
+
+	c, err := NewClusterWithDiscoveryTools(context.Background(), &DiscoveryTools{
+		CibAdmPath:             "../../test/fake_cibadmin.sh",
+		CrmmonAdmPath:          "../../test/fake_crm_mon.sh",
+		CorosyncKeyPath:        "../../test/authkey",
+		CorosyncConfigPath:     "../../test/corosync.conf",
+		CorosyncQuorumtoolPath: "corosync-quorumtool",
+		SBDPath:                "../../test/fake_sbd.sh",
+		SBDConfigPath:          "../../test/sbd_config",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "47d1190ffb4f781974c8356d7f863b03", c.Id)
+	assert.Equal(t, "hana_cluster", c.Name)
+	assert.Equal(t, "hana_cluster", c.Corosync.Totem.ClusterName)
+	assert.Nil(t, c.Corosync.QuorumStatus)
+	assert.NotEmpty(t, c.Crmmon.Nodes)
+}
+
 func TestClusterName(t *testing.T) {
 	root := new(cib.Root)
 
@@ -0,0 +1,320 @@
+package cluster
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/trento-project/trento/internal"
+)
+
+const (
+	CorosyncConfigPath     = "/etc/corosync/corosync.conf"
+	CorosyncQuorumtoolPath = "/usr/sbin/corosync-quorumtool"
+	// corosync-quorumtool exits with this code when the partition is not quorate
+	quorumtoolNotQuorateExitCode = 2
+)
+
+type Corosync struct {
+	Totem        CorosyncTotem         `mapstructure:"totem,omitempty"`
+	Quorum       CorosyncQuorum        `mapstructure:"quorum,omitempty"`
+	Nodes        []*CorosyncNode       `mapstructure:"nodes,omitempty"`
+	QuorumStatus *CorosyncQuorumStatus `mapstructure:"quorumstatus,omitempty"`
+}
+
+type CorosyncTotem struct {
+	Version                         string               `mapstructure:"version,omitempty"`
+	ClusterName                     string               `mapstructure:"clustername,omitempty"`
+	Transport                       string               `mapstructure:"transport,omitempty"`
+	Token                           int                  `mapstructure:"token,omitempty"`
+	Consensus                       int                  `mapstructure:"consensus,omitempty"`
+	TokenRetransmitsBeforeLossConst int                  `mapstructure:"tokenretransmitsbeforelossconst,omitempty"`
+	MaxMessages                     int                  `mapstructure:"maxmessages,omitempty"`
+	Secauth                         string               `mapstructure:"secauth,omitempty"`
+	CryptoCipher                    string               `mapstructure:"cryptocipher,omitempty"`
+	CryptoHash                      string               `mapstructure:"cryptohash,omitempty"`
+	Interfaces                      []*CorosyncInterface `mapstructure:"interfaces,omitempty"`
+}
+
+// CorosyncInterface is a totem interface, configuring a ring of the udp/udpu transports
+// or a link of the knet transport
+type CorosyncInterface struct {
+	Number      int    `mapstructure:"number"`
+	Bindnetaddr string `mapstructure:"bindnetaddr,omitempty"`
+	Mcastaddr   string `mapstructure:"mcastaddr,omitempty"`
+	Mcastport   int    `mapstructure:"mcastport,omitempty"`
+	TTL         int    `mapstructure:"ttl,omitempty"`
+}
+
+type CorosyncQuorum struct {
+	Provider      string `mapstructure:"provider,omitempty"`
+	ExpectedVotes int    `mapstructure:"expectedvotes,omitempty"`
+	TwoNode       bool   `mapstructure:"twonode,omitempty"`
+	WaitForAll    bool   `mapstructure:"waitforall,omitempty"`
+}
+
+// CorosyncNode is a node of the nodelist, where RingAddresses holds the ringX_addr addresses indexed by ring or link number
+type CorosyncNode struct {
+	NodeId        int      `mapstructure:"nodeid,omitempty"`
+	Name          string   `mapstructure:"name,omitempty"`
+	RingAddresses []string `mapstructure:"ringaddresses,omitempty"`
+}
+
+type CorosyncQuorumStatus struct {
+	Provider        string            `mapstructure:"provider,omitempty"`
+	Nodes           int               `mapstructure:"nodes,omitempty"`
+	NodeId          int               `mapstructure:"nodeid,omitempty"`
+	RingId          string            `mapstructure:"ringid,omitempty"`
+	Quorate         bool              `mapstructure:"quorate,omitempty"`
+	ExpectedVotes   int               `mapstructure:"expectedvotes,omitempty"`
+	HighestExpected int               `mapstructure:"highestexpected,omitempty"`
+	TotalVotes      int               `mapstructure:"totalvotes,omitempty"`
+	Quorum          int               `mapstructure:"quorum,omitempty"`
+	Flags           []string          `mapstructure:"flags,omitempty"`
+	Members         []*CorosyncMember `mapstructure:"members,omitempty"`
+}
+
+type CorosyncMember struct {
+	NodeId int    `mapstructure:"nodeid,omitempty"`
+	Votes  int    `mapstructure:"votes,omitempty"`
+	Name   string `mapstructure:"name,omitempty"`
+	Local  bool   `mapstructure:"local,omitempty"`
+}
+
+var corosyncQuorumtoolExecCommand = exec.Command
+
+func NewCorosync(ctx context.Context, corosyncConfigPath, corosyncQuorumtoolPath string) (Corosync, error) {
+	var c = Corosync{}
+
+	corosyncConfigRaw, err := ioutil.ReadFile(corosyncConfigPath)
+	if err != nil {
+		return c, fmt.Errorf("could not read corosync config file %s", err)
+	}
+
+	config, err := parseCorosyncConfig(corosyncConfigRaw)
+	if err != nil {
+		return c, err
+	}
+
+	c.loadConfig(config)
+
+	c.QuorumStatus, err = corosyncQuorumStatus(ctx, corosyncQuorumtoolPath)
+	if err != nil {
+		return c, err
+	}
+
+	return c, nil
+}
+
+// corosyncSection is a section of the corosync.conf file, e.g. totem { ... }
+type corosyncSection struct {
+	attributes map[string]string
+	sections   map[string][]*corosyncSection
+}
+
+func newCorosyncSection() *corosyncSection {
+	return &corosyncSection{
+		attributes: make(map[string]string),
+		sections:   make(map[string][]*corosyncSection),
+	}
+}
+
+func (s *corosyncSection) section(name string) *corosyncSection {
+	if sections := s.sections[name]; len(sections) > 0 {
+		return sections[0]
+	}
+
+	return newCorosyncSection()
+}
+
+func (s *corosyncSection) integer(name string) int {
+	value, _ := strconv.Atoi(s.attributes[name])
+	return value
+}
+
+func (s *corosyncSection) boolean(name string) bool {
+	value := s.attributes[name]
+	return value == "1" || value == "yes" || value == "on"
+}
+
+// parseCorosyncConfig parses the nested sections of a corosync.conf file, e.g.
+//
+//	totem {
+//	    version: 2
+//	    interface {
+//	        ringnumber: 0
+//	    }
+//	}
+func parseCorosyncConfig(corosyncConfigRaw []byte) (*corosyncSection, error) {
+	root := newCorosyncSection()
+	stack := []*corosyncSection{root}
+
+	scanner := bufio.NewScanner(bytes.NewReader(corosyncConfigRaw))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		current := stack[len(stack)-1]
+
+		switch {
+		case line == "":
+			continue
+		case strings.HasSuffix(line, "{"):
+			name := strings.TrimSpace(strings.TrimSuffix(line, "{"))
+			section := newCorosyncSection()
+			current.sections[name] = append(current.sections[name], section)
+			stack = append(stack, section)
+		case line == "}":
+			if len(stack) == 1 {
+				return nil, errors.New("could not parse corosync config file: unexpected }")
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			// Addresses might be IPv6 ones, so only the first colon splits the key and the value
+			kv := strings.SplitN(line, ":", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("could not parse corosync config file: invalid line %s", line)
+			}
+			current.attributes[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+	}
+
+	if len(stack) != 1 {
+		return nil, errors.New("could not parse corosync config file: missing }")
+	}
+
+	return root, nil
+}
+
+func (c *Corosync) loadConfig(config *corosyncSection) {
+	totem := config.section("totem")
+	c.Totem = CorosyncTotem{
+		Version:                         totem.attributes["version"],
+		ClusterName:                     totem.attributes["cluster_name"],
+		Transport:                       totem.attributes["transport"],
+		Token:                           totem.integer("token"),
+		Consensus:                       totem.integer("consensus"),
+		TokenRetransmitsBeforeLossConst: totem.integer("token_retransmits_before_loss_const"),
+		MaxMessages:                     totem.integer("max_messages"),
+		Secauth:                         totem.attributes["secauth"],
+		CryptoCipher:                    totem.attributes["crypto_cipher"],
+		CryptoHash:                      totem.attributes["crypto_hash"],
+	}
+
+	for _, i := range totem.sections["interface"] {
+		number := i.integer("ringnumber")
+		if _, ok := i.attributes["linknumber"]; ok {
+			number = i.integer("linknumber")
+		}
+
+		c.Totem.Interfaces = append(c.Totem.Interfaces, &CorosyncInterface{
+			Number:      number,
+			Bindnetaddr: i.attributes["bindnetaddr"],
+			Mcastaddr:   i.attributes["mcastaddr"],
+			Mcastport:   i.integer("mcastport"),
+			TTL:         i.integer("ttl"),
+		})
+	}
+
+	quorum := config.section("quorum")
+	c.Quorum = CorosyncQuorum{
+		Provider:      quorum.attributes["provider"],
+		ExpectedVotes: quorum.integer("expected_votes"),
+		TwoNode:       quorum.boolean("two_node"),
+		WaitForAll:    quorum.boolean("wait_for_all"),
+	}
+
+	ringAddressPattern := regexp.MustCompile(`^ring(\d+)_addr$`)
+	for _, n := range config.section("nodelist").sections["node"] {
+		node := &CorosyncNode{
+			NodeId: n.integer("nodeid"),
+			Name:   n.attributes["name"],
+		}
+
+		for key, value := range n.attributes {
+			match := ringAddressPattern.FindStringSubmatch(key)
+			if match == nil {
+				continue
+			}
+
+			ring, _ := strconv.Atoi(match[1])
+			for len(node.RingAddresses) <= ring {
+				node.RingAddresses = append(node.RingAddresses, "")
+			}
+			node.RingAddresses[ring] = value
+		}
+
+		c.Nodes = append(c.Nodes, node)
+	}
+}
+
+// Possible output
+// Quorum information
+// ------------------
+// Date:             Tue Jan 11 14:02:47 2022
+// Quorum provider:  corosync_votequorum
+// Nodes:            2
+// Node ID:          1
+// Ring ID:          1.2c
+// Quorate:          Yes
+//
+// Votequorum information
+// ----------------------
+// Expected votes:   2
+// Highest expected: 2
+// Total votes:      2
+// Quorum:           1
+// Flags:            2Node Quorate WaitForAll
+//
+// Membership information
+// ----------------------
+//
+//	Nodeid      Votes Name
+//	     1          1 hana01 (local)
+//	     2          1 hana02
+func corosyncQuorumStatus(ctx context.Context, corosyncQuorumtoolPath string) (*CorosyncQuorumStatus, error) {
+	output, err := internal.CommandOutput(ctx, corosyncQuorumtoolExecCommand(corosyncQuorumtoolPath, "-s"))
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != quorumtoolNotQuorateExitCode {
+			return nil, fmt.Errorf("corosync-quorumtool command error: %s", err)
+		}
+	}
+
+	outputStr := string(output)
+	status := &CorosyncQuorumStatus{
+		Provider: assignPatternResult(outputStr, `Quorum provider: *(.*)`),
+		RingId:   assignPatternResult(outputStr, `Ring ID: *(.*)`),
+		Quorate:  assignPatternResult(outputStr, `Quorate: *(.*)`) == "Yes",
+		Flags:    strings.Fields(assignPatternResult(outputStr, `Flags: *(.*)`)),
+	}
+	status.Nodes, _ = strconv.Atoi(assignPatternResult(outputStr, `Nodes: *(.*)`))
+	status.NodeId, _ = strconv.Atoi(assignPatternResult(outputStr, `Node ID: *(.*)`))
+	status.ExpectedVotes, _ = strconv.Atoi(assignPatternResult(outputStr, `Expected votes: *(.*)`))
+	status.HighestExpected, _ = strconv.Atoi(assignPatternResult(outputStr, `Highest expected: *(.*)`))
+	status.TotalVotes, _ = strconv.Atoi(assignPatternResult(outputStr, `Total votes: *(.*)`))
+	status.Quorum, _ = strconv.Atoi(assignPatternResult(outputStr, `Quorum: *(\d+)`))
+
+	r := regexp.MustCompile(`(?m)^\s*(\d+)\s+(\d+)\s+(\S+)( \(local\))?`)
+	for _, match := range r.FindAllStringSubmatch(outputStr, -1) {
+		nodeId, _ := strconv.Atoi(match[1])
+		votes, _ := strconv.Atoi(match[2])
+		status.Members = append(status.Members, &CorosyncMember{
+			NodeId: nodeId,
+			Votes:  votes,
+			Name:   match[3],
+			Local:  match[4] != "",
+		})
+	}
+
+	return status, nil
+}
@@ -0,0 +1,240 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mockCorosyncQuorumtool(command string, args ...string) *exec.Cmd {
+	return exec.Command("../../test/fake_corosync_quorumtool.sh")
+}
+
+func mockCorosyncQuorumtoolNotQuorate(command string, args ...string) *exec.Cmd {
+	cmd := `Quorum information
+------------------
+Date:             Tue Jan 11 14:05:12 2022
+Quorum provider:  corosync_votequorum
+Nodes:            1
+Node ID:          2
+Ring ID:          2.30
+Quorate:          No
+
+Votequorum information
+----------------------
+Expected votes:   3
+Highest expected: 3
+Total votes:      1
+Quorum:           2 Activity blocked
+Flags:            
+
+Membership information
+----------------------
+    Nodeid      Votes Name
+         2          1 node02 (local)`
+
+	script := fmt.Sprintf("echo \"%s\" && exit 2", cmd)
+
+	return exec.Command("bash", "-c", script)
+}
+
+func mockCorosyncQuorumtoolErr(command string, args ...string) *exec.Cmd {
+	script := "echo \"Cannot initialize QUORUM service\" && exit 1"
+
+	return exec.Command("bash", "-c", script)
+}
+
+// mockCorosyncQuorumtoolCommand replaces the corosync-quorumtool command until the test completes
+func mockCorosyncQuorumtoolCommand(t *testing.T, command func(string, ...string) *exec.Cmd) {
+	previous := corosyncQuorumtoolExecCommand
+	corosyncQuorumtoolExecCommand = command
+	t.Cleanup(func() {
+		corosyncQuorumtoolExecCommand = previous
+	})
+}
+
+func TestNewCorosync(t *testing.T) {
+	mockCorosyncQuorumtoolCommand(t, mockCorosyncQuorumtool)
+
+	c, err := NewCorosync(context.Background(), "../../test/corosync.conf", "corosync-quorumtool")
+
+	expectedCorosync := Corosync{
+		Totem: CorosyncTotem{
+			Version:                         "2",
+			ClusterName:                     "hana_cluster",
+			Transport:                       "udpu",
+			Token:                           5000,
+			Consensus:                       6000,
+			TokenRetransmitsBeforeLossConst: 10,
+			MaxMessages:                     20,
+			Secauth:                         "on",
+			CryptoCipher:                    "aes256",
+			CryptoHash:                      "sha1",
+			Interfaces: []*CorosyncInterface{
+				{
+					Number:    0,
+					Mcastport: 5405,
+					TTL:       1,
+				},
+			},
+		},
+		Quorum: CorosyncQuorum{
+			Provider:      "corosync_votequorum",
+			ExpectedVotes: 2,
+			TwoNode:       true,
+		},
+		Nodes: []*CorosyncNode{
+			{
+				NodeId:        1,
+				RingAddresses: []string{"10.80.1.11"},
+			},
+			{
+				NodeId:        2,
+				RingAddresses: []string{"10.80.1.12"},
+			},
+		},
+		QuorumStatus: &CorosyncQuorumStatus{
+			Provider:        "corosync_votequorum",
+			Nodes:           2,
+			NodeId:          1,
+			RingId:          "1.2c",
+			Quorate:         true,
+			ExpectedVotes:   2,
+			HighestExpected: 2,
+			TotalVotes:      2,
+			Quorum:          1,
+			Flags:           []string{"2Node", "Quorate", "WaitForAll"},
+			Members: []*CorosyncMember{
+				{
+					NodeId: 1,
+					Votes:  1,
+					Name:   "node01",
+					Local:  true,
+				},
+				{
+					NodeId: 2,
+					Votes:  1,
+					Name:   "node02",
+				},
+			},
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedCorosync, c)
+}
+
+func TestNewCorosyncConfigError(t *testing.T) {
+	_, err := NewCorosync(context.Background(), "notexist", "corosync-quorumtool")
+
+	assert.EqualError(t, err, "could not read corosync config file open notexist: no such file or directory")
+}
+
+func TestParseCorosyncConfigKnet(t *testing.T) {
+	corosyncConfig := []byte(`
+totem {
+	version: 2
+	cluster_name: netweaver_cluster
+	transport: knet
+	interface {
+		linknumber: 0
+	}
+	interface {
+		linknumber: 1
+		knet_transport: sctp
+	}
+}
+
+nodelist {
+	node {
+		name: node01
+		nodeid: 1
+		ring0_addr: fd00::11
+		ring1_addr: 192.168.100.11
+	}
+	node {
+		name: node02
+		nodeid: 2
+		ring1_addr: 192.168.100.12
+	}
+}
+
+quorum {
+	provider: corosync_votequorum
+	wait_for_all: 1
+}
+`)
+
+	config, err := parseCorosyncConfig(corosyncConfig)
+	assert.NoError(t, err)
+
+	c := Corosync{}
+	c.loadConfig(config)
+
+	assert.Equal(t, "knet", c.Totem.Transport)
+	assert.Equal(t, []*CorosyncInterface{{Number: 0}, {Number: 1}}, c.Totem.Interfaces)
+	assert.Equal(t, CorosyncQuorum{Provider: "corosync_votequorum", WaitForAll: true}, c.Quorum)
+	assert.Equal(t, []*CorosyncNode{
+		{
+			NodeId:        1,
+			Name:          "node01",
+			RingAddresses: []string{"fd00::11", "192.168.100.11"},
+		},
+		{
+			NodeId:        2,
+			Name:          "node02",
+			RingAddresses: []string{"", "192.168.100.12"},
+		},
+	}, c.Nodes)
+}
+
+func TestParseCorosyncConfigError(t *testing.T) {
+	_, err := parseCorosyncConfig([]byte("totem {\n\tversion: 2\n"))
+	assert.EqualError(t, err, "could not parse corosync config file: missing }")
+
+	_, err = parseCorosyncConfig([]byte("totem {\n}\n}\n"))
+	assert.EqualError(t, err, "could not parse corosync config file: unexpected }")
+
+	_, err = parseCorosyncConfig([]byte("totem {\n\tversion\n}\n"))
+	assert.EqualError(t, err, "could not parse corosync config file: invalid line version")
+}
+
+func TestCorosyncQuorumStatusNotQuorate(t *testing.T) {
+	mockCorosyncQuorumtoolCommand(t, mockCorosyncQuorumtoolNotQuorate)
+
+	status, err := corosyncQuorumStatus(context.Background(), "corosync-quorumtool")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &CorosyncQuorumStatus{
+		Provider:        "corosync_votequorum",
+		Nodes:           1,
+		NodeId:          2,
+		RingId:          "2.30",
+		Quorate:         false,
+		ExpectedVotes:   3,
+		HighestExpected: 3,
+		TotalVotes:      1,
+		Quorum:          2,
+		Flags:           []string{},
+		Members: []*CorosyncMember{
+			{
+				NodeId: 2,
+				Votes:  1,
+				Name:   "node02",
+				Local:  true,
+			},
+		},
+	}, status)
+}
+
+func TestCorosyncQuorumStatusError(t *testing.T) {
+	mockCorosyncQuorumtoolCommand(t, mockCorosyncQuorumtoolErr)
+
+	status, err := corosyncQuorumStatus(context.Background(), "corosync-quorumtool")
+
+	assert.Nil(t, status)
+	assert.EqualError(t, err, "corosync-quorumtool command error: exit status 1")
+}
@@ -34,12 +34,16 @@ type Root struct {
 		Nodes []struct {
 			Name            string `xml:"name,attr"`
 			ResourceHistory []struct {
-				Name               string `xml:"id,attr"`
-				MigrationThreshold int    `xml:"migration-threshold,attr"`
-				FailCount          int    `xml:"fail-count,attr"`
+				Name               string             `xml:"id,attr"`
+				MigrationThreshold int                `xml:"migration-threshold,attr"`
+				FailCount          int                `xml:"fail-count,attr"`
+				LastFailure        string             `xml:"last-failure,attr"`
+				OperationHistory   []OperationHistory `xml:"operation_history"`
 			} `xml:"resource_history"`
 		} `xml:"node"`
 	} `xml:"node_history"`
+	Failures  []Failure  `xml:"failures>failure"`
+	Tickets   []Ticket   `xml:"tickets>ticket"`
 	Resources []Resource `xml:"resources>resource"`
 	Clones    []Clone    `xml:"resources>clone"`
 	Groups    []Group    `xml:"resources>group"`
@@ -93,3 +97,38 @@ type Group struct {
 	Id        string     `xml:"id,attr"`
 	Resources []Resource `xml:"resource"`
 }
+
+// OperationHistory is an operation run on a resource, where RC is the OCF exit code of the resource agent
+type OperationHistory struct {
+	Call         int    `xml:"call,attr"`
+	Task         string `xml:"task,attr"`
+	Interval     string `xml:"interval,attr"`
+	LastRCChange string `xml:"last-rc-change,attr"`
+	LastRun      string `xml:"last-run,attr"`
+	ExecTime     string `xml:"exec-time,attr"`
+	QueueTime    string `xml:"queue-time,attr"`
+	RC           int    `xml:"rc,attr"`
+	RCText       string `xml:"rc_text,attr"`
+	ExitReason   string `xml:"exit-reason,attr"`
+}
+
+// Failure is a failed action of a resource, where OpKey is made of the resource id, the task and the interval,
+// e.g. rsc_SAPHana_PRD_HDB00_monitor_60000
+type Failure struct {
+	OpKey        string `xml:"op_key,attr"`
+	Node         string `xml:"node,attr"`
+	ExitStatus   string `xml:"exitstatus,attr"`
+	ExitReason   string `xml:"exitreason,attr"`
+	ExitCode     int    `xml:"exitcode,attr"`
+	Call         int    `xml:"call,attr"`
+	Status       string `xml:"status,attr"`
+	LastRCChange string `xml:"last-rc-change,attr"`
+	Task         string `xml:"task,attr"`
+	Interval     string `xml:"interval,attr"`
+}
+
+type Ticket struct {
+	Id      string `xml:"id,attr"`
+	Status  string `xml:"status,attr"`
+	Standby bool   `xml:"standby,attr"`
+}
@@ -1,22 +1,25 @@
 package crmmon
 
 import (
+	"context"
 	"encoding/xml"
 	"os/exec"
 
 	"github.com/pkg/errors"
+
+	"github.com/trento-project/trento/internal"
 )
 
 type Parser interface {
-	Parse() (Root, error)
+	Parse(ctx context.Context) (Root, error)
 }
 
 type crmMonParser struct {
 	crmMonPath string
 }
 
-func (c *crmMonParser) Parse() (crmMon Root, err error) {
-	crmMonXML, err := exec.Command(c.crmMonPath, "-X", "--inactive").Output()
+func (c *crmMonParser) Parse(ctx context.Context) (crmMon Root, err error) {
+	crmMonXML, err := internal.CommandOutput(ctx, exec.Command(c.crmMonPath, "-X", "--inactive"))
 	if err != nil {
 		return crmMon, errors.Wrap(err, "error while executing crm_mon")
 	}
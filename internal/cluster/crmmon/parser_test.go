@@ -1,6 +1,7 @@
 package crmmon
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,7 +14,7 @@ func TestConstructor(t *testing.T) {
 
 func TestParse(t *testing.T) {
 	p := NewCrmMonParser("../../../test/fake_crm_mon.sh")
-	data, err := p.Parse()
+	data, err := p.Parse(context.Background())
 	assert.NoError(t, err)
 	assert.Equal(t, "2.0.0", data.Version)
 	assert.Equal(t, 8, data.Summary.Resources.Number)
@@ -53,9 +54,42 @@ func TestParse(t *testing.T) {
 	assert.Equal(t, "Stopped", data.Resources[0].Role)
 }
 
+func TestParseFailures(t *testing.T) {
+	p := NewCrmMonParser("../../../test/fake_crm_mon.sh")
+	data, err := p.Parse(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(data.Failures))
+	assert.Equal(t, "test-stop_start_0", data.Failures[0].OpKey)
+	assert.Equal(t, "node02", data.Failures[0].Node)
+	assert.Equal(t, "start", data.Failures[0].Task)
+	assert.Equal(t, 5, data.Failures[0].ExitCode)
+	assert.Equal(t, "not installed", data.Failures[0].ExitStatus)
+	assert.Equal(t, "Setup problem: couldn't find command: dummy", data.Failures[0].ExitReason)
+	assert.Equal(t, 0, len(data.Tickets))
+}
+
+func TestParseOperationHistory(t *testing.T) {
+	p := NewCrmMonParser("../../../test/fake_crm_mon.sh")
+	data, err := p.Parse(context.Background())
+	assert.NoError(t, err)
+	history := data.NodeHistory.Nodes[0].ResourceHistory[0].OperationHistory
+	assert.Equal(t, 3, len(history))
+	assert.Equal(t, "monitor", history[2].Task)
+	assert.Equal(t, "60000ms", history[2].Interval)
+	assert.Equal(t, 8, history[2].RC)
+	assert.Equal(t, "master", history[2].RCText)
+
+	testStop := data.NodeHistory.Nodes[1].ResourceHistory[3]
+	assert.Equal(t, "test-stop", testStop.Name)
+	assert.Equal(t, "Mon Feb 24 09:46:41 2020", testStop.LastFailure)
+	assert.Equal(t, 2, len(testStop.OperationHistory))
+	assert.Equal(t, "not installed", testStop.OperationHistory[0].RCText)
+	assert.Equal(t, "Setup problem: couldn't find command: dummy", testStop.OperationHistory[0].ExitReason)
+}
+
 func TestParseClones(t *testing.T) {
 	p := NewCrmMonParser("../../../test/fake_crm_mon.sh")
-	data, err := p.Parse()
+	data, err := p.Parse(context.Background())
 	assert.NoError(t, err)
 	assert.Equal(t, 3, len(data.Clones))
 	assert.Equal(t, "msl_SAPHana_PRD_HDB00", data.Clones[0].Id)
@@ -71,7 +105,7 @@ func TestParseClones(t *testing.T) {
 
 func TestParseGroups(t *testing.T) {
 	p := NewCrmMonParser("../../../test/fake_crm_mon.sh")
-	data, err := p.Parse()
+	data, err := p.Parse(context.Background())
 	assert.NoError(t, err)
 	assert.Equal(t, 2, len(data.Groups))
 
@@ -90,7 +124,7 @@ func TestParseGroups(t *testing.T) {
 
 func TestParseNodeAttributes(t *testing.T) {
 	p := NewCrmMonParser("../../../test/fake_crm_mon.sh")
-	data, err := p.Parse()
+	data, err := p.Parse(context.Background())
 	assert.NoError(t, err)
 	assert.Len(t, data.NodeAttributes.Nodes, 2)
 	assert.Equal(t, "node01", data.NodeAttributes.Nodes[0].Name)
@@ -0,0 +1,106 @@
+package cluster
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/internal"
+	"github.com/trento-project/trento/internal/cluster/cib"
+)
+
+const (
+	sapInstanceResourceType      string = "SAPInstance"
+	sapInstanceNameAttribute     string = "InstanceName"
+	sapInstanceProfileAttribute  string = "START_PROFILE"
+	sapHalibProperty             string = "service/halib"
+	sapHalibClusterConnectorProp string = "service/halib_cluster_connector"
+	sapProfilePattern            string = `([\w\/]+)\s*=\s*(.+)`
+)
+
+// SAPInstanceProfile holds the SAP HA interface settings found in the profile of an instance
+// managed by a SAPInstance resource, telling whether the instance is set up with sap_cluster_connector
+type SAPInstanceProfile struct {
+	ResourceId            string `mapstructure:"resourceid,omitempty"`
+	Path                  string `mapstructure:"path,omitempty"`
+	Halib                 string `mapstructure:"halib,omitempty"`
+	HalibClusterConnector string `mapstructure:"halibclusterconnector,omitempty"`
+}
+
+// getSAPInstanceProfiles reads the profiles of the instances managed by SAPInstance resources.
+// Profiles which can not be read from this node are skipped
+func getSAPInstanceProfiles(c *Cluster) []*SAPInstanceProfile {
+	var profiles []*SAPInstanceProfile
+
+	primitives := c.Cib.Configuration.Resources.Primitives
+	for _, g := range c.Cib.Configuration.Resources.Groups {
+		primitives = append(primitives, g.Primitives...)
+	}
+
+	for _, p := range primitives {
+		if p.Type != sapInstanceResourceType {
+			continue
+		}
+
+		profilePath := getSAPInstanceProfilePath(p)
+		if profilePath == "" {
+			continue
+		}
+
+		profile, err := getSAPInstanceProfile(profilePath)
+		if err != nil {
+			log.Debugf("Could not read the profile of the SAP instance resource %s: %s", p.Id, err)
+			continue
+		}
+
+		profile.ResourceId = p.Id
+		profiles = append(profiles, profile)
+	}
+
+	return profiles
+}
+
+// getSAPInstanceProfilePath returns the START_PROFILE of the resource, or the default instance profile path
+// /usr/sap/<SID>/SYS/profile/<SID>_<INSTANCE>_<VHOST> when it is not set
+func getSAPInstanceProfilePath(p cib.Primitive) string {
+	var instanceName string
+
+	for _, a := range p.InstanceAttributes {
+		switch a.Name {
+		case sapInstanceProfileAttribute:
+			if a.Value != "" {
+				return a.Value
+			}
+		case sapInstanceNameAttribute:
+			instanceName = a.Value
+		}
+	}
+
+	sid := strings.SplitN(instanceName, "_", 2)[0]
+	if sid == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("/usr/sap/%s/SYS/profile/%s", sid, instanceName)
+}
+
+func getSAPInstanceProfile(profilePath string) (*SAPInstanceProfile, error) {
+	profileRaw, err := ioutil.ReadFile(profilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &SAPInstanceProfile{Path: profilePath}
+
+	configMap := internal.FindMatches(sapProfilePattern, profileRaw)
+	if halib, ok := configMap[sapHalibProperty].(string); ok {
+		profile.Halib = strings.TrimSpace(halib)
+	}
+	if connector, ok := configMap[sapHalibClusterConnectorProp].(string); ok {
+		profile.HalibClusterConnector = strings.TrimSpace(connector)
+	}
+
+	return profile, nil
+}
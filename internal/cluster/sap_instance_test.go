@@ -0,0 +1,67 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trento-project/trento/internal/cluster/cib"
+)
+
+func TestGetSAPInstanceProfiles(t *testing.T) {
+	c := &Cluster{}
+	c.Cib.Configuration.Resources.Groups = []cib.Group{
+		{
+			Id: "grp_NWP_ASCS00",
+			Primitives: []cib.Primitive{
+				{
+					Id:   "rsc_ip_NWP_ASCS00",
+					Type: "IPaddr2",
+				},
+				{
+					Id:   "rsc_sap_NWP_ASCS00",
+					Type: "SAPInstance",
+					InstanceAttributes: []cib.Attribute{
+						{Name: "InstanceName", Value: "NWP_ASCS00_sapnwpas"},
+						{Name: "START_PROFILE", Value: "../../test/sap_profile_ascs"},
+					},
+				},
+			},
+		},
+		{
+			Id: "grp_NWP_ERS10",
+			Primitives: []cib.Primitive{
+				{
+					Id:   "rsc_sap_NWP_ERS10",
+					Type: "SAPInstance",
+					InstanceAttributes: []cib.Attribute{
+						{Name: "InstanceName", Value: "NWP_ERS10_sapnwper"},
+						{Name: "START_PROFILE", Value: "../../test/not_found"},
+					},
+				},
+			},
+		},
+	}
+
+	profiles := getSAPInstanceProfiles(c)
+
+	assert.Equal(t, []*SAPInstanceProfile{
+		{
+			ResourceId:            "rsc_sap_NWP_ASCS00",
+			Path:                  "../../test/sap_profile_ascs",
+			Halib:                 "$(DIR_CT_RUN)/saphascriptco.so",
+			HalibClusterConnector: "/usr/bin/sap_suse_cluster_connector",
+		},
+	}, profiles)
+}
+
+func TestGetSAPInstanceProfilePath(t *testing.T) {
+	p := cib.Primitive{
+		InstanceAttributes: []cib.Attribute{
+			{Name: "InstanceName", Value: "NWP_ERS10_sapnwper"},
+		},
+	}
+
+	assert.Equal(t, "/usr/sap/NWP/SYS/profile/NWP_ERS10_sapnwper", getSAPInstanceProfilePath(p))
+	assert.Equal(t, "", getSAPInstanceProfilePath(cib.Primitive{}))
+}
@@ -1,6 +1,7 @@
 package cluster
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -58,7 +59,7 @@ type SBDNode struct {
 var sbdDumpExecCommand = exec.Command
 var sbdListExecCommand = exec.Command
 
-func NewSBD(cluster, sbdPath, sbdConfigPath string) (SBD, error) {
+func NewSBD(ctx context.Context, cluster, sbdPath, sbdConfigPath string) (SBD, error) {
 	var s = SBD{cluster: cluster}
 
 	c, err := getSBDConfig(sbdConfigPath)
@@ -72,7 +73,7 @@ func NewSBD(cluster, sbdPath, sbdConfigPath string) (SBD, error) {
 
 	for _, device := range strings.Split(strings.Trim(c["SBD_DEVICE"].(string), "\""), ";") {
 		sbdDevice := NewSBDDevice(sbdPath, device)
-		err := sbdDevice.LoadDeviceData()
+		err := sbdDevice.LoadDeviceData(ctx)
 		if err != nil {
 			log.Printf("Error getting sbd information: %s", err)
 		}
@@ -109,10 +110,10 @@ func NewSBDDevice(sbdPath string, device string) SBDDevice {
 	}
 }
 
-func (s *SBDDevice) LoadDeviceData() error {
+func (s *SBDDevice) LoadDeviceData(ctx context.Context) error {
 	var sbdErrors []string
 
-	dump, err := sbdDump(s.sbdPath, s.Device)
+	dump, err := sbdDump(ctx, s.sbdPath, s.Device)
 	s.Dump = dump
 
 	if err != nil {
@@ -122,7 +123,7 @@ func (s *SBDDevice) LoadDeviceData() error {
 		s.Status = SBDStatusHealthy
 	}
 
-	list, err := sbdList(s.sbdPath, s.Device)
+	list, err := sbdList(ctx, s.sbdPath, s.Device)
 	s.List = list
 
 	if err != nil {
@@ -158,10 +159,10 @@ func assignPatternResult(text string, pattern string) string {
 //Timeout (loop)     : 1
 //Timeout (msgwait)  : 10
 //==Header on disk /dev/vdc is dumped
-func sbdDump(sbdPath string, device string) (SBDDump, error) {
+func sbdDump(ctx context.Context, sbdPath string, device string) (SBDDump, error) {
 	var dump = SBDDump{}
 
-	sbdDump, err := sbdDumpExecCommand(sbdPath, "-d", device, "dump").Output()
+	sbdDump, err := internal.CommandOutput(ctx, sbdDumpExecCommand(sbdPath, "-d", device, "dump"))
 	sbdDumpStr := string(sbdDump)
 
 	dump.Header = assignPatternResult(sbdDumpStr, `Header version *: (.*)`)
@@ -184,10 +185,10 @@ func sbdDump(sbdPath string, device string) (SBDDump, error) {
 // Possible output
 //0	hana01	clear
 //1	hana02	clear
-func sbdList(sbdPath string, device string) ([]*SBDNode, error) {
+func sbdList(ctx context.Context, sbdPath string, device string) ([]*SBDNode, error) {
 	var list = []*SBDNode{}
 
-	output, err := sbdListExecCommand(sbdPath, "-d", device, "list").Output()
+	output, err := internal.CommandOutput(ctx, sbdListExecCommand(sbdPath, "-d", device, "list"))
 
 	// Loop through sbd list output and find for matches
 	r := regexp.MustCompile(`(\d+)\s+(\S+)\s+(\S+)`)
@@ -1,6 +1,7 @@
 package cluster
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"testing"
@@ -52,7 +53,7 @@ sbd failed; please check the logs.`
 func TestSbdDump(t *testing.T) {
 	sbdDumpExecCommand = mockSbdDump
 
-	dump, err := sbdDump("/bin/sbd", "/dev/vdc")
+	dump, err := sbdDump(context.Background(), "/bin/sbd", "/dev/vdc")
 
 	expectedDump := SBDDump{
 		Header:          "2.1",
@@ -72,7 +73,7 @@ func TestSbdDump(t *testing.T) {
 func TestSbdDumpError(t *testing.T) {
 	sbdDumpExecCommand = mockSbdDumpErr
 
-	dump, err := sbdDump("/bin/sbd", "/dev/vdc")
+	dump, err := sbdDump(context.Background(), "/bin/sbd", "/dev/vdc")
 
 	expectedDump := SBDDump{
 		Header:          "2.1",
@@ -92,7 +93,7 @@ func TestSbdDumpError(t *testing.T) {
 func TestSbdList(t *testing.T) {
 	sbdListExecCommand = mockSbdList
 
-	list, err := sbdList("/bin/sbd", "/dev/vdc")
+	list, err := sbdList(context.Background(), "/bin/sbd", "/dev/vdc")
 
 	expectedList := []*SBDNode{
 		&SBDNode{
@@ -114,7 +115,7 @@ func TestSbdList(t *testing.T) {
 func TestSbdListError(t *testing.T) {
 	sbdListExecCommand = mockSbdListErr
 
-	list, err := sbdList("/bin/sbd", "/dev/vdc")
+	list, err := sbdList(context.Background(), "/bin/sbd", "/dev/vdc")
 
 	expectedList := []*SBDNode{}
 
@@ -128,7 +129,7 @@ func TestLoadDeviceData(t *testing.T) {
 	sbdDumpExecCommand = mockSbdDump
 	sbdListExecCommand = mockSbdList
 
-	err := s.LoadDeviceData()
+	err := s.LoadDeviceData(context.Background())
 
 	expectedDevice := NewSBDDevice("/bin/sbd", "/dev/vdc")
 	expectedDevice.Status = "healthy"
@@ -164,7 +165,7 @@ func TestLoadDeviceDataDumpError(t *testing.T) {
 
 	sbdDumpExecCommand = mockSbdDumpErr
 
-	err := s.LoadDeviceData()
+	err := s.LoadDeviceData(context.Background())
 
 	expectedDevice := NewSBDDevice("/bin/sbdErr", "/dev/vdc")
 	expectedDevice.Status = "unhealthy"
@@ -203,7 +204,7 @@ func TestLoadDeviceDataListError(t *testing.T) {
 	sbdDumpExecCommand = mockSbdDump
 	sbdListExecCommand = mockSbdListErr
 
-	err := s.LoadDeviceData()
+	err := s.LoadDeviceData(context.Background())
 
 	expectedDevice := NewSBDDevice("/bin/sbdErr", "/dev/vdc")
 	expectedDevice.Status = "healthy"
@@ -230,7 +231,7 @@ func TestLoadDeviceDataError(t *testing.T) {
 	sbdDumpExecCommand = mockSbdDumpErr
 	sbdListExecCommand = mockSbdListErr
 
-	err := s.LoadDeviceData()
+	err := s.LoadDeviceData(context.Background())
 
 	expectedDevice := NewSBDDevice("/bin/sbdErr", "/dev/vdc")
 	expectedDevice.Status = "unhealthy"
@@ -285,7 +286,7 @@ func TestNewSBD(t *testing.T) {
 	sbdDumpExecCommand = mockSbdDump
 	sbdListExecCommand = mockSbdList
 
-	s, err := NewSBD("mycluster", "/bin/sbd", "../../test/sbd_config")
+	s, err := NewSBD(context.Background(), "mycluster", "/bin/sbd", "../../test/sbd_config")
 
 	expectedSbd := SBD{
 		cluster: "mycluster",
@@ -364,7 +365,7 @@ func TestNewSBD(t *testing.T) {
 }
 
 func TestNewSBDError(t *testing.T) {
-	s, err := NewSBD("mycluster", "/bin/sbd", "../../test/sbd_config_no_device")
+	s, err := NewSBD(context.Background(), "mycluster", "/bin/sbd", "../../test/sbd_config_no_device")
 
 	expectedSbd := SBD{
 		cluster: "mycluster",
@@ -387,7 +388,7 @@ func TestNewSBDUnhealthyDevices(t *testing.T) {
 	sbdDumpExecCommand = mockSbdDumpErr
 	sbdListExecCommand = mockSbdListErr
 
-	s, err := NewSBD("mycluster", "/bin/sbd", "../../test/sbd_config")
+	s, err := NewSBD(context.Background(), "mycluster", "/bin/sbd", "../../test/sbd_config")
 
 	expectedSbd := SBD{
 		cluster: "mycluster",
@@ -447,7 +448,7 @@ func TestNewSBDQuotedDevices(t *testing.T) {
 	sbdDumpExecCommand = mockSbdDump
 	sbdListExecCommand = mockSbdList
 
-	s, err := NewSBD("mycluster", "/bin/sbd", "../../test/sbd_config_quoted_devices")
+	s, err := NewSBD(context.Background(), "mycluster", "/bin/sbd", "../../test/sbd_config_quoted_devices")
 
 	assert.Equal(t, len(s.Devices), 2)
 	assert.Equal(t, "/dev/vdc", s.Devices[0].Device)
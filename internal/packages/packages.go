@@ -0,0 +1,88 @@
+package packages
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/trento/internal"
+)
+
+//go:generate mockery --all
+
+// rpmQueryFormat prints a tab separated line with the name, version, release, architecture and
+// installation time of each installed package
+const rpmQueryFormat = "%{NAME}\t%{VERSION}\t%{RELEASE}\t%{ARCH}\t%{INSTALLTIME}\n"
+
+type Packages []*Package
+
+type Package struct {
+	Name        string `json:"name" mapstructure:"name"`
+	Version     string `json:"version" mapstructure:"version"`
+	Release     string `json:"release" mapstructure:"release"`
+	Arch        string `json:"arch" mapstructure:"arch"`
+	InstallTime int64  `json:"install_time,omitempty" mapstructure:"install_time,omitempty"`
+}
+
+type CustomCommand func(name string, arg ...string) *exec.Cmd
+
+var customExecCommand CustomCommand = exec.Command
+
+// NewPackages returns the rpm packages installed in the host, sorted by name and architecture,
+// so that the inventory is only published again when a package changes
+func NewPackages(ctx context.Context) (Packages, error) {
+	log.Info("Identifying the installed packages...")
+	output, err := internal.CommandOutput(ctx, customExecCommand("rpm", "-qa", "--queryformat", rpmQueryFormat))
+	if err != nil {
+		return nil, err
+	}
+
+	packages := parseRpmOutput(output)
+	log.Infof("Packages (%d entries) discovered", len(packages))
+
+	return packages, nil
+}
+
+func parseRpmOutput(output []byte) Packages {
+	var packages Packages
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != 5 {
+			continue
+		}
+
+		// The imported gpg keys are listed as packages too
+		if fields[0] == "gpg-pubkey" {
+			continue
+		}
+
+		installTime, _ := strconv.ParseInt(fields[4], 10, 64)
+
+		packages = append(packages, &Package{
+			Name:        fields[0],
+			Version:     fields[1],
+			Release:     fields[2],
+			Arch:        fields[3],
+			InstallTime: installTime,
+		})
+	}
+
+	sort.SliceStable(packages, func(i, j int) bool {
+		if packages[i].Name != packages[j].Name {
+			return packages[i].Name < packages[j].Name
+		}
+		if packages[i].Arch != packages[j].Arch {
+			return packages[i].Arch < packages[j].Arch
+		}
+		return packages[i].Version+"-"+packages[i].Release < packages[j].Version+"-"+packages[j].Release
+	})
+
+	return packages
+}
@@ -0,0 +1,85 @@
+package packages
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/trento-project/trento/internal/packages/mocks"
+)
+
+func mockRpm() *exec.Cmd {
+	return exec.Command("printf", "%s",
+		"pacemaker\t2.0.5+20201202.ba59be712\t150300.8.3.1\tx86_64\t1634567890\n"+
+			"gpg-pubkey\t39db7c82\t5f68629b\t(none)\t1634500000\n"+
+			"corosync\t2.4.5\t10.14.6.1\tx86_64\t1634567800\n"+
+			"kernel-default\t5.3.18\t59.27.1\tx86_64\t1634567700\n"+
+			"kernel-default\t5.3.18\t57.3\tx86_64\t1634000000\n"+
+			"malformed line\n")
+}
+
+func mockRpmErr() *exec.Cmd {
+	return exec.Command("error")
+}
+
+func TestNewPackages(t *testing.T) {
+	mockCommand := new(mocks.CustomCommand)
+
+	customExecCommand = mockCommand.Execute
+
+	mockCommand.On("Execute", "rpm", "-qa", "--queryformat", rpmQueryFormat).Return(
+		mockRpm(),
+	)
+
+	packages, err := NewPackages(context.Background())
+
+	expectedPackages := Packages{
+		&Package{
+			Name:        "corosync",
+			Version:     "2.4.5",
+			Release:     "10.14.6.1",
+			Arch:        "x86_64",
+			InstallTime: 1634567800,
+		},
+		&Package{
+			Name:        "kernel-default",
+			Version:     "5.3.18",
+			Release:     "57.3",
+			Arch:        "x86_64",
+			InstallTime: 1634000000,
+		},
+		&Package{
+			Name:        "kernel-default",
+			Version:     "5.3.18",
+			Release:     "59.27.1",
+			Arch:        "x86_64",
+			InstallTime: 1634567700,
+		},
+		&Package{
+			Name:        "pacemaker",
+			Version:     "2.0.5+20201202.ba59be712",
+			Release:     "150300.8.3.1",
+			Arch:        "x86_64",
+			InstallTime: 1634567890,
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedPackages, packages)
+}
+
+func TestNewPackagesErr(t *testing.T) {
+	mockCommand := new(mocks.CustomCommand)
+
+	customExecCommand = mockCommand.Execute
+
+	mockCommand.On("Execute", "rpm", "-qa", "--queryformat", rpmQueryFormat).Return(
+		mockRpmErr(),
+	)
+
+	packages, err := NewPackages(context.Background())
+
+	assert.Equal(t, Packages(nil), packages)
+	assert.EqualError(t, err, "exec: \"error\": executable file not found in $PATH")
+}
@@ -0,0 +1,143 @@
+package pki
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// CertificateAuthority signs the client certificates of the agents with the CA trusted by the collector
+type CertificateAuthority struct {
+	certificate *x509.Certificate
+	key         crypto.Signer
+}
+
+// LoadCertificateAuthority loads the PEM encoded CA certificate and private key
+func LoadCertificateAuthority(certPath string, keyPath string) (*CertificateAuthority, error) {
+	certPEM, err := ioutil.ReadFile(certPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the CA certificate")
+	}
+
+	keyPEM, err := ioutil.ReadFile(keyPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the CA key")
+	}
+
+	return NewCertificateAuthority(certPEM, keyPEM)
+}
+
+func NewCertificateAuthority(certPEM []byte, keyPEM []byte) (*CertificateAuthority, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, fmt.Errorf("could not decode the CA certificate")
+	}
+
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse the CA certificate")
+	}
+
+	if !certificate.IsCA {
+		return nil, fmt.Errorf("the certificate is not a CA certificate")
+	}
+
+	key, err := ParsePrivateKey(keyPEM)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse the CA key")
+	}
+
+	return &CertificateAuthority{
+		certificate: certificate,
+		key:         key,
+	}, nil
+}
+
+// Sign issues a client certificate for a certificate signing request, which must be made for the given common name
+func (ca *CertificateAuthority) Sign(csrPEM []byte, commonName string, validity time.Duration) ([]byte, *x509.Certificate, error) {
+	csr, err := ParseCertificateRequest(csrPEM)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if csr.Subject.CommonName != commonName {
+		return nil, nil, fmt.Errorf("the certificate request is for %s, not for %s", csr.Subject.CommonName, commonName)
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    now.Add(-5 * time.Minute),
+		NotAfter:     now.Add(validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.certificate, csr.PublicKey, ca.key)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not sign the certificate")
+	}
+
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), certificate, nil
+}
+
+// ParseCertificateRequest decodes a PEM encoded certificate signing request, checking its signature
+func ParseCertificateRequest(csrPEM []byte) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode(csrPEM)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, fmt.Errorf("could not decode the certificate request")
+	}
+
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse the certificate request")
+	}
+
+	err = csr.CheckSignature()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid certificate request signature")
+	}
+
+	return csr, nil
+}
+
+// ParsePrivateKey decodes a PEM encoded PKCS#8, PKCS#1 or EC private key
+func ParsePrivateKey(keyPEM []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("could not decode the private key")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type")
+		}
+		return signer, nil
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	return x509.ParseECPrivateKey(block.Bytes)
+}
@@ -0,0 +1,76 @@
+package pki
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trento-project/trento/test/helpers"
+)
+
+func newTestCertificateAuthority(t *testing.T) *CertificateAuthority {
+	ca, err := NewCertificateAuthority(helpers.NewCertificateAuthorityPEM(t))
+	require.NoError(t, err)
+
+	return ca
+}
+
+func TestSign(t *testing.T) {
+	ca := newTestCertificateAuthority(t)
+
+	csrPEM, keyPEM, err := NewCertificateRequest("some-agent")
+	assert.NoError(t, err)
+
+	certPEM, certificate, err := ca.Sign(csrPEM, "some-agent", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, "some-agent", certificate.Subject.CommonName)
+	assert.Equal(t, []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}, certificate.ExtKeyUsage)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), certificate.NotAfter, time.Minute)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.certificate)
+	_, err = certificate.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	assert.NoError(t, err)
+
+	block, _ := pem.Decode(certPEM)
+	assert.Equal(t, "CERTIFICATE", block.Type)
+
+	key, err := ParsePrivateKey(keyPEM)
+	assert.NoError(t, err)
+	assert.Equal(t, key.Public(), certificate.PublicKey)
+}
+
+func TestSignOtherCommonName(t *testing.T) {
+	ca := newTestCertificateAuthority(t)
+
+	csrPEM, _, err := NewCertificateRequest("other-agent")
+	assert.NoError(t, err)
+
+	_, _, err = ca.Sign(csrPEM, "some-agent", time.Hour)
+	assert.EqualError(t, err, "the certificate request is for other-agent, not for some-agent")
+}
+
+func TestSignInvalidRequest(t *testing.T) {
+	ca := newTestCertificateAuthority(t)
+
+	_, _, err := ca.Sign([]byte("not a csr"), "some-agent", time.Hour)
+	assert.EqualError(t, err, "could not decode the certificate request")
+}
+
+func TestNewCertificateAuthorityNotCA(t *testing.T) {
+	ca := newTestCertificateAuthority(t)
+
+	csrPEM, keyPEM, err := NewCertificateRequest("some-agent")
+	assert.NoError(t, err)
+	certPEM, _, err := ca.Sign(csrPEM, "some-agent", time.Hour)
+	assert.NoError(t, err)
+
+	_, err = NewCertificateAuthority(certPEM, keyPEM)
+	assert.EqualError(t, err, "the certificate is not a CA certificate")
+}
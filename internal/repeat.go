@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	random      = rand.New(rand.NewSource(time.Now().UnixNano()))
+	randomMutex sync.Mutex
+)
+
+type repeatConfig struct {
+	startupJitter time.Duration
+	jitter        float64
+	maxBackoff    time.Duration
+}
+
+// RepeatOption configures how Repeat schedules the executions
+type RepeatOption func(*repeatConfig)
+
+// WithStartupJitter delays the first execution by a random time up to maxDelay,
+// so that the processes started at the same time don't run in lockstep
+func WithStartupJitter(maxDelay time.Duration) RepeatOption {
+	return func(c *repeatConfig) {
+		c.startupJitter = maxDelay
+	}
+}
+
+// WithJitter randomly shortens or lengthens each interval by up to the given fraction of it,
+// e.g. 0.1 spreads the executions between 90% and 110% of the interval
+func WithJitter(fraction float64) RepeatOption {
+	return func(c *repeatConfig) {
+		c.jitter = fraction
+	}
+}
+
+// WithBackoff doubles the interval after each consecutive failed execution, up to maxInterval.
+// The interval is restored after the first successful execution
+func WithBackoff(maxInterval time.Duration) RepeatOption {
+	return func(c *repeatConfig) {
+		c.maxBackoff = maxInterval
+	}
+}
+
+// Repeat executes a function at a given interval, until the context is done.
+// The first tick runs immediately, unless a startup jitter is given.
+// Each interval starts once the previous tick returns, and a tick returning an error counts as a failure for the backoff
+func Repeat(operation string, tick func() error, interval time.Duration, ctx context.Context, options ...RepeatOption) {
+	config := &repeatConfig{}
+	for _, option := range options {
+		option(config)
+	}
+
+	if config.startupJitter > 0 {
+		delay := randomDuration(config.startupJitter)
+		log.Debugf("First execution for operation %s in %s", operation, delay)
+		if !wait(ctx, delay) {
+			return
+		}
+	}
+
+	failures := 0
+	for {
+		if err := tick(); err != nil {
+			failures++
+		} else {
+			failures = 0
+		}
+
+		next := config.nextInterval(interval, failures)
+		log.Debugf("Next execution for operation %s in %s", operation, next)
+		if !wait(ctx, next) {
+			return
+		}
+	}
+}
+
+// nextInterval returns the interval until the next execution, after the given number of consecutive failures
+func (c *repeatConfig) nextInterval(interval time.Duration, failures int) time.Duration {
+	next := interval
+	if c.maxBackoff > interval {
+		for i := 1; i < failures && next < c.maxBackoff; i++ {
+			next *= 2
+		}
+		if next > c.maxBackoff {
+			next = c.maxBackoff
+		}
+	}
+
+	if c.jitter > 0 {
+		spread := time.Duration(float64(next) * c.jitter)
+		next = next - spread + randomDuration(2*spread)
+	}
+
+	return next
+}
+
+// randomDuration returns a random duration in [0, max)
+func randomDuration(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+
+	randomMutex.Lock()
+	defer randomMutex.Unlock()
+
+	return time.Duration(random.Int63n(int64(max)))
+}
+
+// wait sleeps for the given duration, returning false if the context is done before
+func wait(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,88 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepeat(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ticks := 0
+
+	tick := func() error {
+		ticks++
+		if ticks == 3 {
+			cancel()
+		}
+		return nil
+	}
+
+	start := time.Now()
+	Repeat("test", tick, 10*time.Millisecond, ctx)
+
+	assert.Equal(t, 3, ticks)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+}
+
+func TestRepeatStartupJitterCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ticks := 0
+
+	tick := func() error {
+		ticks++
+		return nil
+	}
+
+	Repeat("test", tick, time.Millisecond, ctx, WithStartupJitter(time.Hour))
+
+	assert.Equal(t, 0, ticks)
+}
+
+func TestRepeatBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var tickTimes []time.Time
+
+	tick := func() error {
+		tickTimes = append(tickTimes, time.Now())
+		if len(tickTimes) == 4 {
+			cancel()
+		}
+		return errors.New("failure")
+	}
+
+	Repeat("test", tick, 10*time.Millisecond, ctx, WithBackoff(time.Second))
+
+	assert.Len(t, tickTimes, 4)
+	assert.GreaterOrEqual(t, tickTimes[1].Sub(tickTimes[0]), 10*time.Millisecond)
+	assert.GreaterOrEqual(t, tickTimes[2].Sub(tickTimes[1]), 20*time.Millisecond)
+	assert.GreaterOrEqual(t, tickTimes[3].Sub(tickTimes[2]), 40*time.Millisecond)
+}
+
+func TestRepeatNextInterval(t *testing.T) {
+	config := &repeatConfig{maxBackoff: 10 * time.Minute}
+
+	assert.Equal(t, time.Minute, config.nextInterval(time.Minute, 0))
+	assert.Equal(t, time.Minute, config.nextInterval(time.Minute, 1))
+	assert.Equal(t, 2*time.Minute, config.nextInterval(time.Minute, 2))
+	assert.Equal(t, 8*time.Minute, config.nextInterval(time.Minute, 4))
+	assert.Equal(t, 10*time.Minute, config.nextInterval(time.Minute, 5))
+	assert.Equal(t, 10*time.Minute, config.nextInterval(time.Minute, 1000))
+
+	noBackoff := &repeatConfig{}
+	assert.Equal(t, time.Minute, noBackoff.nextInterval(time.Minute, 10))
+}
+
+func TestRepeatNextIntervalJitter(t *testing.T) {
+	config := &repeatConfig{jitter: 0.1}
+
+	for i := 0; i < 100; i++ {
+		next := config.nextInterval(time.Minute, 0)
+		assert.GreaterOrEqual(t, next, 54*time.Second)
+		assert.Less(t, next, 66*time.Second)
+	}
+}
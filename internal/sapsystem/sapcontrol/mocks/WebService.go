@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	mock "github.com/stretchr/testify/mock"
 	sapcontrol "github.com/trento-project/trento/internal/sapsystem/sapcontrol"
 )
@@ -12,13 +14,82 @@ type WebService struct {
 	mock.Mock
 }
 
-// GetInstanceProperties provides a mock function with given fields:
-func (_m *WebService) GetInstanceProperties() (*sapcontrol.GetInstancePropertiesResponse, error) {
-	ret := _m.Called()
+// ABAPGetWPTable provides a mock function with given fields: ctx
+func (_m *WebService) ABAPGetWPTable(ctx context.Context) (*sapcontrol.ABAPGetWPTableResponse, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *sapcontrol.ABAPGetWPTableResponse
+	if rf, ok := ret.Get(0).(func(context.Context) *sapcontrol.ABAPGetWPTableResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sapcontrol.ABAPGetWPTableResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAlertTree provides a mock function with given fields: ctx
+func (_m *WebService) GetAlertTree(ctx context.Context) (*sapcontrol.GetAlertTreeResponse, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *sapcontrol.GetAlertTreeResponse
+	if rf, ok := ret.Get(0).(func(context.Context) *sapcontrol.GetAlertTreeResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sapcontrol.GetAlertTreeResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAlerts provides a mock function with given fields: ctx, rootTid
+func (_m *WebService) GetAlerts(ctx context.Context, rootTid string) (*sapcontrol.GetAlertsResponse, error) {
+	ret := _m.Called(ctx, rootTid)
+
+	var r0 *sapcontrol.GetAlertsResponse
+	if rf, ok := ret.Get(0).(func(context.Context, string) *sapcontrol.GetAlertsResponse); ok {
+		r0 = rf(ctx, rootTid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sapcontrol.GetAlertsResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, rootTid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetInstanceProperties provides a mock function with given fields: ctx
+func (_m *WebService) GetInstanceProperties(ctx context.Context) (*sapcontrol.GetInstancePropertiesResponse, error) {
+	ret := _m.Called(ctx)
 
 	var r0 *sapcontrol.GetInstancePropertiesResponse
-	if rf, ok := ret.Get(0).(func() *sapcontrol.GetInstancePropertiesResponse); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context) *sapcontrol.GetInstancePropertiesResponse); ok {
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*sapcontrol.GetInstancePropertiesResponse)
@@ -26,8 +97,8 @@ func (_m *WebService) GetInstanceProperties() (*sapcontrol.GetInstanceProperties
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -35,13 +106,13 @@ func (_m *WebService) GetInstanceProperties() (*sapcontrol.GetInstanceProperties
 	return r0, r1
 }
 
-// GetProcessList provides a mock function with given fields:
-func (_m *WebService) GetProcessList() (*sapcontrol.GetProcessListResponse, error) {
-	ret := _m.Called()
+// GetProcessList provides a mock function with given fields: ctx
+func (_m *WebService) GetProcessList(ctx context.Context) (*sapcontrol.GetProcessListResponse, error) {
+	ret := _m.Called(ctx)
 
 	var r0 *sapcontrol.GetProcessListResponse
-	if rf, ok := ret.Get(0).(func() *sapcontrol.GetProcessListResponse); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context) *sapcontrol.GetProcessListResponse); ok {
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*sapcontrol.GetProcessListResponse)
@@ -49,8 +120,31 @@ func (_m *WebService) GetProcessList() (*sapcontrol.GetProcessListResponse, erro
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetQueueStatistic provides a mock function with given fields: ctx
+func (_m *WebService) GetQueueStatistic(ctx context.Context) (*sapcontrol.GetQueueStatisticResponse, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *sapcontrol.GetQueueStatisticResponse
+	if rf, ok := ret.Get(0).(func(context.Context) *sapcontrol.GetQueueStatisticResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sapcontrol.GetQueueStatisticResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -58,13 +152,13 @@ func (_m *WebService) GetProcessList() (*sapcontrol.GetProcessListResponse, erro
 	return r0, r1
 }
 
-// GetSystemInstanceList provides a mock function with given fields:
-func (_m *WebService) GetSystemInstanceList() (*sapcontrol.GetSystemInstanceListResponse, error) {
-	ret := _m.Called()
+// GetSystemInstanceList provides a mock function with given fields: ctx
+func (_m *WebService) GetSystemInstanceList(ctx context.Context) (*sapcontrol.GetSystemInstanceListResponse, error) {
+	ret := _m.Called(ctx)
 
 	var r0 *sapcontrol.GetSystemInstanceListResponse
-	if rf, ok := ret.Get(0).(func() *sapcontrol.GetSystemInstanceListResponse); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context) *sapcontrol.GetSystemInstanceListResponse); ok {
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*sapcontrol.GetSystemInstanceListResponse)
@@ -72,8 +166,123 @@ func (_m *WebService) GetSystemInstanceList() (*sapcontrol.GetSystemInstanceList
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSystemUpdateList provides a mock function with given fields: ctx
+func (_m *WebService) GetSystemUpdateList(ctx context.Context) (*sapcontrol.GetSystemUpdateListResponse, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *sapcontrol.GetSystemUpdateListResponse
+	if rf, ok := ret.Get(0).(func(context.Context) *sapcontrol.GetSystemUpdateListResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sapcontrol.GetSystemUpdateListResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetVersionInfo provides a mock function with given fields: ctx
+func (_m *WebService) GetVersionInfo(ctx context.Context) (*sapcontrol.GetVersionInfoResponse, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *sapcontrol.GetVersionInfoResponse
+	if rf, ok := ret.Get(0).(func(context.Context) *sapcontrol.GetVersionInfoResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sapcontrol.GetVersionInfoResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HACheckConfig provides a mock function with given fields: ctx
+func (_m *WebService) HACheckConfig(ctx context.Context) (*sapcontrol.HACheckConfigResponse, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *sapcontrol.HACheckConfigResponse
+	if rf, ok := ret.Get(0).(func(context.Context) *sapcontrol.HACheckConfigResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sapcontrol.HACheckConfigResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HACheckFailoverConfig provides a mock function with given fields: ctx
+func (_m *WebService) HACheckFailoverConfig(ctx context.Context) (*sapcontrol.HACheckFailoverConfigResponse, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *sapcontrol.HACheckFailoverConfigResponse
+	if rf, ok := ret.Get(0).(func(context.Context) *sapcontrol.HACheckFailoverConfigResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sapcontrol.HACheckFailoverConfigResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HAGetFailoverConfig provides a mock function with given fields: ctx
+func (_m *WebService) HAGetFailoverConfig(ctx context.Context) (*sapcontrol.HAGetFailoverConfigResponse, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *sapcontrol.HAGetFailoverConfigResponse
+	if rf, ok := ret.Get(0).(func(context.Context) *sapcontrol.HAGetFailoverConfigResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sapcontrol.HAGetFailoverConfigResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -14,9 +14,18 @@ import (
 //go:generate mockery --all
 
 type WebService interface {
-	GetInstanceProperties() (*GetInstancePropertiesResponse, error)
-	GetProcessList() (*GetProcessListResponse, error)
-	GetSystemInstanceList() (*GetSystemInstanceListResponse, error)
+	GetInstanceProperties(ctx context.Context) (*GetInstancePropertiesResponse, error)
+	GetProcessList(ctx context.Context) (*GetProcessListResponse, error)
+	GetSystemInstanceList(ctx context.Context) (*GetSystemInstanceListResponse, error)
+	HACheckConfig(ctx context.Context) (*HACheckConfigResponse, error)
+	HACheckFailoverConfig(ctx context.Context) (*HACheckFailoverConfigResponse, error)
+	HAGetFailoverConfig(ctx context.Context) (*HAGetFailoverConfigResponse, error)
+	GetVersionInfo(ctx context.Context) (*GetVersionInfoResponse, error)
+	GetSystemUpdateList(ctx context.Context) (*GetSystemUpdateListResponse, error)
+	GetAlertTree(ctx context.Context) (*GetAlertTreeResponse, error)
+	GetAlerts(ctx context.Context, rootTid string) (*GetAlertsResponse, error)
+	GetQueueStatistic(ctx context.Context) (*GetQueueStatisticResponse, error)
+	ABAPGetWPTable(ctx context.Context) (*ABAPGetWPTableResponse, error)
 }
 
 type STATECOLOR string
@@ -36,6 +45,20 @@ const (
 	STATECOLOR_CODE_RED    STATECOLOR_CODE = 4
 )
 
+type HAVerificationState string
+type HACheckCategory string
+
+const (
+	HAVerificationStateSuccess HAVerificationState = "SAPControl-HA-SUCCESS"
+	HAVerificationStateWarning HAVerificationState = "SAPControl-HA-WARNING"
+	HAVerificationStateError   HAVerificationState = "SAPControl-HA-ERROR"
+
+	HACheckCategorySAPConfiguration HACheckCategory = "SAPControl-SAP-CONFIGURATION"
+	HACheckCategorySAPState         HACheckCategory = "SAPControl-SAP-STATE"
+	HACheckCategoryHAConfiguration  HACheckCategory = "SAPControl-HA-CONFIGURATION"
+	HACheckCategoryHAState          HACheckCategory = "SAPControl-HA-STATE"
+)
+
 type GetInstanceProperties struct {
 	XMLName xml.Name `xml:"urn:SAPControl GetInstanceProperties"`
 }
@@ -63,6 +86,90 @@ type GetSystemInstanceListResponse struct {
 	Instances []*SAPInstance `xml:"instance>item,omitempty" json:"instance>item,omitempty"`
 }
 
+type HACheckConfig struct {
+	XMLName xml.Name `xml:"urn:SAPControl HACheckConfig"`
+}
+
+type HACheckConfigResponse struct {
+	XMLName xml.Name   `xml:"urn:SAPControl HACheckConfigResponse"`
+	Checks  []*HACheck `xml:"check>item,omitempty" json:"check>item,omitempty"`
+}
+
+type HACheckFailoverConfig struct {
+	XMLName xml.Name `xml:"urn:SAPControl HACheckFailoverConfig"`
+}
+
+type HACheckFailoverConfigResponse struct {
+	XMLName xml.Name   `xml:"urn:SAPControl HACheckFailoverConfigResponse"`
+	Checks  []*HACheck `xml:"check>item,omitempty" json:"check>item,omitempty"`
+}
+
+type HAGetFailoverConfig struct {
+	XMLName xml.Name `xml:"urn:SAPControl HAGetFailoverConfig"`
+}
+
+type HAGetFailoverConfigResponse struct {
+	XMLName xml.Name `xml:"urn:SAPControl HAGetFailoverConfigResponse"`
+	HAFailoverConfig
+}
+
+type GetVersionInfo struct {
+	XMLName xml.Name `xml:"urn:SAPControl GetVersionInfo"`
+}
+
+type GetVersionInfoResponse struct {
+	XMLName  xml.Name               `xml:"urn:SAPControl GetVersionInfoResponse"`
+	Versions []*InstanceVersionInfo `xml:"version>item,omitempty" json:"version>item,omitempty"`
+}
+
+type GetSystemUpdateList struct {
+	XMLName xml.Name `xml:"urn:SAPControl GetSystemUpdateList"`
+	Timeout int32    `xml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+type GetSystemUpdateListResponse struct {
+	XMLName   xml.Name                `xml:"urn:SAPControl GetSystemUpdateListResponse"`
+	Instances []*UpdateSystemInstance `xml:"updatelist>item,omitempty" json:"updatelist>item,omitempty"`
+}
+
+type GetAlertTree struct {
+	XMLName xml.Name `xml:"urn:SAPControl GetAlertTree"`
+}
+
+type GetAlertTreeResponse struct {
+	XMLName xml.Name     `xml:"urn:SAPControl GetAlertTreeResponse"`
+	Tree    []*AlertNode `xml:"tree>item,omitempty" json:"tree>item,omitempty"`
+}
+
+type GetAlerts struct {
+	XMLName xml.Name `xml:"urn:SAPControl GetAlerts"`
+	RootTid string   `xml:"RootTid,omitempty" json:"RootTid,omitempty"`
+}
+
+type GetAlertsResponse struct {
+	XMLName     xml.Name `xml:"urn:SAPControl GetAlertsResponse"`
+	RootTidName string   `xml:"RootTidName,omitempty" json:"RootTidName,omitempty"`
+	Alerts      []*Alert `xml:"alert>item,omitempty" json:"alert>item,omitempty"`
+}
+
+type GetQueueStatistic struct {
+	XMLName xml.Name `xml:"urn:SAPControl GetQueueStatistic"`
+}
+
+type GetQueueStatisticResponse struct {
+	XMLName xml.Name            `xml:"urn:SAPControl GetQueueStatisticResponse"`
+	Queues  []*TaskHandlerQueue `xml:"queue>item,omitempty" json:"queue>item,omitempty"`
+}
+
+type ABAPGetWPTable struct {
+	XMLName xml.Name `xml:"urn:SAPControl ABAPGetWPTable"`
+}
+
+type ABAPGetWPTableResponse struct {
+	XMLName       xml.Name       `xml:"urn:SAPControl ABAPGetWPTableResponse"`
+	WorkProcesses []*WorkProcess `xml:"workprocess>item,omitempty" json:"workprocess>item,omitempty"`
+}
+
 type OSProcess struct {
 	Name        string     `xml:"name,omitempty" json:"name,omitempty" mapstructure:"name,omitempty"`
 	Description string     `xml:"description,omitempty" json:"description,omitempty" mapstructure:"description,omitempty"`
@@ -89,6 +196,88 @@ type SAPInstance struct {
 	Dispstatus    STATECOLOR `xml:"dispstatus,omitempty" json:"dispstatus,omitempty" mapstructure:"dispstatus,omitempty"`
 }
 
+type InstanceVersionInfo struct {
+	Filename    string `xml:"Filename,omitempty" json:"Filename,omitempty" mapstructure:"filename,omitempty"`
+	VersionInfo string `xml:"VersionInfo,omitempty" json:"VersionInfo,omitempty" mapstructure:"versioninfo,omitempty"`
+	Time        string `xml:"Time,omitempty" json:"Time,omitempty" mapstructure:"time,omitempty"`
+}
+
+type UpdateSystemInstance struct {
+	Hostname   string     `xml:"hostname,omitempty" json:"hostname,omitempty" mapstructure:"hostname,omitempty"`
+	InstanceNr int32      `xml:"instanceNr,omitempty" json:"instanceNr" mapstructure:"instancenr"`
+	Status     string     `xml:"status,omitempty" json:"status,omitempty" mapstructure:"status,omitempty"`
+	Starttime  string     `xml:"starttime,omitempty" json:"starttime,omitempty" mapstructure:"starttime,omitempty"`
+	Endtime    string     `xml:"endtime,omitempty" json:"endtime,omitempty" mapstructure:"endtime,omitempty"`
+	Dispstatus STATECOLOR `xml:"dispstatus,omitempty" json:"dispstatus,omitempty" mapstructure:"dispstatus,omitempty"`
+}
+
+type AlertNode struct {
+	Name           string     `xml:"name,omitempty" json:"name,omitempty" mapstructure:"name,omitempty"`
+	Parent         int32      `xml:"parent,omitempty" json:"parent" mapstructure:"parent"`
+	ActualValue    STATECOLOR `xml:"ActualValue,omitempty" json:"ActualValue,omitempty" mapstructure:"actualvalue,omitempty"`
+	Description    string     `xml:"description,omitempty" json:"description,omitempty" mapstructure:"description,omitempty"`
+	Time           string     `xml:"Time,omitempty" json:"Time,omitempty" mapstructure:"time,omitempty"`
+	AnalyseTool    string     `xml:"AnalyseTool,omitempty" json:"AnalyseTool,omitempty" mapstructure:"analysetool,omitempty"`
+	VisibleLevel   string     `xml:"VisibleLevel,omitempty" json:"VisibleLevel,omitempty" mapstructure:"visiblelevel,omitempty"`
+	HighAlertValue STATECOLOR `xml:"HighAlertValue,omitempty" json:"HighAlertValue,omitempty" mapstructure:"highalertvalue,omitempty"`
+	AlDescription  string     `xml:"AlDescription,omitempty" json:"AlDescription,omitempty" mapstructure:"aldescription,omitempty"`
+	AlTime         string     `xml:"AlTime,omitempty" json:"AlTime,omitempty" mapstructure:"altime,omitempty"`
+	Tid            string     `xml:"Tid,omitempty" json:"Tid,omitempty" mapstructure:"tid,omitempty"`
+}
+
+type Alert struct {
+	Object      string     `xml:"Object,omitempty" json:"Object,omitempty" mapstructure:"object,omitempty"`
+	Attribute   string     `xml:"Attribute,omitempty" json:"Attribute,omitempty" mapstructure:"attribute,omitempty"`
+	Value       STATECOLOR `xml:"Value,omitempty" json:"Value,omitempty" mapstructure:"value,omitempty"`
+	Description string     `xml:"Description,omitempty" json:"Description,omitempty" mapstructure:"description,omitempty"`
+	Time        string     `xml:"Time,omitempty" json:"Time,omitempty" mapstructure:"time,omitempty"`
+	Tid         string     `xml:"Tid,omitempty" json:"Tid,omitempty" mapstructure:"tid,omitempty"`
+	Aid         string     `xml:"Aid,omitempty" json:"Aid,omitempty" mapstructure:"aid,omitempty"`
+}
+
+type TaskHandlerQueue struct {
+	Typ    string `xml:"Typ,omitempty" json:"Typ,omitempty" mapstructure:"typ,omitempty"`
+	Now    int32  `xml:"Now,omitempty" json:"Now" mapstructure:"now"`
+	High   int32  `xml:"High,omitempty" json:"High" mapstructure:"high"`
+	Max    int32  `xml:"Max,omitempty" json:"Max" mapstructure:"max"`
+	Writes int32  `xml:"Writes,omitempty" json:"Writes" mapstructure:"writes"`
+	Reads  int32  `xml:"Reads,omitempty" json:"Reads" mapstructure:"reads"`
+}
+
+type WorkProcess struct {
+	No      int32  `xml:"No,omitempty" json:"No" mapstructure:"no"`
+	Typ     string `xml:"Typ,omitempty" json:"Typ,omitempty" mapstructure:"typ,omitempty"`
+	Pid     int32  `xml:"Pid,omitempty" json:"Pid" mapstructure:"pid"`
+	Status  string `xml:"Status,omitempty" json:"Status,omitempty" mapstructure:"status,omitempty"`
+	Reason  string `xml:"Reason,omitempty" json:"Reason,omitempty" mapstructure:"reason,omitempty"`
+	Start   string `xml:"Start,omitempty" json:"Start,omitempty" mapstructure:"start,omitempty"`
+	Err     string `xml:"Err,omitempty" json:"Err,omitempty" mapstructure:"err,omitempty"`
+	Sem     string `xml:"Sem,omitempty" json:"Sem,omitempty" mapstructure:"sem,omitempty"`
+	Cpu     string `xml:"Cpu,omitempty" json:"Cpu,omitempty" mapstructure:"cpu,omitempty"`
+	Time    string `xml:"Time,omitempty" json:"Time,omitempty" mapstructure:"time,omitempty"`
+	Program string `xml:"Program,omitempty" json:"Program,omitempty" mapstructure:"program,omitempty"`
+	Client  string `xml:"Client,omitempty" json:"Client,omitempty" mapstructure:"client,omitempty"`
+	User    string `xml:"User,omitempty" json:"User,omitempty" mapstructure:"user,omitempty"`
+	Action  string `xml:"Action,omitempty" json:"Action,omitempty" mapstructure:"action,omitempty"`
+	Table   string `xml:"Table,omitempty" json:"Table,omitempty" mapstructure:"table,omitempty"`
+}
+
+type HACheck struct {
+	State       HAVerificationState `xml:"state,omitempty" json:"state,omitempty" mapstructure:"state,omitempty"`
+	Category    HACheckCategory     `xml:"category,omitempty" json:"category,omitempty" mapstructure:"category,omitempty"`
+	Description string              `xml:"description,omitempty" json:"description,omitempty" mapstructure:"description,omitempty"`
+	Comment     string              `xml:"comment,omitempty" json:"comment,omitempty" mapstructure:"comment,omitempty"`
+}
+
+type HAFailoverConfig struct {
+	HAActive              bool     `xml:"HAActive,omitempty" json:"HAActive,omitempty" mapstructure:"haactive,omitempty"`
+	HAProductVersion      string   `xml:"HAProductVersion,omitempty" json:"HAProductVersion,omitempty" mapstructure:"haproductversion,omitempty"`
+	HASAPInterfaceVersion string   `xml:"HASAPInterfaceVersion,omitempty" json:"HASAPInterfaceVersion,omitempty" mapstructure:"hasapinterfaceversion,omitempty"`
+	HADocumentation       string   `xml:"HADocumentation,omitempty" json:"HADocumentation,omitempty" mapstructure:"hadocumentation,omitempty"`
+	HAActiveNode          string   `xml:"HAActiveNode,omitempty" json:"HAActiveNode,omitempty" mapstructure:"haactivenode,omitempty"`
+	HANodes               []string `xml:"HANodes>item,omitempty" json:"HANodes>item,omitempty" mapstructure:"hanodes,omitempty"`
+}
+
 type webService struct {
 	client *soap.Client
 }
@@ -115,10 +304,10 @@ func NewWebService(instNumber string) WebService {
 }
 
 // GetInstanceProperties returns a list of available instance features and information how to get it.
-func (s *webService) GetInstanceProperties() (*GetInstancePropertiesResponse, error) {
+func (s *webService) GetInstanceProperties(ctx context.Context) (*GetInstancePropertiesResponse, error) {
 	request := &GetInstanceProperties{}
 	response := &GetInstancePropertiesResponse{}
-	err := s.client.Call("''", request, response)
+	err := s.client.CallContext(ctx, "''", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -128,10 +317,10 @@ func (s *webService) GetInstanceProperties() (*GetInstancePropertiesResponse, er
 
 // GetProcessList returns a list of all processes directly started by the webservice
 // according to the SAP start profile.
-func (s *webService) GetProcessList() (*GetProcessListResponse, error) {
+func (s *webService) GetProcessList(ctx context.Context) (*GetProcessListResponse, error) {
 	request := &GetProcessList{}
 	response := &GetProcessListResponse{}
-	err := s.client.Call("''", request, response)
+	err := s.client.CallContext(ctx, "''", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -141,10 +330,121 @@ func (s *webService) GetProcessList() (*GetProcessListResponse, error) {
 
 // GetSystemInstanceList returns a list of all processes directly started by the webservice
 // according to the SAP start profile.
-func (s *webService) GetSystemInstanceList() (*GetSystemInstanceListResponse, error) {
+func (s *webService) GetSystemInstanceList(ctx context.Context) (*GetSystemInstanceListResponse, error) {
 	request := &GetSystemInstanceList{}
 	response := &GetSystemInstanceListResponse{}
-	err := s.client.Call("''", request, response)
+	err := s.client.CallContext(ctx, "''", request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// HACheckConfig checks the high availability configuration and status of the system.
+func (s *webService) HACheckConfig(ctx context.Context) (*HACheckConfigResponse, error) {
+	request := &HACheckConfig{}
+	response := &HACheckConfigResponse{}
+	err := s.client.CallContext(ctx, "''", request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// HACheckFailoverConfig checks the high availability failover configuration of the instance
+// through the HA cluster software.
+func (s *webService) HACheckFailoverConfig(ctx context.Context) (*HACheckFailoverConfigResponse, error) {
+	request := &HACheckFailoverConfig{}
+	response := &HACheckFailoverConfigResponse{}
+	err := s.client.CallContext(ctx, "''", request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// HAGetFailoverConfig returns the high availability failover configuration and status of the instance
+// as seen by the HA cluster software.
+func (s *webService) HAGetFailoverConfig(ctx context.Context) (*HAGetFailoverConfigResponse, error) {
+	request := &HAGetFailoverConfig{}
+	response := &HAGetFailoverConfigResponse{}
+	err := s.client.CallContext(ctx, "''", request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetVersionInfo returns the version of the executables of the instance, e.g. the SAP kernel.
+func (s *webService) GetVersionInfo(ctx context.Context) (*GetVersionInfoResponse, error) {
+	request := &GetVersionInfo{}
+	response := &GetVersionInfoResponse{}
+	err := s.client.CallContext(ctx, "''", request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetSystemUpdateList returns the update status of all the instances of the system,
+// e.g. during a rolling kernel update.
+func (s *webService) GetSystemUpdateList(ctx context.Context) (*GetSystemUpdateListResponse, error) {
+	request := &GetSystemUpdateList{}
+	response := &GetSystemUpdateListResponse{}
+	err := s.client.CallContext(ctx, "''", request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetAlertTree returns the CCMS monitoring tree of the instance, along with the current alert value of each node.
+func (s *webService) GetAlertTree(ctx context.Context) (*GetAlertTreeResponse, error) {
+	request := &GetAlertTree{}
+	response := &GetAlertTreeResponse{}
+	err := s.client.CallContext(ctx, "''", request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetAlerts returns the current CCMS alerts of the given node of the monitoring tree and its children.
+func (s *webService) GetAlerts(ctx context.Context, rootTid string) (*GetAlertsResponse, error) {
+	request := &GetAlerts{RootTid: rootTid}
+	response := &GetAlertsResponse{}
+	err := s.client.CallContext(ctx, "''", request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetQueueStatistic returns the usage of the task handler queues of the ABAP dispatcher.
+func (s *webService) GetQueueStatistic(ctx context.Context) (*GetQueueStatisticResponse, error) {
+	request := &GetQueueStatistic{}
+	response := &GetQueueStatisticResponse{}
+	err := s.client.CallContext(ctx, "''", request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// ABAPGetWPTable returns the work processes of the ABAP instance.
+func (s *webService) ABAPGetWPTable(ctx context.Context) (*ABAPGetWPTableResponse, error) {
+	request := &ABAPGetWPTable{}
+	response := &ABAPGetWPTableResponse{}
+	err := s.client.CallContext(ctx, "''", request, response)
 	if err != nil {
 		return nil, err
 	}
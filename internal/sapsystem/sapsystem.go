@@ -2,6 +2,7 @@ package sapsystem
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net"
@@ -91,6 +92,34 @@ type SAPControl struct {
 	Processes  map[string]*sapcontrol.OSProcess        `mapstructure:"processes,omitempty"`
 	Instances  map[string]*sapcontrol.SAPInstance      `mapstructure:"instances,omitempty"`
 	Properties map[string]*sapcontrol.InstanceProperty `mapstructure:"properties,omitempty"`
+	Versions   []*sapcontrol.InstanceVersionInfo       `mapstructure:"versions,omitempty"`
+	UpdateList []*sapcontrol.UpdateSystemInstance      `mapstructure:"updatelist,omitempty"`
+	Alerts     []*Alert                                `mapstructure:"alerts,omitempty"`
+	// Only for ABAP application instances
+	Queues        []*sapcontrol.TaskHandlerQueue `mapstructure:"queues,omitempty"`
+	WorkProcesses []*WorkProcesses               `mapstructure:"workprocesses,omitempty"`
+	// Only for Application type, from the SAP HA interface
+	HAFailoverConfig *sapcontrol.HAFailoverConfig `mapstructure:"hafailoverconfig,omitempty"`
+	HAChecks         []*sapcontrol.HACheck        `mapstructure:"hachecks,omitempty"`
+	HAFailoverChecks []*sapcontrol.HACheck        `mapstructure:"hafailoverchecks,omitempty"`
+}
+
+// Alert is a CCMS alert of an instance, without the handles of the monitoring tree nodes
+type Alert struct {
+	Object      string                `mapstructure:"object,omitempty"`
+	Attribute   string                `mapstructure:"attribute,omitempty"`
+	Value       sapcontrol.STATECOLOR `mapstructure:"value,omitempty"`
+	Description string                `mapstructure:"description,omitempty"`
+	Time        string                `mapstructure:"time,omitempty"`
+}
+
+// WorkProcesses is the number of work processes of a given type of an ABAP instance, and how many
+// of them are busy running or holding a request. Only the summary is published, as the work process
+// table changes on every discovery and holds the names of the users running the requests
+type WorkProcesses struct {
+	Type  string `mapstructure:"type,omitempty"`
+	Total int    `mapstructure:"total"`
+	Busy  int    `mapstructure:"busy"`
 }
 
 type DatabaseData struct {
@@ -115,7 +144,7 @@ type CustomCommand func(name string, arg ...string) *exec.Cmd
 
 var customExecCommand CustomCommand = exec.Command
 
-func NewSAPSystemsList() (SAPSystemsList, error) {
+func NewSAPSystemsList(ctx context.Context) (SAPSystemsList, error) {
 	var systems = SAPSystemsList{}
 
 	appFS := afero.NewOsFs()
@@ -126,7 +155,7 @@ func NewSAPSystemsList() (SAPSystemsList, error) {
 
 	// Find systems
 	for _, sysPath := range systemPaths {
-		system, err := NewSAPSystem(appFS, sysPath)
+		system, err := NewSAPSystem(ctx, appFS, sysPath)
 		if err != nil {
 			log.Printf("Error discovering a SAP system: %s", err)
 			continue
@@ -172,7 +201,7 @@ func (sl SAPSystemsList) GetTypesString() string {
 	return strings.Join(typesString, ",")
 }
 
-func NewSAPSystem(fs afero.Fs, sysPath string) (*SAPSystem, error) {
+func NewSAPSystem(ctx context.Context, fs afero.Fs, sysPath string) (*SAPSystem, error) {
 	system := &SAPSystem{
 		SID:       sysPath[strings.LastIndex(sysPath, "/")+1:],
 		Instances: make(map[string]*SAPInstance),
@@ -195,7 +224,7 @@ func NewSAPSystem(fs afero.Fs, sysPath string) (*SAPSystem, error) {
 	// Find instances
 	for _, instPath := range instPaths {
 		webService := newWebService(instPath[1])
-		instance, err := NewSAPInstance(webService)
+		instance, err := NewSAPInstance(ctx, webService)
 		if err != nil {
 			log.Printf("Error discovering a SAP instance: %s", err)
 			continue
@@ -222,7 +251,7 @@ func NewSAPSystem(fs afero.Fs, sysPath string) (*SAPSystem, error) {
 		}
 	}
 
-	system, err = setSystemId(fs, system)
+	system, err = setSystemId(ctx, fs, system)
 	if err != nil {
 		return system, err
 	}
@@ -325,7 +354,7 @@ func getDBAddress(system *SAPSystem) (string, error) {
 	return "", fmt.Errorf("could not get any IPv4 address")
 }
 
-func setSystemId(fs afero.Fs, system *SAPSystem) (*SAPSystem, error) {
+func setSystemId(ctx context.Context, fs afero.Fs, system *SAPSystem) (*SAPSystem, error) {
 	// Set system ID
 	var err error
 	var id string
@@ -334,7 +363,7 @@ func setSystemId(fs afero.Fs, system *SAPSystem) (*SAPSystem, error) {
 	case Database:
 		id, err = getUniqueIdHana(fs, system.SID)
 	case Application:
-		id, err = getUniqueIdApplication(system.SID)
+		id, err = getUniqueIdApplication(ctx, system.SID)
 	case DiagnosticsAgent:
 		id, err = getUniqueIdDiagnostics(fs)
 	default:
@@ -371,10 +400,10 @@ func getUniqueIdHana(fs afero.Fs, sid string) (string, error) {
 	return hanaIdMd5, nil
 }
 
-func getUniqueIdApplication(sid string) (string, error) {
+func getUniqueIdApplication(ctx context.Context, sid string) (string, error) {
 	user := fmt.Sprintf("%sadm", strings.ToLower(sid))
 	cmd := fmt.Sprintf(sappfparCmd, sid)
-	sappfpar, err := customExecCommand("su", "-lc", cmd, user).Output()
+	sappfpar, err := internal.CommandOutput(ctx, customExecCommand("su", "-lc", cmd, user))
 	if err != nil {
 		return "", fmt.Errorf("error running sappfpar command with sid %s", sid)
 	}
@@ -441,18 +470,20 @@ func getDatabases(fs afero.Fs, sid string) ([]*DatabaseData, error) {
 	return databaseList, nil
 }
 
-func NewSAPInstance(w sapcontrol.WebService) (*SAPInstance, error) {
+func NewSAPInstance(ctx context.Context, w sapcontrol.WebService) (*SAPInstance, error) {
 	host, _ := os.Hostname()
 	var sapInstance = &SAPInstance{
 		Host: host,
 	}
 
-	scontrol, err := NewSAPControl(w)
+	scontrol, err := NewSAPControl(ctx, w)
 	if err != nil {
 		return sapInstance, err
 	}
 
 	sapInstance.SAPControl = scontrol
+	sapInstance.SAPControl.loadVersionData(ctx)
+	sapInstance.SAPControl.loadAlerts(ctx)
 
 	instanceName, ok := sapInstance.SAPControl.Properties["INSTANCE_NAME"]
 	if !ok {
@@ -468,9 +499,17 @@ func NewSAPInstance(w sapcontrol.WebService) (*SAPInstance, error) {
 
 	if sapInstance.Type == Database {
 		sid := sapInstance.SAPControl.Properties["SAPSYSTEMNAME"].Value
-		sapInstance.SystemReplication = systemReplicationStatus(sid, sapInstance.Name)
-		sapInstance.HostConfiguration = landscapeHostConfiguration(sid, sapInstance.Name)
-		sapInstance.HdbnsutilSRstate = hdbnsutilSrstate(sid, sapInstance.Name)
+		sapInstance.SystemReplication = systemReplicationStatus(ctx, sid, sapInstance.Name)
+		sapInstance.HostConfiguration = landscapeHostConfiguration(ctx, sid, sapInstance.Name)
+		sapInstance.HdbnsutilSRstate = hdbnsutilSrstate(ctx, sid, sapInstance.Name)
+	}
+
+	if sapInstance.Type == Application {
+		sapInstance.SAPControl.loadHAData(ctx)
+
+		if sapInstance.SAPControl.isABAPInstance() {
+			sapInstance.SAPControl.loadABAPData(ctx)
+		}
 	}
 
 	return sapInstance, nil
@@ -501,36 +540,36 @@ func detectType(sapControl *SAPControl) (int, error) {
 	return instanceType, nil
 }
 
-func runPythonSupport(sid, instance, script string) map[string]interface{} {
+func runPythonSupport(ctx context.Context, sid, instance, script string) map[string]interface{} {
 	user := fmt.Sprintf("%sadm", strings.ToLower(sid))
 	cmdPath := path.Join(sapInstallationPath, sid, instance, "exe/python_support", script)
 	cmd := fmt.Sprintf("python %s --sapcontrol=1", cmdPath)
 	// Even with a error return code, some data is available
-	srData, _ := customExecCommand("su", "-lc", cmd, user).Output()
+	srData, _ := internal.CommandOutput(ctx, customExecCommand("su", "-lc", cmd, user))
 
 	dataMap := internal.FindMatches(`(\S+)=(.*)`, srData)
 
 	return dataMap
 }
 
-func systemReplicationStatus(sid, instance string) map[string]interface{} {
-	return runPythonSupport(sid, instance, "systemReplicationStatus.py")
+func systemReplicationStatus(ctx context.Context, sid, instance string) map[string]interface{} {
+	return runPythonSupport(ctx, sid, instance, "systemReplicationStatus.py")
 }
 
-func landscapeHostConfiguration(sid, instance string) map[string]interface{} {
-	return runPythonSupport(sid, instance, "landscapeHostConfiguration.py")
+func landscapeHostConfiguration(ctx context.Context, sid, instance string) map[string]interface{} {
+	return runPythonSupport(ctx, sid, instance, "landscapeHostConfiguration.py")
 }
 
-func hdbnsutilSrstate(sid, instance string) map[string]interface{} {
+func hdbnsutilSrstate(ctx context.Context, sid, instance string) map[string]interface{} {
 	user := fmt.Sprintf("%sadm", strings.ToLower(sid))
 	cmdPath := path.Join(sapInstallationPath, sid, instance, "exe", "hdbnsutil")
 	cmd := fmt.Sprintf("%s -sr_state -sapcontrol=1", cmdPath)
-	srData, _ := customExecCommand("su", "-lc", cmd, user).Output()
+	srData, _ := internal.CommandOutput(ctx, customExecCommand("su", "-lc", cmd, user))
 	dataMap := internal.FindMatches(`(.+)=(.*)`, srData)
 	return dataMap
 }
 
-func NewSAPControl(w sapcontrol.WebService) (*SAPControl, error) {
+func NewSAPControl(ctx context.Context, w sapcontrol.WebService) (*SAPControl, error) {
 	var scontrol = &SAPControl{
 		webService: w,
 		Processes:  make(map[string]*sapcontrol.OSProcess),
@@ -538,7 +577,7 @@ func NewSAPControl(w sapcontrol.WebService) (*SAPControl, error) {
 		Properties: make(map[string]*sapcontrol.InstanceProperty),
 	}
 
-	properties, err := scontrol.webService.GetInstanceProperties()
+	properties, err := scontrol.webService.GetInstanceProperties(ctx)
 	if err != nil {
 		return scontrol, errors.Wrap(err, "SAPControl web service error")
 	}
@@ -547,7 +586,7 @@ func NewSAPControl(w sapcontrol.WebService) (*SAPControl, error) {
 		scontrol.Properties[prop.Property] = prop
 	}
 
-	processes, err := scontrol.webService.GetProcessList()
+	processes, err := scontrol.webService.GetProcessList(ctx)
 	if err != nil {
 		return scontrol, errors.Wrap(err, "SAPControl web service error")
 	}
@@ -556,7 +595,7 @@ func NewSAPControl(w sapcontrol.WebService) (*SAPControl, error) {
 		scontrol.Processes[proc.Name] = proc
 	}
 
-	instances, err := scontrol.webService.GetSystemInstanceList()
+	instances, err := scontrol.webService.GetSystemInstanceList(ctx)
 	if err != nil {
 		return scontrol, errors.Wrap(err, "SAPControl web service error")
 	}
@@ -567,3 +606,133 @@ func NewSAPControl(w sapcontrol.WebService) (*SAPControl, error) {
 
 	return scontrol, nil
 }
+
+// loadVersionData gets the version of the instance executables and the update status of the system instances.
+// Older sapstartsrv versions don't provide them, so the errors are only logged
+func (s *SAPControl) loadVersionData(ctx context.Context) {
+	versions, err := s.webService.GetVersionInfo(ctx)
+	if err != nil {
+		log.Debugf("Could not get the version info: %s", err)
+	} else {
+		s.Versions = versions.Versions
+	}
+
+	updateList, err := s.webService.GetSystemUpdateList(ctx)
+	if err != nil {
+		log.Debugf("Could not get the system update list: %s", err)
+	} else {
+		s.UpdateList = updateList.Instances
+	}
+}
+
+// loadAlerts gets the current CCMS alerts of the instance, starting from the root nodes of the
+// monitoring tree. The monitoring might not be available, so the errors are only logged
+func (s *SAPControl) loadAlerts(ctx context.Context) {
+	alertTree, err := s.webService.GetAlertTree(ctx)
+	if err != nil {
+		log.Debugf("Could not get the alert tree: %s", err)
+		return
+	}
+
+	for _, node := range alertTree.Tree {
+		// The root nodes don't have any parent
+		if node.Parent >= 0 {
+			continue
+		}
+
+		alerts, err := s.webService.GetAlerts(ctx, node.Tid)
+		if err != nil {
+			log.Debugf("Could not get the alerts of %s: %s", node.Name, err)
+			continue
+		}
+
+		for _, alert := range alerts.Alerts {
+			s.Alerts = append(s.Alerts, &Alert{
+				Object:      alert.Object,
+				Attribute:   alert.Attribute,
+				Value:       alert.Value,
+				Description: alert.Description,
+				Time:        alert.Time,
+			})
+		}
+	}
+}
+
+// isABAPInstance returns true if the local instance runs an ABAP dispatcher
+func (s *SAPControl) isABAPInstance() bool {
+	sapLocalhost, ok := s.Properties["SAPLOCALHOST"]
+	if !ok {
+		return false
+	}
+
+	instance, ok := s.Instances[sapLocalhost.Value]
+	if !ok {
+		return false
+	}
+
+	return strings.Contains(instance.Features, "ABAP")
+}
+
+// loadABAPData gets the dispatcher queues and the work processes of an ABAP instance.
+// The dispatcher might not be running, so the errors are only logged
+func (s *SAPControl) loadABAPData(ctx context.Context) {
+	queues, err := s.webService.GetQueueStatistic(ctx)
+	if err != nil {
+		log.Debugf("Could not get the queue statistic: %s", err)
+	} else {
+		s.Queues = queues.Queues
+	}
+
+	workProcesses, err := s.webService.ABAPGetWPTable(ctx)
+	if err != nil {
+		log.Debugf("Could not get the work process table: %s", err)
+	} else {
+		s.WorkProcesses = summarizeWorkProcesses(workProcesses.WorkProcesses)
+	}
+}
+
+// summarizeWorkProcesses returns the number of work processes by type, and how many of them are busy
+func summarizeWorkProcesses(workProcesses []*sapcontrol.WorkProcess) []*WorkProcesses {
+	var summaries []*WorkProcesses
+	byType := make(map[string]*WorkProcesses)
+	for _, w := range workProcesses {
+		summary, ok := byType[w.Typ]
+		if !ok {
+			summary = &WorkProcesses{Type: w.Typ}
+			byType[w.Typ] = summary
+			summaries = append(summaries, summary)
+		}
+
+		summary.Total++
+		if w.Status == "Run" || w.Status == "Hold" {
+			summary.Busy++
+		}
+	}
+
+	return summaries
+}
+
+// loadHAData gets the SAP HA interface configuration and checks.
+// The HA interface is optional, so the errors are only logged
+func (s *SAPControl) loadHAData(ctx context.Context) {
+	failoverConfig, err := s.webService.HAGetFailoverConfig(ctx)
+	if err != nil {
+		log.Debugf("Could not get the HA failover config: %s", err)
+	} else {
+		s.HAFailoverConfig = &failoverConfig.HAFailoverConfig
+	}
+
+	checks, err := s.webService.HACheckConfig(ctx)
+	if err != nil {
+		log.Debugf("Could not check the HA config: %s", err)
+	} else {
+		s.HAChecks = checks.Checks
+	}
+
+	failoverChecks, err := s.webService.HACheckFailoverConfig(ctx)
+	if err != nil {
+		log.Debugf("Could not check the HA failover config: %s", err)
+	} else {
+		s.HAFailoverChecks = failoverChecks.Checks
+	}
+}
@@ -1,6 +1,7 @@
 package sapsystem
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	sapSystemMocks "github.com/trento-project/trento/internal/sapsystem/mocks"
 	"github.com/trento-project/trento/internal/sapsystem/sapcontrol"
 	sapControlMocks "github.com/trento-project/trento/internal/sapsystem/sapcontrol/mocks"
@@ -33,7 +35,7 @@ func fakeNewWebService(instNumber string) sapcontrol.WebService {
 		instance = "ERS02"
 	}
 
-	mockWebService.On("GetInstanceProperties").Return(&sapcontrol.GetInstancePropertiesResponse{
+	mockWebService.On("GetInstanceProperties", mock.Anything).Return(&sapcontrol.GetInstancePropertiesResponse{
 		Properties: []*sapcontrol.InstanceProperty{
 			{
 				Property:     "SAPSYSTEMNAME",
@@ -53,14 +55,20 @@ func fakeNewWebService(instNumber string) sapcontrol.WebService {
 		},
 	}, nil)
 
-	mockWebService.On("GetProcessList").Return(&sapcontrol.GetProcessListResponse{
+	mockWebService.On("GetProcessList", mock.Anything).Return(&sapcontrol.GetProcessListResponse{
 		Processes: []*sapcontrol.OSProcess{},
 	}, nil)
 
-	mockWebService.On("GetSystemInstanceList").Return(&sapcontrol.GetSystemInstanceListResponse{
+	mockWebService.On("GetSystemInstanceList", mock.Anything).Return(&sapcontrol.GetSystemInstanceListResponse{
 		Instances: []*sapcontrol.SAPInstance{},
 	}, nil)
 
+	mockWebService.On("GetVersionInfo", mock.Anything).Return(&sapcontrol.GetVersionInfoResponse{}, nil)
+
+	mockWebService.On("GetSystemUpdateList", mock.Anything).Return(&sapcontrol.GetSystemUpdateListResponse{}, nil)
+
+	mockWebService.On("GetAlertTree", mock.Anything).Return(&sapcontrol.GetAlertTreeResponse{}, nil)
+
 	return mockWebService
 }
 
@@ -115,7 +123,7 @@ func TestNewSAPSystem(t *testing.T) {
 	cmd := fmt.Sprintf(sappfparCmd, "DEV")
 	mockCommand.On("Execute", "su", "-lc", cmd, "devadm").Return(mockSappfpar())
 
-	system, err := NewSAPSystem(appFS, "/usr/sap/DEV")
+	system, err := NewSAPSystem(context.Background(), appFS, "/usr/sap/DEV")
 
 	assert.Equal(t, Unknown, system.Type)
 	assert.Contains(t, system.Instances, "ASCS01")
@@ -165,7 +173,7 @@ key2 = value2
 		SID:  "DEV",
 	}
 
-	system, err := setSystemId(appFS, system)
+	system, err := setSystemId(context.Background(), appFS, system)
 
 	assert.NoError(t, err)
 	assert.Equal(t, "089d1a278481b86e821237f8e98e6de7", system.Id)
@@ -184,7 +192,7 @@ func TestSetSystemIdApplication(t *testing.T) {
 		SID:  "DEV",
 	}
 
-	system, err := setSystemId(appFS, system)
+	system, err := setSystemId(context.Background(), appFS, system)
 
 	assert.NoError(t, err)
 	assert.Equal(t, "089d1a278481b86e821237f8e98e6de7", system.Id)
@@ -198,7 +206,7 @@ func TestSetSystemIdOther(t *testing.T) {
 		SID:  "DEV",
 	}
 
-	system, err := setSystemId(appFS, system)
+	system, err := setSystemId(context.Background(), appFS, system)
 
 	assert.NoError(t, err)
 	assert.Equal(t, "-", system.Id)
@@ -219,7 +227,7 @@ func TestSetSystemIdDiagnostics(t *testing.T) {
 		SID:  "DAA",
 	}
 
-	system, err := setSystemId(appFS, system)
+	system, err := setSystemId(context.Background(), appFS, system)
 
 	assert.NoError(t, err)
 	assert.Equal(t, "d3d5dd5ec501127e0011a2531e3b11ff", system.Id)
@@ -292,7 +300,7 @@ func TestNewSAPInstanceDatabase(t *testing.T) {
 
 	customExecCommand = mockCommand.Execute
 
-	mockWebService.On("GetInstanceProperties").Return(&sapcontrol.GetInstancePropertiesResponse{
+	mockWebService.On("GetInstanceProperties", mock.Anything).Return(&sapcontrol.GetInstancePropertiesResponse{
 		Properties: []*sapcontrol.InstanceProperty{
 			{
 				Property:     "prop1",
@@ -317,7 +325,7 @@ func TestNewSAPInstanceDatabase(t *testing.T) {
 		},
 	}, nil)
 
-	mockWebService.On("GetProcessList").Return(&sapcontrol.GetProcessListResponse{
+	mockWebService.On("GetProcessList", mock.Anything).Return(&sapcontrol.GetProcessListResponse{
 		Processes: []*sapcontrol.OSProcess{
 			{
 				Name:        "enserver",
@@ -340,7 +348,7 @@ func TestNewSAPInstanceDatabase(t *testing.T) {
 		},
 	}, nil)
 
-	mockWebService.On("GetSystemInstanceList").Return(&sapcontrol.GetSystemInstanceListResponse{
+	mockWebService.On("GetSystemInstanceList", mock.Anything).Return(&sapcontrol.GetSystemInstanceListResponse{
 		Instances: []*sapcontrol.SAPInstance{
 			{
 				Hostname:      "host1",
@@ -363,6 +371,20 @@ func TestNewSAPInstanceDatabase(t *testing.T) {
 		},
 	}, nil)
 
+	mockWebService.On("GetVersionInfo", mock.Anything).Return(&sapcontrol.GetVersionInfoResponse{
+		Versions: []*sapcontrol.InstanceVersionInfo{
+			{
+				Filename:    "/usr/sap/PRD/HDB00/exe/sapstartsrv",
+				VersionInfo: "753, patch 800, changelist 1996813, RKS compatibility level 1, optimized, opt (Jun 18 2020, 20:46:19), linuxx86_64",
+				Time:        "2020 06 18 20:46:19",
+			},
+		},
+	}, nil)
+
+	mockWebService.On("GetSystemUpdateList", mock.Anything).Return(nil, fmt.Errorf("method not supported"))
+
+	mockWebService.On("GetAlertTree", mock.Anything).Return(nil, fmt.Errorf("monitoring not available"))
+
 	mockCommand.On("Execute", "su", "-lc", "python /usr/sap/PRD/HDB00/exe/python_support/systemReplicationStatus.py --sapcontrol=1", "prdadm").Return(
 		mockSystemReplicationStatus(),
 	)
@@ -375,7 +397,7 @@ func TestNewSAPInstanceDatabase(t *testing.T) {
 		mockHdbnsutilSrstate(),
 	)
 
-	sapInstance, _ := NewSAPInstance(mockWebService)
+	sapInstance, _ := NewSAPInstance(context.Background(), mockWebService)
 	host, _ := os.Hostname()
 
 	expectedInstance := &SAPInstance{
@@ -446,6 +468,13 @@ func TestNewSAPInstanceDatabase(t *testing.T) {
 					Dispstatus:    sapcontrol.STATECOLOR_YELLOW,
 				},
 			},
+			Versions: []*sapcontrol.InstanceVersionInfo{
+				{
+					Filename:    "/usr/sap/PRD/HDB00/exe/sapstartsrv",
+					VersionInfo: "753, patch 800, changelist 1996813, RKS compatibility level 1, optimized, opt (Jun 18 2020, 20:46:19), linuxx86_64",
+					Time:        "2020 06 18 20:46:19",
+				},
+			},
 		},
 		SystemReplication: SystemReplication{
 			"service/hana01/30001/SHIPPED_LOG_POSITION_TIME":             "2021-06-12 12:43:13.059197",
@@ -541,7 +570,7 @@ func TestNewSAPInstanceDatabase(t *testing.T) {
 func TestNewSAPInstanceApp(t *testing.T) {
 	mockWebService := new(sapControlMocks.WebService)
 
-	mockWebService.On("GetInstanceProperties").Return(&sapcontrol.GetInstancePropertiesResponse{
+	mockWebService.On("GetInstanceProperties", mock.Anything).Return(&sapcontrol.GetInstancePropertiesResponse{
 		Properties: []*sapcontrol.InstanceProperty{
 			{
 				Property:     "prop1",
@@ -566,7 +595,7 @@ func TestNewSAPInstanceApp(t *testing.T) {
 		},
 	}, nil)
 
-	mockWebService.On("GetProcessList").Return(&sapcontrol.GetProcessListResponse{
+	mockWebService.On("GetProcessList", mock.Anything).Return(&sapcontrol.GetProcessListResponse{
 		Processes: []*sapcontrol.OSProcess{
 			{
 				Name:        "enserver",
@@ -589,7 +618,7 @@ func TestNewSAPInstanceApp(t *testing.T) {
 		},
 	}, nil)
 
-	mockWebService.On("GetSystemInstanceList").Return(&sapcontrol.GetSystemInstanceListResponse{
+	mockWebService.On("GetSystemInstanceList", mock.Anything).Return(&sapcontrol.GetSystemInstanceListResponse{
 		Instances: []*sapcontrol.SAPInstance{
 			{
 				Hostname:      "host1",
@@ -612,7 +641,93 @@ func TestNewSAPInstanceApp(t *testing.T) {
 		},
 	}, nil)
 
-	sapInstance, _ := NewSAPInstance(mockWebService)
+	mockWebService.On("GetVersionInfo", mock.Anything).Return(&sapcontrol.GetVersionInfoResponse{
+		Versions: []*sapcontrol.InstanceVersionInfo{
+			{
+				Filename:    "/usr/sap/PRD/D00/exe/disp+work",
+				VersionInfo: "753, patch 900, changelist 2011573, RKS compatibility level 1, optimized, opt (Oct 27 2020, 13:35:59), linuxx86_64",
+				Time:        "2020 10 27 13:35:59",
+			},
+		},
+	}, nil)
+
+	mockWebService.On("GetSystemUpdateList", mock.Anything).Return(&sapcontrol.GetSystemUpdateListResponse{
+		Instances: []*sapcontrol.UpdateSystemInstance{
+			{
+				Hostname:   "host1",
+				InstanceNr: 0,
+				Status:     "ok",
+				Dispstatus: sapcontrol.STATECOLOR_GREEN,
+			},
+		},
+	}, nil)
+
+	mockWebService.On("GetAlertTree", mock.Anything).Return(&sapcontrol.GetAlertTreeResponse{
+		Tree: []*sapcontrol.AlertNode{
+			{
+				Name:        "PRD",
+				Parent:      -1,
+				ActualValue: sapcontrol.STATECOLOR_RED,
+				Tid:         "root_tid",
+			},
+			{
+				Name:        "Dialog",
+				Parent:      0,
+				ActualValue: sapcontrol.STATECOLOR_RED,
+				Tid:         "dialog_tid",
+			},
+		},
+	}, nil)
+
+	mockWebService.On("GetAlerts", mock.Anything, "root_tid").Return(&sapcontrol.GetAlertsResponse{
+		RootTidName: "PRD",
+		Alerts: []*sapcontrol.Alert{
+			{
+				Object:      "Dialog",
+				Attribute:   "ResponseTime",
+				Value:       sapcontrol.STATECOLOR_RED,
+				Description: "Dialog response time exceeds the threshold",
+				Time:        "2021 11 10 10:25:12",
+				Tid:         "dialog_tid",
+				Aid:         "alert_id",
+			},
+		},
+	}, nil)
+
+	mockWebService.On("HAGetFailoverConfig", mock.Anything).Return(&sapcontrol.HAGetFailoverConfigResponse{
+		HAFailoverConfig: sapcontrol.HAFailoverConfig{
+			HAActive:              true,
+			HAProductVersion:      "SUSE Linux Enterprise Server for SAP Applications 15 SP2",
+			HASAPInterfaceVersion: "SUSE Linux Enterprise Server for SAP Applications 15 SP2 (sap_suse_cluster_connector 3.1.2)",
+			HADocumentation:       "https://www.suse.com/products/sles-for-sap/resource-library/sap-best-practices/",
+			HAActiveNode:          "host1",
+			HANodes:               []string{"host1", "host2"},
+		},
+	}, nil)
+
+	mockWebService.On("HACheckConfig", mock.Anything).Return(&sapcontrol.HACheckConfigResponse{
+		Checks: []*sapcontrol.HACheck{
+			{
+				State:       sapcontrol.HAVerificationStateSuccess,
+				Category:    sapcontrol.HACheckCategorySAPConfiguration,
+				Description: "Redundant ABAP instance configuration",
+				Comment:     "2 ABAP instances detected",
+			},
+		},
+	}, nil)
+
+	mockWebService.On("HACheckFailoverConfig", mock.Anything).Return(&sapcontrol.HACheckFailoverConfigResponse{
+		Checks: []*sapcontrol.HACheck{
+			{
+				State:       sapcontrol.HAVerificationStateWarning,
+				Category:    sapcontrol.HACheckCategoryHAConfiguration,
+				Description: "HA failover configuration",
+				Comment:     "The SAP instance is not part of a resource group",
+			},
+		},
+	}, nil)
+
+	sapInstance, _ := NewSAPInstance(context.Background(), mockWebService)
 	host, _ := os.Hostname()
 
 	expectedInstance := &SAPInstance{
@@ -683,6 +798,54 @@ func TestNewSAPInstanceApp(t *testing.T) {
 					Dispstatus:    sapcontrol.STATECOLOR_YELLOW,
 				},
 			},
+			Versions: []*sapcontrol.InstanceVersionInfo{
+				{
+					Filename:    "/usr/sap/PRD/D00/exe/disp+work",
+					VersionInfo: "753, patch 900, changelist 2011573, RKS compatibility level 1, optimized, opt (Oct 27 2020, 13:35:59), linuxx86_64",
+					Time:        "2020 10 27 13:35:59",
+				},
+			},
+			UpdateList: []*sapcontrol.UpdateSystemInstance{
+				{
+					Hostname:   "host1",
+					InstanceNr: 0,
+					Status:     "ok",
+					Dispstatus: sapcontrol.STATECOLOR_GREEN,
+				},
+			},
+			Alerts: []*Alert{
+				{
+					Object:      "Dialog",
+					Attribute:   "ResponseTime",
+					Value:       sapcontrol.STATECOLOR_RED,
+					Description: "Dialog response time exceeds the threshold",
+					Time:        "2021 11 10 10:25:12",
+				},
+			},
+			HAFailoverConfig: &sapcontrol.HAFailoverConfig{
+				HAActive:              true,
+				HAProductVersion:      "SUSE Linux Enterprise Server for SAP Applications 15 SP2",
+				HASAPInterfaceVersion: "SUSE Linux Enterprise Server for SAP Applications 15 SP2 (sap_suse_cluster_connector 3.1.2)",
+				HADocumentation:       "https://www.suse.com/products/sles-for-sap/resource-library/sap-best-practices/",
+				HAActiveNode:          "host1",
+				HANodes:               []string{"host1", "host2"},
+			},
+			HAChecks: []*sapcontrol.HACheck{
+				{
+					State:       sapcontrol.HAVerificationStateSuccess,
+					Category:    sapcontrol.HACheckCategorySAPConfiguration,
+					Description: "Redundant ABAP instance configuration",
+					Comment:     "2 ABAP instances detected",
+				},
+			},
+			HAFailoverChecks: []*sapcontrol.HACheck{
+				{
+					State:       sapcontrol.HAVerificationStateWarning,
+					Category:    sapcontrol.HACheckCategoryHAConfiguration,
+					Description: "HA failover configuration",
+					Comment:     "The SAP instance is not part of a resource group",
+				},
+			},
 		},
 		SystemReplication: SystemReplication(nil),
 		HostConfiguration: HostConfiguration(nil),
@@ -692,6 +855,100 @@ func TestNewSAPInstanceApp(t *testing.T) {
 	assert.Equal(t, expectedInstance, sapInstance)
 }
 
+func TestLoadHADataNotConfigured(t *testing.T) {
+	mockWebService := new(sapControlMocks.WebService)
+
+	mockWebService.On("HAGetFailoverConfig", mock.Anything).Return(nil, fmt.Errorf("HA interface not configured"))
+	mockWebService.On("HACheckConfig", mock.Anything).Return(nil, fmt.Errorf("HA interface not configured"))
+	mockWebService.On("HACheckFailoverConfig", mock.Anything).Return(nil, fmt.Errorf("HA interface not configured"))
+
+	scontrol := &SAPControl{webService: mockWebService}
+	scontrol.loadHAData(context.Background())
+
+	assert.Nil(t, scontrol.HAFailoverConfig)
+	assert.Nil(t, scontrol.HAChecks)
+	assert.Nil(t, scontrol.HAFailoverChecks)
+	mockWebService.AssertExpectations(t)
+}
+
+func TestIsABAPInstance(t *testing.T) {
+	scontrol := &SAPControl{
+		Properties: map[string]*sapcontrol.InstanceProperty{
+			"SAPLOCALHOST": &sapcontrol.InstanceProperty{
+				Property:     "SAPLOCALHOST",
+				Propertytype: "string",
+				Value:        "host1",
+			},
+		},
+		Instances: map[string]*sapcontrol.SAPInstance{
+			"host1": &sapcontrol.SAPInstance{
+				Hostname: "host1",
+				Features: "ABAP|GATEWAY|ICMAN|IGS",
+			},
+			"host2": &sapcontrol.SAPInstance{
+				Hostname: "host2",
+				Features: "MESSAGESERVER|ENQUE",
+			},
+		},
+	}
+
+	assert.True(t, scontrol.isABAPInstance())
+
+	scontrol.Properties["SAPLOCALHOST"].Value = "host2"
+	assert.False(t, scontrol.isABAPInstance())
+}
+
+func TestLoadABAPData(t *testing.T) {
+	mockWebService := new(sapControlMocks.WebService)
+
+	mockWebService.On("GetQueueStatistic", mock.Anything).Return(&sapcontrol.GetQueueStatisticResponse{
+		Queues: []*sapcontrol.TaskHandlerQueue{
+			{
+				Typ:    "ABAP/DIA",
+				Now:    8,
+				High:   10,
+				Max:    14000,
+				Writes: 2420,
+				Reads:  2412,
+			},
+		},
+	}, nil)
+	mockWebService.On("ABAPGetWPTable", mock.Anything).Return(nil, fmt.Errorf("dispatcher not running"))
+
+	scontrol := &SAPControl{webService: mockWebService}
+	scontrol.loadABAPData(context.Background())
+
+	assert.Equal(t, []*sapcontrol.TaskHandlerQueue{
+		{
+			Typ:    "ABAP/DIA",
+			Now:    8,
+			High:   10,
+			Max:    14000,
+			Writes: 2420,
+			Reads:  2412,
+		},
+	}, scontrol.Queues)
+	assert.Nil(t, scontrol.WorkProcesses)
+	mockWebService.AssertExpectations(t)
+}
+
+func TestSummarizeWorkProcesses(t *testing.T) {
+	workProcesses := []*sapcontrol.WorkProcess{
+		{No: 0, Typ: "DIA", Status: "Run", User: "SAPSYS", Cpu: "0:00:02"},
+		{No: 1, Typ: "DIA", Status: "Wait"},
+		{No: 2, Typ: "UPD", Status: "Wait"},
+		{No: 3, Typ: "DIA", Status: "Stop"},
+		{No: 4, Typ: "UPD", Status: "Hold", User: "DDIC"},
+	}
+
+	assert.Equal(t, []*WorkProcesses{
+		{Type: "DIA", Total: 3, Busy: 1},
+		{Type: "UPD", Total: 2, Busy: 1},
+	}, summarizeWorkProcesses(workProcesses))
+
+	assert.Nil(t, summarizeWorkProcesses(nil))
+}
+
 func TestGetSIDsString(t *testing.T) {
 	sysList := SAPSystemsList{
 		&SAPSystem{
@@ -1,11 +1,13 @@
 package subscription
 
 import (
+	"context"
 	"encoding/json"
 	"os/exec"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/trento/internal"
 )
 
 //go:generate mockery --all
@@ -28,11 +30,11 @@ type CustomCommand func(name string, arg ...string) *exec.Cmd
 
 var customExecCommand CustomCommand = exec.Command
 
-func NewSubscriptions() (Subscriptions, error) {
+func NewSubscriptions(ctx context.Context) (Subscriptions, error) {
 	var subs Subscriptions
 
 	log.Info("Identifying the SUSE subscription details...")
-	output, err := customExecCommand("SUSEConnect", "-s").Output()
+	output, err := internal.CommandOutput(ctx, customExecCommand("SUSEConnect", "-s"))
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package subscription
 
 import (
+	"context"
 	"os/exec"
 	"testing"
 
@@ -31,7 +32,7 @@ func TestNewSubscriptions(t *testing.T) {
 		mockSUSEConnect(),
 	)
 
-	subs, err := NewSubscriptions()
+	subs, err := NewSubscriptions(context.Background())
 
 	expectedSubs := Subscriptions{
 		&Subscription{
@@ -65,7 +66,7 @@ func TestNewSubscriptionsErr(t *testing.T) {
 		mockSUSEConnectErr(),
 	)
 
-	subs, err := NewSubscriptions()
+	subs, err := NewSubscriptions(context.Background())
 
 	assert.Equal(t, Subscriptions(nil), subs)
 	assert.EqualError(t, err, "exec: \"error\": executable file not found in $PATH")
@@ -0,0 +1,215 @@
+package tuning
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/trento/internal"
+)
+
+//go:generate mockery --all
+
+const (
+	sysctlPath            = "proc/sys"
+	transparentHugepages  = "sys/kernel/mm/transparent_hugepage/enabled"
+	cpuGovernorsPattern   = "sys/devices/system/cpu/cpu[0-9]*/cpufreq/scaling_governor"
+	saptuneConfigPath     = "etc/sysconfig/saptune"
+	saptuneService        = "saptune.service"
+	sapconfService        = "sapconf.service"
+	systemdActiveState    = "active"
+	saptuneSolutionsEntry = "TUNE_FOR_SOLUTIONS"
+	saptuneNotesEntry     = "TUNE_FOR_NOTES"
+	saptuneNoteOrderEntry = "NOTE_APPLY_ORDER"
+	saptuneVersionEntry   = "SAPTUNE_VERSION"
+)
+
+// SysctlKeys are the kernel parameters tuned by the SAP Notes for HANA and NetWeaver systems
+var SysctlKeys = []string{
+	"kernel.numa_balancing",
+	"kernel.pid_max",
+	"kernel.sem",
+	"kernel.shmall",
+	"kernel.shmmax",
+	"kernel.shmmni",
+	"net.core.somaxconn",
+	"net.ipv4.tcp_max_syn_backlog",
+	"net.ipv4.tcp_slow_start_after_idle",
+	"net.ipv4.tcp_timestamps",
+	"vm.dirty_background_bytes",
+	"vm.dirty_bytes",
+	"vm.max_map_count",
+	"vm.pagecache_limit_mb",
+	"vm.swappiness",
+}
+
+var saptuneConfigPattern = regexp.MustCompile(`(?m)^(\w+)="?([^"\n]*)"?`)
+
+type Tuning struct {
+	Sysctl               map[string]string `json:"sysctl"`
+	TransparentHugepages string            `json:"transparent_hugepages"`
+	CPUGovernors         []string          `json:"cpu_governors"`
+	Saptune              *Saptune          `json:"saptune"`
+	SapconfActive        bool              `json:"sapconf_active"`
+}
+
+type Saptune struct {
+	Version   string   `json:"version"`
+	Active    bool     `json:"active"`
+	Solutions []string `json:"solutions"`
+	Notes     []string `json:"notes"`
+}
+
+type CustomCommand func(name string, arg ...string) *exec.Cmd
+
+var customExecCommand CustomCommand = exec.Command
+
+// NewTuning returns the kernel tuning of the host, along with the saptune and sapconf state
+func NewTuning(ctx context.Context) (*Tuning, error) {
+	return newTuning(ctx, "/")
+}
+
+func newTuning(ctx context.Context, root string) (*Tuning, error) {
+	log.Info("Discovering the kernel tuning...")
+
+	activeServices, err := getActiveServices(ctx, saptuneService, sapconfService)
+	if err != nil {
+		return nil, err
+	}
+
+	tuning := &Tuning{
+		Sysctl:               getSysctlValues(root),
+		TransparentHugepages: getTransparentHugepages(root),
+		CPUGovernors:         getCPUGovernors(root),
+		Saptune:              getSaptune(root),
+		SapconfActive:        activeServices[sapconfService],
+	}
+
+	if tuning.Saptune != nil {
+		tuning.Saptune.Active = activeServices[saptuneService]
+	}
+
+	log.Info("Kernel tuning discovered")
+
+	return tuning, nil
+}
+
+func getSysctlValues(root string) map[string]string {
+	values := make(map[string]string)
+
+	for _, key := range SysctlKeys {
+		value, err := ioutil.ReadFile(path.Join(root, sysctlPath, strings.ReplaceAll(key, ".", "/")))
+		if err != nil {
+			// Some parameters only exist in some kernel versions
+			log.Debugf("Could not read the %s kernel parameter: %s", key, err)
+			continue
+		}
+
+		// Multi-valued parameters, as kernel.sem, are separated by tabs
+		values[key] = strings.Join(strings.Fields(string(value)), " ")
+	}
+
+	return values
+}
+
+// getTransparentHugepages returns the selected transparent hugepages mode, e.g. never
+// when the kernel reports "always madvise [never]"
+func getTransparentHugepages(root string) string {
+	content, err := ioutil.ReadFile(path.Join(root, transparentHugepages))
+	if err != nil {
+		log.Debugf("Could not read the transparent hugepages mode: %s", err)
+		return ""
+	}
+
+	for _, mode := range strings.Fields(string(content)) {
+		if strings.HasPrefix(mode, "[") && strings.HasSuffix(mode, "]") {
+			return strings.Trim(mode, "[]")
+		}
+	}
+
+	return ""
+}
+
+// getCPUGovernors returns the distinct CPU frequency governors of the host CPUs.
+// Many virtual machines do not expose the CPU frequency scaling, so it might be empty
+func getCPUGovernors(root string) []string {
+	files, err := filepath.Glob(path.Join(root, cpuGovernorsPattern))
+	if err != nil {
+		return nil
+	}
+
+	var governors []string
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			continue
+		}
+
+		governor := strings.TrimSpace(string(content))
+		if governor != "" && !internal.Contains(governors, governor) {
+			governors = append(governors, governor)
+		}
+	}
+
+	sort.Strings(governors)
+
+	return governors
+}
+
+// getSaptune returns the saptune solutions and notes configured in its sysconfig file,
+// nil if saptune is not installed
+func getSaptune(root string) *Saptune {
+	content, err := ioutil.ReadFile(path.Join(root, saptuneConfigPath))
+	if err != nil {
+		log.Debugf("Could not read the saptune configuration: %s", err)
+		return nil
+	}
+
+	return parseSaptuneConfig(content)
+}
+
+func parseSaptuneConfig(content []byte) *Saptune {
+	config := make(map[string]string)
+	for _, match := range saptuneConfigPattern.FindAllStringSubmatch(string(content), -1) {
+		config[match[1]] = match[2]
+	}
+
+	// The apply order includes the notes of the solutions along with the ones enabled on their own
+	notes := strings.Fields(config[saptuneNoteOrderEntry])
+	if len(notes) == 0 {
+		notes = strings.Fields(config[saptuneNotesEntry])
+	}
+
+	return &Saptune{
+		Version:   config[saptuneVersionEntry],
+		Solutions: strings.Fields(config[saptuneSolutionsEntry]),
+		Notes:     notes,
+	}
+}
+
+// getActiveServices tells which of the given systemd services are active
+func getActiveServices(ctx context.Context, services ...string) (map[string]bool, error) {
+	args := append([]string{"is-active"}, services...)
+	output, err := internal.CommandOutput(ctx, customExecCommand("systemctl", args...))
+
+	// systemctl exits with an error when any of the services is not active
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return nil, err
+	}
+
+	activeServices := make(map[string]bool)
+	states := strings.Split(strings.TrimSpace(string(output)), "\n")
+	for i, service := range services {
+		activeServices[service] = i < len(states) && strings.TrimSpace(states[i]) == systemdActiveState
+	}
+
+	return activeServices, nil
+}
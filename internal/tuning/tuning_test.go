@@ -0,0 +1,100 @@
+package tuning
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/trento-project/trento/internal/tuning/mocks"
+)
+
+func mockSystemctl() *exec.Cmd {
+	return exec.Command("sh", "-c", "printf 'active\ninactive\n'; exit 3")
+}
+
+func mockSystemctlErr() *exec.Cmd {
+	return exec.Command("error")
+}
+
+func TestNewTuning(t *testing.T) {
+	mockCommand := new(mocks.CustomCommand)
+
+	customExecCommand = mockCommand.Execute
+
+	mockCommand.On("Execute", "systemctl", "is-active", "saptune.service", "sapconf.service").Return(
+		mockSystemctl(),
+	)
+
+	tuning, err := newTuning(context.Background(), "../../test/tuning_root")
+
+	expectedTuning := &Tuning{
+		Sysctl: map[string]string{
+			"kernel.numa_balancing":              "0",
+			"kernel.sem":                         "32000 1024000000 500 32000",
+			"net.ipv4.tcp_slow_start_after_idle": "0",
+			"vm.dirty_background_bytes":          "314572",
+			"vm.dirty_bytes":                     "629145",
+			"vm.max_map_count":                   "2147483647",
+			"vm.swappiness":                      "10",
+		},
+		TransparentHugepages: "never",
+		CPUGovernors:         []string{"performance"},
+		Saptune: &Saptune{
+			Version:   "3",
+			Active:    true,
+			Solutions: []string{"HANA"},
+			Notes:     []string{"941735", "1771258", "1980196", "2578899", "2684254", "2382421"},
+		},
+		SapconfActive: false,
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedTuning, tuning)
+}
+
+func TestNewTuningNoSaptune(t *testing.T) {
+	mockCommand := new(mocks.CustomCommand)
+
+	customExecCommand = mockCommand.Execute
+
+	mockCommand.On("Execute", "systemctl", "is-active", "saptune.service", "sapconf.service").Return(
+		exec.Command("sh", "-c", "printf 'inactive\nactive\n'; exit 3"),
+	)
+
+	tuning, err := newTuning(context.Background(), "../../test/notexist")
+
+	expectedTuning := &Tuning{
+		Sysctl:        map[string]string{},
+		SapconfActive: true,
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedTuning, tuning)
+}
+
+func TestNewTuningErr(t *testing.T) {
+	mockCommand := new(mocks.CustomCommand)
+
+	customExecCommand = mockCommand.Execute
+
+	mockCommand.On("Execute", "systemctl", "is-active", "saptune.service", "sapconf.service").Return(
+		mockSystemctlErr(),
+	)
+
+	tuning, err := newTuning(context.Background(), "../../test/tuning_root")
+
+	assert.Nil(t, tuning)
+	assert.EqualError(t, err, "exec: \"error\": executable file not found in $PATH")
+}
+
+func TestParseSaptuneConfigWithoutApplyOrder(t *testing.T) {
+	saptune := parseSaptuneConfig([]byte("TUNE_FOR_SOLUTIONS=\"NETWEAVER\"\nTUNE_FOR_NOTES=\"2578899 1771258\"\n# SAPTUNE_VERSION=\"2\"\n"))
+
+	expectedSaptune := &Saptune{
+		Solutions: []string{"NETWEAVER"},
+		Notes:     []string{"2578899", "1771258"},
+	}
+
+	assert.Equal(t, expectedSaptune, saptune)
+}
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"bytes"
 	"context"
 	"crypto/md5"
 	"encoding/hex"
@@ -8,9 +9,10 @@ import (
 	"hash/crc32"
 	"io"
 	"os"
+	"os/exec"
 	"regexp"
 	"strings"
-	"time"
+	"syscall"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -98,23 +100,34 @@ func CRC32hash(input []byte) int {
 
 }
 
-// Repeat executes a function at a given interval.
-// the first tick runs immediately
-func Repeat(operation string, tick func(), interval time.Duration, ctx context.Context) {
-	tick()
-
-	ticker := time.NewTicker(interval)
-	msg := fmt.Sprintf("Next execution for operation %s in %s", operation, interval)
-	log.Debugf(msg)
-
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ticker.C:
-			tick()
-			log.Debugf(msg)
-		case <-ctx.Done():
-			return
-		}
+// CommandOutput runs the command and returns its standard output, as exec.Cmd.Output does.
+// The command is run in its own process group, which is killed if the context is done before the command completes,
+// so that the processes it started, like the ones run by su, don't outlive it keeping the output open
+func CommandOutput(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+
+	err := cmd.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err = <-done:
+		return stdout.Bytes(), err
+	case <-ctx.Done():
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		<-done
+		return stdout.Bytes(), ctx.Err()
 	}
 }
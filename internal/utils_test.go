@@ -0,0 +1,40 @@
+package internal
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandOutput(t *testing.T) {
+	output, err := CommandOutput(context.Background(), exec.Command("echo", "trento"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "trento\n", string(output))
+}
+
+func TestCommandOutputCancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := CommandOutput(ctx, exec.Command("sleep", "10"))
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestCommandOutputCancelledChildren(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// The shell runs sleep in a child process, which keeps the standard output open if only the shell is killed
+	start := time.Now()
+	_, err := CommandOutput(ctx, exec.Command("sh", "-c", "sleep 10; echo trento"))
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
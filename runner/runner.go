@@ -27,6 +27,13 @@ const (
 	AnsibleHostFile   = "ansible/ansible_hosts"
 )
 
+const (
+	// tickJitter randomly spreads the check executions around the configured interval
+	tickJitter = 0.1
+	// maxTickBackoff caps the interval between the check executions while they keep failing
+	maxTickBackoff = time.Hour
+)
+
 type Runner struct {
 	config    *Config
 	ctx       context.Context
@@ -193,33 +200,46 @@ func (c *Runner) startCheckRunnerTicker() {
 		return
 	}
 
-	tick := func() {
-		if err = metaRunner.RunPlaybook(); err != nil {
+	tick := func() error {
+		if err := metaRunner.RunPlaybook(); err != nil {
 			log.Errorf("Error running the catalog meta-playbook")
-			return
+			return err
 		}
 
 		content, err := NewClusterInventoryContent(c.trentoApi)
 		if err != nil {
 			log.Errorf("Error creating the ansible inventory content: %s", err)
-			return
+			return err
 		}
 
 		inventoryFile := path.Join(c.config.AnsibleFolder, AnsibleHostFile)
 		err = CreateInventory(inventoryFile, content)
 		if err != nil {
 			log.Errorf("Error creating the ansible inventory file")
-			return
+			return err
 		}
 
 		if err = checkRunner.SetInventory(inventoryFile); err != nil {
 			log.Errorf("Error setting the ansible inventory file")
-			return
+			return err
+		}
+
+		// ansible-playbook fails as soon as a single host is unreachable, so the checks playbook
+		// errors don't back off the executions of the remaining clusters
+		if err = checkRunner.RunPlaybook(); err != nil {
+			log.Errorf("Error running the checks playbook: %s", err)
 		}
 
-		checkRunner.RunPlaybook()
+		return nil
 	}
 
 	interval := c.config.Interval
-	internal.Repeat("runner.ansible_playbook", tick, interval, c.ctx)
+	internal.Repeat(
+		"runner.ansible_playbook",
+		tick,
+		interval,
+		c.ctx,
+		internal.WithJitter(tickJitter),
+		internal.WithBackoff(maxTickBackoff),
+	)
 }
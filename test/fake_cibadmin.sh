@@ -124,6 +124,19 @@ cat <<EOF
       <rsc_location id="cli-prefer-cln_SAPHanaTopology_PRD_HDB00" rsc="cln_SAPHanaTopology_PRD_HDB00" role="Started" node="node01" score="INFINITY"/>
       <rsc_location id="cli-ban-msl_SAPHana_PRD_HDB00-on-node01" rsc="msl_SAPHana_PRD_HDB00" role="Started" node="node01" score="-INFINITY"/>
       <rsc_location id="test" rsc="test" role="Started" node="node02" score="666"/>
+      <rsc_location id="loc_test_pingd" rsc="test">
+        <rule id="loc_test_pingd-rule" score="-INFINITY">
+          <expression id="loc_test_pingd-rule-expression" operation="not_defined" attribute="pingd"/>
+        </rule>
+      </rsc_location>
+      <rsc_colocation id="col_test_stop" score="1000" rsc="test" with-rsc="test-stop"/>
+      <rsc_order id="ord_test_stop" kind="Optional">
+        <resource_set id="ord_test_stop-0" sequential="true">
+          <resource_ref id="test-stop"/>
+          <resource_ref id="test"/>
+        </resource_set>
+      </rsc_order>
+      <rsc_ticket id="tkt_test_stop" ticket="ticket-A" rsc="test-stop" loss-policy="stop"/>
     </constraints>
     <rsc_defaults>
       <meta_attributes id="rsc-options">
@@ -0,0 +1,27 @@
+#!/bin/bash
+
+# /usr/sbin/corosync-quorumtool -s
+cat <<RESULT
+Quorum information
+------------------
+Date:             Tue Jan 11 14:02:47 2022
+Quorum provider:  corosync_votequorum
+Nodes:            2
+Node ID:          1
+Ring ID:          1.2c
+Quorate:          Yes
+
+Votequorum information
+----------------------
+Expected votes:   2
+Highest expected: 2
+Total votes:      2
+Quorum:           1
+Flags:            2Node Quorate WaitForAll
+
+Membership information
+----------------------
+    Nodeid      Votes Name
+         1          1 node01 (local)
+         2          1 node02
+RESULT
@@ -135,11 +135,15 @@ cat <<EOF
             <resource_history id="test" orphan="false" migration-threshold="5000">
                 <operation_history call="29" task="start" last-rc-change="Mon Feb 24 09:45:49 2020" last-run="Mon Feb 24 09:45:49 2020" exec-time="11ms" queue-time="0ms" rc="0" rc_text="ok" />
             </resource_history>
-            <resource_history id="test-stop" orphan="false" migration-threshold="5000">
+            <resource_history id="test-stop" orphan="false" migration-threshold="5000" fail-count="1" last-failure="Mon Feb 24 09:46:41 2020">
+                <operation_history call="34" task="start" last-rc-change="Mon Feb 24 09:46:41 2020" last-run="Mon Feb 24 09:46:41 2020" exec-time="15ms" queue-time="0ms" rc="5" rc_text="not installed" exit-reason="Setup problem: couldn't find command: dummy" />
                 <operation_history call="35" task="stop" last-rc-change="Mon Feb 24 09:46:58 2020" last-run="Mon Feb 24 09:46:58 2020" exec-time="12ms" queue-time="0ms" rc="0" rc_text="ok" />
             </resource_history>
         </node>
     </node_history>
+    <failures>
+        <failure op_key="test-stop_start_0" node="node02" exitstatus="not installed" exitreason="Setup problem: couldn't find command: dummy" exitcode="5" call="34" status="complete" last-rc-change="Mon Feb 24 09:46:41 2020" queued="0" exec="15" interval="0" task="start" />
+    </failures>
     <tickets>
     </tickets>
     <bans>
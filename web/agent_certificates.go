@@ -0,0 +1,50 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func NewAgentCertificatesHandler(agentCertificatesService services.AgentCertificatesService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		certificates, err := agentCertificatesService.GetAll()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		var expiring int
+		for _, certificate := range certificates {
+			if !certificate.IsRevoked() && (certificate.ExpiresSoon() || certificate.Expired()) {
+				expiring++
+			}
+		}
+
+		c.HTML(http.StatusOK, "agent_certificates.html.tmpl", gin.H{
+			"Certificates":      certificates,
+			"ExpiringCount":     expiring,
+			"ExpiryWarningDays": int(models.CertificateExpiryWarning.Hours() / 24),
+		})
+	}
+}
+
+func NewApproveAgentCertificateHandler(agentCertificatesService services.AgentCertificatesService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_, err := agentCertificatesService.Approve(c.Param("id"))
+		if errors.Is(err, services.ErrCertificateRequestNotFound) {
+			_ = c.Error(NotFoundError("could not find a pending certificate request"))
+			return
+		}
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.Redirect(http.StatusFound, "/certificates")
+	}
+}
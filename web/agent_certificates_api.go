@@ -0,0 +1,227 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+type JSONCertificateRequest struct {
+	AgentID string `json:"agent_id" binding:"required"`
+	CSR     string `json:"csr" binding:"required"`
+}
+
+type JSONCertificateRenewal struct {
+	CSR string `json:"csr" binding:"required"`
+}
+
+// ApiRequestAgentCertificateHandler stores the certificate signing request of an agent, to be approved by an administrator.
+// Agents already holding a request or a certificate have to prove their identity to replace it
+func ApiRequestAgentCertificateHandler(agentCertificatesService services.AgentCertificatesService, agentCredentialsService services.AgentCredentialsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var r JSONCertificateRequest
+
+		err := c.BindJSON(&r)
+		if err != nil {
+			return
+		}
+
+		authenticated, err := isCertificateRequestAuthenticated(c, r.AgentID, agentCertificatesService, agentCredentialsService)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+
+		err = agentCertificatesService.Request(r.AgentID, r.CSR, authenticated)
+		if errors.Is(err, services.ErrInvalidCertificateRequest) {
+			_ = c.AbortWithError(http.StatusBadRequest, err).SetType(gin.ErrorTypeBind)
+			return
+		}
+		if errors.Is(err, services.ErrAgentCertificateExists) {
+			log.Warnf("Rejected certificate request of agent %s: %s", r.AgentID, err)
+			c.AbortWithStatus(http.StatusConflict)
+			return
+		}
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+
+		log.Infof("Agent %s requested a certificate, pending approval", r.AgentID)
+		c.JSON(http.StatusAccepted, nil)
+	}
+}
+
+// ApiGetAgentCertificateHandler returns the certificate request status of an agent, along with the certificate once issued
+func ApiGetAgentCertificateHandler(agentCertificatesService services.AgentCertificatesService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		certificate, err := agentCertificatesService.GetByAgentID(c.Param("id"))
+		if errors.Is(err, services.ErrAgentCertificateNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, certificate)
+	}
+}
+
+// ApiRenewAgentCertificateHandler issues a new certificate to an agent authenticated with its current one
+func ApiRenewAgentCertificateHandler(agentCertificatesService services.AgentCertificatesService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var r JSONCertificateRenewal
+
+		err := c.BindJSON(&r)
+		if err != nil {
+			return
+		}
+
+		certificate, err := agentCertificatesService.Renew(c.Param("id"), clientCertificateSerialNumber(c), r.CSR)
+		if errors.Is(err, services.ErrInvalidCertificateRequest) {
+			_ = c.AbortWithError(http.StatusBadRequest, err).SetType(gin.ErrorTypeBind)
+			return
+		}
+		if errors.Is(err, services.ErrInvalidAgentCertificate) {
+			log.Warnf("Rejected certificate renewal of agent %s: %s", c.Param("id"), err)
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+
+		log.Infof("Agent %s renewed its certificate", certificate.AgentID)
+		c.JSON(http.StatusCreated, certificate)
+	}
+}
+
+// isCertificateRequestAuthenticated tells whether the agent requesting a certificate proved its identity,
+// either with the credentials got on enrollment or with the certificate currently issued to it
+func isCertificateRequestAuthenticated(c *gin.Context, agentID string, agentCertificatesService services.AgentCertificatesService, agentCredentialsService services.AgentCredentialsService) (bool, error) {
+	if id, secret, ok := c.Request.BasicAuth(); ok && id == agentID {
+		err := agentCredentialsService.Authenticate(id, secret)
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, services.ErrInvalidAgentCredentials) {
+			return false, err
+		}
+	}
+
+	if c.Request.TLS != nil && len(c.Request.TLS.VerifiedChains) > 0 &&
+		c.Request.TLS.VerifiedChains[0][0].Subject.CommonName == agentID {
+		err := agentCertificatesService.Authenticate(agentID, clientCertificateSerialNumber(c))
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, services.ErrInvalidAgentCertificate) {
+			return false, err
+		}
+	}
+
+	return false, nil
+}
+
+// ApiListAgentCertificatesHandler godoc
+// @Summary List the certificate requests and certificates of the agents
+// @Produce json
+// @Success 200 {object} []models.AgentCertificate
+// @Failure 500 {object} map[string]string
+// @Router /certificates [get]
+func ApiListAgentCertificatesHandler(agentCertificatesService services.AgentCertificatesService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		certificates, err := agentCertificatesService.GetAll()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if certificates == nil {
+			c.JSON(http.StatusOK, []*models.AgentCertificate{})
+			return
+		}
+
+		c.JSON(http.StatusOK, certificates)
+	}
+}
+
+// ApiApproveAgentCertificateHandler godoc
+// @Summary Approve the pending certificate request of an agent, issuing its certificate
+// @Produce json
+// @Param id path string true "Agent id"
+// @Success 200 {object} models.AgentCertificate
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /certificates/{id}/approve [post]
+func ApiApproveAgentCertificateHandler(agentCertificatesService services.AgentCertificatesService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		certificate, err := agentCertificatesService.Approve(c.Param("id"))
+		if errors.Is(err, services.ErrCertificateRequestNotFound) {
+			_ = c.Error(NotFoundError("could not find a pending certificate request"))
+			return
+		}
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, certificate)
+	}
+}
+
+// ApiRevokeAgentCertificateHandler godoc
+// @Summary Revoke the certificate issued to an agent, which can not use nor renew it anymore
+// @Produce json
+// @Param id path string true "Agent id"
+// @Success 204 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /certificates/{id}/revoke [post]
+func ApiRevokeAgentCertificateHandler(agentCertificatesService services.AgentCertificatesService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := agentCertificatesService.Revoke(c.Param("id"))
+		if errors.Is(err, services.ErrAgentCertificateNotFound) {
+			_ = c.Error(NotFoundError("could not find an issued certificate"))
+			return
+		}
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusNoContent, nil)
+	}
+}
+
+// ApiDeleteAgentCertificateHandler godoc
+// @Summary Delete the certificate request and certificate of an agent, so that it can request a new one
+// @Produce json
+// @Param id path string true "Agent id"
+// @Success 204 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /certificates/{id} [delete]
+func ApiDeleteAgentCertificateHandler(agentCertificatesService services.AgentCertificatesService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := agentCertificatesService.Delete(c.Param("id"))
+		if errors.Is(err, services.ErrAgentCertificateNotFound) {
+			_ = c.Error(NotFoundError("could not find a certificate request or certificate"))
+			return
+		}
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusNoContent, nil)
+	}
+}
@@ -0,0 +1,374 @@
+package web
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func setupTestCAConfig() *Config {
+	config := setupTestConfig()
+	config.CAKey = "some-ca-key"
+
+	return config
+}
+
+// withClientCertificate fakes a request over a TLS connection authenticated with a verified client certificate,
+// whose serial number is 1f
+func withClientCertificate(req *http.Request, commonName string) {
+	certificate := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}, SerialNumber: big.NewInt(0x1f)}
+	req.TLS = &tls.ConnectionState{
+		VerifiedChains: [][]*x509.Certificate{{certificate}},
+	}
+}
+
+func TestApiRequestAgentCertificateHandler(t *testing.T) {
+	mockAgentCertificatesService := new(services.MockAgentCertificatesService)
+	mockAgentCertificatesService.On("Request", "agent_id", "some-csr", false).Return(nil)
+	mockAgentCertificatesService.On("Request", "agent_id", "invalid-csr", false).Return(services.ErrInvalidCertificateRequest)
+	mockAgentCertificatesService.On("Request", "requested_agent_id", "some-csr", false).Return(services.ErrAgentCertificateExists)
+
+	deps := setupTestDependencies()
+	deps.agentCertificatesService = mockAgentCertificatesService
+
+	app, err := NewAppWithDeps(setupTestCAConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		body         string
+		expectedCode int
+	}{
+		{`{"agent_id":"agent_id","csr":"some-csr"}`, 202},
+		{`{"agent_id":"agent_id","csr":"invalid-csr"}`, 400},
+		{`{"agent_id":"agent_id"}`, 400},
+		{`{"agent_id":"requested_agent_id","csr":"some-csr"}`, 409},
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/certificates", bytes.NewBufferString(tc.body))
+		app.collectorEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, tc.expectedCode, resp.Code, tc.body)
+	}
+
+	mockAgentCertificatesService.AssertNumberOfCalls(t, "Request", 3)
+}
+
+func TestApiRequestAgentCertificateHandlerAuthenticated(t *testing.T) {
+	mockAgentCredentialsService := new(services.MockAgentCredentialsService)
+	mockAgentCredentialsService.On("Authenticate", "agent_id", "some-secret").Return(nil)
+	mockAgentCredentialsService.On("Authenticate", "agent_id", "wrong-secret").Return(services.ErrInvalidAgentCredentials)
+
+	mockAgentCertificatesService := new(services.MockAgentCertificatesService)
+	mockAgentCertificatesService.On("Authenticate", "agent_id", "1f").Return(nil)
+	mockAgentCertificatesService.On("Authenticate", "revoked_agent_id", "1f").Return(services.ErrInvalidAgentCertificate)
+	mockAgentCertificatesService.On("Request", mock.Anything, "some-csr", mock.Anything).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.agentCredentialsService = mockAgentCredentialsService
+	deps.agentCertificatesService = mockAgentCertificatesService
+
+	app, err := NewAppWithDeps(setupTestCAConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		agentID               string
+		secret                string
+		commonName            string
+		expectedAuthenticated bool
+	}{
+		{"agent_id", "some-secret", "", true},
+		{"agent_id", "wrong-secret", "", false},
+		{"agent_id", "", "agent_id", true},
+		{"agent_id", "", "other_agent_id", false},
+		{"revoked_agent_id", "", "revoked_agent_id", false},
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/certificates", bytes.NewBufferString(`{"agent_id":"`+tc.agentID+`","csr":"some-csr"}`))
+		if tc.secret != "" {
+			req.SetBasicAuth(tc.agentID, tc.secret)
+		}
+		if tc.commonName != "" {
+			withClientCertificate(req, tc.commonName)
+		}
+		app.collectorEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, 202, resp.Code)
+		mockAgentCertificatesService.AssertCalled(t, "Request", tc.agentID, "some-csr", tc.expectedAuthenticated)
+	}
+}
+
+func TestApiGetAgentCertificateHandler(t *testing.T) {
+	certificate := &models.AgentCertificate{
+		AgentID:     "agent_id",
+		Status:      models.AgentCertificatePending,
+		RequestedAt: time.Date(2021, 11, 01, 10, 00, 00, 0, time.UTC),
+	}
+
+	mockAgentCertificatesService := new(services.MockAgentCertificatesService)
+	mockAgentCertificatesService.On("GetByAgentID", "agent_id").Return(certificate, nil)
+	mockAgentCertificatesService.On("GetByAgentID", "unknown").Return(nil, services.ErrAgentCertificateNotFound)
+
+	deps := setupTestDependencies()
+	deps.agentCertificatesService = mockAgentCertificatesService
+
+	app, err := NewAppWithDeps(setupTestCAConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/certificates/agent_id", nil)
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	expectedBody, _ := json.Marshal(certificate)
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, string(expectedBody), resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/certificates/unknown", nil)
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+}
+
+func TestApiAgentCertificatesHandlersDisabled(t *testing.T) {
+	deps := setupTestDependencies()
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/certificates", bytes.NewBufferString(`{"agent_id":"agent_id","csr":"some-csr"}`))
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+}
+
+func TestApiRenewAgentCertificateHandler(t *testing.T) {
+	notAfter := time.Date(2022, 02, 01, 10, 00, 00, 0, time.UTC)
+	certificate := &models.AgentCertificate{
+		AgentID:     "agent_id",
+		Status:      models.AgentCertificateIssued,
+		Certificate: "some-certificate",
+		NotAfter:    &notAfter,
+	}
+
+	mockAgentCertificatesService := new(services.MockAgentCertificatesService)
+	mockAgentCertificatesService.On("Authenticate", "agent_id", "1f").Return(nil)
+	mockAgentCertificatesService.On("Authenticate", "other_agent_id", "1f").Return(nil)
+	mockAgentCertificatesService.On("Authenticate", "revoked_agent_id", "1f").Return(services.ErrInvalidAgentCertificate)
+	mockAgentCertificatesService.On("Renew", "agent_id", "1f", "some-csr").Return(certificate, nil)
+
+	deps := setupTestDependencies()
+	deps.agentCertificatesService = mockAgentCertificatesService
+
+	app, err := NewAppWithDeps(setupTestCAConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		url          string
+		commonName   string
+		expectedCode int
+	}{
+		{"/api/certificates/agent_id/renew", "", 401},
+		{"/api/certificates/agent_id/renew", "other_agent_id", 403},
+		{"/api/certificates/agent_id/renew", "agent_id", 201},
+		{"/api/certificates/revoked_agent_id/renew", "revoked_agent_id", 401},
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", tc.url, bytes.NewBufferString(`{"csr":"some-csr"}`))
+		if tc.commonName != "" {
+			withClientCertificate(req, tc.commonName)
+		}
+		app.collectorEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, tc.expectedCode, resp.Code, tc.commonName)
+	}
+
+	mockAgentCertificatesService.AssertNumberOfCalls(t, "Renew", 1)
+}
+
+func TestClientCertificateMiddleware(t *testing.T) {
+	hostsService := new(services.MockHostsService)
+	hostsService.On("Heartbeat", "agent_id", (*models.AgentStatus)(nil), "192.0.2.1").Return(nil)
+
+	mockAgentCertificatesService := new(services.MockAgentCertificatesService)
+	mockAgentCertificatesService.On("Authenticate", "agent_id", "1f").Return(nil)
+	mockAgentCertificatesService.On("Authenticate", "other_agent_id", "1f").Return(nil)
+	mockAgentCertificatesService.On("Authenticate", "revoked_agent_id", "1f").Return(services.ErrInvalidAgentCertificate)
+	mockAgentCertificatesService.On("Authenticate", "failing_agent_id", "1f").Return(errors.New("guru meditation"))
+
+	deps := setupTestDependencies()
+	deps.hostsService = hostsService
+	deps.agentCertificatesService = mockAgentCertificatesService
+
+	app, err := NewAppWithDeps(setupTestCAConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		url          string
+		commonName   string
+		expectedCode int
+	}{
+		{"/api/hosts/agent_id/heartbeat", "", 401},
+		{"/api/hosts/agent_id/heartbeat", "other_agent_id", 403},
+		{"/api/hosts/agent_id/heartbeat", "agent_id", 204},
+		{"/api/hosts/revoked_agent_id/heartbeat", "revoked_agent_id", 401},
+		{"/api/collect", "revoked_agent_id", 401},
+		{"/api/collect/unchanged", "revoked_agent_id", 401},
+		{"/api/hosts/failing_agent_id/heartbeat", "failing_agent_id", 500},
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", tc.url, nil)
+		if tc.commonName != "" {
+			withClientCertificate(req, tc.commonName)
+		}
+		app.collectorEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, tc.expectedCode, resp.Code, tc.commonName)
+	}
+
+	hostsService.AssertNumberOfCalls(t, "Heartbeat", 1)
+}
+
+func TestApiListAgentCertificatesHandler(t *testing.T) {
+	certificates := []*models.AgentCertificate{
+		{
+			AgentID:     "agent_1",
+			Status:      models.AgentCertificatePending,
+			RequestedAt: time.Date(2021, 11, 01, 10, 00, 00, 0, time.UTC),
+		},
+	}
+
+	mockAgentCertificatesService := new(services.MockAgentCertificatesService)
+	mockAgentCertificatesService.On("GetAll").Return(certificates, nil)
+
+	deps := setupTestDependencies()
+	deps.agentCertificatesService = mockAgentCertificatesService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/certificates", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	expectedBody, _ := json.Marshal(certificates)
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, string(expectedBody), resp.Body.String())
+}
+
+func TestApiApproveAgentCertificateHandler(t *testing.T) {
+	certificate := &models.AgentCertificate{
+		AgentID:     "agent_1",
+		Status:      models.AgentCertificateIssued,
+		Certificate: "some-certificate",
+	}
+
+	mockAgentCertificatesService := new(services.MockAgentCertificatesService)
+	mockAgentCertificatesService.On("Approve", "agent_1").Return(certificate, nil)
+	mockAgentCertificatesService.On("Approve", "unknown").Return(nil, services.ErrCertificateRequestNotFound)
+
+	deps := setupTestDependencies()
+	deps.agentCertificatesService = mockAgentCertificatesService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/certificates/agent_1/approve", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	expectedBody, _ := json.Marshal(certificate)
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, string(expectedBody), resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/api/certificates/unknown/approve", nil)
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+}
+
+func TestApiRevokeAgentCertificateHandler(t *testing.T) {
+	mockAgentCertificatesService := new(services.MockAgentCertificatesService)
+	mockAgentCertificatesService.On("Revoke", "agent_1").Return(nil)
+	mockAgentCertificatesService.On("Revoke", "unknown").Return(services.ErrAgentCertificateNotFound)
+
+	deps := setupTestDependencies()
+	deps.agentCertificatesService = mockAgentCertificatesService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/certificates/agent_1/revoke", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 204, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/api/certificates/unknown/revoke", nil)
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+}
+
+func TestApiDeleteAgentCertificateHandler(t *testing.T) {
+	mockAgentCertificatesService := new(services.MockAgentCertificatesService)
+	mockAgentCertificatesService.On("Delete", "agent_1").Return(nil)
+	mockAgentCertificatesService.On("Delete", "unknown").Return(services.ErrAgentCertificateNotFound)
+
+	deps := setupTestDependencies()
+	deps.agentCertificatesService = mockAgentCertificatesService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("DELETE", "/api/certificates/agent_1", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 204, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("DELETE", "/api/certificates/unknown", nil)
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+}
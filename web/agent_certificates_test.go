@@ -0,0 +1,89 @@
+package web
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestAgentCertificatesHandler(t *testing.T) {
+	expiring := time.Now().Add(48 * time.Hour)
+	valid := time.Now().Add(60 * 24 * time.Hour)
+	revoked := time.Now().Add(-time.Hour)
+	certificates := []*models.AgentCertificate{
+		{
+			AgentID:     "pending_agent",
+			Status:      models.AgentCertificatePending,
+			RequestedAt: time.Now(),
+		},
+		{
+			AgentID:     "expiring_agent",
+			Status:      models.AgentCertificateIssued,
+			RequestedAt: time.Now(),
+			NotAfter:    &expiring,
+		},
+		{
+			AgentID:     "valid_agent",
+			Status:      models.AgentCertificateIssued,
+			RequestedAt: time.Now(),
+			NotAfter:    &valid,
+		},
+		{
+			AgentID:     "revoked_agent",
+			Status:      models.AgentCertificateRevoked,
+			RequestedAt: time.Now(),
+			NotAfter:    &expiring,
+			RevokedAt:   &revoked,
+		},
+	}
+
+	mockAgentCertificatesService := new(services.MockAgentCertificatesService)
+	mockAgentCertificatesService.On("GetAll").Return(certificates, nil)
+
+	deps := setupTestDependencies()
+	deps.agentCertificatesService = mockAgentCertificatesService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/certificates", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	body := resp.Body.String()
+	assert.Equal(t, 200, resp.Code)
+	assert.Contains(t, body, "1 agent certificate(s) expired or expiring within 14 days")
+	assert.Contains(t, body, "pending approval")
+	assert.Contains(t, body, "expiring soon")
+	assert.Contains(t, body, "revoked")
+	assert.Contains(t, body, "/certificates/pending_agent/approve")
+	assert.NotContains(t, body, "/certificates/valid_agent/approve")
+}
+
+func TestApproveAgentCertificateHandler(t *testing.T) {
+	mockAgentCertificatesService := new(services.MockAgentCertificatesService)
+	mockAgentCertificatesService.On("Approve", "agent_1").Return(&models.AgentCertificate{}, nil)
+
+	deps := setupTestDependencies()
+	deps.agentCertificatesService = mockAgentCertificatesService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/certificates/agent_1/approve", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 302, resp.Code)
+	assert.Equal(t, "/certificates", resp.Header().Get("Location"))
+	mockAgentCertificatesService.AssertExpectations(t)
+}
@@ -0,0 +1,127 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+// ApiCreateEnrollmentTokenHandler godoc
+// @Summary Create a one-time token to enroll an agent
+// @Produce json
+// @Success 201 {object} models.EnrollmentToken
+// @Failure 500 {object} map[string]string
+// @Router /enrollment-tokens [post]
+func ApiCreateEnrollmentTokenHandler(agentCredentialsService services.AgentCredentialsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, err := agentCredentialsService.CreateEnrollmentToken()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, token)
+	}
+}
+
+// ApiListAgentCredentialsHandler godoc
+// @Summary List the enrolled agents
+// @Produce json
+// @Success 200 {object} []models.AgentCredentials
+// @Failure 500 {object} map[string]string
+// @Router /agents [get]
+func ApiListAgentCredentialsHandler(agentCredentialsService services.AgentCredentialsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		credentials, err := agentCredentialsService.GetAll()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if credentials == nil {
+			c.JSON(http.StatusOK, []*models.AgentCredentials{})
+			return
+		}
+
+		c.JSON(http.StatusOK, credentials)
+	}
+}
+
+// ApiRevokeAgentCredentialsHandler godoc
+// @Summary Revoke the credentials of an agent, rejecting its data. The credentials must be deleted for the agent to enroll again
+// @Produce json
+// @Param id path string true "Agent id"
+// @Success 204 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /agents/{id}/credentials [delete]
+func ApiRevokeAgentCredentialsHandler(agentCredentialsService services.AgentCredentialsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		err := agentCredentialsService.Revoke(id)
+		if errors.Is(err, services.ErrAgentNotEnrolled) {
+			_ = c.Error(NotFoundError("could not find enrolled agent"))
+			return
+		}
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusNoContent, nil)
+	}
+}
+
+// ApiDeleteAgentCredentialsHandler godoc
+// @Summary Delete the credentials of an agent, either active or revoked, so that it can enroll again
+// @Produce json
+// @Param id path string true "Agent id"
+// @Success 204 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /agents/{id} [delete]
+func ApiDeleteAgentCredentialsHandler(agentCredentialsService services.AgentCredentialsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		err := agentCredentialsService.Delete(id)
+		if errors.Is(err, services.ErrAgentNotEnrolled) {
+			_ = c.Error(NotFoundError("could not find enrolled agent"))
+			return
+		}
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusNoContent, nil)
+	}
+}
+
+// ApiListAgentIdentityConflictsHandler godoc
+// @Summary List the agent ids recently reported in turns by several hosts, usually cloned without regenerating their machine id
+// @Produce json
+// @Success 200 {object} []models.AgentIdentityConflict
+// @Failure 500 {object} map[string]string
+// @Router /identity-conflicts [get]
+func ApiListAgentIdentityConflictsHandler(hostsService services.HostsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		conflicts, err := hostsService.GetIdentityConflicts()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if conflicts == nil {
+			c.JSON(http.StatusOK, []*models.AgentIdentityConflict{})
+			return
+		}
+
+		c.JSON(http.StatusOK, conflicts)
+	}
+}
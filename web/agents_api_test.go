@@ -0,0 +1,127 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestApiCreateEnrollmentTokenHandler(t *testing.T) {
+	token := &models.EnrollmentToken{
+		Token:     "some-token",
+		ExpiresAt: time.Date(2021, 11, 02, 10, 00, 00, 0, time.UTC),
+	}
+
+	mockAgentCredentialsService := new(services.MockAgentCredentialsService)
+	mockAgentCredentialsService.On("CreateEnrollmentToken").Return(token, nil)
+
+	deps := setupTestDependencies()
+	deps.agentCredentialsService = mockAgentCredentialsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/enrollment-tokens", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	expectedBody, _ := json.Marshal(token)
+	assert.Equal(t, 201, resp.Code)
+	assert.JSONEq(t, string(expectedBody), resp.Body.String())
+}
+
+func TestApiListAgentCredentialsHandler(t *testing.T) {
+	revokedAt := time.Date(2021, 11, 02, 10, 00, 00, 0, time.UTC)
+	credentials := []*models.AgentCredentials{
+		{
+			AgentID:    "agent_1",
+			EnrolledAt: time.Date(2021, 11, 01, 10, 00, 00, 0, time.UTC),
+		},
+		{
+			AgentID:    "agent_2",
+			EnrolledAt: time.Date(2021, 11, 01, 10, 00, 00, 0, time.UTC),
+			RevokedAt:  &revokedAt,
+		},
+	}
+
+	mockAgentCredentialsService := new(services.MockAgentCredentialsService)
+	mockAgentCredentialsService.On("GetAll").Return(credentials, nil)
+
+	deps := setupTestDependencies()
+	deps.agentCredentialsService = mockAgentCredentialsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/agents", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	expectedBody, _ := json.Marshal(credentials)
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, string(expectedBody), resp.Body.String())
+}
+
+func TestApiRevokeAgentCredentialsHandler(t *testing.T) {
+	mockAgentCredentialsService := new(services.MockAgentCredentialsService)
+	mockAgentCredentialsService.On("Revoke", "agent_1").Return(nil)
+	mockAgentCredentialsService.On("Revoke", "unknown").Return(services.ErrAgentNotEnrolled)
+
+	deps := setupTestDependencies()
+	deps.agentCredentialsService = mockAgentCredentialsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("DELETE", "/api/agents/agent_1/credentials", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 204, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("DELETE", "/api/agents/unknown/credentials", nil)
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+}
+
+func TestApiDeleteAgentCredentialsHandler(t *testing.T) {
+	mockAgentCredentialsService := new(services.MockAgentCredentialsService)
+	mockAgentCredentialsService.On("Delete", "agent_1").Return(nil)
+	mockAgentCredentialsService.On("Delete", "unknown").Return(services.ErrAgentNotEnrolled)
+
+	deps := setupTestDependencies()
+	deps.agentCredentialsService = mockAgentCredentialsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("DELETE", "/api/agents/agent_1", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 204, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("DELETE", "/api/agents/unknown", nil)
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+}
@@ -21,6 +21,7 @@ import (
 
 	trentoDB "github.com/trento-project/trento/internal/db"
 	"github.com/trento-project/trento/internal/grafana"
+	"github.com/trento-project/trento/internal/pki"
 	trentoPrometheus "github.com/trento-project/trento/internal/prometheus"
 	"github.com/trento-project/trento/version"
 	"github.com/trento-project/trento/web/datapipeline"
@@ -45,7 +46,8 @@ var DBTables = []interface{}{
 	&entities.Check{}, &datapipeline.DataCollectedEvent{}, &datapipeline.Subscription{},
 	&entities.HostTelemetry{}, &entities.Cluster{}, &entities.Host{}, &entities.HostHeartbeat{},
 	&entities.SlesSubscription{}, &entities.SAPSystemInstance{}, &entities.ChecksResult{},
-	&entities.HealthState{},
+	&entities.HealthState{}, &entities.EnrollmentToken{}, &entities.AgentCredentials{},
+	&entities.AgentCertificate{}, &entities.AgentSighting{}, &entities.HostPackage{},
 }
 
 type App struct {
@@ -65,26 +67,35 @@ type Config struct {
 	DBConfig      *trentoDB.Config
 	GrafanaConfig *grafana.Config
 	PrometheusURL string
+	// EnableAgentAuth requires the agents to authenticate their requests with the credentials got on enrollment
+	EnableAgentAuth bool
+	// CAKey is the private key of the CA, enabling the internal CA which signs the agent client certificates
+	CAKey string
+	// AgentCertValidity is how long the agent certificates issued by the internal CA are valid
+	AgentCertValidity time.Duration
 }
 
 type Dependencies struct {
-	webEngine               *gin.Engine
-	collectorEngine         *gin.Engine
-	store                   cookie.Store
-	projectorWorkersPool    *datapipeline.ProjectorsWorkerPool
-	checksService           services.ChecksService
-	subscriptionsService    services.SubscriptionsService
-	tagsService             services.TagsService
-	collectorService        services.CollectorService
-	sapSystemsService       services.SAPSystemsService
-	clustersService         services.ClustersService
-	hostsService            services.HostsService
-	settingsService         services.SettingsService
-	healthSummaryService    services.HealthSummaryService
-	telemetryRegistry       *telemetry.TelemetryRegistry
-	telemetryPublisher      telemetry.Publisher
-	premiumDetectionService services.PremiumDetectionService
-	prometheusService       services.PrometheusService
+	webEngine                *gin.Engine
+	collectorEngine          *gin.Engine
+	store                    cookie.Store
+	projectorWorkersPool     *datapipeline.ProjectorsWorkerPool
+	checksService            services.ChecksService
+	subscriptionsService     services.SubscriptionsService
+	tagsService              services.TagsService
+	collectorService         services.CollectorService
+	sapSystemsService        services.SAPSystemsService
+	clustersService          services.ClustersService
+	hostsService             services.HostsService
+	settingsService          services.SettingsService
+	healthSummaryService     services.HealthSummaryService
+	telemetryRegistry        *telemetry.TelemetryRegistry
+	telemetryPublisher       telemetry.Publisher
+	premiumDetectionService  services.PremiumDetectionService
+	prometheusService        services.PrometheusService
+	agentCredentialsService  services.AgentCredentialsService
+	agentCertificatesService services.AgentCertificatesService
+	packagesService          services.PackagesService
 }
 
 func DefaultDependencies(ctx context.Context, config *Config) Dependencies {
@@ -129,12 +140,24 @@ func DefaultDependencies(ctx context.Context, config *Config) Dependencies {
 	telemetryRegistry := telemetry.NewTelemetryRegistry(db)
 	telemetryPublisher := telemetry.NewTelemetryPublisher()
 	healthSummaryService := services.NewHealthSummaryService(sapSystemsService, clustersService, hostsService)
+	agentCredentialsService := services.NewAgentCredentialsService(db)
+
+	var ca *pki.CertificateAuthority
+	if config.CAKey != "" {
+		ca, err = pki.LoadCertificateAuthority(config.CA, config.CAKey)
+		if err != nil {
+			log.Fatalf("failed loading the internal CA: %s", err)
+		}
+	}
+	agentCertificatesService := services.NewAgentCertificatesService(db, ca, config.AgentCertValidity)
+	packagesService := services.NewPackagesService(db)
 
 	return Dependencies{
 		webEngine, collectorEngine, store, projectorWorkersPool,
 		checksService, subscriptionsService, tagsService,
 		collectorService, sapSystemsService, clustersService, hostsService, settingsService, healthSummaryService,
-		telemetryRegistry, telemetryPublisher, premiumDetection, prometheusService,
+		telemetryRegistry, telemetryPublisher, premiumDetection, prometheusService, agentCredentialsService,
+		agentCertificatesService, packagesService,
 	}
 }
 
@@ -191,9 +214,13 @@ func NewAppWithDeps(config *Config, deps Dependencies) (*App, error) {
 	webEngine.GET("/clusters", NewClusterListHandler(deps.clustersService))
 	webEngine.GET("/clusters/:id", NewClusterHandler(deps.clustersService))
 	webEngine.GET("/sapsystems", NewSAPSystemListHandler(deps.sapSystemsService))
+	webEngine.GET("/sapsystems/versions", NewSAPVersionListHandler(deps.sapSystemsService))
 	webEngine.GET("/sapsystems/:id", NewSAPResourceHandler(deps.hostsService, deps.sapSystemsService))
 	webEngine.GET("/databases", NewHANADatabaseListHandler(deps.sapSystemsService))
 	webEngine.GET("/databases/:id", NewSAPResourceHandler(deps.hostsService, deps.sapSystemsService))
+	webEngine.GET("/packages", NewPackageListHandler(deps.packagesService, deps.clustersService))
+	webEngine.GET("/certificates", NewAgentCertificatesHandler(deps.agentCertificatesService))
+	webEngine.POST("/certificates/:id/approve", NewApproveAgentCertificateHandler(deps.agentCertificatesService))
 
 	apiGroup := webEngine.Group("/api")
 	{
@@ -209,6 +236,7 @@ func NewAppWithDeps(config *Config, deps Dependencies) (*App, error) {
 		apiGroup.POST("/sapsystems/:id/tags", ApiSAPSystemCreateTagHandler(deps.sapSystemsService, deps.tagsService))
 		apiGroup.DELETE("/sapsystems/:id/tags/:tag", ApiSAPSystemDeleteTagHandler(deps.sapSystemsService, deps.tagsService))
 		apiGroup.GET("/sapsystems/health", ApiSAPSystemsHealthSummaryHandler(deps.healthSummaryService))
+		apiGroup.GET("/sapsystems/versions", ApiListSAPInstanceVersionsHandler(deps.sapSystemsService))
 		apiGroup.POST("/databases/:id/tags", ApiDatabaseCreateTagHandler(deps.sapSystemsService, deps.tagsService))
 		apiGroup.DELETE("/databases/:id/tags/:tag", ApiDatabaseDeleteTagHandler(deps.sapSystemsService, deps.tagsService))
 		apiGroup.GET("/checks/:id/settings", ApiCheckGetSettingsByIdHandler(deps.clustersService))
@@ -216,14 +244,43 @@ func NewAppWithDeps(config *Config, deps Dependencies) (*App, error) {
 		apiGroup.PUT("/checks/catalog", ApiCreateChecksCatalogHandler(deps.checksService))
 		apiGroup.GET("/checks/catalog", ApiChecksCatalogHandler(deps.checksService))
 		apiGroup.POST("/checks/:id/results", ApiCreateChecksResultHandler(deps.checksService))
+		apiGroup.GET("/packages", ApiListPackagesHandler(deps.packagesService))
 		apiGroup.GET("/prometheus/targets", ApiGetPrometheusHttpSdTargets(deps.prometheusService))
+		apiGroup.POST("/enrollment-tokens", ApiCreateEnrollmentTokenHandler(deps.agentCredentialsService))
+		apiGroup.GET("/agents", ApiListAgentCredentialsHandler(deps.agentCredentialsService))
+		apiGroup.DELETE("/agents/:id", ApiDeleteAgentCredentialsHandler(deps.agentCredentialsService))
+		apiGroup.DELETE("/agents/:id/credentials", ApiRevokeAgentCredentialsHandler(deps.agentCredentialsService))
+		apiGroup.GET("/identity-conflicts", ApiListAgentIdentityConflictsHandler(deps.hostsService))
+		apiGroup.GET("/certificates", ApiListAgentCertificatesHandler(deps.agentCertificatesService))
+		apiGroup.POST("/certificates/:id/approve", ApiApproveAgentCertificateHandler(deps.agentCertificatesService))
+		apiGroup.POST("/certificates/:id/revoke", ApiRevokeAgentCertificateHandler(deps.agentCertificatesService))
+		apiGroup.DELETE("/certificates/:id", ApiDeleteAgentCertificateHandler(deps.agentCertificatesService))
 	}
 
 	collectorEngine := deps.collectorEngine
-	collectorEngine.POST("/api/collect", ApiCollectDataHandler(deps.collectorService))
-	collectorEngine.POST("/api/hosts/:id/heartbeat", ApiHostHeartbeatHandler(deps.hostsService))
+	collectorEngine.Use(DecompressRequestMiddleware())
+	collectorEngine.POST("/api/enroll", ApiEnrollAgentHandler(deps.agentCredentialsService))
 	collectorEngine.GET("/api/ping", ApiPingHandler)
 
+	if config.CAKey != "" {
+		collectorEngine.POST("/api/certificates", ApiRequestAgentCertificateHandler(deps.agentCertificatesService, deps.agentCredentialsService))
+		collectorEngine.GET("/api/certificates/:id", ApiGetAgentCertificateHandler(deps.agentCertificatesService))
+		collectorEngine.POST("/api/certificates/:id/renew", ClientCertificateMiddleware(deps.agentCertificatesService), ApiRenewAgentCertificateHandler(deps.agentCertificatesService))
+	}
+
+	agentGroup := collectorEngine.Group("/api")
+	{
+		if config.EnableAgentAuth {
+			agentGroup.Use(AgentAuthMiddleware(deps.agentCredentialsService))
+		}
+		if config.CAKey != "" {
+			agentGroup.Use(ClientCertificateMiddleware(deps.agentCertificatesService))
+		}
+		agentGroup.POST("/collect", ApiCollectDataHandler(deps.collectorService))
+		agentGroup.POST("/collect/unchanged", ApiCollectUnchangedDataHandler(deps.collectorService))
+		agentGroup.POST("/hosts/:id/heartbeat", ApiHostHeartbeatHandler(deps.hostsService))
+	}
+
 	return app, nil
 }
 
@@ -244,6 +301,12 @@ func (a *App) Start(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
+
+		// Agents without a certificate yet must reach the collector to request one,
+		// the agent endpoints require it through ClientCertificateMiddleware
+		if a.config.CAKey != "" {
+			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		}
 	}
 
 	collectorServer := &http.Server{
@@ -391,6 +391,17 @@ func TestClusterHandlerHANA(t *testing.T) {
 					Role:      "Started",
 					Status:    "failed",
 					FailCount: 0,
+					Reasons:   []string{"start failed on test_node_2: not installed (command not found)"},
+				},
+			},
+			FailedActions: []*models.ClusterFailedAction{
+				{
+					ResourceID:   "dummy_failed",
+					Task:         "start",
+					Node:         "test_node_2",
+					ExitStatus:   "not installed",
+					ExitReason:   "command not found",
+					LastRCChange: "Wed Jun 30 18:11:37 2021",
 				},
 			},
 			Nodes: []*models.HANAClusterNode{
@@ -426,6 +437,32 @@ func TestClusterHandlerHANA(t *testing.T) {
 					Health:      models.HostHealthCritical,
 				},
 			},
+			Corosync: &models.ClusterCorosync{
+				Transport:      "udpu",
+				Token:          30000,
+				Consensus:      36000,
+				QuorumProvider: "corosync_votequorum",
+				TwoNode:        true,
+				ExpectedVotes:  2,
+				TotalVotes:     1,
+				Quorum:         1,
+				Quorate:        true,
+				Rings:          []int{0, 1},
+				Nodes: []*models.ClusterCorosyncNode{
+					{
+						NodeID:        1,
+						Name:          "test_node_1",
+						RingAddresses: []string{"10.0.0.1", "10.0.1.1"},
+						Votes:         1,
+						Member:        true,
+					},
+					{
+						NodeID:        2,
+						Name:          "test_node_2",
+						RingAddresses: []string{"10.0.0.2"},
+					},
+				},
+			},
 		},
 	}, nil)
 
@@ -478,4 +515,221 @@ func TestClusterHandlerHANA(t *testing.T) {
 	assert.Regexp(t, regexp.MustCompile("<td>sbd</td><td>stonith:external/sbd</td><td>Started</td><td>active</td><td>0</td>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<td>dummy_failed</td><td>dummy</td><td>Started</td><td>failed</td><td>0</td>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<h4>Stopped resources</h4><div.*><div.*><span .*>dummy_failed</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<span .*>dummy_failed</span><ul .*><li>start failed on test_node_2: not installed \\(command not found\\)</li></ul>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<h4>Failed actions</h4>.*<td.*>dummy_failed</td><td.*>start</td><td.*>test_node_2</td><td.*><span .*>not installed</span></td><td.*>command not found</td><td.*>Wed Jun 30 18:11:37 2021</td>"), minified)
+	// Corosync
+	assert.Regexp(t, regexp.MustCompile("<strong>Quorum:</strong><br><span .*>Quorate</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<strong>Votes:</strong><br><span.*>1 of 2 expected, 1 needed</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<th scope=col>Ring 0</th><th scope=col>Ring 1</th>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<td.*>1</td><td.*>test_node_1</td><td>10\\.0\\.0\\.1</td><td>10\\.0\\.1\\.1</td><td.*>1</td><td.*><span .*>Member</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<td.*>2</td><td.*>test_node_2</td><td>10\\.0\\.0\\.2</td><td></td><td.*>0</td><td.*><span .*>Not member</span>"), minified)
+}
+
+func TestClusterHandlerHANAScaleOut(t *testing.T) {
+	clusterID := "a615a35f65627be5a757319a0741127f"
+
+	clustersService := new(services.MockClustersService)
+	clustersService.On("GetByID", clusterID).Return(&models.Cluster{
+		ID:          clusterID,
+		Name:        "hana_scale_out_cluster",
+		ClusterType: models.ClusterTypeHANAScaleOut,
+		SID:         "PRD",
+		Health:      models.CheckPassing,
+		Details: &models.HANAClusterDetails{
+			SystemReplicationMode:          "sync",
+			SystemReplicationOperationMode: "logreplay",
+			SecondarySyncState:             "SOK",
+			SRHealthState:                  "4",
+			PrimarySite:                    "Site1",
+			SecondarySite:                  "Site2",
+			FencingType:                    "external/sbd",
+			CIBLastWritten:                 time.Date(2021, time.June, 30, 18, 11, 37, 0, time.UTC),
+			Nodes: []*models.HANAClusterNode{
+				{
+					HostID:          "host1",
+					Name:            "vmhana01",
+					Site:            "Site1",
+					IPAddresses:     []string{"192.168.1.1"},
+					HANAStatus:      models.HANAStatusPrimary,
+					NameServerRole:  "master",
+					IndexServerRole: "master",
+					Health:          models.HostHealthPassing,
+				},
+				{
+					HostID:          "host2",
+					Name:            "vmhana02",
+					Site:            "Site2",
+					IPAddresses:     []string{"192.168.1.2"},
+					HANAStatus:      models.HANAStatusSecondary,
+					NameServerRole:  "slave",
+					IndexServerRole: "slave",
+					Health:          models.HostHealthPassing,
+				},
+				{
+					HostID:        "host3",
+					Name:          "vmhanamm",
+					IPAddresses:   []string{"192.168.1.3"},
+					HANAStatus:    models.HANAStatusUnknown,
+					MajorityMaker: true,
+					Health:        models.HostHealthPassing,
+				},
+			},
+		},
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.clustersService = clustersService
+
+	config := setupTestConfig()
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/clusters/"+clusterID, nil)
+	req.Header.Set("Accept", "text/html")
+
+	app.webEngine.ServeHTTP(resp, req)
+
+	clustersService.AssertExpectations(t)
+
+	m := minify.New()
+	m.AddFunc("text/html", html.Minify)
+	m.Add("text/html", &html.Minifier{
+		KeepDefaultAttrVals: true,
+		KeepEndTags:         true,
+	})
+	minified, err := m.String("text/html", resp.Body.String())
+	assert.NoError(t, err)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Regexp(t, regexp.MustCompile("<strong>Cluster type:</strong><br><span.*>HANA scale-out</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<strong>Primary site:</strong><br><span.*>Site1</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<strong>Secondary site:</strong><br><span.*>Site2</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<a.*href=/hosts/host1.*>vmhana01</a></td><td.*>192\\.168\\.1\\.1</td><td.*></td><td.*>master</td><td.*>master</td><td.*><span .*>HANA Primary</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<a.*href=/hosts/host2.*>vmhana02</a></td><td.*>192\\.168\\.1\\.2</td><td.*></td><td.*>slave</td><td.*>slave</td><td.*><span .*>HANA Secondary</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<h4>Majority maker</h4><div.*><div.*><a href=/hosts/host3>vmhanamm</a>"), minified)
+	assert.NotRegexp(t, regexp.MustCompile("<td.*><a.*href=/hosts/host3.*>vmhanamm</a></td>"), minified)
+}
+
+func TestClusterHandlerASCSERS(t *testing.T) {
+	clusterID := "6b972d4ffd0a1b2f34a5e7cbf5e6e2b8"
+
+	clustersService := new(services.MockClustersService)
+	clustersService.On("GetByID", clusterID).Return(&models.Cluster{
+		ID:          clusterID,
+		Name:        "netweaver_cluster",
+		ClusterType: models.ClusterTypeASCSERS,
+		SID:         "NWP",
+		Health:      models.CheckPassing,
+		Details: &models.ASCSERSClusterDetails{
+			EnsaVersion:    models.EnsaVersion1,
+			FencingType:    "external/sbd",
+			CIBLastWritten: time.Date(2022, time.January, 11, 13, 43, 6, 0, time.UTC),
+			Nodes: []*models.HANAClusterNode{
+				{
+					HostID:      "host1",
+					Name:        "vmnwp01",
+					IPAddresses: []string{"192.168.1.1"},
+					VirtualIPs:  []string{"10.80.1.25"},
+					Health:      models.HostHealthPassing,
+				},
+				{
+					HostID:      "host2",
+					Name:        "vmnwp02",
+					IPAddresses: []string{"192.168.1.2"},
+					VirtualIPs:  []string{"10.80.1.26"},
+					Health:      models.HostHealthPassing,
+				},
+			},
+			Instances: []*models.ASCSERSInstance{
+				{
+					Type:            models.ASCSERSInstanceTypeASCS,
+					SID:             "NWP",
+					InstanceNumber:  "00",
+					VirtualHostname: "sapnwpas",
+					Group:           "grp_NWP_ASCS00",
+					Node:            "vmnwp01",
+					VirtualIPs:      []string{"10.80.1.25"},
+					Filesystems: []*models.ClusterFilesystem{
+						{
+							ResourceID: "rsc_fs_NWP_ASCS00",
+							Device:     "/dev/disk/by-label/NWPASCS00",
+							Directory:  "/usr/sap/NWP/ASCS00",
+							FSType:     "xfs",
+						},
+					},
+					Halib:                 "$(DIR_CT_RUN)/saphascriptco.so",
+					HalibClusterConnector: "/usr/bin/sap_suse_cluster_connector",
+					SAPSystemID:           "nwp_system_id",
+					HostID:                "host1",
+					HAConfig: &models.SAPInstanceHAConfig{
+						Active:              true,
+						SAPInterfaceVersion: "sap_suse_cluster_connector 3.1.2",
+						Checks: []*models.SAPInstanceHACheck{
+							{
+								State:       "SAPControl-HA-SUCCESS",
+								Category:    "SAPControl-SAP-CONFIGURATION",
+								Description: "Redundant ABAP instance configuration",
+								Comment:     "2 ABAP instances detected",
+							},
+						},
+						FailoverChecks: []*models.SAPInstanceHACheck{
+							{
+								State:       "SAPControl-HA-ERROR",
+								Category:    "SAPControl-HA-STATE",
+								Description: "HA software",
+								Comment:     "The SAP instance is not running on the active node",
+							},
+						},
+					},
+				},
+				{
+					Type:            models.ASCSERSInstanceTypeERS,
+					SID:             "NWP",
+					InstanceNumber:  "10",
+					VirtualHostname: "sapnwper",
+					Group:           "grp_NWP_ERS10",
+					VirtualIPs:      []string{"10.80.1.26"},
+				},
+			},
+		},
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.clustersService = clustersService
+
+	config := setupTestConfig()
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/clusters/"+clusterID, nil)
+	req.Header.Set("Accept", "text/html")
+
+	app.webEngine.ServeHTTP(resp, req)
+
+	clustersService.AssertExpectations(t)
+
+	m := minify.New()
+	m.AddFunc("text/html", html.Minify)
+	m.Add("text/html", &html.Minifier{
+		KeepDefaultAttrVals: true,
+		KeepEndTags:         true,
+	})
+	minified, err := m.String("text/html", resp.Body.String())
+	assert.NoError(t, err)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Regexp(t, regexp.MustCompile("<strong>Cluster type:</strong><br><span.*>ASCS/ERS</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<strong>Enqueue server:</strong><br><span.*>ENSA1</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<td.*>ASCS 00</td><td.*><a href=/sapsystems/nwp_system_id>NWP</a></td><td.*><a href=/hosts/host1>vmnwp01</a></td><td.*>sapnwpas</td><td.*>10\\.80\\.1\\.25</td><td.*>grp_NWP_ASCS00</td><td.*><span .*>Configured</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<td.*>ERS 10</td><td.*>NWP</td><td.*><span .*>Stopped</span></td><td.*>sapnwper</td><td.*>10\\.80\\.1\\.26</td><td.*>grp_NWP_ERS10</td><td.*><span .*>Not configured</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<h4>SAP HA interface checks</h4>.*<td.*><i .*text-success.*>check_circle</i></td><td.*>ASCS 00</td><td.*>SAP configuration</td><td.*>Redundant ABAP instance configuration</td><td.*>2 ABAP instances detected</td><td.*><span .*>Active</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<td.*><i .*text-danger.*>error</i></td><td.*>ASCS 00</td><td.*>HA state</td><td.*>HA software</td><td.*>The SAP instance is not running on the active node</td>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<td.*>ASCS 00</td><td.*>rsc_fs_NWP_ASCS00</td><td.*>/dev/disk/by-label/NWPASCS00</td><td.*>/usr/sap/NWP/ASCS00</td><td.*>xfs</td>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<a.*href=/hosts/host2.*>vmnwp02</a></td><td.*>192\\.168\\.1\\.2</td><td.*>10\\.80\\.1\\.26</td>"), minified)
 }
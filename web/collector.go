@@ -1,17 +1,99 @@
 package web
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	log "github.com/sirupsen/logrus"
 	"github.com/trento-project/trento/web/datapipeline"
 	"github.com/trento-project/trento/web/services"
 )
 
-// ApiCollectDataHandler handles the request to collect agent data from the API
+var errBatchAgentIDRequired = errors.New("the agent_id of the batch is required")
+
+// ApiCollectDataHandler handles the request to collect agent data from the API.
+// The request either holds a single event or a batch of them
 func ApiCollectDataHandler(collectorService services.CollectorService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var e datapipeline.DataCollectedEvent
+		body, err := c.GetRawData()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		// Agents not batching their data send a single event
+		var batch datapipeline.DataCollectedBatch
+		if err := json.Unmarshal(body, &batch); err != nil || batch.Batch == nil {
+			var e datapipeline.DataCollectedEvent
+
+			err := binding.JSON.BindBody(body, &e)
+			if err != nil {
+				_ = c.AbortWithError(http.StatusBadRequest, err).SetType(gin.ErrorTypeBind)
+				return
+			}
+
+			if !isAuthenticatedAgent(c, e.AgentID) {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+
+			err = collectorService.StoreEvent(&e)
+			if err != nil {
+				_ = c.Error(err)
+				return
+			}
+
+			c.Writer.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		if batch.AgentID == "" {
+			_ = c.AbortWithError(http.StatusBadRequest, errBatchAgentIDRequired).SetType(gin.ErrorTypeBind)
+			return
+		}
+
+		if !isAuthenticatedAgent(c, batch.AgentID) {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		events := make([]*datapipeline.DataCollectedEvent, 0, len(batch.Batch))
+		for _, rawEvent := range batch.Batch {
+			var e datapipeline.DataCollectedEvent
+
+			err := binding.JSON.BindBody(rawEvent, &e)
+			if err != nil {
+				_ = c.AbortWithError(http.StatusBadRequest, err).SetType(gin.ErrorTypeBind)
+				return
+			}
+
+			// An agent can only batch its own data
+			if e.AgentID != batch.AgentID {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+
+			events = append(events, &e)
+		}
+
+		err = collectorService.StoreEvents(events)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.Writer.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// ApiCollectUnchangedDataHandler handles the notification of unchanged agent data.
+// It answers with a conflict if the agent has to send the full payload again
+func ApiCollectUnchangedDataHandler(collectorService services.CollectorService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var e datapipeline.DataUnchangedEvent
 
 		err := c.BindJSON(&e)
 		if err != nil {
@@ -19,7 +101,16 @@ func ApiCollectDataHandler(collectorService services.CollectorService) gin.Handl
 			return
 		}
 
-		err = collectorService.StoreEvent(&e)
+		if !isAuthenticatedAgent(c, e.AgentID) {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		err = collectorService.ConfirmUnchanged(&e)
+		if errors.Is(err, services.ErrResyncRequired) {
+			c.Writer.WriteHeader(http.StatusConflict)
+			return
+		}
 		if err != nil {
 			_ = c.Error(err)
 			return
@@ -28,3 +119,44 @@ func ApiCollectDataHandler(collectorService services.CollectorService) gin.Handl
 		c.Writer.WriteHeader(http.StatusAccepted)
 	}
 }
+
+type JSONEnrollmentRequest struct {
+	AgentID string `json:"agent_id" binding:"required"`
+	Token   string `json:"token" binding:"required"`
+}
+
+type JSONEnrollmentResponse struct {
+	Secret string `json:"secret"`
+}
+
+// ApiEnrollAgentHandler exchanges an enrollment token for the credentials of an agent
+func ApiEnrollAgentHandler(agentCredentialsService services.AgentCredentialsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var r JSONEnrollmentRequest
+
+		err := c.BindJSON(&r)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		secret, err := agentCredentialsService.Enroll(r.Token, r.AgentID)
+		if errors.Is(err, services.ErrInvalidEnrollmentToken) {
+			log.Warnf("Rejected enrollment of agent %s: %s", r.AgentID, err)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		if errors.Is(err, services.ErrAgentAlreadyEnrolled) {
+			log.Warnf("Rejected enrollment of agent %s: %s", r.AgentID, err)
+			c.AbortWithStatus(http.StatusConflict)
+			return
+		}
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		log.Infof("Agent %s enrolled", r.AgentID)
+		c.JSON(http.StatusCreated, &JSONEnrollmentResponse{Secret: secret})
+	}
+}
@@ -2,13 +2,16 @@ package web
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
+	"io"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/models"
 	"github.com/trento-project/trento/web/services"
 )
 
@@ -37,3 +40,303 @@ func TestApiCollectDataHandler(t *testing.T) {
 
 	assert.Equal(t, 202, resp.Code)
 }
+
+func TestApiCollectDataHandlerCompressed(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", &datapipeline.DataCollectedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: "discovery",
+		Payload:       []byte(`{"some":"data"}`),
+	}).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+
+	config := setupTestConfig()
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var body bytes.Buffer
+	writer := gzip.NewWriter(&body)
+	writer.Write([]byte(`{"agent_id":"agent_id","discovery_type":"discovery","payload":{"some":"data"}}`))
+	writer.Close()
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/collect", &body)
+	req.Header.Set("Content-Encoding", "gzip")
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 202, resp.Code)
+	collectorService.AssertExpectations(t)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/api/collect", bytes.NewBufferString("not gzip"))
+	req.Header.Set("Content-Encoding", "gzip")
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 400, resp.Code)
+}
+
+func TestApiCollectDataHandlerBatch(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvents", []*datapipeline.DataCollectedEvent{
+		{
+			AgentID:       "agent_id",
+			DiscoveryType: "host_discovery",
+			Payload:       []byte(`{"host":"data"}`),
+		},
+		{
+			AgentID:       "agent_id",
+			DiscoveryType: "cloud_discovery",
+			Payload:       []byte(`{"cloud":"data"}`),
+		},
+	}).Return(nil).Once()
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+
+	config := setupTestConfig()
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/collect", bytes.NewBufferString(`{"agent_id":"agent_id","batch":[
+		{"agent_id":"agent_id","discovery_type":"host_discovery","payload":{"host":"data"}},
+		{"agent_id":"agent_id","discovery_type":"cloud_discovery","payload":{"cloud":"data"}}
+	]}`))
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 202, resp.Code)
+	collectorService.AssertExpectations(t)
+
+	for _, tc := range []struct {
+		body         string
+		expectedCode int
+	}{
+		{`{"agent_id":"agent_id","batch":[
+			{"agent_id":"agent_id","discovery_type":"host_discovery","payload":{"host":"data"}},
+			{"agent_id":"agent_id","payload":{"cloud":"data"}}
+		]}`, 400},
+		{`{"batch":[
+			{"agent_id":"agent_id","discovery_type":"host_discovery","payload":{"host":"data"}}
+		]}`, 400},
+		{`{"agent_id":"agent_id","batch":[
+			{"agent_id":"agent_id","discovery_type":"host_discovery","payload":{"host":"data"}},
+			{"agent_id":"other_agent_id","discovery_type":"cloud_discovery","payload":{"cloud":"data"}}
+		]}`, 403},
+	} {
+		resp = httptest.NewRecorder()
+		req = httptest.NewRequest("POST", "/api/collect", bytes.NewBufferString(tc.body))
+
+		app.collectorEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, tc.expectedCode, resp.Code)
+	}
+
+	collectorService.AssertNumberOfCalls(t, "StoreEvents", 1)
+}
+
+func TestApiCollectUnchangedDataHandler(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("ConfirmUnchanged", &datapipeline.DataUnchangedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: "discovery",
+		PayloadHash:   "hash",
+	}).Return(nil)
+	collectorService.On("ConfirmUnchanged", mock.Anything).Return(services.ErrResyncRequired)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+
+	config := setupTestConfig()
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	body, _ := json.Marshal(&datapipeline.DataUnchangedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: "discovery",
+		PayloadHash:   "hash",
+	})
+	req := httptest.NewRequest("POST", "/api/collect/unchanged", bytes.NewBuffer(body))
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 202, resp.Code)
+
+	resp = httptest.NewRecorder()
+	body, _ = json.Marshal(&datapipeline.DataUnchangedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: "discovery",
+		PayloadHash:   "outdated_hash",
+	})
+	req = httptest.NewRequest("POST", "/api/collect/unchanged", bytes.NewBuffer(body))
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 409, resp.Code)
+}
+
+func TestApiEnrollAgentHandler(t *testing.T) {
+	agentCredentialsService := new(services.MockAgentCredentialsService)
+	agentCredentialsService.On("Enroll", "valid-token", "agent_id").Return("some-secret", nil)
+	agentCredentialsService.On("Enroll", "used-token", "agent_id").Return("", services.ErrInvalidEnrollmentToken)
+	agentCredentialsService.On("Enroll", "valid-token", "enrolled_agent_id").Return("", services.ErrAgentAlreadyEnrolled)
+
+	deps := setupTestDependencies()
+	deps.agentCredentialsService = agentCredentialsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/enroll", bytes.NewBufferString(`{"agent_id":"agent_id","token":"valid-token"}`))
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 201, resp.Code)
+	assert.JSONEq(t, `{"secret":"some-secret"}`, resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/api/enroll", bytes.NewBufferString(`{"agent_id":"agent_id","token":"used-token"}`))
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 401, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/api/enroll", bytes.NewBufferString(`{"agent_id":"enrolled_agent_id","token":"valid-token"}`))
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 409, resp.Code)
+}
+
+func TestAgentAuthMiddleware(t *testing.T) {
+	agentCredentialsService := new(services.MockAgentCredentialsService)
+	agentCredentialsService.On("Authenticate", "agent_id", "some-secret").Return(nil)
+	agentCredentialsService.On("Authenticate", "agent_id", "wrong-secret").Return(services.ErrInvalidAgentCredentials)
+	agentCredentialsService.On("Authenticate", "revoked_agent_id", "some-secret").Return(services.ErrInvalidAgentCredentials)
+
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", mock.Anything).Return(nil)
+
+	hostsService := new(services.MockHostsService)
+	hostsService.On("Heartbeat", "agent_id", (*models.AgentStatus)(nil), "192.0.2.1").Return(nil)
+
+	deps := setupTestDependencies()
+	deps.agentCredentialsService = agentCredentialsService
+	deps.collectorService = collectorService
+	deps.hostsService = hostsService
+
+	config := setupTestConfig()
+	config.EnableAgentAuth = true
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := json.Marshal(&datapipeline.DataCollectedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: "discovery",
+		Payload:       []byte("{}"),
+	})
+
+	for _, tc := range []struct {
+		url          string
+		agentID      string
+		secret       string
+		expectedCode int
+	}{
+		{"/api/collect", "", "", 401},
+		{"/api/collect", "agent_id", "wrong-secret", 401},
+		{"/api/collect", "revoked_agent_id", "some-secret", 401},
+		{"/api/collect", "agent_id", "some-secret", 202},
+		{"/api/hosts/agent_id/heartbeat", "agent_id", "some-secret", 204},
+		{"/api/hosts/other_agent_id/heartbeat", "agent_id", "some-secret", 403},
+	} {
+		var requestBody io.Reader
+		if tc.url == "/api/collect" {
+			requestBody = bytes.NewBuffer(body)
+		}
+
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", tc.url, requestBody)
+		if tc.agentID != "" {
+			req.SetBasicAuth(tc.agentID, tc.secret)
+		}
+
+		app.collectorEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, tc.expectedCode, resp.Code, tc.url)
+	}
+
+	collectorService.AssertNumberOfCalls(t, "StoreEvent", 1)
+}
+
+func TestAgentAuthMiddlewareOtherAgentData(t *testing.T) {
+	agentCredentialsService := new(services.MockAgentCredentialsService)
+	agentCredentialsService.On("Authenticate", "agent_id", "some-secret").Return(nil)
+
+	deps := setupTestDependencies()
+	deps.agentCredentialsService = agentCredentialsService
+	deps.collectorService = new(services.MockCollectorService)
+
+	config := setupTestConfig()
+	config.EnableAgentAuth = true
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := json.Marshal(&datapipeline.DataCollectedEvent{
+		AgentID:       "other_agent_id",
+		DiscoveryType: "discovery",
+		Payload:       []byte("{}"),
+	})
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/collect", bytes.NewBuffer(body))
+	req.SetBasicAuth("agent_id", "some-secret")
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 403, resp.Code)
+}
+
+func TestAgentAuthMiddlewareOtherAgentBatch(t *testing.T) {
+	agentCredentialsService := new(services.MockAgentCredentialsService)
+	agentCredentialsService.On("Authenticate", "agent_id", "some-secret").Return(nil)
+
+	deps := setupTestDependencies()
+	deps.agentCredentialsService = agentCredentialsService
+	deps.collectorService = new(services.MockCollectorService)
+
+	config := setupTestConfig()
+	config.EnableAgentAuth = true
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/collect", bytes.NewBufferString(`{"agent_id":"other_agent_id","batch":[
+		{"agent_id":"other_agent_id","discovery_type":"host_discovery","payload":{"host":"data"}}
+	]}`))
+	req.SetBasicAuth("agent_id", "some-secret")
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 403, resp.Code)
+}
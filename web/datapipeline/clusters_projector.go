@@ -4,12 +4,16 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/trento/internal"
 	"github.com/trento-project/trento/internal/cluster"
 	"github.com/trento-project/trento/internal/cluster/cib"
+	"github.com/trento-project/trento/internal/cluster/crmmon"
 	"github.com/trento-project/trento/web/entities"
 	"github.com/trento-project/trento/web/models"
 	"gorm.io/datatypes"
@@ -21,6 +25,9 @@ const (
 	partialSrHealth = "hana_sr_health"
 )
 
+// sapInstanceNamePattern matches the InstanceName of a SAPInstance resource, e.g. NWP_ASCS00_sapnwpas
+var sapInstanceNamePattern = regexp.MustCompile(`^([A-Z][A-Z0-9]{2})_([A-Z]+)(\d{2})_(.+)$`)
+
 func NewClustersProjector(db *gorm.DB) *projector {
 	clusterProjector := NewProjector("clusters", db)
 	clusterProjector.AddHandler(ClusterDiscovery, clustersProjector_ClusterDiscoveryHandler)
@@ -88,20 +95,33 @@ func transformClusterData(cluster *cluster.Cluster) (*entities.Cluster, error) {
 	}, nil
 }
 
-// detectClusterType returns the cluster type based on the cluster resources
+// detectClusterType returns the cluster type based on the cluster resources,
+// either the running ones or the ones configured in the CIB
 func detectClusterType(cluster *cluster.Cluster) string {
 	var hasSapHanaTopology, hasSAPHanaController, hasSAPHana bool
 
+	var agents []string
 	for _, c := range cluster.Crmmon.Clones {
 		for _, r := range c.Resources {
-			switch r.Agent {
-			case "ocf::suse:SAPHanaTopology":
-				hasSapHanaTopology = true
-			case "ocf::suse:SAPHana":
-				hasSAPHana = true
-			case "ocf::suse:SAPHanaController":
-				hasSAPHanaController = true
-			}
+			agents = append(agents, r.Agent)
+		}
+	}
+
+	var cibClones []cib.Clone
+	cibClones = append(cibClones, cluster.Cib.Configuration.Resources.Clones...)
+	cibClones = append(cibClones, cluster.Cib.Configuration.Resources.Masters...)
+	for _, c := range cibClones {
+		agents = append(agents, fmt.Sprintf("%s::%s:%s", c.Primitive.Class, c.Primitive.Provider, c.Primitive.Type))
+	}
+
+	for _, agent := range agents {
+		switch agent {
+		case "ocf::suse:SAPHanaTopology":
+			hasSapHanaTopology = true
+		case "ocf::suse:SAPHana":
+			hasSAPHana = true
+		case "ocf::suse:SAPHanaController":
+			hasSAPHanaController = true
 		}
 	}
 
@@ -110,6 +130,8 @@ func detectClusterType(cluster *cluster.Cluster) string {
 		return models.ClusterTypeHANAScaleUp
 	case hasSapHanaTopology && hasSAPHanaController:
 		return models.ClusterTypeHANAScaleOut
+	case hasASCSERSInstances(cluster):
+		return models.ClusterTypeASCSERS
 	default:
 		return models.ClusterTypeUnknown
 	}
@@ -128,6 +150,12 @@ func parseClusterSID(c *cluster.Cluster) string {
 		}
 	}
 
+	for _, r := range parseSAPInstanceResources(c) {
+		if r.sid != "" {
+			return r.sid
+		}
+	}
+
 	return ""
 }
 
@@ -136,6 +164,8 @@ func parseClusterDetails(c *cluster.Cluster) (json.RawMessage, error) {
 	switch detectClusterType(c) {
 	case models.ClusterTypeHANAScaleUp, models.ClusterTypeHANAScaleOut:
 		return parseHANAClusterDetails(c)
+	case models.ClusterTypeASCSERS:
+		return parseASCSERSClusterDetails(c)
 	default:
 		return json.RawMessage{}, nil
 	}
@@ -146,32 +176,340 @@ func parseHANAClusterDetails(c *cluster.Cluster) (json.RawMessage, error) {
 	sid := parseClusterSID(c)
 	nodes := parseClusterNodes(c)
 
-	var systemReplicationMode, systemReplicationOperationMode, secondarySyncState, srHealthState string
-	if len(nodes) > 0 {
-		systemReplicationMode, _ = parseHANAAttribute(nodes[0], "srmode", sid)
-		systemReplicationOperationMode, _ = parseHANAAttribute(nodes[0], "op_mode", sid)
-		secondarySyncState = parseHANASecondarySyncState(nodes, sid)
-		srHealthState = parseHANAHealthState(nodes, sid)
+	dateLayout := "Mon Jan 2 15:04:05 2006"
+	cibLastWritten, _ := time.Parse(dateLayout, c.Crmmon.Summary.LastChange.Time)
+
+	clusterDetail := &entities.HANAClusterDetails{
+		CIBLastWritten:   cibLastWritten,
+		FencingType:      parseClusterFencingType(c),
+		StoppedResources: parseClusterStoppedResources(c),
+		FailedActions:    parseClusterFailedActions(c),
+		Nodes:            nodes,
+		SBDDevices:       parseSBDDevices(c),
+		Corosync:         parseClusterCorosync(c),
+	}
+
+	if detectClusterType(c) == models.ClusterTypeHANAScaleOut {
+		parseHANAScaleOutDetails(c, clusterDetail, sid)
+	} else if len(nodes) > 0 {
+		clusterDetail.SystemReplicationMode, _ = parseHANAAttribute(nodes[0], "srmode", sid)
+		clusterDetail.SystemReplicationOperationMode, _ = parseHANAAttribute(nodes[0], "op_mode", sid)
+		clusterDetail.SecondarySyncState = parseHANASecondarySyncState(nodes, sid)
+		clusterDetail.SRHealthState = parseHANAHealthState(nodes, sid)
+	}
+
+	return json.Marshal(clusterDetail)
+}
+
+// parseHANAScaleOutDetails parses the system replication state of a HANA scale-out cluster.
+// Unlike scale-up, the site roles, landscape status and sync state are global attributes kept in the CIB
+// by SAPHanaSR-ScaleOut, while the node roles only tell the name server and index server roles of each node
+func parseHANAScaleOutDetails(c *cluster.Cluster, clusterDetail *entities.HANAClusterDetails, sid string) {
+	for _, node := range clusterDetail.Nodes {
+		roles, ok := parseHANAAttribute(node, "roles", sid)
+		if !ok && node.Site == "" {
+			// The majority maker only runs the cluster stack, it has no HANA instance
+			node.MajorityMaker = true
+			continue
+		}
+
+		// e.g. master1:master:worker:master, the configured and current name server roles,
+		// and the configured and current index server roles
+		if fields := strings.Split(roles, ":"); len(fields) == 4 {
+			node.NameServerRole = fields[1]
+			node.IndexServerRole = fields[3]
+		}
+
+		node.HANAStatus = parseHANAScaleOutStatus(c, node, sid)
+
+		switch siteRole, _ := parseHANAGlobalAttribute(c, "site_srr_"+node.Site, sid); siteRole {
+		case "P":
+			clusterDetail.PrimarySite = node.Site
+		case "S":
+			clusterDetail.SecondarySite = node.Site
+		}
+
+		if clusterDetail.SystemReplicationMode == "" {
+			clusterDetail.SystemReplicationMode, _ = parseHANAAttribute(node, "srmode", sid)
+		}
+		if clusterDetail.SystemReplicationOperationMode == "" {
+			clusterDetail.SystemReplicationOperationMode, _ = parseHANAAttribute(node, "op_mode", sid)
+		}
+	}
+
+	if clusterDetail.SystemReplicationMode == "" {
+		clusterDetail.SystemReplicationMode, _ = parseHANAGlobalAttribute(c, "glob_srmode", sid)
+	}
+	if clusterDetail.SystemReplicationOperationMode == "" {
+		clusterDetail.SystemReplicationOperationMode, _ = parseHANAGlobalAttribute(c, "glob_op_mode", sid)
+	}
+
+	clusterDetail.SecondarySyncState = models.HANAStatusUnknown
+	if clusterDetail.SecondarySite == "" {
+		return
+	}
+
+	if syncState, ok := parseHANAScaleOutSyncState(c, clusterDetail.SecondarySite, sid); ok {
+		clusterDetail.SecondarySyncState = syncState
+	}
+	clusterDetail.SRHealthState, _ = parseHANAGlobalAttribute(c, "site_lss_"+clusterDetail.SecondarySite, sid)
+}
+
+// parseHANAScaleOutStatus returns the SAPHanaSR Health state of a HANA scale-out node, out of the role of its site.
+// Possible values: Primary, Secondary, Failed, Unknown
+func parseHANAScaleOutStatus(c *cluster.Cluster, node *entities.HANAClusterNode, sid string) string {
+	siteRole, ok := parseHANAGlobalAttribute(c, "site_srr_"+node.Site, sid)
+	if !ok {
+		return models.HANAStatusUnknown
+	}
+
+	syncState, ok := parseHANAScaleOutSyncState(c, node.Site, sid)
+	if !ok {
+		syncState, ok = parseHANAAttribute(node, "sync_state", sid)
+	}
+	if !ok {
+		return models.HANAStatusUnknown
+	}
+
+	switch {
+	case siteRole == "P" && syncState == "PRIM":
+		return models.HANAStatusPrimary
+	case siteRole == "P" && syncState != "PRIM":
+		return models.HANAStatusFailed
+	case siteRole == "S" && syncState == models.HANASrSyncSOK:
+		return models.HANAStatusSecondary
+	case siteRole == "S" && syncState != models.HANASrSyncSOK:
+		return models.HANAStatusFailed
+	}
+
+	return models.HANAStatusUnknown
+}
+
+// parseHANAScaleOutSyncState returns the sync state of a HANA scale-out site, as reported by the SAPHanaSR hook
+func parseHANAScaleOutSyncState(c *cluster.Cluster, site string, sid string) (string, bool) {
+	if syncState, ok := parseHANAGlobalAttribute(c, "site_srHook_"+site, sid); ok {
+		return syncState, true
+	}
+
+	// Older SAPHanaSR-ScaleOut versions only keep the sync state of the secondary site
+	if siteRole, _ := parseHANAGlobalAttribute(c, "site_srr_"+site, sid); siteRole == "S" {
+		return parseHANAGlobalAttribute(c, "glob_sync_state", sid)
+	}
+
+	return "", false
+}
+
+// sapInstanceResource is a SAPInstance resource of the CIB, along with the group it belongs to, if any
+type sapInstanceResource struct {
+	primitive    cib.Primitive
+	group        *cib.Group
+	sid          string
+	instanceType string
+	number       string
+	hostname     string
+}
+
+// parseSAPInstanceResources returns the SAPInstance resources managing an ASCS or ERS instance
+func parseSAPInstanceResources(c *cluster.Cluster) []*sapInstanceResource {
+	var resources []*sapInstanceResource
+
+	addResource := func(p cib.Primitive, g *cib.Group) {
+		if p.Type != "SAPInstance" {
+			return
+		}
+
+		var isERS bool
+		var instanceName string
+		for _, a := range p.InstanceAttributes {
+			switch a.Name {
+			case "InstanceName":
+				instanceName = a.Value
+			case "IS_ERS":
+				isERS = strings.EqualFold(a.Value, "true")
+			}
+		}
+
+		match := sapInstanceNamePattern.FindStringSubmatch(instanceName)
+		if match == nil {
+			return
+		}
+
+		resource := &sapInstanceResource{
+			primitive: p,
+			group:     g,
+			sid:       match[1],
+			number:    match[3],
+			hostname:  match[4],
+		}
+
+		// The IS_ERS attribute marks the ERS instance regardless of its name
+		switch {
+		case isERS || match[2] == "ERS":
+			resource.instanceType = models.ASCSERSInstanceTypeERS
+		case match[2] == "ASCS" || match[2] == "SCS":
+			resource.instanceType = models.ASCSERSInstanceTypeASCS
+		default:
+			return
+		}
+
+		resources = append(resources, resource)
 	}
 
+	for _, p := range c.Cib.Configuration.Resources.Primitives {
+		addResource(p, nil)
+	}
+
+	for i, g := range c.Cib.Configuration.Resources.Groups {
+		for _, p := range g.Primitives {
+			addResource(p, &c.Cib.Configuration.Resources.Groups[i])
+		}
+	}
+
+	return resources
+}
+
+// hasASCSERSInstances tells whether the cluster manages both an ASCS and an ERS instance
+func hasASCSERSInstances(c *cluster.Cluster) bool {
+	var hasASCS, hasERS bool
+
+	for _, r := range parseSAPInstanceResources(c) {
+		switch r.instanceType {
+		case models.ASCSERSInstanceTypeASCS:
+			hasASCS = true
+		case models.ASCSERSInstanceTypeERS:
+			hasERS = true
+		}
+	}
+
+	return hasASCS && hasERS
+}
+
+// parseASCSERSClusterDetails parses the ASCS/ERS cluster details
+func parseASCSERSClusterDetails(c *cluster.Cluster) (json.RawMessage, error) {
 	dateLayout := "Mon Jan 2 15:04:05 2006"
 	cibLastWritten, _ := time.Parse(dateLayout, c.Crmmon.Summary.LastChange.Time)
 
-	clusterDetail := &entities.HANAClusterDetails{
-		SystemReplicationMode:          systemReplicationMode,
-		SecondarySyncState:             secondarySyncState,
-		SystemReplicationOperationMode: systemReplicationOperationMode,
-		SRHealthState:                  srHealthState,
-		CIBLastWritten:                 cibLastWritten,
-		FencingType:                    parseClusterFencingType(c),
-		StoppedResources:               parseClusterStoppedResources(c),
-		Nodes:                          nodes,
-		SBDDevices:                     parseSBDDevices(c),
+	clusterDetail := &entities.ASCSERSClusterDetails{
+		CIBLastWritten:   cibLastWritten,
+		FencingType:      parseClusterFencingType(c),
+		StoppedResources: parseClusterStoppedResources(c),
+		FailedActions:    parseClusterFailedActions(c),
+		Nodes:            parseClusterNodes(c),
+		SBDDevices:       parseSBDDevices(c),
+		Corosync:         parseClusterCorosync(c),
+	}
+
+	for _, r := range parseSAPInstanceResources(c) {
+		instance := &entities.ASCSERSInstance{
+			Type:            r.instanceType,
+			SID:             r.sid,
+			InstanceNumber:  r.number,
+			VirtualHostname: r.hostname,
+			ResourceID:      r.primitive.Id,
+			Node:            parseClusterResourceNode(c, r.primitive.Id),
+		}
+
+		if r.group != nil {
+			instance.Group = r.group.Id
+			instance.VirtualIPs, instance.Filesystems = parseClusterGroupNetworkAndStorage(r.group)
+		}
+
+		for _, p := range c.SAPInstanceProfiles {
+			if p.ResourceId == r.primitive.Id {
+				instance.Halib = p.Halib
+				instance.HalibClusterConnector = p.HalibClusterConnector
+				break
+			}
+		}
+
+		if r.instanceType == models.ASCSERSInstanceTypeASCS {
+			clusterDetail.EnsaVersion = parseEnsaVersion(c, r)
+		}
+
+		clusterDetail.Instances = append(clusterDetail.Instances, instance)
 	}
 
 	return json.Marshal(clusterDetail)
 }
 
+// parseEnsaVersion returns the Standalone Enqueue Server version out of the ASCS resource setup.
+// With ENSA1 the ASCS instance must fail over to the node running the ERS instance, to take over the replicated
+// lock table. This is done with a location rule on the runs_ers_<SID> node attribute and a migration threshold of 1,
+// both of them dropped in ENSA2 setups
+func parseEnsaVersion(c *cluster.Cluster, ascs *sapInstanceResource) string {
+	for _, a := range ascs.primitive.MetaAttributes {
+		if a.Name == "migration-threshold" && a.Value == "1" {
+			return models.EnsaVersion1
+		}
+	}
+
+	ascsResources := []string{ascs.primitive.Id}
+	if ascs.group != nil {
+		ascsResources = append(ascsResources, ascs.group.Id)
+	}
+
+	for _, l := range c.Cib.Configuration.Constraints.RscLocations {
+		if !internal.Contains(ascsResources, l.Resource) {
+			continue
+		}
+
+		for _, rule := range l.Rules {
+			for _, e := range rule.Expressions {
+				if e.Attribute == "runs_ers_"+ascs.sid {
+					return models.EnsaVersion1
+				}
+			}
+		}
+	}
+
+	return models.EnsaVersion2
+}
+
+// parseClusterResourceNode returns the node where a resource is running, or an empty string if it is stopped
+func parseClusterResourceNode(c *cluster.Cluster, resourceID string) string {
+	resources := c.Crmmon.Resources
+	for _, g := range c.Crmmon.Groups {
+		resources = append(resources, g.Resources...)
+	}
+
+	for _, r := range resources {
+		if r.Id == resourceID && r.Node != nil {
+			return r.Node.Name
+		}
+	}
+
+	return ""
+}
+
+// parseClusterGroupNetworkAndStorage returns the virtual IPs and the filesystems managed in a resource group
+func parseClusterGroupNetworkAndStorage(g *cib.Group) ([]string, []*entities.ClusterFilesystem) {
+	var virtualIPs []string
+	var filesystems []*entities.ClusterFilesystem
+
+	for _, p := range g.Primitives {
+		attributes := make(map[string]string)
+		for _, a := range p.InstanceAttributes {
+			attributes[a.Name] = a.Value
+		}
+
+		switch p.Type {
+		case "IPaddr2":
+			if ip, ok := attributes["ip"]; ok {
+				virtualIPs = append(virtualIPs, ip)
+			}
+		case "Filesystem":
+			filesystems = append(filesystems, &entities.ClusterFilesystem{
+				ResourceID: p.Id,
+				Device:     attributes["device"],
+				Directory:  attributes["directory"],
+				FSType:     attributes["fstype"],
+			})
+		}
+	}
+
+	return virtualIPs, filesystems
+}
+
 // parseClusterNodes parses the cluster nodes from the crmmon/cib data
 func parseClusterNodes(c *cluster.Cluster) []*entities.HANAClusterNode {
 	var nodes []*entities.HANAClusterNode
@@ -197,11 +535,33 @@ func parseClusterNodes(c *cluster.Cluster) []*entities.HANAClusterNode {
 			node.Attributes[a.Name] = a.Value
 		}
 
+		nodes = append(nodes, node)
+	}
+
+	// Nodes without attributes, like the majority maker of a HANA scale-out cluster, are not listed along with them
+	for _, n := range c.Crmmon.Nodes {
+		listed := false
+		for _, node := range nodes {
+			if node.Name == n.Name {
+				listed = true
+				break
+			}
+		}
+
+		if !listed {
+			nodes = append(nodes, &entities.HANAClusterNode{
+				Name:       n.Name,
+				Attributes: make(map[string]string),
+			})
+		}
+	}
+
+	for _, node := range nodes {
 		for _, r := range resources {
 			if r.Node == nil {
 				continue
 			}
-			if r.Node.Name == n.Name {
+			if r.Node.Name == node.Name {
 				resource := &entities.ClusterResource{
 					ID:   r.Id,
 					Type: r.Agent,
@@ -240,7 +600,7 @@ func parseClusterNodes(c *cluster.Cluster) []*entities.HANAClusterNode {
 				}
 
 				for _, nh := range c.Crmmon.NodeHistory.Nodes {
-					if nh.Name == n.Name {
+					if nh.Name == node.Name {
 						for _, rh := range nh.ResourceHistory {
 							if rh.Name == resource.ID {
 								resource.FailCount = rh.FailCount
@@ -256,12 +616,24 @@ func parseClusterNodes(c *cluster.Cluster) []*entities.HANAClusterNode {
 
 		node.Site, _ = parseHANAAttribute(node, "site", sid)
 		node.HANAStatus = parseHANAStatus(node, sid)
-		nodes = append(nodes, node)
 	}
 
 	return nodes
 }
 
+// parseHANAGlobalAttribute returns an HANA attribute stored in the CIB cluster properties,
+// e.g. hana_prd_site_srr_Site1 for the site_srr_Site1 attribute
+func parseHANAGlobalAttribute(c *cluster.Cluster, attributeName string, sid string) (string, bool) {
+	hanaAttributeName := fmt.Sprintf("hana_%s_%s", strings.ToLower(sid), attributeName)
+	for _, p := range c.Cib.Configuration.CrmConfig.ClusterProperties {
+		if p.Name == hanaAttributeName {
+			return p.Value, true
+		}
+	}
+
+	return "", false
+}
+
 // parseHANAAttribute returns an HANA attribute value
 func parseHANAAttribute(node *entities.HANAClusterNode, attributeName string, sid string) (string, bool) {
 	hanaAttributeName := fmt.Sprintf("hana_%s_%s", strings.ToLower(sid), attributeName)
@@ -356,10 +728,25 @@ func parseClusterStoppedResources(c *cluster.Cluster) []*entities.ClusterResourc
 		resources = append(resources, c.Resources...)
 	}
 
+	stopped := make(map[string]bool)
+	for _, r := range resources {
+		if r.NodesRunningOn == 0 && !r.Active {
+			stopped[r.Id] = true
+		}
+	}
+	// A group or a clone is stopped when none of its resources is running
+	for _, g := range c.Crmmon.Groups {
+		stopped[g.Id] = allResourcesStopped(g.Resources, stopped)
+	}
+	for _, cl := range c.Crmmon.Clones {
+		stopped[cl.Id] = allResourcesStopped(cl.Resources, stopped)
+	}
+
 	for _, r := range resources {
 		if r.NodesRunningOn == 0 && !r.Active {
 			resource := &entities.ClusterResource{
-				ID: r.Id,
+				ID:      r.Id,
+				Reasons: parseClusterStoppedResourceReasons(c, r.Id, stopped),
 			}
 			stoppedResources = append(stoppedResources, resource)
 		}
@@ -368,6 +755,311 @@ func parseClusterStoppedResources(c *cluster.Cluster) []*entities.ClusterResourc
 	return stoppedResources
 }
 
+func allResourcesStopped(resources []crmmon.Resource, stopped map[string]bool) bool {
+	for _, r := range resources {
+		if !stopped[r.Id] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseClusterStoppedResourceReasons explains why a resource is stopped, looking at its meta attributes,
+// its failed operations and the constraints that apply to it, its group or its clone
+func parseClusterStoppedResourceReasons(c *cluster.Cluster, resourceID string, stopped map[string]bool) []string {
+	var reasons []string
+
+	ids := append([]string{resourceID}, parseClusterResourceParents(c, resourceID)...)
+	isResource := make(map[string]bool)
+	for _, id := range ids {
+		isResource[id] = true
+	}
+
+	for _, id := range ids {
+		meta := parseClusterResourceMetaAttributes(c, id)
+		for _, attr := range meta {
+			switch {
+			case attr.Name == "target-role" && attr.Value == "Stopped":
+				reasons = append(reasons, fmt.Sprintf("Disabled: target-role is Stopped on %s", id))
+			case attr.Name == "is-managed" && attr.Value == "false":
+				reasons = append(reasons, fmt.Sprintf("Unmanaged: is-managed is false on %s", id))
+			}
+		}
+	}
+
+	failedCalls := make(map[string]bool)
+	for _, f := range c.Crmmon.Failures {
+		if failureResourceID(f) != resourceID {
+			continue
+		}
+		failedCalls[fmt.Sprintf("%s/%d", f.Node, f.Call)] = true
+		reasons = append(reasons, formatFailedOperation(f.Task, f.Node, f.ExitStatus, f.ExitReason))
+	}
+
+	for _, n := range c.Crmmon.NodeHistory.Nodes {
+		for _, h := range n.ResourceHistory {
+			if h.Name != resourceID {
+				continue
+			}
+
+			// Failures cleaned up from the failures section are still in the operation history
+			for _, op := range h.OperationHistory {
+				if !isFailedOperation(op) || failedCalls[fmt.Sprintf("%s/%d", n.Name, op.Call)] {
+					continue
+				}
+				reasons = append(reasons, formatFailedOperation(op.Task, n.Name, op.RCText, op.ExitReason))
+			}
+
+			if h.MigrationThreshold > 0 && h.FailCount >= h.MigrationThreshold {
+				reasons = append(reasons, fmt.Sprintf(
+					"Banned from %s: fail count %d reached the migration threshold %d", n.Name, h.FailCount, h.MigrationThreshold))
+			}
+		}
+	}
+
+	constraints := c.Cib.Configuration.Constraints
+	for _, l := range constraints.RscLocations {
+		if !isResource[l.Resource] && !resourceSetsContain(l.ResourceSets, isResource) {
+			continue
+		}
+		if l.Score == "-INFINITY" && l.Node != "" {
+			reasons = append(reasons, fmt.Sprintf("Banned from %s by location constraint %s", l.Node, l.Id))
+		}
+		// The rule expressions are not evaluated, so they might not match on any node
+		for _, rule := range l.Rules {
+			if rule.Score == "-INFINITY" {
+				reasons = append(reasons, fmt.Sprintf(
+					"Possibly banned by rule %s of location constraint %s, if its expression matches", rule.Id, l.Id))
+			}
+		}
+	}
+
+	for _, col := range constraints.RscColocations {
+		if !isMandatoryScore(col.Score) {
+			continue
+		}
+		if isResource[col.Resource] && stopped[col.WithResource] {
+			reasons = append(reasons, fmt.Sprintf(
+				"Colocated with the stopped resource %s by constraint %s", col.WithResource, col.Id))
+		}
+		for _, partner := range stoppedColocationSetPartners(col.ResourceSets, isResource, stopped) {
+			reasons = append(reasons, fmt.Sprintf(
+				"Colocated with the stopped resource %s by constraint %s", partner, col.Id))
+		}
+	}
+
+	for _, o := range constraints.RscOrders {
+		if o.Kind != "" && o.Kind != "Mandatory" {
+			continue
+		}
+		if isResource[o.Then] && stopped[o.First] {
+			reasons = append(reasons, fmt.Sprintf("Ordered after the stopped resource %s by constraint %s", o.First, o.Id))
+		}
+		for _, set := range o.ResourceSets {
+			if set.Sequential == "false" {
+				continue
+			}
+			for i, ref := range set.ResourceRefs {
+				if !isResource[ref.Id] || i == 0 || !stopped[set.ResourceRefs[i-1].Id] {
+					continue
+				}
+				reasons = append(reasons, fmt.Sprintf(
+					"Ordered after the stopped resource %s by constraint %s", set.ResourceRefs[i-1].Id, o.Id))
+			}
+		}
+	}
+
+	granted := make(map[string]bool)
+	for _, t := range c.Crmmon.Tickets {
+		granted[t.Id] = t.Status == "granted" && !t.Standby
+	}
+	for _, t := range constraints.RscTickets {
+		if granted[t.Ticket] {
+			continue
+		}
+		applies := isResource[t.Resource]
+		for _, set := range t.ResourceSets {
+			for _, ref := range set.ResourceRefs {
+				applies = applies || isResource[ref.Id]
+			}
+		}
+		if applies {
+			reasons = append(reasons, fmt.Sprintf("Requires the ticket %s, which is not granted, by constraint %s", t.Ticket, t.Id))
+		}
+	}
+
+	return reasons
+}
+
+func resourceSetsContain(sets []cib.ResourceSet, isResource map[string]bool) bool {
+	for _, set := range sets {
+		for _, ref := range set.ResourceRefs {
+			if isResource[ref.Id] {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// stoppedColocationSetPartners returns the stopped resources the given ones are colocated with by
+// the resource sets of a colocation constraint. Within a sequential set each resource is colocated
+// with the previous one, while the resources of a set are colocated with the following set, with
+// all of its resources if sequential or with any of them otherwise
+func stoppedColocationSetPartners(sets []cib.ResourceSet, isResource map[string]bool, stopped map[string]bool) []string {
+	var partners []string
+	found := make(map[string]bool)
+	add := func(id string) {
+		if !found[id] {
+			found[id] = true
+			partners = append(partners, id)
+		}
+	}
+
+	for k, set := range sets {
+		for i, ref := range set.ResourceRefs {
+			if !isResource[ref.Id] {
+				continue
+			}
+
+			if set.Sequential != "false" && i > 0 && stopped[set.ResourceRefs[i-1].Id] {
+				add(set.ResourceRefs[i-1].Id)
+			}
+
+			if k+1 == len(sets) {
+				continue
+			}
+
+			next := sets[k+1]
+			var nextStopped []string
+			for _, nextRef := range next.ResourceRefs {
+				if stopped[nextRef.Id] {
+					nextStopped = append(nextStopped, nextRef.Id)
+				}
+			}
+			if next.Sequential != "false" || len(nextStopped) == len(next.ResourceRefs) {
+				for _, id := range nextStopped {
+					add(id)
+				}
+			}
+		}
+	}
+
+	return partners
+}
+
+// parseClusterResourceParents returns the group and the clone a primitive belongs to, if any
+func parseClusterResourceParents(c *cluster.Cluster, resourceID string) []string {
+	var parents []string
+
+	resources := c.Cib.Configuration.Resources
+	for _, g := range resources.Groups {
+		for _, p := range g.Primitives {
+			if p.Id == resourceID {
+				parents = append(parents, g.Id)
+			}
+		}
+	}
+
+	for _, cl := range append(resources.Clones, resources.Masters...) {
+		if cl.Primitive.Id == resourceID {
+			parents = append(parents, cl.Id)
+		}
+	}
+
+	return parents
+}
+
+// parseClusterResourceMetaAttributes returns the meta attributes of a primitive, a group or a clone
+func parseClusterResourceMetaAttributes(c *cluster.Cluster, resourceID string) []cib.Attribute {
+	resources := c.Cib.Configuration.Resources
+	for _, p := range resources.Primitives {
+		if p.Id == resourceID {
+			return p.MetaAttributes
+		}
+	}
+
+	for _, g := range resources.Groups {
+		if g.Id == resourceID {
+			return g.MetaAttributes
+		}
+		for _, p := range g.Primitives {
+			if p.Id == resourceID {
+				return p.MetaAttributes
+			}
+		}
+	}
+
+	for _, cl := range append(resources.Clones, resources.Masters...) {
+		if cl.Id == resourceID {
+			return cl.MetaAttributes
+		}
+		if cl.Primitive.Id == resourceID {
+			return cl.Primitive.MetaAttributes
+		}
+	}
+
+	return nil
+}
+
+// failureResourceID returns the resource of a failed action, stripping the task and the interval from its operation key
+func failureResourceID(f crmmon.Failure) string {
+	i := strings.LastIndex(f.OpKey, "_"+f.Task+"_")
+	if i < 0 {
+		return f.OpKey
+	}
+
+	return f.OpKey[:i]
+}
+
+// isFailedOperation tells whether an operation failed, ignoring the OCF return codes expected from probes
+// and monitors, e.g. "not running" (7) for a stopped resource and "master" (8) for a promoted one
+func isFailedOperation(op crmmon.OperationHistory) bool {
+	switch op.RC {
+	case 0:
+		return false
+	case 7:
+		return op.Task != "probe" && op.Task != "monitor"
+	case 8:
+		return op.Task != "probe" && op.Task != "monitor" && op.Task != "promote"
+	}
+
+	return true
+}
+
+func isMandatoryScore(score string) bool {
+	return score == "INFINITY" || score == "+INFINITY"
+}
+
+func formatFailedOperation(task, node, status, exitReason string) string {
+	reason := fmt.Sprintf("%s failed on %s: %s", task, node, status)
+	if exitReason != "" {
+		reason = fmt.Sprintf("%s (%s)", reason, exitReason)
+	}
+
+	return reason
+}
+
+// parseClusterFailedActions returns the failed resource actions reported by crm_mon
+func parseClusterFailedActions(c *cluster.Cluster) []*entities.ClusterFailedAction {
+	var failedActions []*entities.ClusterFailedAction
+
+	for _, f := range c.Crmmon.Failures {
+		failedActions = append(failedActions, &entities.ClusterFailedAction{
+			ResourceID:   failureResourceID(f),
+			Task:         f.Task,
+			Node:         f.Node,
+			ExitStatus:   f.ExitStatus,
+			ExitReason:   f.ExitReason,
+			LastRCChange: f.LastRCChange,
+		})
+	}
+
+	return failedActions
+}
+
 // parseSBDDevices returns a slice of SBD devices
 func parseSBDDevices(c *cluster.Cluster) []*entities.SBDDevice {
 	var sbdDevices []*entities.SBDDevice
@@ -382,6 +1074,76 @@ func parseSBDDevices(c *cluster.Cluster) []*entities.SBDDevice {
 	return sbdDevices
 }
 
+// parseClusterCorosync returns the corosync rings and nodes, along with the quorum state reported by corosync-quorumtool.
+// Nodes are named after the quorum members, as the nodelist usually only has their addresses
+func parseClusterCorosync(c *cluster.Cluster) *entities.ClusterCorosync {
+	corosync := c.Corosync
+	if corosync.Totem.Version == "" && corosync.QuorumStatus == nil {
+		return nil
+	}
+
+	clusterCorosync := &entities.ClusterCorosync{
+		Transport:      corosync.Totem.Transport,
+		Token:          corosync.Totem.Token,
+		Consensus:      corosync.Totem.Consensus,
+		QuorumProvider: corosync.Quorum.Provider,
+		TwoNode:        corosync.Quorum.TwoNode,
+		WaitForAll:     corosync.Quorum.WaitForAll,
+		ExpectedVotes:  corosync.Quorum.ExpectedVotes,
+	}
+
+	// Rings might be configured with the addresses of the nodes only, e.g. with the knet transport
+	rings := make(map[int]bool)
+	for _, i := range corosync.Totem.Interfaces {
+		rings[i.Number] = true
+	}
+
+	members := make(map[int]*cluster.CorosyncMember)
+	if corosync.QuorumStatus != nil {
+		clusterCorosync.Quorate = corosync.QuorumStatus.Quorate
+		clusterCorosync.TotalVotes = corosync.QuorumStatus.TotalVotes
+		clusterCorosync.Quorum = corosync.QuorumStatus.Quorum
+		if corosync.QuorumStatus.ExpectedVotes != 0 {
+			clusterCorosync.ExpectedVotes = corosync.QuorumStatus.ExpectedVotes
+		}
+
+		for _, m := range corosync.QuorumStatus.Members {
+			members[m.NodeId] = m
+		}
+	}
+
+	for _, n := range corosync.Nodes {
+		node := &entities.ClusterCorosyncNode{
+			NodeID:        n.NodeId,
+			Name:          n.Name,
+			RingAddresses: n.RingAddresses,
+		}
+
+		if m, ok := members[n.NodeId]; ok {
+			node.Member = true
+			node.Votes = m.Votes
+			if node.Name == "" {
+				node.Name = m.Name
+			}
+		}
+
+		for ring, address := range n.RingAddresses {
+			if address != "" {
+				rings[ring] = true
+			}
+		}
+
+		clusterCorosync.Nodes = append(clusterCorosync.Nodes, node)
+	}
+
+	for ring := range rings {
+		clusterCorosync.Rings = append(clusterCorosync.Rings, ring)
+	}
+	sort.Ints(clusterCorosync.Rings)
+
+	return clusterCorosync
+}
+
 func computeDiscoveredHealth(c *entities.Cluster) (string, error) {
 	switch c.ClusterType {
 	case models.ClusterTypeHANAScaleUp, models.ClusterTypeHANAScaleOut:
@@ -9,6 +9,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/trento-project/trento/internal/cluster"
+	"github.com/trento-project/trento/internal/cluster/cib"
+	"github.com/trento-project/trento/internal/cluster/crmmon"
 	"github.com/trento-project/trento/test/helpers"
 	"github.com/trento-project/trento/web/entities"
 	"github.com/trento-project/trento/web/models"
@@ -200,6 +202,34 @@ func TestTransformClusterData_HANAScaleUp(t *testing.T) {
 					Status: "unhealthy",
 				},
 			},
+			Corosync: &entities.ClusterCorosync{
+				Transport:      "udpu",
+				Token:          30000,
+				Consensus:      36000,
+				QuorumProvider: "corosync_votequorum",
+				TwoNode:        true,
+				ExpectedVotes:  2,
+				TotalVotes:     2,
+				Quorum:         1,
+				Quorate:        true,
+				Rings:          []int{0},
+				Nodes: []*entities.ClusterCorosyncNode{
+					{
+						NodeID:        1,
+						Name:          "vmhana01",
+						RingAddresses: []string{"10.74.1.10"},
+						Votes:         1,
+						Member:        true,
+					},
+					{
+						NodeID:        2,
+						Name:          "vmhana02",
+						RingAddresses: []string{"10.74.1.11"},
+						Votes:         1,
+						Member:        true,
+					},
+				},
+			},
 		},
 	)
 
@@ -215,6 +245,119 @@ func TestTransformClusterData_HANAScaleUp(t *testing.T) {
 		}, clusterOut)
 }
 
+func loadHANAScaleOutCluster(t *testing.T) *cluster.Cluster {
+	byteValue, err := ioutil.ReadFile("./test/fixtures/discovery/cluster/cluster_discovery_hana_scale_out.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var clusterIn cluster.Cluster
+	err = json.Unmarshal(byteValue, &clusterIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &clusterIn
+}
+
+func TestTransformClusterData_HANAScaleOut(t *testing.T) {
+	clusterOut, err := transformClusterData(loadHANAScaleOutCluster(t))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "a615a35f65627be5a757319a0741127f", clusterOut.ID)
+	assert.Equal(t, models.ClusterTypeHANAScaleOut, clusterOut.ClusterType)
+	assert.Equal(t, "PRD", clusterOut.SID)
+	assert.Equal(t, 5, clusterOut.HostsNumber)
+
+	var details entities.HANAClusterDetails
+	err = json.Unmarshal(clusterOut.Details, &details)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "sync", details.SystemReplicationMode)
+	assert.Equal(t, "logreplay", details.SystemReplicationOperationMode)
+	assert.Equal(t, "SOK", details.SecondarySyncState)
+	assert.Equal(t, "4", details.SRHealthState)
+	assert.Equal(t, "Site1", details.PrimarySite)
+	assert.Equal(t, "Site2", details.SecondarySite)
+	assert.Equal(t, "external/sbd", details.FencingType)
+
+	assert.Len(t, details.Nodes, 5)
+
+	expectedNodes := []struct {
+		name            string
+		site            string
+		hanaStatus      string
+		nameServerRole  string
+		indexServerRole string
+	}{
+		{"vmhana01", "Site1", models.HANAStatusPrimary, "master", "master"},
+		{"vmhana02", "Site1", models.HANAStatusPrimary, "slave", "slave"},
+		{"vmhana03", "Site2", models.HANAStatusSecondary, "master", "master"},
+		{"vmhana04", "Site2", models.HANAStatusSecondary, "slave", "slave"},
+	}
+
+	for i, expected := range expectedNodes {
+		node := details.Nodes[i]
+		assert.Equal(t, expected.name, node.Name)
+		assert.Equal(t, expected.site, node.Site)
+		assert.Equal(t, expected.hanaStatus, node.HANAStatus)
+		assert.Equal(t, expected.nameServerRole, node.NameServerRole)
+		assert.Equal(t, expected.indexServerRole, node.IndexServerRole)
+		assert.False(t, node.MajorityMaker)
+	}
+
+	assert.Equal(t, []string{"10.74.1.12"}, details.Nodes[0].VirtualIPs)
+	assert.Len(t, details.Nodes[0].Resources, 4)
+
+	majorityMaker := details.Nodes[4]
+	assert.Equal(t, "vmhanamm", majorityMaker.Name)
+	assert.Equal(t, "", majorityMaker.Site)
+	assert.True(t, majorityMaker.MajorityMaker)
+	assert.Empty(t, majorityMaker.Resources)
+
+	health, err := computeDiscoveredHealth(clusterOut)
+	assert.NoError(t, err)
+	assert.Equal(t, models.HealthSummaryHealthPassing, health)
+}
+
+func TestTransformClusterData_HANAScaleOutSecondaryFailed(t *testing.T) {
+	clusterIn := loadHANAScaleOutCluster(t)
+	for i, p := range clusterIn.Cib.Configuration.CrmConfig.ClusterProperties {
+		switch p.Name {
+		case "hana_prd_site_srHook_Site2":
+			clusterIn.Cib.Configuration.CrmConfig.ClusterProperties[i].Value = "SFAIL"
+		case "hana_prd_site_lss_Site2":
+			clusterIn.Cib.Configuration.CrmConfig.ClusterProperties[i].Value = "1"
+		}
+	}
+
+	clusterOut, err := transformClusterData(clusterIn)
+	assert.NoError(t, err)
+
+	var details entities.HANAClusterDetails
+	err = json.Unmarshal(clusterOut.Details, &details)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "SFAIL", details.SecondarySyncState)
+	assert.Equal(t, "1", details.SRHealthState)
+	assert.Equal(t, "Site2", details.SecondarySite)
+	assert.Equal(t, models.HANAStatusPrimary, details.Nodes[0].HANAStatus)
+	assert.Equal(t, models.HANAStatusFailed, details.Nodes[2].HANAStatus)
+	assert.Equal(t, models.HANAStatusFailed, details.Nodes[3].HANAStatus)
+
+	health, err := computeDiscoveredHealth(clusterOut)
+	assert.NoError(t, err)
+	assert.Equal(t, models.HealthSummaryHealthCritical, health)
+}
+
+func TestDetectClusterType_HANAScaleOutFromCIB(t *testing.T) {
+	clusterIn := loadHANAScaleOutCluster(t)
+	// Nothing running, e.g. while the cluster is being set up
+	clusterIn.Crmmon.Clones = nil
+
+	assert.Equal(t, models.ClusterTypeHANAScaleOut, detectClusterType(clusterIn))
+}
+
 func TestTransformClusterData_Unknown(t *testing.T) {
 	jsonFile, err := os.Open("./test/fixtures/discovery/cluster/cluster_discovery_unknown.json")
 	if err != nil {
@@ -235,6 +378,287 @@ func TestTransformClusterData_Unknown(t *testing.T) {
 		}, clusterOut)
 }
 
+func loadASCSERSCluster(t *testing.T) *cluster.Cluster {
+	byteValue, err := ioutil.ReadFile("./test/fixtures/discovery/cluster/cluster_discovery_ascs_ers.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var clusterIn cluster.Cluster
+	err = json.Unmarshal(byteValue, &clusterIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &clusterIn
+}
+
+func TestTransformClusterData_ASCSERS(t *testing.T) {
+	clusterOut, err := transformClusterData(loadASCSERSCluster(t))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "6b972d4ffd0a1b2f34a5e7cbf5e6e2b8", clusterOut.ID)
+	assert.Equal(t, models.ClusterTypeASCSERS, clusterOut.ClusterType)
+	assert.Equal(t, "NWP", clusterOut.SID)
+
+	var details entities.ASCSERSClusterDetails
+	err = json.Unmarshal(clusterOut.Details, &details)
+	assert.NoError(t, err)
+
+	assert.Equal(t, models.EnsaVersion1, details.EnsaVersion)
+	assert.Equal(t, "external/sbd", details.FencingType)
+	assert.Len(t, details.Nodes, 2)
+	assert.Len(t, details.SBDDevices, 1)
+	assert.Empty(t, details.StoppedResources)
+
+	assert.Equal(t, []*entities.ASCSERSInstance{
+		{
+			Type:            models.ASCSERSInstanceTypeASCS,
+			SID:             "NWP",
+			InstanceNumber:  "00",
+			VirtualHostname: "sapnwpas",
+			ResourceID:      "rsc_sap_NWP_ASCS00",
+			Group:           "grp_NWP_ASCS00",
+			Node:            "vmnwp01",
+			VirtualIPs:      []string{"10.80.1.25"},
+			Filesystems: []*entities.ClusterFilesystem{
+				{
+					ResourceID: "rsc_fs_NWP_ASCS00",
+					Device:     "/dev/disk/by-label/NWPASCS00",
+					Directory:  "/usr/sap/NWP/ASCS00",
+					FSType:     "xfs",
+				},
+			},
+			Halib:                 "$(DIR_CT_RUN)/saphascriptco.so",
+			HalibClusterConnector: "/usr/bin/sap_suse_cluster_connector",
+		},
+		{
+			Type:            models.ASCSERSInstanceTypeERS,
+			SID:             "NWP",
+			InstanceNumber:  "10",
+			VirtualHostname: "sapnwper",
+			ResourceID:      "rsc_sap_NWP_ERS10",
+			Group:           "grp_NWP_ERS10",
+			Node:            "vmnwp02",
+			VirtualIPs:      []string{"10.80.1.26"},
+			Filesystems: []*entities.ClusterFilesystem{
+				{
+					ResourceID: "rsc_fs_NWP_ERS10",
+					Device:     "/dev/disk/by-label/NWPERS10",
+					Directory:  "/usr/sap/NWP/ERS10",
+					FSType:     "xfs",
+				},
+			},
+			Halib:                 "$(DIR_CT_RUN)/saphascriptco.so",
+			HalibClusterConnector: "/usr/bin/sap_suse_cluster_connector",
+		},
+	}, details.Instances)
+
+	health, err := computeDiscoveredHealth(clusterOut)
+	assert.NoError(t, err)
+	assert.Equal(t, models.HealthSummaryHealthUnknown, health)
+}
+
+func TestTransformClusterData_ASCSERSEnsa2(t *testing.T) {
+	clusterIn := loadASCSERSCluster(t)
+	clusterIn.Cib.Configuration.Constraints.RscLocations = nil
+	clusterIn.Cib.Configuration.Resources.Groups[0].Primitives[2].MetaAttributes = nil
+	clusterIn.SAPInstanceProfiles = nil
+
+	clusterOut, err := transformClusterData(clusterIn)
+	assert.NoError(t, err)
+
+	var details entities.ASCSERSClusterDetails
+	err = json.Unmarshal(clusterOut.Details, &details)
+	assert.NoError(t, err)
+
+	assert.Equal(t, models.EnsaVersion2, details.EnsaVersion)
+	assert.Equal(t, "", details.Instances[0].HalibClusterConnector)
+}
+
+func TestDetectClusterType_ASCSERSWithoutERS(t *testing.T) {
+	clusterIn := loadASCSERSCluster(t)
+	clusterIn.Cib.Configuration.Resources.Groups = clusterIn.Cib.Configuration.Resources.Groups[:1]
+
+	assert.Equal(t, models.ClusterTypeUnknown, detectClusterType(clusterIn))
+}
+
+func TestParseClusterCorosync_NotQuorateKnet(t *testing.T) {
+	c := &cluster.Cluster{
+		Corosync: cluster.Corosync{
+			Totem: cluster.CorosyncTotem{
+				Version:   "2",
+				Transport: "knet",
+			},
+			Quorum: cluster.CorosyncQuorum{
+				Provider: "corosync_votequorum",
+			},
+			Nodes: []*cluster.CorosyncNode{
+				{NodeId: 1, Name: "node01", RingAddresses: []string{"10.80.1.11", "192.168.100.11"}},
+				{NodeId: 2, Name: "node02", RingAddresses: []string{"10.80.1.12", "192.168.100.12"}},
+				{NodeId: 3, Name: "node03", RingAddresses: []string{"10.80.1.13", "192.168.100.13"}},
+			},
+			QuorumStatus: &cluster.CorosyncQuorumStatus{
+				Quorate:       false,
+				ExpectedVotes: 3,
+				TotalVotes:    1,
+				Quorum:        2,
+				Members: []*cluster.CorosyncMember{
+					{NodeId: 2, Votes: 1, Name: "node02", Local: true},
+				},
+			},
+		},
+	}
+
+	corosync := parseClusterCorosync(c)
+
+	assert.False(t, corosync.Quorate)
+	assert.Equal(t, 3, corosync.ExpectedVotes)
+	assert.Equal(t, 1, corosync.TotalVotes)
+	assert.Equal(t, []int{0, 1}, corosync.Rings)
+	assert.False(t, corosync.Nodes[0].Member)
+	assert.True(t, corosync.Nodes[1].Member)
+	assert.Equal(t, 1, corosync.Nodes[1].Votes)
+	assert.False(t, corosync.Nodes[2].Member)
+}
+
+func TestParseClusterCorosync_NotDiscovered(t *testing.T) {
+	assert.Nil(t, parseClusterCorosync(&cluster.Cluster{}))
+}
+
+func loadPublishedCluster(t *testing.T) *cluster.Cluster {
+	byteValue, err := ioutil.ReadFile("./test/fixtures/discovery/cluster/expected_published_cluster_discovery.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var published struct {
+		Payload cluster.Cluster `json:"payload"`
+	}
+	err = json.Unmarshal(byteValue, &published)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &published.Payload
+}
+
+func TestParseClusterStoppedResources_Reasons(t *testing.T) {
+	stoppedResources := parseClusterStoppedResources(loadPublishedCluster(t))
+
+	assert.Equal(t, 3, len(stoppedResources))
+	assert.Equal(t, "test-stop", stoppedResources[0].ID)
+	assert.Equal(t, []string{
+		"Disabled: target-role is Stopped on test-stop",
+		"start failed on node02: not installed (Setup problem: couldn't find command: dummy)",
+		"Requires the ticket ticket-A, which is not granted, by constraint tkt_test_stop",
+	}, stoppedResources[0].Reasons)
+	// The stopped instances of a running clone are not explained
+	assert.Equal(t, "clusterfs", stoppedResources[1].ID)
+	assert.Empty(t, stoppedResources[1].Reasons)
+}
+
+func TestParseClusterStoppedResources_Dependencies(t *testing.T) {
+	c := loadPublishedCluster(t)
+	c.Crmmon.Failures = nil
+	c.Crmmon.Tickets = []crmmon.Ticket{{Id: "ticket-A", Status: "granted"}}
+	c.Crmmon.Resources[1].Active = false
+	c.Crmmon.Resources[1].NodesRunningOn = 0
+	c.Cib.Configuration.Constraints.RscColocations[1].Score = "INFINITY"
+	c.Cib.Configuration.Constraints.RscOrders[1].Kind = "Mandatory"
+
+	stoppedResources := parseClusterStoppedResources(c)
+
+	assert.Equal(t, 4, len(stoppedResources))
+	assert.Equal(t, []string{
+		"Disabled: target-role is Stopped on test-stop",
+		"start failed on node02: not installed (Setup problem: couldn't find command: dummy)",
+	}, stoppedResources[0].Reasons)
+	assert.Equal(t, "test", stoppedResources[1].ID)
+	assert.Equal(t, []string{
+		"Possibly banned by rule loc_test_pingd-rule of location constraint loc_test_pingd, if its expression matches",
+		"Colocated with the stopped resource test-stop by constraint col_test_stop",
+		"Ordered after the stopped resource test-stop by constraint ord_test_stop",
+	}, stoppedResources[1].Reasons)
+}
+
+func TestParseClusterStoppedResources_ResourceSets(t *testing.T) {
+	c := loadPublishedCluster(t)
+	c.Crmmon.Failures = nil
+	c.Crmmon.Tickets = []crmmon.Ticket{{Id: "ticket-A", Status: "granted"}}
+	c.Crmmon.Resources[1].Active = false
+	c.Crmmon.Resources[1].NodesRunningOn = 0
+
+	constraints := &c.Cib.Configuration.Constraints
+	constraints.RscLocations[4].Resource = ""
+	constraints.RscLocations[4].Rules = nil
+	constraints.RscLocations[4].Node = "node01"
+	constraints.RscLocations[4].Score = "-INFINITY"
+	constraints.RscLocations[4].ResourceSets = []cib.ResourceSet{
+		newResourceSet("loc_test_pingd-0", "", "rsc_ip_PRD_HDB00", "test"),
+	}
+	constraints.RscColocations[1].Score = "INFINITY"
+	constraints.RscColocations[1].Resource = ""
+	constraints.RscColocations[1].WithResource = ""
+	constraints.RscColocations[1].ResourceSets = []cib.ResourceSet{
+		newResourceSet("col_test_stop-0", "", "test"),
+		newResourceSet("col_test_stop-1", "false", "test-stop", "rsc_ip_PRD_HDB00"),
+	}
+	constraints.RscOrders[1].ResourceSets = nil
+
+	stoppedResources := parseClusterStoppedResources(c)
+
+	assert.Equal(t, "test", stoppedResources[1].ID)
+	assert.Equal(t, []string{
+		"Banned from node01 by location constraint loc_test_pingd",
+	}, stoppedResources[1].Reasons)
+
+	// The colocation is satisfied by any resource of an unordered set
+	constraints.RscColocations[1].ResourceSets[1] = newResourceSet("col_test_stop-1", "true", "test-stop", "rsc_ip_PRD_HDB00")
+
+	stoppedResources = parseClusterStoppedResources(c)
+
+	assert.Equal(t, "test", stoppedResources[1].ID)
+	assert.Equal(t, []string{
+		"Banned from node01 by location constraint loc_test_pingd",
+		"Colocated with the stopped resource test-stop by constraint col_test_stop",
+	}, stoppedResources[1].Reasons)
+}
+
+func newResourceSet(id string, sequential string, resourceIDs ...string) cib.ResourceSet {
+	set := cib.ResourceSet{Id: id, Sequential: sequential}
+	for _, resourceID := range resourceIDs {
+		set.ResourceRefs = append(set.ResourceRefs, struct {
+			Id string `xml:"id,attr"`
+		}{Id: resourceID})
+	}
+
+	return set
+}
+
+func TestParseClusterFailedActions(t *testing.T) {
+	failedActions := parseClusterFailedActions(loadPublishedCluster(t))
+
+	assert.Equal(t, []*entities.ClusterFailedAction{
+		{
+			ResourceID:   "test-stop",
+			Task:         "start",
+			Node:         "node02",
+			ExitStatus:   "not installed",
+			ExitReason:   "Setup problem: couldn't find command: dummy",
+			LastRCChange: "Mon Feb 24 09:46:41 2020",
+		},
+	}, failedActions)
+}
+
+func TestIsFailedOperation(t *testing.T) {
+	assert.False(t, isFailedOperation(crmmon.OperationHistory{Task: "start", RC: 0}))
+	assert.False(t, isFailedOperation(crmmon.OperationHistory{Task: "probe", RC: 7}))
+	assert.False(t, isFailedOperation(crmmon.OperationHistory{Task: "monitor", RC: 8}))
+	assert.True(t, isFailedOperation(crmmon.OperationHistory{Task: "start", RC: 7}))
+	assert.True(t, isFailedOperation(crmmon.OperationHistory{Task: "monitor", RC: 1}))
+}
+
 func TestParseHANAStatus_Primary(t *testing.T) {
 	node := &entities.HANAClusterNode{
 		Attributes: map[string]string{
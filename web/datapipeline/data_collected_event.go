@@ -1,6 +1,7 @@
 package datapipeline
 
 import (
+	"encoding/json"
 	"time"
 
 	"gorm.io/datatypes"
@@ -12,12 +13,29 @@ const (
 	HostDiscovery         = "host_discovery"
 	SubscriptionDiscovery = "subscription_discovery"
 	CloudDiscovery        = "cloud_discovery"
+	PackagesDiscovery     = "packages_discovery"
+	TuningDiscovery       = "tuning_discovery"
 )
 
 type DataCollectedEvent struct {
 	ID            int64
 	CreatedAt     time.Time
-	AgentID       string         `json:"agent_id" binding:"required"`
-	DiscoveryType string         `json:"discovery_type" binding:"required"`
+	AgentID       string         `json:"agent_id" binding:"required" gorm:"index:idx_data_collected_events_agent_discovery"`
+	DiscoveryType string         `json:"discovery_type" binding:"required" gorm:"index:idx_data_collected_events_agent_discovery"`
 	Payload       datatypes.JSON `json:"payload" binding:"required"`
+	PayloadHash   string         `json:"-"`
+}
+
+// DataCollectedBatch is the envelope of several data collected events sent at once by an agent
+type DataCollectedBatch struct {
+	AgentID string            `json:"agent_id"`
+	Batch   []json.RawMessage `json:"batch"`
+}
+
+// DataUnchangedEvent is sent by the agents instead of a DataCollectedEvent
+// when the discovered payload did not change since the last one published
+type DataUnchangedEvent struct {
+	AgentID       string `json:"agent_id" binding:"required"`
+	DiscoveryType string `json:"discovery_type" binding:"required"`
+	PayloadHash   string `json:"payload_hash" binding:"required"`
 }
@@ -3,6 +3,7 @@ package datapipeline
 import (
 	"encoding/json"
 	"net"
+	"path"
 
 	"github.com/mitchellh/mapstructure"
 	log "github.com/sirupsen/logrus"
@@ -10,6 +11,7 @@ import (
 	"github.com/trento-project/trento/internal/cloud"
 	"github.com/trento-project/trento/internal/cluster"
 	"github.com/trento-project/trento/internal/hosts"
+	"github.com/trento-project/trento/internal/tuning"
 	"github.com/trento-project/trento/web/entities"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
@@ -22,6 +24,7 @@ func NewHostsProjector(db *gorm.DB) *projector {
 	hostsProjector.AddHandler(HostDiscovery, hostsProjector_HostDiscoveryHandler)
 	hostsProjector.AddHandler(CloudDiscovery, hostsProjector_CloudDiscoveryHandler)
 	hostsProjector.AddHandler(ClusterDiscovery, hostsProjector_ClusterDiscoveryHandler)
+	hostsProjector.AddHandler(TuningDiscovery, hostsProjector_TuningDiscoveryHandler)
 
 	return hostsProjector
 }
@@ -95,6 +98,29 @@ func hostsProjector_ClusterDiscoveryHandler(dataCollectedEvent *DataCollectedEve
 	return storeHost(db, host, "cluster_id", "cluster_name", "cluster_type")
 }
 
+func hostsProjector_TuningDiscoveryHandler(dataCollectedEvent *DataCollectedEvent, db *gorm.DB) error {
+	decoder := getPayloadDecoder(dataCollectedEvent.Payload)
+
+	var discoveredTuning tuning.Tuning
+	if err := decoder.Decode(&discoveredTuning); err != nil {
+		log.Errorf("can't decode data: %s", err)
+		return err
+	}
+
+	jsonTuning, err := json.Marshal(parseTuning(&discoveredTuning))
+	if err != nil {
+		log.Errorf("can't encode tuning data: %s", err)
+		return err
+	}
+
+	host := entities.Host{
+		AgentID: dataCollectedEvent.AgentID,
+		Tuning:  (datatypes.JSON)(jsonTuning),
+	}
+
+	return storeHost(db, host, "tuning")
+}
+
 func storeHost(db *gorm.DB, host entities.Host, updateColumns ...string) error {
 	return db.Clauses(clause.OnConflict{
 		Columns: []clause.Column{
@@ -118,11 +144,37 @@ func filterIPAddresses(ipAddresses []string) []string {
 	return filtered
 }
 
-func parseCloudData(provider string, metadata interface{}) *entities.AzureCloudData {
+func parseTuning(discoveredTuning *tuning.Tuning) *entities.HostTuning {
+	hostTuning := &entities.HostTuning{
+		Sysctl:               discoveredTuning.Sysctl,
+		TransparentHugepages: discoveredTuning.TransparentHugepages,
+		CPUGovernors:         discoveredTuning.CPUGovernors,
+		SapconfActive:        discoveredTuning.SapconfActive,
+	}
+
+	if discoveredTuning.Saptune != nil {
+		hostTuning.Saptune = &entities.SaptuneState{
+			Version:   discoveredTuning.Saptune.Version,
+			Active:    discoveredTuning.Saptune.Active,
+			Solutions: discoveredTuning.Saptune.Solutions,
+			Notes:     discoveredTuning.Saptune.Notes,
+		}
+	}
+
+	return hostTuning
+}
+
+func parseCloudData(provider string, metadata interface{}) interface{} {
 	switch provider {
-	case "azure":
+	case cloud.Azure:
 		cloudData := parseAzureCloudData(metadata)
 		return &cloudData
+	case cloud.Aws:
+		cloudData := parseAwsCloudData(metadata)
+		return &cloudData
+	case cloud.Gcp:
+		cloudData := parseGcpCloudData(metadata)
+		return &cloudData
 	default:
 		return nil
 	}
@@ -148,3 +200,84 @@ func parseAzureCloudData(metadata interface{}) entities.AzureCloudData {
 		AdminUsername:   azureMetadata.Compute.OsProfile.AdminUserName,
 	}
 }
+
+func parseAwsCloudData(metadata interface{}) entities.AwsCloudData {
+	var awsMetadata cloud.AwsMetadata
+
+	err := mapstructure.Decode(metadata, &awsMetadata)
+	if err != nil {
+		log.Errorf("can't decode aws metadata: %s", err)
+		return entities.AwsCloudData{}
+	}
+
+	var networkInterfaces []entities.AwsNetworkInterface
+	for _, networkInterface := range awsMetadata.NetworkInterfaces {
+		networkInterfaces = append(networkInterfaces, entities.AwsNetworkInterface{
+			ID:         networkInterface.InterfaceId,
+			MacAddress: networkInterface.MacAddress,
+			PrivateIPs: networkInterface.PrivateIps,
+			PublicIPs:  networkInterface.PublicIps,
+			SubnetID:   networkInterface.SubnetId,
+			VpcID:      networkInterface.VpcId,
+		})
+	}
+
+	return entities.AwsCloudData{
+		InstanceID:        awsMetadata.InstanceId,
+		InstanceType:      awsMetadata.InstanceType,
+		Region:            awsMetadata.Region,
+		AvailabilityZone:  awsMetadata.AvailabilityZone,
+		AccountID:         awsMetadata.AccountId,
+		AmiID:             awsMetadata.AmiId,
+		NetworkInterfaces: networkInterfaces,
+		Tags:              awsMetadata.Tags,
+	}
+}
+
+func parseGcpCloudData(metadata interface{}) entities.GcpCloudData {
+	var gcpMetadata cloud.GcpMetadata
+
+	err := mapstructure.Decode(metadata, &gcpMetadata)
+	if err != nil {
+		log.Errorf("can't decode gcp metadata: %s", err)
+		return entities.GcpCloudData{}
+	}
+
+	var disks []entities.GcpDisk
+	for _, disk := range gcpMetadata.Instance.Disks {
+		disks = append(disks, entities.GcpDisk{
+			Name: disk.DeviceName,
+			Type: disk.Type,
+			Mode: disk.Mode,
+		})
+	}
+
+	var networkInterfaces []entities.GcpNetworkInterface
+	for _, networkInterface := range gcpMetadata.Instance.NetworkInterfaces {
+		var externalIPs []string
+		for _, accessConfig := range networkInterface.AccessConfigs {
+			if accessConfig.ExternalIp != "" {
+				externalIPs = append(externalIPs, accessConfig.ExternalIp)
+			}
+		}
+
+		networkInterfaces = append(networkInterfaces, entities.GcpNetworkInterface{
+			Network:     path.Base(networkInterface.Network),
+			IP:          networkInterface.Ip,
+			MacAddress:  networkInterface.Mac,
+			ExternalIPs: externalIPs,
+		})
+	}
+
+	// Zone, machine type and image are resource paths, e.g. projects/123456789012/zones/europe-west1-b
+	return entities.GcpCloudData{
+		InstanceName:      gcpMetadata.Instance.Name,
+		ProjectID:         gcpMetadata.Project.ProjectId,
+		Zone:              path.Base(gcpMetadata.Instance.Zone),
+		MachineType:       path.Base(gcpMetadata.Instance.MachineType),
+		Image:             path.Base(gcpMetadata.Instance.Image),
+		Disks:             disks,
+		NetworkInterfaces: networkInterfaces,
+		Tags:              gcpMetadata.Instance.Tags,
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/suite"
 	"github.com/trento-project/trento/agent/discovery/mocks"
+	"github.com/trento-project/trento/internal/tuning"
 	_ "github.com/trento-project/trento/test"
 	"github.com/trento-project/trento/test/helpers"
 	"github.com/trento-project/trento/web/entities"
@@ -106,6 +107,104 @@ func (s *HostsProjectorTestSuite) Test_CloudDiscoveryHandler() {
 	}, projectedAzureCloudData)
 }
 
+// Test_CloudDiscoveryHandler_Aws tests the CloudDiscoveryHandler function execution on an AWS CloudDiscovery published by an agent
+func (s *HostsProjectorTestSuite) Test_CloudDiscoveryHandler_Aws() {
+	discoveredCloudMock := mocks.NewDiscoveredAwsCloudMock()
+
+	requestBody, _ := json.Marshal(discoveredCloudMock)
+
+	hostsProjector_CloudDiscoveryHandler(&DataCollectedEvent{
+		ID:            1,
+		AgentID:       "agent_id",
+		DiscoveryType: CloudDiscovery,
+		Payload:       requestBody,
+	}, s.tx)
+
+	var projectedHost entities.Host
+	s.tx.First(&projectedHost)
+
+	s.Equal("aws", projectedHost.CloudProvider)
+
+	var projectedAwsCloudData entities.AwsCloudData
+	err := json.Unmarshal(projectedHost.CloudData, &projectedAwsCloudData)
+
+	s.NoError(err)
+	s.EqualValues(entities.AwsCloudData{
+		InstanceID:       "i-0123456789abcdef0",
+		InstanceType:     "r5.4xlarge",
+		Region:           "eu-central-1",
+		AvailabilityZone: "eu-central-1a",
+		AccountID:        "123456789012",
+		AmiID:            "ami-0a1b2c3d4e5f6a7b8",
+		NetworkInterfaces: []entities.AwsNetworkInterface{
+			{
+				ID:         "eni-0123456789abcdef0",
+				MacAddress: "0a:1b:2c:3d:4e:5f",
+				PrivateIPs: []string{"10.0.1.10", "10.0.1.11"},
+				PublicIPs:  []string{"3.120.1.10"},
+				SubnetID:   "subnet-0123456789abcdef0",
+				VpcID:      "vpc-0123456789abcdef0",
+			},
+		},
+		Tags: map[string]string{
+			"Name":     "vmhana01",
+			"workload": "sap",
+		},
+	}, projectedAwsCloudData)
+}
+
+// Test_CloudDiscoveryHandler_Gcp tests the CloudDiscoveryHandler function execution on a GCP CloudDiscovery published by an agent
+func (s *HostsProjectorTestSuite) Test_CloudDiscoveryHandler_Gcp() {
+	discoveredCloudMock := mocks.NewDiscoveredGcpCloudMock()
+
+	requestBody, _ := json.Marshal(discoveredCloudMock)
+
+	hostsProjector_CloudDiscoveryHandler(&DataCollectedEvent{
+		ID:            1,
+		AgentID:       "agent_id",
+		DiscoveryType: CloudDiscovery,
+		Payload:       requestBody,
+	}, s.tx)
+
+	var projectedHost entities.Host
+	s.tx.First(&projectedHost)
+
+	s.Equal("gcp", projectedHost.CloudProvider)
+
+	var projectedGcpCloudData entities.GcpCloudData
+	err := json.Unmarshal(projectedHost.CloudData, &projectedGcpCloudData)
+
+	s.NoError(err)
+	s.EqualValues(entities.GcpCloudData{
+		InstanceName: "vmhana01",
+		ProjectID:    "sap-landscape",
+		Zone:         "europe-west1-b",
+		MachineType:  "n1-highmem-32",
+		Image:        "sles-15-sp2-sap-v20210604",
+		Disks: []entities.GcpDisk{
+			{
+				Name: "persistent-disk-0",
+				Type: "PERSISTENT",
+				Mode: "READ_WRITE",
+			},
+			{
+				Name: "vmhana01-hana-data",
+				Type: "PERSISTENT-SSD",
+				Mode: "READ_WRITE",
+			},
+		},
+		NetworkInterfaces: []entities.GcpNetworkInterface{
+			{
+				Network:     "sap-network",
+				IP:          "10.0.0.10",
+				MacAddress:  "42:01:0a:00:00:0a",
+				ExternalIPs: []string{"34.77.10.10"},
+			},
+		},
+		Tags: []string{"hana", "sap"},
+	}, projectedGcpCloudData)
+}
+
 func (s *HostsProjectorTestSuite) Test_parseAzureCloudData_Empty() {
 	azureCloudData := parseAzureCloudData(struct{}{})
 	s.EqualValues(entities.AzureCloudData{}, azureCloudData)
@@ -134,6 +233,55 @@ func (s *HostsProjectorTestSuite) Test_ClusterDiscoveryHandler() {
 	s.Equal("", projectedHost.CloudProvider)
 }
 
+// Test_TuningDiscoveryHandler tests the TuningDiscoveryHandler function execution on a TuningDiscovery published by an agent
+func (s *HostsProjectorTestSuite) Test_TuningDiscoveryHandler() {
+	discoveredTuningMock := mocks.NewDiscoveredTuningMock()
+
+	requestBody, _ := json.Marshal(discoveredTuningMock)
+	err := hostsProjector_TuningDiscoveryHandler(&DataCollectedEvent{
+		ID:            1,
+		AgentID:       "agent_id",
+		DiscoveryType: TuningDiscovery,
+		Payload:       requestBody,
+	}, s.tx)
+	s.NoError(err)
+
+	var projectedHost entities.Host
+	s.tx.First(&projectedHost)
+
+	s.Equal("", projectedHost.Name)
+
+	var projectedTuning entities.HostTuning
+	err = json.Unmarshal(projectedHost.Tuning, &projectedTuning)
+
+	s.NoError(err)
+	s.EqualValues(entities.HostTuning{
+		Sysctl: map[string]string{
+			"kernel.numa_balancing":              "0",
+			"kernel.sem":                         "32000 1024000000 500 32000",
+			"net.ipv4.tcp_slow_start_after_idle": "0",
+			"vm.dirty_background_bytes":          "314572",
+			"vm.dirty_bytes":                     "629145",
+			"vm.max_map_count":                   "2147483647",
+			"vm.swappiness":                      "10",
+		},
+		TransparentHugepages: "never",
+		CPUGovernors:         []string{"performance"},
+		Saptune: &entities.SaptuneState{
+			Version:   "3",
+			Active:    true,
+			Solutions: []string{"HANA"},
+			Notes:     []string{"941735", "1771258", "1980196", "2578899", "2684254", "2382421"},
+		},
+		SapconfActive: false,
+	}, projectedTuning)
+}
+
+func (s *HostsProjectorTestSuite) Test_parseTuning_NoSaptune() {
+	hostTuning := parseTuning(&tuning.Tuning{SapconfActive: true})
+	s.EqualValues(&entities.HostTuning{SapconfActive: true}, hostTuning)
+}
+
 // Test_HostsProjector tests the HostsProjector projects all of the discoveries it is interested in, resulting in a single host readmodel
 func (s *HostsProjectorTestSuite) Test_TelemetryProjector() {
 	hostsProjector := NewHostsProjector(s.tx)
@@ -0,0 +1,60 @@
+package datapipeline
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/internal/packages"
+	"github.com/trento-project/trento/web/entities"
+	"gorm.io/gorm"
+)
+
+func NewPackagesProjector(db *gorm.DB) *projector {
+	packagesProjector := NewProjector("packages", db)
+
+	packagesProjector.AddHandler(PackagesDiscovery, packagesProjector_PackagesDiscoveryHandler)
+
+	return packagesProjector
+}
+
+// packagesProjector_PackagesDiscoveryHandler replaces the package inventory of the host,
+// as the agent only publishes it again when some package changed
+func packagesProjector_PackagesDiscoveryHandler(dataCollectedEvent *DataCollectedEvent, db *gorm.DB) error {
+	decoder := getPayloadDecoder(dataCollectedEvent.Payload)
+
+	var discoveredPackages packages.Packages
+	if err := decoder.Decode(&discoveredPackages); err != nil {
+		log.Errorf("can't decode data: %s", err)
+		return err
+	}
+
+	var packageEntities []entities.HostPackage
+
+	for _, p := range discoveredPackages {
+		packageEntity := entities.HostPackage{
+			AgentID: dataCollectedEvent.AgentID,
+			Name:    p.Name,
+			Arch:    p.Arch,
+			Version: p.Version,
+			Release: p.Release,
+		}
+
+		if p.InstallTime > 0 {
+			packageEntity.InstallTime = time.Unix(p.InstallTime, 0).UTC()
+		}
+
+		packageEntities = append(packageEntities, packageEntity)
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("agent_id", dataCollectedEvent.AgentID).Delete(&entities.HostPackage{}).Error; err != nil {
+			return err
+		}
+		if len(packageEntities) > 0 {
+			return tx.CreateInBatches(&packageEntities, 500).Error
+		}
+
+		return nil
+	})
+}
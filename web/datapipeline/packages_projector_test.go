@@ -0,0 +1,105 @@
+package datapipeline
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	_ "github.com/trento-project/trento/test"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type PackagesProjectorTestSuite struct {
+	suite.Suite
+	db *gorm.DB
+	tx *gorm.DB
+}
+
+func TestPackagesProjectorTestSuite(t *testing.T) {
+	suite.Run(t, new(PackagesProjectorTestSuite))
+}
+
+func (suite *PackagesProjectorTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&Subscription{}, &entities.HostPackage{})
+}
+
+func (suite *PackagesProjectorTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(Subscription{}, entities.HostPackage{})
+}
+
+func (suite *PackagesProjectorTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+
+	suite.tx.Create(&entities.HostPackage{
+		AgentID: "779cdd70-e9e2-58ca-b18a-bf3eb3f71244",
+		Name:    "pacemaker",
+		Arch:    "x86_64",
+		Version: "2.0.3+20200511.2b248d828",
+		Release: "1.10",
+	})
+
+	suite.tx.Create(&entities.HostPackage{
+		AgentID: "879cdd70-e9e2-58ca-b18a-bf3eb3f71244",
+		Name:    "pacemaker",
+		Arch:    "x86_64",
+		Version: "2.0.4+20200616.2deceaa3a",
+		Release: "3.9.1",
+	})
+}
+
+func (suite *PackagesProjectorTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func loadPackagesDiscoveryEvent() *DataCollectedEvent {
+	jsonFile, err := os.Open("./test/fixtures/discovery/packages/expected_published_packages_discovery.json")
+	if err != nil {
+		panic(err)
+	}
+	byteValue, _ := ioutil.ReadAll(jsonFile)
+	var dataCollectedEvent *DataCollectedEvent
+	json.Unmarshal(byteValue, &dataCollectedEvent)
+
+	return dataCollectedEvent
+}
+
+func (suite *PackagesProjectorTestSuite) Test_PackagesProjector() {
+	err := packagesProjector_PackagesDiscoveryHandler(loadPackagesDiscoveryEvent(), suite.tx)
+	suite.NoError(err)
+
+	var projectedPackages []*entities.HostPackage
+	suite.tx.Where("agent_id", "779cdd70-e9e2-58ca-b18a-bf3eb3f71244").Order("name").Find(&projectedPackages)
+	suite.Equal(6, len(projectedPackages))
+
+	var pacemaker entities.HostPackage
+	suite.tx.Where("agent_id = ? AND name = ?", "779cdd70-e9e2-58ca-b18a-bf3eb3f71244", "pacemaker").First(&pacemaker)
+
+	suite.Equal("2.0.4+20200616.2deceaa3a", pacemaker.Version)
+	suite.Equal("3.9.1", pacemaker.Release)
+	suite.Equal("x86_64", pacemaker.Arch)
+	suite.Equal(time.Unix(1631886094, 0).UTC(), pacemaker.InstallTime.UTC())
+}
+
+func (suite *PackagesProjectorTestSuite) Test_PackagesProjectorDelete() {
+	dataCollectedEvent := loadPackagesDiscoveryEvent()
+	packagesProjector_PackagesDiscoveryHandler(dataCollectedEvent, suite.tx)
+
+	// Send a new discovery with empty data
+	dataCollectedEvent.Payload = datatypes.JSON([]byte(`[]`))
+	packagesProjector_PackagesDiscoveryHandler(dataCollectedEvent, suite.tx)
+
+	var count int64
+	suite.tx.Table("host_packages").Where("agent_id", "779cdd70-e9e2-58ca-b18a-bf3eb3f71244").Count(&count)
+	suite.Equal(int64(0), count)
+
+	suite.tx.Table("host_packages").Where("agent_id", "879cdd70-e9e2-58ca-b18a-bf3eb3f71244").Count(&count)
+	suite.Equal(int64(1), count)
+}
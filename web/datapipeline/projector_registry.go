@@ -12,5 +12,6 @@ func InitProjectorsRegistry(db *gorm.DB) ProjectorRegistry {
 		NewHostTelemetryProjector(db),
 		NewSlesSubscriptionsProjector(db),
 		NewSAPSystemsProjector(db),
+		NewPackagesProjector(db),
 	}
 }
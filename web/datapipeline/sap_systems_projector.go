@@ -1,16 +1,26 @@
 package datapipeline
 
 import (
+	"encoding/json"
 	"fmt"
+	"path"
+	"regexp"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/trento-project/trento/internal/sapsystem"
+	"github.com/trento-project/trento/internal/sapsystem/sapcontrol"
 	"github.com/trento-project/trento/web/entities"
 	"github.com/trento-project/trento/web/models"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
+// The version info of the SAP executables starts with the kernel release and the patch level,
+// e.g. "753, patch 900, changelist 2011573, ..."
+var sapKernelVersionPattern = regexp.MustCompile(`^(\d+), patch (\d+)`)
+
 func NewSAPSystemsProjector(db *gorm.DB) *projector {
 	SAPSystemsProjector := NewProjector("sapsystems", db)
 
@@ -35,6 +45,16 @@ func SAPSystemsProjector_SAPSystemsDiscoveryHandler(dataCollectedEvent *DataColl
 			Error
 	}
 
+	queueOverloads, err := loadSAPQueueOverloads(db, dataCollectedEvent.AgentID)
+	if err != nil {
+		return err
+	}
+
+	collectedAt := dataCollectedEvent.CreatedAt
+	if collectedAt.IsZero() {
+		collectedAt = time.Now()
+	}
+
 	for _, s := range discoveredSAPSystems {
 		var sapSystemType, dbHost, dbName, dbAddress string
 		var tenants []string
@@ -93,6 +113,14 @@ func SAPSystemsProjector_SAPSystemsDiscoveryHandler(dataCollectedEvent *DataColl
 			instance.SystemReplication = parseReplicationMode(i.SystemReplication)
 			instance.SystemReplicationStatus = parseReplicationStatus(i.SystemReplication)
 			addSAPControlData(&instance, i.SAPControl)
+			instance.KernelRelease, instance.KernelPatch = parseSAPKernelVersion(i.SAPControl.Versions)
+			instance.Versions = parseSAPInstanceVersions(i.SAPControl.Versions)
+			instance.Alerts = parseSAPInstanceAlerts(i.SAPControl.Alerts)
+			instance.Queues = parseSAPInstanceQueues(i.SAPControl.Queues)
+			instance.WorkProcesses = parseSAPInstanceWorkProcesses(i.SAPControl.WorkProcesses)
+			instance.QueueOverloadedSince = parseSAPQueueOverloadedSince(
+				i.SAPControl.Queues, queueOverloads[sapInstanceKey(s.Id, instanceNumber)], collectedAt)
+			instance.HAConfig = parseSAPInstanceHAConfig(i.SAPControl)
 
 			instances = append(instances, instance)
 		}
@@ -102,7 +130,8 @@ func SAPSystemsProjector_SAPSystemsDiscoveryHandler(dataCollectedEvent *DataColl
 			"id", "sid", "type", "features", "instance_number",
 			"system_replication", "system_replication_status",
 			"sap_hostname", "start_priority", "http_port", "https_port", "status",
-			"tenants", "db_host", "db_name", "db_address")
+			"tenants", "db_host", "db_name", "db_address", "kernel_release", "kernel_patch", "versions", "alerts",
+			"queues", "work_processes", "queue_overloaded_since", "ha_config")
 		if err != nil {
 			return err
 		}
@@ -189,3 +218,214 @@ func addSAPControlData(instance *entities.SAPSystemInstance, sapControl *sapsyst
 		}
 	}
 }
+
+// parseSAPKernelVersion returns the kernel release and patch level of an instance.
+// The disp+work executable is the reference for the kernel, otherwise the first executable with a
+// parsable version is used, e.g. sapstartsrv in HANA instances
+func parseSAPKernelVersion(versions []*sapcontrol.InstanceVersionInfo) (string, string) {
+	var release, patch string
+
+	for _, v := range versions {
+		matches := sapKernelVersionPattern.FindStringSubmatch(v.VersionInfo)
+		if matches == nil {
+			continue
+		}
+
+		if path.Base(v.Filename) == "disp+work" {
+			return matches[1], matches[2]
+		}
+
+		if release == "" {
+			release, patch = matches[1], matches[2]
+		}
+	}
+
+	return release, patch
+}
+
+func parseSAPInstanceVersions(versions []*sapcontrol.InstanceVersionInfo) datatypes.JSON {
+	if len(versions) == 0 {
+		return nil
+	}
+
+	var componentVersions []*entities.SAPInstanceComponentVersion
+	for _, v := range versions {
+		componentVersions = append(componentVersions, &entities.SAPInstanceComponentVersion{
+			Name:     path.Base(v.Filename),
+			Filename: v.Filename,
+			Version:  v.VersionInfo,
+			Time:     v.Time,
+		})
+	}
+
+	data, err := json.Marshal(componentVersions)
+	if err != nil {
+		log.Errorf("can't marshal the SAP instance versions: %s", err)
+		return nil
+	}
+
+	return data
+}
+
+func parseSAPInstanceAlerts(alerts []*sapsystem.Alert) datatypes.JSON {
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	var sapInstanceAlerts []*entities.SAPInstanceAlert
+	for _, a := range alerts {
+		sapInstanceAlerts = append(sapInstanceAlerts, &entities.SAPInstanceAlert{
+			Object:      a.Object,
+			Attribute:   a.Attribute,
+			Value:       string(a.Value),
+			Description: a.Description,
+			Time:        a.Time,
+		})
+	}
+
+	data, err := json.Marshal(sapInstanceAlerts)
+	if err != nil {
+		log.Errorf("can't marshal the SAP instance alerts: %s", err)
+		return nil
+	}
+
+	return data
+}
+
+// loadSAPQueueOverloads returns since when the dispatcher queues of the instances of an agent are overloaded
+func loadSAPQueueOverloads(db *gorm.DB, agentID string) (map[string]*time.Time, error) {
+	var instances []entities.SAPSystemInstance
+
+	err := db.
+		Select("id", "instance_number", "queue_overloaded_since").
+		Where("agent_id = ? AND queue_overloaded_since IS NOT NULL", agentID).
+		Find(&instances).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	queueOverloads := make(map[string]*time.Time)
+	for _, i := range instances {
+		queueOverloads[sapInstanceKey(i.ID, i.InstanceNumber)] = i.QueueOverloadedSince
+	}
+
+	return queueOverloads, nil
+}
+
+func sapInstanceKey(sapSystemID string, instanceNumber string) string {
+	return fmt.Sprintf("%s/%s", sapSystemID, instanceNumber)
+}
+
+// parseSAPQueueOverloadedSince returns since when any of the dispatcher queues is above the usage threshold,
+// keeping the previous value while the queues stay overloaded, or nil if none of them is overloaded
+func parseSAPQueueOverloadedSince(queues []*sapcontrol.TaskHandlerQueue, overloadedSince *time.Time, collectedAt time.Time) *time.Time {
+	for _, q := range queues {
+		queue := models.SAPInstanceQueue{Now: int(q.Now), Max: int(q.Max)}
+		if !queue.IsOverloaded() {
+			continue
+		}
+
+		if overloadedSince != nil {
+			return overloadedSince
+		}
+
+		return &collectedAt
+	}
+
+	return nil
+}
+
+func parseSAPInstanceQueues(queues []*sapcontrol.TaskHandlerQueue) datatypes.JSON {
+	if len(queues) == 0 {
+		return nil
+	}
+
+	var sapInstanceQueues []*entities.SAPInstanceQueue
+	for _, q := range queues {
+		sapInstanceQueues = append(sapInstanceQueues, &entities.SAPInstanceQueue{
+			Type: q.Typ,
+			Now:  int(q.Now),
+			High: int(q.High),
+			Max:  int(q.Max),
+		})
+	}
+
+	data, err := json.Marshal(sapInstanceQueues)
+	if err != nil {
+		log.Errorf("can't marshal the SAP instance queues: %s", err)
+		return nil
+	}
+
+	return data
+}
+
+// parseSAPInstanceWorkProcesses returns the number of work processes by type, and how many of them
+// are busy running or holding a request
+func parseSAPInstanceWorkProcesses(workProcesses []*sapsystem.WorkProcesses) datatypes.JSON {
+	if len(workProcesses) == 0 {
+		return nil
+	}
+
+	var sapInstanceWorkProcesses []*entities.SAPInstanceWorkProcesses
+	for _, w := range workProcesses {
+		sapInstanceWorkProcesses = append(sapInstanceWorkProcesses, &entities.SAPInstanceWorkProcesses{
+			Type:  w.Type,
+			Total: w.Total,
+			Busy:  w.Busy,
+		})
+	}
+
+	data, err := json.Marshal(sapInstanceWorkProcesses)
+	if err != nil {
+		log.Errorf("can't marshal the SAP instance work processes: %s", err)
+		return nil
+	}
+
+	return data
+}
+
+// parseSAPInstanceHAConfig returns the SAP HA interface configuration and checks of an instance,
+// or nil if the instance doesn't have any HA interface data
+func parseSAPInstanceHAConfig(sapControl *sapsystem.SAPControl) datatypes.JSON {
+	if sapControl.HAFailoverConfig == nil && sapControl.HAChecks == nil && sapControl.HAFailoverChecks == nil {
+		return nil
+	}
+
+	haConfig := &entities.SAPInstanceHAConfig{
+		Checks:         parseSAPInstanceHAChecks(sapControl.HAChecks),
+		FailoverChecks: parseSAPInstanceHAChecks(sapControl.HAFailoverChecks),
+	}
+
+	if c := sapControl.HAFailoverConfig; c != nil {
+		haConfig.Active = c.HAActive
+		haConfig.ProductVersion = c.HAProductVersion
+		haConfig.SAPInterfaceVersion = c.HASAPInterfaceVersion
+		haConfig.Documentation = c.HADocumentation
+		haConfig.ActiveNode = c.HAActiveNode
+		haConfig.Nodes = c.HANodes
+	}
+
+	data, err := json.Marshal(haConfig)
+	if err != nil {
+		log.Errorf("can't marshal the SAP HA config: %s", err)
+		return nil
+	}
+
+	return data
+}
+
+func parseSAPInstanceHAChecks(checks []*sapcontrol.HACheck) []*entities.SAPInstanceHACheck {
+	var haChecks []*entities.SAPInstanceHACheck
+	for _, c := range checks {
+		haChecks = append(haChecks, &entities.SAPInstanceHACheck{
+			State:       string(c.State),
+			Category:    string(c.Category),
+			Description: c.Description,
+			Comment:     c.Comment,
+		})
+	}
+
+	return haChecks
+}
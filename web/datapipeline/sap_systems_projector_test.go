@@ -3,10 +3,14 @@ package datapipeline
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"github.com/trento-project/trento/agent/discovery/mocks"
+	"github.com/trento-project/trento/internal/sapsystem"
+	"github.com/trento-project/trento/internal/sapsystem/sapcontrol"
 	"github.com/trento-project/trento/test/helpers"
 	"github.com/trento-project/trento/web/entities"
 	"github.com/trento-project/trento/web/models"
@@ -167,6 +171,30 @@ func (s *SAPSystemsProjectorTestSuite) Test_SAPSystemDiscoveryHandler_Applicatio
 	s.Equal("3", projectedSAPSystemInstance.StartPriority)
 	s.Equal(50213, projectedSAPSystemInstance.HttpPort)
 	s.Equal(50214, projectedSAPSystemInstance.HttpsPort)
+	s.Equal("753", projectedSAPSystemInstance.KernelRelease)
+	s.Equal("900", projectedSAPSystemInstance.KernelPatch)
+
+	instanceVersion := projectedSAPSystemInstance.VersionToModel()
+	s.Equal(2, len(instanceVersion.Components))
+	s.Equal("disp+work", instanceVersion.Components[0].Name)
+
+	alerts := projectedSAPSystemInstance.AlertsToModel()
+	s.Equal(1, len(alerts))
+	s.Equal("ResponseTime", alerts[0].Attribute)
+	s.Equal(models.SAPSystemHealthWarning, alerts[0].Health())
+
+	s.Equal(3, len(projectedSAPSystemInstance.QueuesToModel()))
+	s.Equal([]*models.SAPInstanceWorkProcesses{
+		{Type: "DIA", Total: 2, Busy: 1},
+		{Type: "BTC", Total: 1, Busy: 0},
+	}, projectedSAPSystemInstance.WorkProcessesToModel())
+	s.Nil(projectedSAPSystemInstance.QueueOverloadedSince)
+
+	haConfig := projectedSAPSystemInstance.HAConfigToModel()
+	s.True(haConfig.Active)
+	s.Equal([]string{"sapha1aas1", "sapha1aas2"}, haConfig.Nodes)
+	s.Equal(2, len(haConfig.Checks))
+	s.Equal(1, len(haConfig.FailoverChecks))
 }
 
 func (s *SAPSystemsProjectorTestSuite) Test_SAPSystemDiscoveryHandler_Diagnostics() {
@@ -185,3 +213,178 @@ func (s *SAPSystemsProjectorTestSuite) Test_SAPSystemDiscoveryHandler_Diagnostic
 
 	s.Equal(int64(0), result.RowsAffected)
 }
+
+func TestParseSAPKernelVersion(t *testing.T) {
+	versions := []*sapcontrol.InstanceVersionInfo{
+		{
+			Filename:    "/usr/sap/HA1/D02/exe/sapstartsrv",
+			VersionInfo: "753, patch 800, changelist 1999127, RKS compatibility level 1, optimized, opt (Jul 15 2020, 16:15:01), linuxx86_64",
+		},
+		{
+			Filename:    "/usr/sap/HA1/D02/exe/disp+work",
+			VersionInfo: "753, patch 900, changelist 2011573, RKS compatibility level 1, optimized, opt (Oct 27 2020, 13:35:59), linuxx86_64",
+		},
+	}
+
+	release, patch := parseSAPKernelVersion(versions)
+	assert.Equal(t, "753", release)
+	assert.Equal(t, "900", patch)
+
+	release, patch = parseSAPKernelVersion(versions[:1])
+	assert.Equal(t, "753", release)
+	assert.Equal(t, "800", patch)
+
+	release, patch = parseSAPKernelVersion([]*sapcontrol.InstanceVersionInfo{
+		{Filename: "/usr/sap/HA1/D02/exe/disp+work", VersionInfo: "unknown"},
+	})
+	assert.Equal(t, "", release)
+	assert.Equal(t, "", patch)
+}
+
+func TestParseSAPInstanceVersions(t *testing.T) {
+	versions := []*sapcontrol.InstanceVersionInfo{
+		{
+			Filename:    "/usr/sap/HA1/D02/exe/disp+work",
+			VersionInfo: "753, patch 900, changelist 2011573",
+			Time:        "2020 10 27 13:35:59",
+		},
+	}
+
+	var componentVersions []*entities.SAPInstanceComponentVersion
+	err := json.Unmarshal(parseSAPInstanceVersions(versions), &componentVersions)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*entities.SAPInstanceComponentVersion{
+		{
+			Name:     "disp+work",
+			Filename: "/usr/sap/HA1/D02/exe/disp+work",
+			Version:  "753, patch 900, changelist 2011573",
+			Time:     "2020 10 27 13:35:59",
+		},
+	}, componentVersions)
+
+	assert.Nil(t, parseSAPInstanceVersions(nil))
+}
+
+func TestParseSAPInstanceAlerts(t *testing.T) {
+	alerts := []*sapsystem.Alert{
+		{
+			Object:      "Dialog",
+			Attribute:   "ResponseTime",
+			Value:       sapcontrol.STATECOLOR_RED,
+			Description: "Dialog response time exceeds the threshold",
+			Time:        "2021 11 10 10:25:12",
+		},
+	}
+
+	var sapInstanceAlerts []*entities.SAPInstanceAlert
+	err := json.Unmarshal(parseSAPInstanceAlerts(alerts), &sapInstanceAlerts)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*entities.SAPInstanceAlert{
+		{
+			Object:      "Dialog",
+			Attribute:   "ResponseTime",
+			Value:       "SAPControl-RED",
+			Description: "Dialog response time exceeds the threshold",
+			Time:        "2021 11 10 10:25:12",
+		},
+	}, sapInstanceAlerts)
+
+	assert.Nil(t, parseSAPInstanceAlerts(nil))
+}
+
+func TestParseSAPQueueOverloadedSince(t *testing.T) {
+	collectedAt := time.Date(2021, 11, 10, 10, 30, 0, 0, time.UTC)
+	previous := time.Date(2021, 11, 10, 10, 0, 0, 0, time.UTC)
+
+	overloaded := []*sapcontrol.TaskHandlerQueue{
+		{Typ: "ABAP/NOWP", Now: 0, Max: 14000},
+		{Typ: "ABAP/DIA", Now: 12000, Max: 14000},
+	}
+	notOverloaded := []*sapcontrol.TaskHandlerQueue{
+		{Typ: "ABAP/DIA", Now: 100, Max: 14000},
+		{Typ: "ABAP/UPD", Now: 0, Max: 0},
+	}
+
+	assert.Equal(t, &collectedAt, parseSAPQueueOverloadedSince(overloaded, nil, collectedAt))
+	assert.Equal(t, &previous, parseSAPQueueOverloadedSince(overloaded, &previous, collectedAt))
+	assert.Nil(t, parseSAPQueueOverloadedSince(notOverloaded, &previous, collectedAt))
+	assert.Nil(t, parseSAPQueueOverloadedSince(nil, nil, collectedAt))
+}
+
+func TestParseSAPInstanceQueues(t *testing.T) {
+	queues := []*sapcontrol.TaskHandlerQueue{
+		{Typ: "ABAP/DIA", Now: 8, High: 10, Max: 14000, Writes: 2420, Reads: 2412},
+	}
+
+	var sapInstanceQueues []*entities.SAPInstanceQueue
+	err := json.Unmarshal(parseSAPInstanceQueues(queues), &sapInstanceQueues)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*entities.SAPInstanceQueue{
+		{Type: "ABAP/DIA", Now: 8, High: 10, Max: 14000},
+	}, sapInstanceQueues)
+
+	assert.Nil(t, parseSAPInstanceQueues(nil))
+}
+
+func TestParseSAPInstanceWorkProcesses(t *testing.T) {
+	workProcesses := []*sapsystem.WorkProcesses{
+		{Type: "DIA", Total: 3, Busy: 1},
+		{Type: "UPD", Total: 2, Busy: 1},
+	}
+
+	var sapInstanceWorkProcesses []*entities.SAPInstanceWorkProcesses
+	err := json.Unmarshal(parseSAPInstanceWorkProcesses(workProcesses), &sapInstanceWorkProcesses)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*entities.SAPInstanceWorkProcesses{
+		{Type: "DIA", Total: 3, Busy: 1},
+		{Type: "UPD", Total: 2, Busy: 1},
+	}, sapInstanceWorkProcesses)
+
+	assert.Nil(t, parseSAPInstanceWorkProcesses(nil))
+}
+
+func TestParseSAPInstanceHAConfig(t *testing.T) {
+	sapControl := &sapsystem.SAPControl{
+		HAFailoverConfig: &sapcontrol.HAFailoverConfig{
+			HAActive:              true,
+			HASAPInterfaceVersion: "sap_suse_cluster_connector 3.1.2",
+			HAActiveNode:          "vmnwp01",
+			HANodes:               []string{"vmnwp01", "vmnwp02"},
+		},
+		HAChecks: []*sapcontrol.HACheck{
+			{
+				State:       sapcontrol.HAVerificationStateError,
+				Category:    sapcontrol.HACheckCategorySAPState,
+				Description: "SAP system availability",
+				Comment:     "0 of 1 ABAP instances are available",
+			},
+		},
+	}
+
+	var haConfig entities.SAPInstanceHAConfig
+	err := json.Unmarshal(parseSAPInstanceHAConfig(sapControl), &haConfig)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.SAPInstanceHAConfig{
+		Active:              true,
+		SAPInterfaceVersion: "sap_suse_cluster_connector 3.1.2",
+		ActiveNode:          "vmnwp01",
+		Nodes:               []string{"vmnwp01", "vmnwp02"},
+		Checks: []*entities.SAPInstanceHACheck{
+			{
+				State:       "SAPControl-HA-ERROR",
+				Category:    "SAPControl-SAP-STATE",
+				Description: "SAP system availability",
+				Comment:     "0 of 1 ABAP instances are available",
+			},
+		},
+	}, haConfig)
+}
+
+func TestParseSAPInstanceHAConfig_NotDiscovered(t *testing.T) {
+	assert.Nil(t, parseSAPInstanceHAConfig(&sapsystem.SAPControl{}))
+}
@@ -0,0 +1,33 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+// AgentCertificate tracks the last certificate signing request of an agent and the client certificate issued to it
+type AgentCertificate struct {
+	AgentID      string `gorm:"primaryKey"`
+	Status       string
+	Request      string
+	Certificate  string
+	SerialNumber string
+	RequestedAt  time.Time
+	IssuedAt     *time.Time
+	NotAfter     *time.Time
+	RevokedAt    *time.Time
+}
+
+func (c *AgentCertificate) ToModel() *models.AgentCertificate {
+	return &models.AgentCertificate{
+		AgentID:      c.AgentID,
+		Status:       c.Status,
+		Certificate:  c.Certificate,
+		SerialNumber: c.SerialNumber,
+		RequestedAt:  c.RequestedAt,
+		IssuedAt:     c.IssuedAt,
+		NotAfter:     c.NotAfter,
+		RevokedAt:    c.RevokedAt,
+	}
+}
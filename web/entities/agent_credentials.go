@@ -0,0 +1,33 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+// EnrollmentToken is a one-time token an agent exchanges for its credentials.
+// Only the hash of the token is stored
+type EnrollmentToken struct {
+	TokenHash string `gorm:"primaryKey"`
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	UsedBy    string
+}
+
+// AgentCredentials holds the hash of the secret authenticating the requests of an enrolled agent
+type AgentCredentials struct {
+	AgentID    string `gorm:"primaryKey"`
+	SecretHash string
+	EnrolledAt time.Time
+	RevokedAt  *time.Time
+}
+
+func (c *AgentCredentials) ToModel() *models.AgentCredentials {
+	return &models.AgentCredentials{
+		AgentID:    c.AgentID,
+		EnrolledAt: c.EnrolledAt,
+		RevokedAt:  c.RevokedAt,
+	}
+}
@@ -22,23 +22,70 @@ type Cluster struct {
 }
 
 type HANAClusterDetails struct {
-	SystemReplicationMode          string             `json:"system_replication_mode"`
-	SystemReplicationOperationMode string             `json:"system_replication_operation_mode"`
-	SecondarySyncState             string             `json:"secondary_sync_state"`
-	SRHealthState                  string             `json:"sr_health_state"`
-	CIBLastWritten                 time.Time          `json:"cib_last_written"`
-	FencingType                    string             `json:"fencing_type"`
-	StoppedResources               []*ClusterResource `json:"stopped_resources"`
-	Nodes                          []*HANAClusterNode `json:"nodes"`
-	SBDDevices                     []*SBDDevice       `json:"sbd_devices"`
+	SystemReplicationMode          string                 `json:"system_replication_mode"`
+	SystemReplicationOperationMode string                 `json:"system_replication_operation_mode"`
+	SecondarySyncState             string                 `json:"secondary_sync_state"`
+	SRHealthState                  string                 `json:"sr_health_state"`
+	PrimarySite                    string                 `json:"primary_site"`
+	SecondarySite                  string                 `json:"secondary_site"`
+	CIBLastWritten                 time.Time              `json:"cib_last_written"`
+	FencingType                    string                 `json:"fencing_type"`
+	StoppedResources               []*ClusterResource     `json:"stopped_resources"`
+	FailedActions                  []*ClusterFailedAction `json:"failed_actions"`
+	Nodes                          []*HANAClusterNode     `json:"nodes"`
+	SBDDevices                     []*SBDDevice           `json:"sbd_devices"`
+	Corosync                       *ClusterCorosync       `json:"corosync"`
+}
+
+type ASCSERSClusterDetails struct {
+	EnsaVersion      string                 `json:"ensa_version"`
+	CIBLastWritten   time.Time              `json:"cib_last_written"`
+	FencingType      string                 `json:"fencing_type"`
+	StoppedResources []*ClusterResource     `json:"stopped_resources"`
+	FailedActions    []*ClusterFailedAction `json:"failed_actions"`
+	Nodes            []*HANAClusterNode     `json:"nodes"`
+	SBDDevices       []*SBDDevice           `json:"sbd_devices"`
+	Corosync         *ClusterCorosync       `json:"corosync"`
+	Instances        []*ASCSERSInstance     `json:"instances"`
+}
+
+type ASCSERSInstance struct {
+	Type                  string               `json:"type"`
+	SID                   string               `json:"sid"`
+	InstanceNumber        string               `json:"instance_number"`
+	VirtualHostname       string               `json:"virtual_hostname"`
+	ResourceID            string               `json:"resource_id"`
+	Group                 string               `json:"group"`
+	Node                  string               `json:"node"`
+	VirtualIPs            []string             `json:"virtual_ips"`
+	Filesystems           []*ClusterFilesystem `json:"filesystems"`
+	Halib                 string               `json:"halib"`
+	HalibClusterConnector string               `json:"halib_cluster_connector"`
+}
+
+type ClusterFilesystem struct {
+	ResourceID string `json:"resource_id"`
+	Device     string `json:"device"`
+	Directory  string `json:"directory"`
+	FSType     string `json:"fs_type"`
 }
 
 type ClusterResource struct {
-	ID        string `json:"id"`
-	Type      string `json:"type"`
-	Role      string `json:"role"`
-	Status    string `json:"status"`
-	FailCount int    `json:"fail_count"`
+	ID        string   `json:"id"`
+	Type      string   `json:"type"`
+	Role      string   `json:"role"`
+	Status    string   `json:"status"`
+	FailCount int      `json:"fail_count"`
+	Reasons   []string `json:"reasons"`
+}
+
+type ClusterFailedAction struct {
+	ResourceID   string `json:"resource_id"`
+	Task         string `json:"task"`
+	Node         string `json:"node"`
+	ExitStatus   string `json:"exit_status"`
+	ExitReason   string `json:"exit_reason"`
+	LastRCChange string `json:"last_rc_change"`
 }
 
 type HANAClusterNode struct {
@@ -48,6 +95,33 @@ type HANAClusterNode struct {
 	Resources  []*ClusterResource `json:"resources"`
 	VirtualIPs []string           `json:"virtual_ips"`
 	HANAStatus string             `json:"hana_status"`
+	// NameServerRole and IndexServerRole are the current roles of the node in a HANA scale-out system
+	NameServerRole  string `json:"name_server_role"`
+	IndexServerRole string `json:"index_server_role"`
+	MajorityMaker   bool   `json:"majority_maker"`
+}
+
+type ClusterCorosync struct {
+	Transport      string                 `json:"transport"`
+	Token          int                    `json:"token"`
+	Consensus      int                    `json:"consensus"`
+	QuorumProvider string                 `json:"quorum_provider"`
+	TwoNode        bool                   `json:"two_node"`
+	WaitForAll     bool                   `json:"wait_for_all"`
+	ExpectedVotes  int                    `json:"expected_votes"`
+	TotalVotes     int                    `json:"total_votes"`
+	Quorum         int                    `json:"quorum"`
+	Quorate        bool                   `json:"quorate"`
+	Rings          []int                  `json:"rings"`
+	Nodes          []*ClusterCorosyncNode `json:"nodes"`
+}
+
+type ClusterCorosyncNode struct {
+	NodeID        int      `json:"node_id"`
+	Name          string   `json:"name"`
+	RingAddresses []string `json:"ring_addresses"`
+	Votes         int      `json:"votes"`
+	Member        bool     `json:"member"`
 }
 
 type SBDDevice struct {
@@ -85,6 +159,11 @@ func (h *HANAClusterDetails) ToModel() *models.HANAClusterDetails {
 		stoppedResources = append(stoppedResources, r.ToModel())
 	}
 
+	var failedActions []*models.ClusterFailedAction
+	for _, f := range h.FailedActions {
+		failedActions = append(failedActions, f.ToModel())
+	}
+
 	var nodes []*models.HANAClusterNode
 	for _, n := range h.Nodes {
 		nodes = append(nodes, n.ToModel())
@@ -100,11 +179,80 @@ func (h *HANAClusterDetails) ToModel() *models.HANAClusterDetails {
 		SystemReplicationOperationMode: h.SystemReplicationOperationMode,
 		SecondarySyncState:             h.SecondarySyncState,
 		SRHealthState:                  h.SRHealthState,
+		PrimarySite:                    h.PrimarySite,
+		SecondarySite:                  h.SecondarySite,
 		CIBLastWritten:                 h.CIBLastWritten,
 		FencingType:                    h.FencingType,
 		StoppedResources:               stoppedResources,
+		FailedActions:                  failedActions,
 		Nodes:                          nodes,
 		SBDDevices:                     sbdDevices,
+		Corosync:                       h.Corosync.ToModel(),
+	}
+}
+
+func (a *ASCSERSClusterDetails) ToModel() *models.ASCSERSClusterDetails {
+	var stoppedResources []*models.ClusterResource
+	for _, r := range a.StoppedResources {
+		stoppedResources = append(stoppedResources, r.ToModel())
+	}
+
+	var failedActions []*models.ClusterFailedAction
+	for _, f := range a.FailedActions {
+		failedActions = append(failedActions, f.ToModel())
+	}
+
+	var nodes []*models.HANAClusterNode
+	for _, n := range a.Nodes {
+		nodes = append(nodes, n.ToModel())
+	}
+
+	var sbdDevices []*models.SBDDevice
+	for _, s := range a.SBDDevices {
+		sbdDevices = append(sbdDevices, s.ToModel())
+	}
+
+	var instances []*models.ASCSERSInstance
+	for _, i := range a.Instances {
+		instances = append(instances, i.ToModel())
+	}
+
+	return &models.ASCSERSClusterDetails{
+		EnsaVersion:      a.EnsaVersion,
+		CIBLastWritten:   a.CIBLastWritten,
+		FencingType:      a.FencingType,
+		StoppedResources: stoppedResources,
+		FailedActions:    failedActions,
+		Nodes:            nodes,
+		SBDDevices:       sbdDevices,
+		Corosync:         a.Corosync.ToModel(),
+		Instances:        instances,
+	}
+}
+
+func (i *ASCSERSInstance) ToModel() *models.ASCSERSInstance {
+	var filesystems []*models.ClusterFilesystem
+	for _, f := range i.Filesystems {
+		filesystems = append(filesystems, &models.ClusterFilesystem{
+			ResourceID: f.ResourceID,
+			Device:     f.Device,
+			Directory:  f.Directory,
+			FSType:     f.FSType,
+		})
+	}
+
+	return &models.ASCSERSInstance{
+		Type:                  i.Type,
+		SID:                   i.SID,
+		InstanceNumber:        i.InstanceNumber,
+		VirtualHostname:       i.VirtualHostname,
+		ResourceID:            i.ResourceID,
+		Group:                 i.Group,
+		Node:                  i.Node,
+		VirtualIPs:            i.VirtualIPs,
+		Filesystems:           filesystems,
+		Halib:                 i.Halib,
+		HalibClusterConnector: i.HalibClusterConnector,
 	}
 }
 
@@ -115,6 +263,50 @@ func (r *ClusterResource) ToModel() *models.ClusterResource {
 		Role:      r.Role,
 		Status:    r.Status,
 		FailCount: r.FailCount,
+		Reasons:   r.Reasons,
+	}
+}
+
+func (f *ClusterFailedAction) ToModel() *models.ClusterFailedAction {
+	return &models.ClusterFailedAction{
+		ResourceID:   f.ResourceID,
+		Task:         f.Task,
+		Node:         f.Node,
+		ExitStatus:   f.ExitStatus,
+		ExitReason:   f.ExitReason,
+		LastRCChange: f.LastRCChange,
+	}
+}
+
+func (c *ClusterCorosync) ToModel() *models.ClusterCorosync {
+	if c == nil {
+		return nil
+	}
+
+	var nodes []*models.ClusterCorosyncNode
+	for _, n := range c.Nodes {
+		nodes = append(nodes, &models.ClusterCorosyncNode{
+			NodeID:        n.NodeID,
+			Name:          n.Name,
+			RingAddresses: n.RingAddresses,
+			Votes:         n.Votes,
+			Member:        n.Member,
+		})
+	}
+
+	return &models.ClusterCorosync{
+		Transport:      c.Transport,
+		Token:          c.Token,
+		Consensus:      c.Consensus,
+		QuorumProvider: c.QuorumProvider,
+		TwoNode:        c.TwoNode,
+		WaitForAll:     c.WaitForAll,
+		ExpectedVotes:  c.ExpectedVotes,
+		TotalVotes:     c.TotalVotes,
+		Quorum:         c.Quorum,
+		Quorate:        c.Quorate,
+		Rings:          c.Rings,
+		Nodes:          nodes,
 	}
 }
 
@@ -132,11 +324,14 @@ func (n *HANAClusterNode) ToModel() *models.HANAClusterNode {
 	}
 
 	return &models.HANAClusterNode{
-		Name:       n.Name,
-		Site:       n.Site,
-		Attributes: n.Attributes,
-		Resources:  resources,
-		VirtualIPs: n.VirtualIPs,
-		HANAStatus: n.HANAStatus,
+		Name:            n.Name,
+		Site:            n.Site,
+		Attributes:      n.Attributes,
+		Resources:       resources,
+		VirtualIPs:      n.VirtualIPs,
+		HANAStatus:      n.HANAStatus,
+		NameServerRole:  n.NameServerRole,
+		IndexServerRole: n.IndexServerRole,
+		MajorityMaker:   n.MajorityMaker,
 	}
 }
@@ -1,6 +1,7 @@
 package entities
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/lib/pq"
@@ -20,15 +21,57 @@ type Host struct {
 	SAPSystemInstances SAPSystemInstances `gorm:"foreignkey:AgentID"`
 	AgentVersion       string
 	Heartbeat          *HostHeartbeat    `gorm:"foreignKey:AgentID"`
+	Sightings          []*AgentSighting  `gorm:"foreignKey:AgentID"`
 	Subscription       *SlesSubscription `gorm:"foreignKey:AgentID"`
 	Tags               []*models.Tag     `gorm:"polymorphic:Resource;polymorphicValue:hosts"`
 	UpdatedAt          time.Time
 	CloudData          datatypes.JSON
+	Tuning             datatypes.JSON
 }
 
 type HostHeartbeat struct {
-	AgentID   string `gorm:"primaryKey"`
-	UpdatedAt time.Time
+	AgentID     string `gorm:"primaryKey"`
+	UpdatedAt   time.Time
+	AgentStatus datatypes.JSON
+}
+
+// ToModel returns the agent status reported along with the heartbeat, nil if the agent did not report it
+func (h *HostHeartbeat) ToModel() *models.AgentStatus {
+	if len(h.AgentStatus) == 0 {
+		return nil
+	}
+
+	var agentStatus models.AgentStatus
+	if err := json.Unmarshal(h.AgentStatus, &agentStatus); err != nil {
+		return nil
+	}
+
+	return &agentStatus
+}
+
+// AgentSighting is a host reported by the agents sending heartbeats with an agent id, told apart by
+// its hostname and boot id, along with the last address they came from
+type AgentSighting struct {
+	AgentID  string `gorm:"primaryKey"`
+	Hostname string `gorm:"primaryKey"`
+	// BootID is the boot id of the host, empty for the older agents not reporting their status
+	BootID string `gorm:"primaryKey"`
+	// Origin is the address of the older agents not reporting their status, which tells them apart instead,
+	// empty for the others
+	Origin      string `gorm:"primaryKey"`
+	Address     string
+	LastSeenAt  time.Time
+	Alternating bool
+}
+
+func (s *AgentSighting) ToModel() *models.AgentSighting {
+	return &models.AgentSighting{
+		Hostname:    s.Hostname,
+		BootID:      s.BootID,
+		Address:     s.Address,
+		LastSeenAt:  s.LastSeenAt,
+		Alternating: s.Alternating,
+	}
 }
 
 type AzureCloudData struct {
@@ -42,6 +85,65 @@ type AzureCloudData struct {
 	AdminUsername   string `json:"admin_username"`
 }
 
+type AwsCloudData struct {
+	InstanceID        string                `json:"instance_id"`
+	InstanceType      string                `json:"instance_type"`
+	Region            string                `json:"region"`
+	AvailabilityZone  string                `json:"availability_zone"`
+	AccountID         string                `json:"account_id"`
+	AmiID             string                `json:"ami_id"`
+	NetworkInterfaces []AwsNetworkInterface `json:"network_interfaces"`
+	Tags              map[string]string     `json:"tags"`
+}
+
+type AwsNetworkInterface struct {
+	ID         string   `json:"id"`
+	MacAddress string   `json:"mac_address"`
+	PrivateIPs []string `json:"private_ips"`
+	PublicIPs  []string `json:"public_ips"`
+	SubnetID   string   `json:"subnet_id"`
+	VpcID      string   `json:"vpc_id"`
+}
+
+type GcpCloudData struct {
+	InstanceName      string                `json:"instance_name"`
+	ProjectID         string                `json:"project_id"`
+	Zone              string                `json:"zone"`
+	MachineType       string                `json:"machine_type"`
+	Image             string                `json:"image"`
+	Disks             []GcpDisk             `json:"disks"`
+	NetworkInterfaces []GcpNetworkInterface `json:"network_interfaces"`
+	Tags              []string              `json:"tags"`
+}
+
+type GcpDisk struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Mode string `json:"mode"`
+}
+
+type GcpNetworkInterface struct {
+	Network     string   `json:"network"`
+	IP          string   `json:"ip"`
+	MacAddress  string   `json:"mac_address"`
+	ExternalIPs []string `json:"external_ips"`
+}
+
+type HostTuning struct {
+	Sysctl               map[string]string `json:"sysctl"`
+	TransparentHugepages string            `json:"transparent_hugepages"`
+	CPUGovernors         []string          `json:"cpu_governors"`
+	Saptune              *SaptuneState     `json:"saptune"`
+	SapconfActive        bool              `json:"sapconf_active"`
+}
+
+type SaptuneState struct {
+	Version   string   `json:"version"`
+	Active    bool     `json:"active"`
+	Solutions []string `json:"solutions"`
+	Notes     []string `json:"notes"`
+}
+
 func (h *Host) ToModel() *models.Host {
 	// TODO: move to Tags entity when we will have it
 	var tags []string
@@ -49,16 +151,36 @@ func (h *Host) ToModel() *models.Host {
 		tags = append(tags, tag.Value)
 	}
 
+	var agentStatus *models.AgentStatus
+	if h.Heartbeat != nil {
+		agentStatus = h.Heartbeat.ToModel()
+	}
+
+	var sightings []*models.AgentSighting
+	for _, sighting := range h.Sightings {
+		sightings = append(sightings, sighting.ToModel())
+	}
+
+	var tuning *models.HostTuning
+	if len(h.Tuning) > 0 {
+		if err := json.Unmarshal(h.Tuning, &tuning); err != nil {
+			tuning = nil
+		}
+	}
+
 	return &models.Host{
-		ID:            h.AgentID,
-		Name:          h.Name,
-		IPAddresses:   h.IPAddresses,
-		CloudProvider: h.CloudProvider,
-		ClusterID:     h.ClusterID,
-		ClusterName:   h.ClusterName,
-		ClusterType:   h.ClusterType,
-		AgentVersion:  h.AgentVersion,
-		Tags:          tags,
-		SAPSystems:    h.SAPSystemInstances.ToModel(),
+		ID:             h.AgentID,
+		Name:           h.Name,
+		IPAddresses:    h.IPAddresses,
+		CloudProvider:  h.CloudProvider,
+		ClusterID:      h.ClusterID,
+		ClusterName:    h.ClusterName,
+		ClusterType:    h.ClusterType,
+		AgentVersion:   h.AgentVersion,
+		Tags:           tags,
+		SAPSystems:     h.SAPSystemInstances.ToModel(),
+		Tuning:         tuning,
+		AgentStatus:    agentStatus,
+		AgentSightings: sightings,
 	}
 }
@@ -0,0 +1,38 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+// HostPackage is an rpm package installed in a host. Several versions of the same package
+// can be installed at once, as it happens with the kernel
+type HostPackage struct {
+	AgentID     string `gorm:"primaryKey"`
+	Name        string `gorm:"primaryKey;index"`
+	Arch        string `gorm:"primaryKey"`
+	Version     string `gorm:"primaryKey"`
+	Release     string `gorm:"primaryKey"`
+	InstallTime time.Time
+	Host        *Host `gorm:"foreignKey:AgentID"`
+}
+
+func (p *HostPackage) ToModel() *models.HostPackage {
+	hostPackage := &models.HostPackage{
+		HostID:      p.AgentID,
+		Name:        p.Name,
+		Arch:        p.Arch,
+		Version:     p.Version,
+		Release:     p.Release,
+		InstallTime: p.InstallTime,
+	}
+
+	if p.Host != nil {
+		hostPackage.Hostname = p.Host.Name
+		hostPackage.ClusterID = p.Host.ClusterID
+		hostPackage.ClusterName = p.Host.ClusterName
+	}
+
+	return hostPackage
+}
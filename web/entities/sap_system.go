@@ -1,11 +1,13 @@
 package entities
 
 import (
+	"encoding/json"
 	"sort"
 	"time"
 
 	"github.com/lib/pq"
 	"github.com/trento-project/trento/web/models"
+	"gorm.io/datatypes"
 )
 
 type SAPSystemInstance struct {
@@ -26,12 +28,72 @@ type SAPSystemInstance struct {
 	DBHost                  string
 	DBName                  string
 	DBAddress               string
+	KernelRelease           string
+	KernelPatch             string
+	Versions                datatypes.JSON
+	Alerts                  datatypes.JSON
+	Queues                  datatypes.JSON
+	WorkProcesses           datatypes.JSON
+	QueueOverloadedSince    *time.Time
+	HAConfig                datatypes.JSON
 	Tenants                 pq.StringArray `gorm:"type:text[]"`
 	Host                    *Host          `gorm:"foreignKey:AgentID"`
 	UpdatedAt               time.Time
 	Tags                    []*models.Tag `gorm:"foreignKey:ResourceID"`
 }
 
+// SAPInstanceHAConfig is the configuration and the checks of the SAP HA interface of an instance
+type SAPInstanceHAConfig struct {
+	Active              bool                  `json:"active"`
+	ProductVersion      string                `json:"product_version"`
+	SAPInterfaceVersion string                `json:"sap_interface_version"`
+	Documentation       string                `json:"documentation"`
+	ActiveNode          string                `json:"active_node"`
+	Nodes               []string              `json:"nodes"`
+	Checks              []*SAPInstanceHACheck `json:"checks"`
+	FailoverChecks      []*SAPInstanceHACheck `json:"failover_checks"`
+}
+
+type SAPInstanceHACheck struct {
+	State       string `json:"state"`
+	Category    string `json:"category"`
+	Description string `json:"description"`
+	Comment     string `json:"comment"`
+}
+
+// SAPInstanceComponentVersion is the version of an executable of an instance, as reported by sapcontrol
+type SAPInstanceComponentVersion struct {
+	Name     string `json:"name"`
+	Filename string `json:"filename"`
+	Version  string `json:"version"`
+	Time     string `json:"time"`
+}
+
+// SAPInstanceAlert is a current CCMS alert of an instance
+type SAPInstanceAlert struct {
+	Object      string `json:"object"`
+	Attribute   string `json:"attribute"`
+	Value       string `json:"value"`
+	Description string `json:"description"`
+	Time        string `json:"time"`
+}
+
+// SAPInstanceQueue is the usage of a dispatcher queue of an ABAP instance
+type SAPInstanceQueue struct {
+	Type string `json:"type"`
+	Now  int    `json:"now"`
+	High int    `json:"high"`
+	Max  int    `json:"max"`
+}
+
+// SAPInstanceWorkProcesses is the number of work processes of a given type of an ABAP instance,
+// along with how many of them are busy
+type SAPInstanceWorkProcesses struct {
+	Type  string `json:"type"`
+	Total int    `json:"total"`
+	Busy  int    `json:"busy"`
+}
+
 type SAPSystemInstances []*SAPSystemInstance
 
 func (s SAPSystemInstances) ToModel() []*models.SAPSystem {
@@ -76,6 +138,13 @@ func (s SAPSystemInstances) ToModel() []*models.SAPSystem {
 			HttpsPort:               i.HttpsPort,
 			Type:                    i.Type,
 			SID:                     i.SID,
+			KernelRelease:           i.KernelRelease,
+			KernelPatch:             i.KernelPatch,
+			Alerts:                  i.AlertsToModel(),
+			Queues:                  i.QueuesToModel(),
+			WorkProcesses:           i.WorkProcessesToModel(),
+			QueueOverloadedSince:    i.QueueOverloadedSince,
+			HAConfig:                i.HAConfigToModel(),
 		}
 
 		if i.Host != nil {
@@ -106,6 +175,161 @@ func (s SAPSystemInstances) ToModel() []*models.SAPSystem {
 	return sapSystems
 }
 
+// VersionToModel returns the kernel and component versions of the instance
+func (i *SAPSystemInstance) VersionToModel() *models.SAPInstanceVersion {
+	instanceVersion := &models.SAPInstanceVersion{
+		SAPSystemID:    i.ID,
+		SID:            i.SID,
+		Type:           i.Type,
+		InstanceNumber: i.InstanceNumber,
+		HostID:         i.AgentID,
+		KernelRelease:  i.KernelRelease,
+		KernelPatch:    i.KernelPatch,
+		Components:     []*models.SAPComponentVersion{},
+	}
+
+	if i.Host != nil {
+		instanceVersion.Hostname = i.Host.Name
+	}
+
+	if len(i.Versions) == 0 {
+		return instanceVersion
+	}
+
+	var versions []*SAPInstanceComponentVersion
+	if err := json.Unmarshal(i.Versions, &versions); err != nil {
+		return instanceVersion
+	}
+
+	for _, v := range versions {
+		instanceVersion.Components = append(instanceVersion.Components, &models.SAPComponentVersion{
+			Name:    v.Name,
+			Version: v.Version,
+			Time:    v.Time,
+		})
+	}
+
+	return instanceVersion
+}
+
+// AlertsToModel returns the CCMS alerts of the instance
+func (i *SAPSystemInstance) AlertsToModel() []*models.SAPInstanceAlert {
+	if len(i.Alerts) == 0 {
+		return nil
+	}
+
+	var alerts []*SAPInstanceAlert
+	if err := json.Unmarshal(i.Alerts, &alerts); err != nil {
+		return nil
+	}
+
+	var sapInstanceAlerts []*models.SAPInstanceAlert
+	for _, a := range alerts {
+		sapInstanceAlerts = append(sapInstanceAlerts, &models.SAPInstanceAlert{
+			Object:      a.Object,
+			Attribute:   a.Attribute,
+			Value:       a.Value,
+			Description: a.Description,
+			Time:        a.Time,
+		})
+	}
+
+	return sapInstanceAlerts
+}
+
+// QueuesToModel returns the dispatcher queues of the instance
+func (i *SAPSystemInstance) QueuesToModel() []*models.SAPInstanceQueue {
+	if len(i.Queues) == 0 {
+		return nil
+	}
+
+	var queues []*SAPInstanceQueue
+	if err := json.Unmarshal(i.Queues, &queues); err != nil {
+		return nil
+	}
+
+	var sapInstanceQueues []*models.SAPInstanceQueue
+	for _, q := range queues {
+		sapInstanceQueues = append(sapInstanceQueues, &models.SAPInstanceQueue{
+			Type: q.Type,
+			Now:  q.Now,
+			High: q.High,
+			Max:  q.Max,
+		})
+	}
+
+	return sapInstanceQueues
+}
+
+// WorkProcessesToModel returns the work processes usage of the instance
+func (i *SAPSystemInstance) WorkProcessesToModel() []*models.SAPInstanceWorkProcesses {
+	if len(i.WorkProcesses) == 0 {
+		return nil
+	}
+
+	var workProcesses []*SAPInstanceWorkProcesses
+	if err := json.Unmarshal(i.WorkProcesses, &workProcesses); err != nil {
+		return nil
+	}
+
+	var sapInstanceWorkProcesses []*models.SAPInstanceWorkProcesses
+	for _, w := range workProcesses {
+		sapInstanceWorkProcesses = append(sapInstanceWorkProcesses, &models.SAPInstanceWorkProcesses{
+			Type:  w.Type,
+			Total: w.Total,
+			Busy:  w.Busy,
+		})
+	}
+
+	return sapInstanceWorkProcesses
+}
+
+// HAConfigToModel returns the SAP HA interface data of the instance, or nil if it was not discovered
+func (i *SAPSystemInstance) HAConfigToModel() *models.SAPInstanceHAConfig {
+	if len(i.HAConfig) == 0 {
+		return nil
+	}
+
+	var haConfig SAPInstanceHAConfig
+	if err := json.Unmarshal(i.HAConfig, &haConfig); err != nil {
+		return nil
+	}
+
+	return haConfig.ToModel()
+}
+
+func (c *SAPInstanceHAConfig) ToModel() *models.SAPInstanceHAConfig {
+	var checks []*models.SAPInstanceHACheck
+	for _, check := range c.Checks {
+		checks = append(checks, check.ToModel())
+	}
+
+	var failoverChecks []*models.SAPInstanceHACheck
+	for _, check := range c.FailoverChecks {
+		failoverChecks = append(failoverChecks, check.ToModel())
+	}
+
+	return &models.SAPInstanceHAConfig{
+		Active:              c.Active,
+		ProductVersion:      c.ProductVersion,
+		SAPInterfaceVersion: c.SAPInterfaceVersion,
+		Documentation:       c.Documentation,
+		ActiveNode:          c.ActiveNode,
+		Nodes:               c.Nodes,
+		Checks:              checks,
+		FailoverChecks:      failoverChecks,
+	}
+}
+
+func (c *SAPInstanceHACheck) ToModel() *models.SAPInstanceHACheck {
+	return &models.SAPInstanceHACheck{
+		State:       c.State,
+		Category:    c.Category,
+		Description: c.Description,
+		Comment:     c.Comment,
+	}
+}
+
 func sortBySID(sapSystems []*models.SAPSystem) {
 	sort.Slice(sapSystems, func(i, j int) bool {
 		return sapSystems[i].SID < sapSystems[j].SID
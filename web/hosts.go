@@ -1,6 +1,8 @@
 package web
 
 import (
+	"bytes"
+	"encoding/json"
 	"net/http"
 	"strconv"
 
@@ -93,7 +95,24 @@ func ApiHostHeartbeatHandler(hostService services.HostsService) gin.HandlerFunc
 	return func(c *gin.Context) {
 		agentID := c.Param("id")
 
-		err := hostService.Heartbeat(agentID)
+		body, err := c.GetRawData()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		// Older agents send an empty heartbeat, without their status
+		var agentStatus *models.AgentStatus
+		if len(bytes.TrimSpace(body)) > 0 {
+			agentStatus = &models.AgentStatus{}
+			err = json.Unmarshal(body, agentStatus)
+			if err != nil {
+				_ = c.AbortWithError(http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		err = hostService.Heartbeat(agentID, agentStatus, c.ClientIP())
 		if err != nil {
 			_ = c.Error(err)
 			return
@@ -1,10 +1,13 @@
 package web
 
 import (
+	"bytes"
 	"fmt"
 	"net/http/httptest"
 	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -57,6 +60,25 @@ func hostListFixture() models.HostList {
 			AgentVersion: "v1",
 			Tags:         []string{"tag2"},
 			Health:       "warning",
+			CloudData: models.AwsCloudData{
+				InstanceID:       "i-0123456789abcdef0",
+				InstanceType:     "r5.4xlarge",
+				Region:           "eu-central-1",
+				AvailabilityZone: "eu-central-1a",
+				AccountID:        "123456789012",
+				AmiID:            "ami-0a1b2c3d4e5f6a7b8",
+				NetworkInterfaces: []models.AwsNetworkInterface{
+					{
+						ID:         "eni-0123456789abcdef0",
+						MacAddress: "0a:1b:2c:3d:4e:5f",
+						PrivateIPs: []string{"10.0.1.10", "10.0.1.11"},
+						PublicIPs:  []string{"3.120.1.10"},
+						SubnetID:   "subnet-0123456789abcdef0",
+						VpcID:      "vpc-0123456789abcdef0",
+					},
+				},
+				Tags: map[string]string{"workload": "sap"},
+			},
 		},
 		{
 			ID:            "1",
@@ -73,6 +95,29 @@ func hostListFixture() models.HostList {
 			AgentVersion: "v1",
 			Tags:         []string{"tag3"},
 			Health:       "critical",
+			CloudData: models.GcpCloudData{
+				InstanceName: "host3",
+				ProjectID:    "sap-landscape",
+				Zone:         "europe-west1-b",
+				MachineType:  "n1-highmem-32",
+				Image:        "sles-15-sp2-sap-v20210604",
+				Disks: []models.GcpDisk{
+					{
+						Name: "persistent-disk-0",
+						Type: "PERSISTENT",
+						Mode: "READ_WRITE",
+					},
+				},
+				NetworkInterfaces: []models.GcpNetworkInterface{
+					{
+						Network:     "sap-network",
+						IP:          "10.0.0.10",
+						MacAddress:  "42:01:0a:00:00:0a",
+						ExternalIPs: []string{"34.77.10.10"},
+					},
+				},
+				Tags: []string{"hana"},
+			},
 		},
 	}
 }
@@ -131,11 +176,45 @@ func TestHostListNextHandler(t *testing.T) {
 	assert.Regexp(t, regexp.MustCompile(".*error.*<td .*>.*host3.*</td><td>192.168.1.3</td><td>.*gcp.*</td><td>.*sapsystems/sap_system_id_3.*DEV.*</td><td>v1</td><td .*>.*<input.*value=tag3.*>.*</td>"), minified)
 }
 
+func TestHostListHandlerTuningMismatch(t *testing.T) {
+	hosts := hostListFixture()
+	hosts[0].Tuning = &models.HostTuning{}
+	hosts[1].Tuning = &models.HostTuning{SapconfActive: true}
+	hosts[2].Tuning = &models.HostTuning{
+		Saptune: &models.SaptuneState{Active: true, Solutions: []string{"S4HANA-APPSERVER"}},
+	}
+
+	mockHostsService := new(services.MockHostsService)
+	mockHostsService.On("GetAll", mock.Anything, mock.Anything).Return(hosts, nil)
+	mockHostsService.On("GetCount").Return(3, nil)
+	mockHostsService.On("GetAllSIDs", mock.Anything).Return([]string{"PRD", "QAS", "DEV"}, nil)
+	mockHostsService.On("GetAllTags", mock.Anything).Return([]string{"tag1", "tag2", "tag3"}, nil)
+
+	deps := setupTestDependencies()
+	deps.hostsService = mockHostsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/hosts", nil)
+
+	app.webEngine.ServeHTTP(resp, req)
+
+	responseBody := minifyHtml(resp.Body.String())
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, 1, strings.Count(responseBody, ">tuning mismatch</span>"))
+	assert.Regexp(t, regexp.MustCompile("host1</a>\\s*<span [^>]*data-original-title=\"Neither saptune nor sapconf tune the host\">tuning mismatch</span>"), responseBody)
+}
+
 func TestApiHostHeartbeat(t *testing.T) {
 	agentID := "agent_id"
 
 	mockHostsService := new(services.MockHostsService)
-	mockHostsService.On("Heartbeat", agentID).Return(nil)
+	mockHostsService.On("Heartbeat", agentID, (*models.AgentStatus)(nil), "192.0.2.1").Return(nil)
 
 	deps := setupTestDependencies()
 	deps.hostsService = mockHostsService
@@ -152,6 +231,54 @@ func TestApiHostHeartbeat(t *testing.T) {
 	app.collectorEngine.ServeHTTP(resp, req)
 
 	assert.Equal(t, 204, resp.Code)
+	mockHostsService.AssertExpectations(t)
+}
+
+func TestApiHostHeartbeatWithAgentStatus(t *testing.T) {
+	agentID := "agent_id"
+
+	mockHostsService := new(services.MockHostsService)
+	mockHostsService.On("Heartbeat", agentID, &models.AgentStatus{
+		Hostname: "vmhana01",
+		Version:  "1.0.0",
+		Uptime:   3600,
+		Discoveries: []*models.AgentDiscoveryStatus{
+			{
+				ID:                  "ha_cluster_discovery",
+				LastError:           "crm_mon failed",
+				ConsecutiveFailures: 3,
+			},
+		},
+	}, "192.0.2.1").Return(nil)
+
+	deps := setupTestDependencies()
+	deps.hostsService = mockHostsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	url := fmt.Sprintf("/api/hosts/%s/heartbeat", agentID)
+	req := httptest.NewRequest("POST", url, bytes.NewBufferString(`{
+		"hostname": "vmhana01",
+		"version": "1.0.0",
+		"uptime_seconds": 3600,
+		"discoveries": [{"id": "ha_cluster_discovery", "last_error": "crm_mon failed", "consecutive_failures": 3}]
+	}`))
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 204, resp.Code)
+	mockHostsService.AssertExpectations(t)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", url, bytes.NewBufferString("not json"))
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 400, resp.Code)
 }
 
 func TestHostHandler(t *testing.T) {
@@ -224,6 +351,15 @@ func TestHostHandler(t *testing.T) {
 	assert.Regexp(t, regexp.MustCompile("<td>Node exporter</td><td><span.*>running</span>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<td>Other exporter</td><td><span.*>not running</span>"), minified)
 
+	// Cloud details
+	assert.Regexp(t, regexp.MustCompile("<span.*>AWS</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<span.*>i-0123456789abcdef0</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<span.*>eu-central-1 \\(eu-central-1a\\)</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<span.*>workload: sap</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile(
+		"<td>eni-0123456789abcdef0</td><td>0a:1b:2c:3d:4e:5f</td><td>10.0.1.10, 10.0.1.11</td><td>3.120.1.10</td>"+
+			"<td>subnet-0123456789abcdef0</td><td>vpc-0123456789abcdef0</td>"), minified)
+
 	// Subscriptions
 	assert.Regexp(t, regexp.MustCompile(
 		"<td>SLES_SAP</td><td>x64_84</td><td>15.2</td><td>internal</td><td>Registered</td>"+
@@ -305,6 +441,254 @@ func TestHostHandlerAzure(t *testing.T) {
 			"<td>Registered</td><td></td><td></td><td></td>"), minified)
 }
 
+func TestHostHandlerGcp(t *testing.T) {
+	subscriptionsMocks := new(services.MockSubscriptionsService)
+	mockHostsService := new(services.MockHostsService)
+
+	subscriptionsMocks.On("GetHostSubscriptions", "3").Return([]*models.SlesSubscription{}, nil)
+	subscriptionsMocks.On("IsTrentoPremium").Return(true, nil)
+	mockHostsService.On("GetByID", "3").Return(hostListFixture()[2], nil)
+	mockHostsService.On("GetExportersState", "host3").Return(make(map[string]string), nil)
+
+	deps := setupTestDependencies()
+	deps.subscriptionsService = subscriptionsMocks
+	deps.hostsService = mockHostsService
+
+	config := setupTestConfig()
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/hosts/3", nil)
+	req.Header.Set("Accept", "text/html")
+
+	app.webEngine.ServeHTTP(resp, req)
+
+	m := minify.New()
+	m.AddFunc("text/html", html.Minify)
+	m.Add("text/html", &html.Minifier{
+		KeepDefaultAttrVals: true,
+		KeepEndTags:         true,
+	})
+	minified, err := m.String("text/html", resp.Body.String())
+	if err != nil {
+		panic(err)
+	}
+
+	assert.Equal(t, 200, resp.Code)
+
+	// Cloud details
+	assert.Regexp(t, regexp.MustCompile("<span.*>GCP</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<span.*>sap-landscape</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<span.*>europe-west1-b</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<span.*>n1-highmem-32</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<span.*>hana</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<td>persistent-disk-0</td><td>PERSISTENT</td><td>READ_WRITE</td>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<td>sap-network</td><td>10.0.0.10</td><td>42:01:0a:00:00:0a</td><td>34.77.10.10</td>"), minified)
+}
+
+func TestHostHandlerAgentStatus(t *testing.T) {
+	subscriptionsMocks := new(services.MockSubscriptionsService)
+	mockHostsService := new(services.MockHostsService)
+
+	lastRunAt := time.Date(2021, 11, 01, 10, 00, 00, 0, time.UTC)
+	lastSuccessAt := time.Date(2021, 11, 01, 9, 00, 00, 0, time.UTC)
+
+	host := hostListFixture()[0]
+	host.Health = models.HostHealthWarning
+	host.AgentStatus = &models.AgentStatus{
+		Version: "1.0.0",
+		Uptime:  5430,
+		Discoveries: []*models.AgentDiscoveryStatus{
+			{
+				ID:                  "ha_cluster_discovery",
+				LastRunAt:           &lastRunAt,
+				LastSuccessAt:       &lastSuccessAt,
+				LastError:           "crm_mon failed",
+				ConsecutiveFailures: 3,
+			},
+			{
+				ID:            "host_discovery",
+				LastRunAt:     &lastRunAt,
+				LastSuccessAt: &lastRunAt,
+			},
+		},
+	}
+
+	subscriptionsMocks.On("GetHostSubscriptions", "1").Return([]*models.SlesSubscription{}, nil)
+	subscriptionsMocks.On("IsTrentoPremium").Return(true, nil)
+	mockHostsService.On("GetByID", "1").Return(host, nil)
+	mockHostsService.On("GetExportersState", "host1").Return(make(map[string]string), nil)
+
+	deps := setupTestDependencies()
+	deps.subscriptionsService = subscriptionsMocks
+	deps.hostsService = mockHostsService
+
+	config := setupTestConfig()
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/hosts/1", nil)
+	req.Header.Set("Accept", "text/html")
+
+	app.webEngine.ServeHTTP(resp, req)
+
+	m := minify.New()
+	m.AddFunc("text/html", html.Minify)
+	m.Add("text/html", &html.Minifier{
+		KeepDefaultAttrVals: true,
+		KeepEndTags:         true,
+	})
+	minified, err := m.String("text/html", resp.Body.String())
+	if err != nil {
+		panic(err)
+	}
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Regexp(t, regexp.MustCompile("<td>Trento agent</td><td><span.*>running, discoveries failing</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<span.*>1h30m30s</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile(
+		"<td>ha_cluster_discovery</td><td><span.*>failing</span></td><td>Nov 01, 2021 10:00:00 UTC</td><td>Nov 01, 2021 09:00:00 UTC</td><td>3</td><td>crm_mon failed</td>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<td>host_discovery</td><td><span.*>ok</span></td>"), minified)
+}
+
+func TestHostHandlerIdentityConflict(t *testing.T) {
+	subscriptionsMocks := new(services.MockSubscriptionsService)
+	mockHostsService := new(services.MockHostsService)
+
+	lastSeenAt := time.Date(2021, 11, 01, 10, 00, 00, 0, time.UTC)
+
+	host := hostListFixture()[0]
+	host.AgentSightings = []*models.AgentSighting{
+		{Hostname: "host1", BootID: "a4b5d2e6-4f1c-4c8e-9b0e-1d2f3a4b5c6d", Address: "10.0.0.1", LastSeenAt: lastSeenAt, Alternating: true},
+		{Hostname: "host1", BootID: "f0e1d2c3-b4a5-4697-8a7b-6c5d4e3f2a1b", Address: "10.0.0.1", LastSeenAt: lastSeenAt},
+		{Address: "10.0.0.2", LastSeenAt: lastSeenAt},
+	}
+
+	subscriptionsMocks.On("GetHostSubscriptions", "1").Return([]*models.SlesSubscription{}, nil)
+	subscriptionsMocks.On("IsTrentoPremium").Return(true, nil)
+	mockHostsService.On("GetByID", "1").Return(host, nil)
+	mockHostsService.On("GetExportersState", "host1").Return(make(map[string]string), nil)
+
+	deps := setupTestDependencies()
+	deps.subscriptionsService = subscriptionsMocks
+	deps.hostsService = mockHostsService
+
+	config := setupTestConfig()
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/hosts/1", nil)
+	req.Header.Set("Accept", "text/html")
+
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Contains(t, resp.Body.String(), "tn-identity-conflict")
+	assert.Contains(t, resp.Body.String(), "<li>host1 (10.0.0.1, boot id a4b5d2e6), last seen Nov 01, 2021 10:00:00 UTC</li>")
+	assert.Contains(t, resp.Body.String(), "<li>host1 (10.0.0.1, boot id f0e1d2c3), last seen Nov 01, 2021 10:00:00 UTC</li>")
+	assert.Contains(t, resp.Body.String(), "<li>10.0.0.2, last seen Nov 01, 2021 10:00:00 UTC</li>")
+}
+
+func TestHostHandlerTuning(t *testing.T) {
+	subscriptionsMocks := new(services.MockSubscriptionsService)
+	mockHostsService := new(services.MockHostsService)
+
+	host := hostListFixture()[0]
+	host.Tuning = &models.HostTuning{
+		Sysctl: map[string]string{
+			"kernel.sem":    "32000 1024000000 500 32000",
+			"vm.swappiness": "10",
+		},
+		TransparentHugepages: "never",
+		CPUGovernors:         []string{"performance"},
+		Saptune: &models.SaptuneState{
+			Version:   "3",
+			Active:    true,
+			Solutions: []string{"NETWEAVER"},
+			Notes:     []string{"941735", "2578899"},
+		},
+	}
+
+	subscriptionsMocks.On("GetHostSubscriptions", "1").Return([]*models.SlesSubscription{}, nil)
+	subscriptionsMocks.On("IsTrentoPremium").Return(true, nil)
+	mockHostsService.On("GetByID", "1").Return(host, nil)
+	mockHostsService.On("GetExportersState", "host1").Return(make(map[string]string), nil)
+
+	deps := setupTestDependencies()
+	deps.subscriptionsService = subscriptionsMocks
+	deps.hostsService = mockHostsService
+
+	config := setupTestConfig()
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/hosts/1", nil)
+	req.Header.Set("Accept", "text/html")
+
+	app.webEngine.ServeHTTP(resp, req)
+
+	responseBody := minifyHtml(resp.Body.String())
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Contains(t, responseBody, "Kernel tuning")
+	assert.Contains(t, responseBody, "The tuning applied to this host does not fit its SAP workload: saptune applies the NETWEAVER solution, expected one of HANA, S4HANA-DBSERVER, NETWEAVER+HANA, S4HANA-APP+DB")
+	assert.Regexp(t, regexp.MustCompile("<strong>Solutions:</strong><br><span class=text-muted><span class=\"badge badge-secondary mr-1\">NETWEAVER</span></span>"), responseBody)
+	assert.Regexp(t, regexp.MustCompile("<strong>Transparent hugepages:</strong><br><span class=text-muted>never</span>"), responseBody)
+	assert.Regexp(t, regexp.MustCompile("<strong>CPU governors:</strong><br><span class=text-muted>performance</span>"), responseBody)
+	assert.Regexp(t, regexp.MustCompile("<tr><td>kernel.sem</td><td>32000 1024000000 500 32000</td></tr><tr><td>vm.swappiness</td><td>10</td></tr>"), responseBody)
+}
+
+func TestApiListAgentIdentityConflictsHandler(t *testing.T) {
+	mockHostsService := new(services.MockHostsService)
+	mockHostsService.On("GetIdentityConflicts").Return([]*models.AgentIdentityConflict{
+		{
+			AgentID:  "1",
+			HostName: "host1",
+			Sightings: []*models.AgentSighting{
+				{Hostname: "host1", BootID: "a4b5d2e6-4f1c-4c8e-9b0e-1d2f3a4b5c6d", Address: "10.0.0.1", LastSeenAt: time.Date(2021, 11, 01, 10, 00, 00, 0, time.UTC), Alternating: true},
+				{Hostname: "host1-clone", BootID: "f0e1d2c3-b4a5-4697-8a7b-6c5d4e3f2a1b", Address: "10.0.0.2", LastSeenAt: time.Date(2021, 11, 01, 10, 00, 00, 0, time.UTC)},
+			},
+		},
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.hostsService = mockHostsService
+
+	config := setupTestConfig()
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/identity-conflicts", nil)
+
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `[{
+		"agent_id": "1",
+		"host_name": "host1",
+		"sightings": [
+			{"hostname": "host1", "boot_id": "a4b5d2e6-4f1c-4c8e-9b0e-1d2f3a4b5c6d", "address": "10.0.0.1", "last_seen_at": "2021-11-01T10:00:00Z", "alternating": true},
+			{"hostname": "host1-clone", "boot_id": "f0e1d2c3-b4a5-4697-8a7b-6c5d4e3f2a1b", "address": "10.0.0.2", "last_seen_at": "2021-11-01T10:00:00Z", "alternating": false}
+		]
+	}]`, resp.Body.String())
+	mockHostsService.AssertExpectations(t)
+}
+
 func TestHostHandler404Error(t *testing.T) {
 	subscriptionsMocks := new(services.MockSubscriptionsService)
 	mockHostsService := new(services.MockHostsService)
@@ -156,6 +156,7 @@ func (r *LayoutRender) addFileFromFS(templatesFS fs.FS, file string) {
 		},
 		"markdown": markdownToHTML,
 		"split":    strings.Split,
+		"join":     strings.Join,
 		"script":   script,
 	})
 	patterns := append([]string{r.root, file}, r.blocks...)
@@ -1,6 +1,11 @@
 package web
 
 import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -28,3 +33,134 @@ func EulaMiddleware(premiumDetection services.PremiumDetectionService) gin.Handl
 		c.Next()
 	}
 }
+
+// maxDecompressedBodySize bounds the size of the decompressed request bodies,
+// so that small gzip bombs cannot exhaust the memory of the server
+const maxDecompressedBodySize int64 = 32 << 20
+
+// DecompressRequestMiddleware transparently decompresses the gzip encoded request bodies.
+// Bodies larger than maxDecompressedBodySize once decompressed are rejected
+func DecompressRequestMiddleware() gin.HandlerFunc {
+	return decompressRequestMiddleware(maxDecompressedBodySize)
+}
+
+func decompressRequestMiddleware(maxSize int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Content-Encoding") != "gzip" {
+			c.Next()
+			return
+		}
+
+		reader, err := gzip.NewReader(c.Request.Body)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+		defer reader.Close()
+
+		body, err := ioutil.ReadAll(io.LimitReader(reader, maxSize+1))
+		if err != nil {
+			_ = c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+		if int64(len(body)) > maxSize {
+			c.AbortWithStatus(http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		c.Request.Header.Del("Content-Encoding")
+		c.Request.ContentLength = int64(len(body))
+		c.Next()
+	}
+}
+
+// authenticatedAgentKey is the context key holding the id of the agent authenticated by AgentAuthMiddleware
+// or ClientCertificateMiddleware
+const authenticatedAgentKey = "authenticated_agent_id"
+
+// AgentAuthMiddleware authenticates the agent requests with the basic auth credentials got on enrollment.
+// Agents can only send data about themselves
+func AgentAuthMiddleware(agentCredentialsService services.AgentCredentialsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		agentID, secret, ok := c.Request.BasicAuth()
+		if !ok {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		err := agentCredentialsService.Authenticate(agentID, secret)
+		if errors.Is(err, services.ErrInvalidAgentCredentials) {
+			log.Warnf("Rejected request of agent %s: %s", agentID, err)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+
+		if id := c.Param("id"); id != "" && id != agentID {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		c.Set(authenticatedAgentKey, agentID)
+		c.Next()
+	}
+}
+
+// ClientCertificateMiddleware authenticates the agent requests with the client certificate issued by the internal CA,
+// whose common name is the agent id. Certificates revoked or replaced by a renewal are rejected even if not expired yet.
+// Agents can only send data about themselves
+func ClientCertificateMiddleware(agentCertificatesService services.AgentCertificatesService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.TLS == nil || len(c.Request.TLS.VerifiedChains) == 0 {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		agentID := c.Request.TLS.VerifiedChains[0][0].Subject.CommonName
+
+		err := agentCertificatesService.Authenticate(agentID, clientCertificateSerialNumber(c))
+		if errors.Is(err, services.ErrInvalidAgentCertificate) {
+			log.Warnf("Rejected request of agent %s: %s", agentID, err)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+
+		if id := c.Param("id"); id != "" && id != agentID {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		c.Set(authenticatedAgentKey, agentID)
+		c.Next()
+	}
+}
+
+// clientCertificateSerialNumber returns the serial number of the verified client certificate of the request,
+// in the same format stored by the internal CA, or an empty string if there is none
+func clientCertificateSerialNumber(c *gin.Context) string {
+	if c.Request.TLS == nil || len(c.Request.TLS.VerifiedChains) == 0 {
+		return ""
+	}
+
+	serialNumber := c.Request.TLS.VerifiedChains[0][0].SerialNumber
+	if serialNumber == nil {
+		return ""
+	}
+
+	return serialNumber.Text(16)
+}
+
+// isAuthenticatedAgent tells whether the request was sent by the given agent, always true if agent authentication is disabled
+func isAuthenticatedAgent(c *gin.Context, agentID string) bool {
+	authenticatedAgentID, ok := c.Get(authenticatedAgentKey)
+
+	return !ok || authenticatedAgentID == agentID
+}
@@ -1,10 +1,15 @@
 package web
 
 import (
+	"bytes"
+	"compress/gzip"
 	"errors"
+	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/trento-project/trento/web/services"
 )
@@ -67,3 +72,42 @@ func TestEulaMiddlewareError(t *testing.T) {
 
 	assert.Equal(t, 500, resp.Code)
 }
+
+func TestDecompressRequestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(decompressRequestMiddleware(16))
+	engine.POST("/", func(c *gin.Context) {
+		body, _ := c.GetRawData()
+		c.String(http.StatusOK, string(body))
+	})
+
+	compress := func(data string) *bytes.Buffer {
+		var body bytes.Buffer
+		writer := gzip.NewWriter(&body)
+		writer.Write([]byte(data))
+		writer.Close()
+		return &body
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/", compress("sixteen bytes!!!"))
+	req.Header.Set("Content-Encoding", "gzip")
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, "sixteen bytes!!!", resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/", compress(strings.Repeat("a", 1024)))
+	req.Header.Set("Content-Encoding", "gzip")
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 413, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/", bytes.NewBufferString(strings.Repeat("a", 1024)))
+	engine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+}
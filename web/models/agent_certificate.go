@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+const (
+	AgentCertificatePending = "pending"
+	AgentCertificateIssued  = "issued"
+	AgentCertificateRevoked = "revoked"
+)
+
+// CertificateExpiryWarning is how long before expiring a certificate is reported as nearing expiry
+const CertificateExpiryWarning = 14 * 24 * time.Hour
+
+type AgentCertificate struct {
+	AgentID      string     `json:"agent_id"`
+	Status       string     `json:"status"`
+	Certificate  string     `json:"certificate,omitempty"`
+	SerialNumber string     `json:"serial_number,omitempty"`
+	RequestedAt  time.Time  `json:"requested_at"`
+	IssuedAt     *time.Time `json:"issued_at,omitempty"`
+	NotAfter     *time.Time `json:"not_after,omitempty"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (c *AgentCertificate) IsPending() bool {
+	return c.Status == AgentCertificatePending
+}
+
+func (c *AgentCertificate) IsRevoked() bool {
+	return c.RevokedAt != nil
+}
+
+func (c *AgentCertificate) Expired() bool {
+	return c.NotAfter != nil && time.Now().After(*c.NotAfter)
+}
+
+func (c *AgentCertificate) ExpiresSoon() bool {
+	return c.NotAfter != nil && !c.Expired() && time.Until(*c.NotAfter) < CertificateExpiryWarning
+}
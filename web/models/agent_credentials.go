@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type EnrollmentToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type AgentCredentials struct {
+	AgentID    string     `json:"agent_id"`
+	EnrolledAt time.Time  `json:"enrolled_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
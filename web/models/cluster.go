@@ -5,6 +5,7 @@ import "time"
 const (
 	ClusterTypeHANAScaleUp  = "HANA scale-up"
 	ClusterTypeHANAScaleOut = "HANA scale-out"
+	ClusterTypeASCSERS      = "ASCS/ERS"
 	ClusterTypeUnknown      = "Unknown"
 	HANAStatusPrimary       = "Primary"
 	HANAStatusSecondary     = "Secondary"
@@ -17,6 +18,12 @@ const (
 	// https://github.com/SUSE/SAPHanaSR/blob/master/ra/SAPHana#L1171
 	HANASrHealthOK = "4"
 	HANASrSyncSOK  = "SOK"
+	// Standalone Enqueue Server versions of an ASCS/ERS cluster
+	EnsaVersion1 = "ENSA1"
+	EnsaVersion2 = "ENSA2"
+	// Types of the SAP instances of an ASCS/ERS cluster
+	ASCSERSInstanceTypeASCS = "ASCS"
+	ASCSERSInstanceTypeERS  = "ERS"
 )
 
 type Cluster struct {
@@ -43,11 +50,71 @@ type HANAClusterDetails struct {
 	SystemReplicationOperationMode string
 	SecondarySyncState             string
 	SRHealthState                  string
+	PrimarySite                    string
+	SecondarySite                  string
 	CIBLastWritten                 time.Time
 	FencingType                    string
 	StoppedResources               []*ClusterResource
+	FailedActions                  []*ClusterFailedAction
 	Nodes                          ClusterNodes
 	SBDDevices                     []*SBDDevice
+	Corosync                       *ClusterCorosync
+}
+
+type ASCSERSClusterDetails struct {
+	EnsaVersion      string
+	CIBLastWritten   time.Time
+	FencingType      string
+	StoppedResources []*ClusterResource
+	FailedActions    []*ClusterFailedAction
+	Nodes            ClusterNodes
+	SBDDevices       []*SBDDevice
+	Corosync         *ClusterCorosync
+	Instances        []*ASCSERSInstance
+}
+
+// HasSAPHAConfig tells whether the SAP HA interface data of any instance was discovered
+func (d *ASCSERSClusterDetails) HasSAPHAConfig() bool {
+	for _, i := range d.Instances {
+		if i.HAConfig != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ASCSERSInstance is an ASCS or ERS instance managed by a SAPInstance resource,
+// along with the virtual IPs and filesystems of its resource group
+type ASCSERSInstance struct {
+	Type                  string
+	SID                   string
+	InstanceNumber        string
+	VirtualHostname       string
+	ResourceID            string
+	Group                 string
+	Node                  string
+	VirtualIPs            []string
+	Filesystems           []*ClusterFilesystem
+	Halib                 string
+	HalibClusterConnector string
+	// SAPSystemID and HostID link the instance to the discovered SAP system and to the host running it
+	SAPSystemID string
+	HostID      string
+	// HAConfig is the SAP HA interface configuration and checks of the linked SAP system instance
+	HAConfig *SAPInstanceHAConfig
+}
+
+// HasClusterConnector tells whether the SAP HA interface of the instance is set up with sap_cluster_connector
+func (i *ASCSERSInstance) HasClusterConnector() bool {
+	return i.Halib != "" && i.HalibClusterConnector != ""
+}
+
+type ClusterFilesystem struct {
+	ResourceID string
+	Device     string
+	Directory  string
+	FSType     string
 }
 
 type ClusterResource struct {
@@ -56,6 +123,18 @@ type ClusterResource struct {
 	Role      string
 	Status    string
 	FailCount int
+	// Reasons explains why a stopped resource is not running
+	Reasons []string
+}
+
+// ClusterFailedAction is a failed resource operation reported by crm_mon, along with the exit reason given by the resource agent
+type ClusterFailedAction struct {
+	ResourceID   string
+	Task         string
+	Node         string
+	ExitStatus   string
+	ExitReason   string
+	LastRCChange string
 }
 
 type HANAClusterNode struct {
@@ -68,6 +147,45 @@ type HANAClusterNode struct {
 	HANAStatus  string
 	Attributes  map[string]string
 	Resources   []*ClusterResource
+	// NameServerRole and IndexServerRole are the current roles of the node in a HANA scale-out system
+	NameServerRole  string
+	IndexServerRole string
+	MajorityMaker   bool
+}
+
+// ClusterCorosync is the corosync setup of a cluster, along with the quorum state seen by the DC
+type ClusterCorosync struct {
+	Transport      string
+	Token          int
+	Consensus      int
+	QuorumProvider string
+	TwoNode        bool
+	WaitForAll     bool
+	ExpectedVotes  int
+	TotalVotes     int
+	Quorum         int
+	Quorate        bool
+	// Rings are the numbers of the configured rings, or links with the knet transport
+	Rings []int
+	Nodes []*ClusterCorosyncNode
+}
+
+// ClusterCorosyncNode is a node of the corosync nodelist, where RingAddresses are indexed by ring number
+type ClusterCorosyncNode struct {
+	NodeID        int
+	Name          string
+	RingAddresses []string
+	Votes         int
+	Member        bool
+}
+
+// RingAddress returns the address of the node in a ring, or an empty string if it is not part of it
+func (n *ClusterCorosyncNode) RingAddress(ring int) string {
+	if ring < len(n.RingAddresses) {
+		return n.RingAddresses[ring]
+	}
+
+	return ""
 }
 
 type SBDDevice struct {
@@ -77,11 +195,27 @@ type SBDDevice struct {
 
 type ClusterNodes []*HANAClusterNode
 
+// GroupBySite groups the nodes running HANA by their site, leaving out the majority makers
 func (n ClusterNodes) GroupBySite() map[string]ClusterNodes {
 	sites := make(map[string]ClusterNodes)
 	for _, node := range n {
+		if node.MajorityMaker {
+			continue
+		}
 		sites[node.Site] = append(sites[node.Site], node)
 	}
 
 	return sites
 }
+
+// MajorityMakers returns the majority maker nodes of a HANA scale-out cluster
+func (n ClusterNodes) MajorityMakers() ClusterNodes {
+	var majorityMakers ClusterNodes
+	for _, node := range n {
+		if node.MajorityMaker {
+			majorityMakers = append(majorityMakers, node)
+		}
+	}
+
+	return majorityMakers
+}
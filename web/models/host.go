@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/trento-project/trento/internal/cloud"
 )
 
@@ -28,6 +30,73 @@ type Host struct {
 	AgentVersion  string
 	Tags          []string
 	CloudData     interface{}
+	Tuning        *HostTuning
+	AgentStatus   *AgentStatus
+	// AgentSightings are the hosts the agent was recently reported from, along with their last address
+	AgentSightings []*AgentSighting
+}
+
+// AgentStatus is the agent status reported along with the last heartbeat
+type AgentStatus struct {
+	Hostname    string                  `json:"hostname,omitempty"`
+	BootID      string                  `json:"boot_id,omitempty"`
+	Version     string                  `json:"version"`
+	Uptime      float64                 `json:"uptime_seconds"`
+	Discoveries []*AgentDiscoveryStatus `json:"discoveries"`
+}
+
+type AgentDiscoveryStatus struct {
+	ID                  string     `json:"id"`
+	LastRunAt           *time.Time `json:"last_run_at,omitempty"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastPublishError    string     `json:"last_publish_error,omitempty"`
+}
+
+type AgentSighting struct {
+	Hostname   string    `json:"hostname"`
+	BootID     string    `json:"boot_id"`
+	Address    string    `json:"address"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	// Alternating tells whether the agent id was reported again from this host after being reported from another one
+	Alternating bool `json:"alternating"`
+}
+
+// shortBootIDLength is enough to tell apart the boot ids, which are random UUIDs
+const shortBootIDLength = 8
+
+// ShortBootID returns the beginning of the boot id, to tell apart the hosts sharing the hostname
+func (s *AgentSighting) ShortBootID() string {
+	if len(s.BootID) <= shortBootIDLength {
+		return s.BootID
+	}
+
+	return s.BootID[:shortBootIDLength]
+}
+
+// IsAgentIdentityConflict tells whether the recent sightings of an agent id come from several hosts reporting it in turns.
+// A restarted or renamed host is seen once with each identity, so it is not a conflict
+func IsAgentIdentityConflict(sightings []*AgentSighting) bool {
+	if len(sightings) < 2 {
+		return false
+	}
+
+	for _, sighting := range sightings {
+		if sighting.Alternating {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AgentIdentityConflict is an agent id recently reported from several hosts,
+// usually cloned from the same image without regenerating their machine id
+type AgentIdentityConflict struct {
+	AgentID   string           `json:"agent_id"`
+	HostName  string           `json:"host_name"`
+	Sightings []*AgentSighting `json:"sightings"`
 }
 
 type AzureCloudData struct {
@@ -41,8 +110,71 @@ type AzureCloudData struct {
 	AdminUsername   string `json:"admin_username"`
 }
 
+type AwsCloudData struct {
+	InstanceID        string                `json:"instance_id"`
+	InstanceType      string                `json:"instance_type"`
+	Region            string                `json:"region"`
+	AvailabilityZone  string                `json:"availability_zone"`
+	AccountID         string                `json:"account_id"`
+	AmiID             string                `json:"ami_id"`
+	NetworkInterfaces []AwsNetworkInterface `json:"network_interfaces"`
+	Tags              map[string]string     `json:"tags"`
+}
+
+type AwsNetworkInterface struct {
+	ID         string   `json:"id"`
+	MacAddress string   `json:"mac_address"`
+	PrivateIPs []string `json:"private_ips"`
+	PublicIPs  []string `json:"public_ips"`
+	SubnetID   string   `json:"subnet_id"`
+	VpcID      string   `json:"vpc_id"`
+}
+
+type GcpCloudData struct {
+	InstanceName      string                `json:"instance_name"`
+	ProjectID         string                `json:"project_id"`
+	Zone              string                `json:"zone"`
+	MachineType       string                `json:"machine_type"`
+	Image             string                `json:"image"`
+	Disks             []GcpDisk             `json:"disks"`
+	NetworkInterfaces []GcpNetworkInterface `json:"network_interfaces"`
+	Tags              []string              `json:"tags"`
+}
+
+type GcpDisk struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Mode string `json:"mode"`
+}
+
+type GcpNetworkInterface struct {
+	Network     string   `json:"network"`
+	IP          string   `json:"ip"`
+	MacAddress  string   `json:"mac_address"`
+	ExternalIPs []string `json:"external_ips"`
+}
+
 type HostList []*Host
 
+func (s *AgentStatus) PrettyUptime() string {
+	return (time.Duration(s.Uptime) * time.Second).String()
+}
+
+// HasFailingDiscoveries tells whether the last execution or publication of any discovery failed
+func (s *AgentStatus) HasFailingDiscoveries() bool {
+	for _, d := range s.Discoveries {
+		if d.Failing() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d *AgentDiscoveryStatus) Failing() bool {
+	return d.LastError != "" || d.LastPublishError != ""
+}
+
 func (h *Host) PrettyProvider() string {
 	switch h.CloudProvider {
 	case cloud.Azure:
@@ -55,3 +187,8 @@ func (h *Host) PrettyProvider() string {
 		return ""
 	}
 }
+
+// HasIdentityConflict tells whether the agent id was recently reported by several hosts
+func (h *Host) HasIdentityConflict() bool {
+	return IsAgentIdentityConflict(h.AgentSightings)
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+type HostPackage struct {
+	HostID      string    `json:"host_id"`
+	Hostname    string    `json:"hostname"`
+	ClusterID   string    `json:"cluster_id"`
+	ClusterName string    `json:"cluster_name"`
+	Name        string    `json:"name"`
+	Arch        string    `json:"arch"`
+	Version     string    `json:"version"`
+	Release     string    `json:"release"`
+	InstallTime time.Time `json:"install_time"`
+	// VersionMismatch tells whether other nodes of the same cluster run a different version of the package
+	VersionMismatch bool `json:"version_mismatch"`
+}
+
+// FullVersion returns the version along with the release, as rpm prints it
+func (p *HostPackage) FullVersion() string {
+	if p.Release == "" {
+		return p.Version
+	}
+
+	return p.Version + "-" + p.Release
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/trento-project/trento/internal"
+)
+
+// saptuneWorkloadSolutions are the saptune solutions fitting each kind of SAP workload
+var saptuneWorkloadSolutions = map[string][]string{
+	SAPSystemTypeDatabase:    {"HANA", "S4HANA-DBSERVER", "NETWEAVER+HANA", "S4HANA-APP+DB"},
+	SAPSystemTypeApplication: {"NETWEAVER", "S4HANA-APPSERVER", "NETWEAVER+HANA", "S4HANA-APP+DB"},
+}
+
+type HostTuning struct {
+	Sysctl               map[string]string `json:"sysctl"`
+	TransparentHugepages string            `json:"transparent_hugepages"`
+	CPUGovernors         []string          `json:"cpu_governors"`
+	Saptune              *SaptuneState     `json:"saptune"`
+	SapconfActive        bool              `json:"sapconf_active"`
+}
+
+type SaptuneState struct {
+	Version   string   `json:"version"`
+	Active    bool     `json:"active"`
+	Solutions []string `json:"solutions"`
+	Notes     []string `json:"notes"`
+}
+
+// SaptuneActive tells whether saptune is the one tuning the host
+func (t *HostTuning) SaptuneActive() bool {
+	return t.Saptune != nil && t.Saptune.Active
+}
+
+// SAPWorkloads returns the kinds of SAP systems running in the host, application or database
+func (h *Host) SAPWorkloads() []string {
+	var workloads []string
+	for _, workloadType := range []string{SAPSystemTypeDatabase, SAPSystemTypeApplication} {
+		for _, sapSystem := range h.SAPSystems {
+			if sapSystem.Type == workloadType {
+				workloads = append(workloads, workloadType)
+				break
+			}
+		}
+	}
+
+	return workloads
+}
+
+// ExpectedSaptuneSolutions returns the saptune solutions fitting all the SAP workloads of the host
+func (h *Host) ExpectedSaptuneSolutions() []string {
+	workloads := h.SAPWorkloads()
+	if len(workloads) == 0 {
+		return nil
+	}
+
+	var expected []string
+	for _, solution := range saptuneWorkloadSolutions[workloads[0]] {
+		fits := true
+		for _, workload := range workloads[1:] {
+			fits = fits && internal.Contains(saptuneWorkloadSolutions[workload], solution)
+		}
+		if fits {
+			expected = append(expected, solution)
+		}
+	}
+
+	return expected
+}
+
+// TuningMismatch returns why the tuning applied to the host does not fit the SAP workload
+// discovered on it, empty if it does or there is nothing to compare with.
+// sapconf selects its profile on its own, so only the saptune solutions are compared
+func (h *Host) TuningMismatch() string {
+	expected := h.ExpectedSaptuneSolutions()
+	if h.Tuning == nil || len(expected) == 0 {
+		return ""
+	}
+
+	if !h.Tuning.SaptuneActive() {
+		if h.Tuning.SapconfActive {
+			return ""
+		}
+		return "Neither saptune nor sapconf tune the host"
+	}
+
+	if len(h.Tuning.Saptune.Solutions) == 0 {
+		return fmt.Sprintf("saptune applies no solution, expected one of %s", strings.Join(expected, ", "))
+	}
+
+	for _, solution := range h.Tuning.Saptune.Solutions {
+		if internal.Contains(expected, solution) {
+			return ""
+		}
+	}
+
+	return fmt.Sprintf("saptune applies the %s solution, expected one of %s",
+		strings.Join(h.Tuning.Saptune.Solutions, ", "), strings.Join(expected, ", "))
+}
+
+// HasTuningMismatch tells whether the tuning applied to the host does not fit its SAP workload
+func (h *Host) HasTuningMismatch() bool {
+	return h.TuningMismatch() != ""
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/trento-project/trento/internal/sapsystem/sapcontrol"
 )
 
@@ -12,6 +14,11 @@ const (
 	SAPSystemHealthWarning  = "warning"
 	SAPSystemHealthCritical = "critical"
 	SAPSystemHealthUnknown  = "unknown"
+
+	// A dispatcher queue is overloaded when its usage is above this percentage
+	SAPQueueUsageWarningThreshold = 80
+	// An instance is in warning when its queues stay overloaded longer than this period
+	SAPQueueBacklogGracePeriod = 5 * time.Minute
 )
 
 type SAPSystem struct {
@@ -46,6 +53,77 @@ type SAPSystemInstance struct {
 	ClusterType             string
 	HostID                  string
 	Hostname                string
+	KernelRelease           string
+	KernelPatch             string
+	Alerts                  []*SAPInstanceAlert
+	Queues                  []*SAPInstanceQueue
+	WorkProcesses           []*SAPInstanceWorkProcesses
+	QueueOverloadedSince    *time.Time
+	HAConfig                *SAPInstanceHAConfig
+}
+
+// SAPInstanceQueue is the usage of a dispatcher queue of an ABAP instance
+type SAPInstanceQueue struct {
+	Type string
+	Now  int
+	High int
+	Max  int
+}
+
+// SAPInstanceWorkProcesses is the number of work processes of a given type of an ABAP instance
+type SAPInstanceWorkProcesses struct {
+	Type  string
+	Total int
+	Busy  int
+}
+
+// SAPInstanceAlert is a current CCMS alert of an instance
+type SAPInstanceAlert struct {
+	Object      string
+	Attribute   string
+	Value       string
+	Description string
+	Time        string
+}
+
+// SAPInstanceVersion is the SAP kernel release and patch level of an instance,
+// along with the version of its executables
+type SAPInstanceVersion struct {
+	SAPSystemID    string                 `json:"sap_system_id"`
+	SID            string                 `json:"sid"`
+	Type           string                 `json:"type"`
+	InstanceNumber string                 `json:"instance_number"`
+	HostID         string                 `json:"host_id"`
+	Hostname       string                 `json:"hostname"`
+	KernelRelease  string                 `json:"kernel_release"`
+	KernelPatch    string                 `json:"kernel_patch"`
+	Components     []*SAPComponentVersion `json:"components"`
+}
+
+type SAPComponentVersion struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Time    string `json:"time"`
+}
+
+// SAPInstanceHAConfig is the configuration of the SAP HA interface of an instance,
+// along with the results of the HA checks run by sapcontrol
+type SAPInstanceHAConfig struct {
+	Active              bool
+	ProductVersion      string
+	SAPInterfaceVersion string
+	Documentation       string
+	ActiveNode          string
+	Nodes               []string
+	Checks              []*SAPInstanceHACheck
+	FailoverChecks      []*SAPInstanceHACheck
+}
+
+type SAPInstanceHACheck struct {
+	State       string
+	Category    string
+	Description string
+	Comment     string
 }
 
 type SAPSystemList []*SAPSystem
@@ -60,6 +138,28 @@ func (s SAPSystem) GetAllInstances() []*SAPSystemInstance {
 	return instances
 }
 
+// HasAlerts returns true if any instance of the system has CCMS alerts
+func (s SAPSystem) HasAlerts() bool {
+	for _, instance := range s.Instances {
+		if len(instance.Alerts) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasDispatcherData returns true if any instance of the system has ABAP queues or work processes
+func (s SAPSystem) HasDispatcherData() bool {
+	for _, instance := range s.Instances {
+		if len(instance.Queues) > 0 || len(instance.WorkProcesses) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (s SAPSystemInstance) Health() string {
 	switch s.Status {
 	case string(sapcontrol.STATECOLOR_RED):
@@ -67,8 +167,89 @@ func (s SAPSystemInstance) Health() string {
 	case string(sapcontrol.STATECOLOR_YELLOW):
 		return SAPSystemHealthWarning
 	case string(sapcontrol.STATECOLOR_GREEN):
+		// An overloaded dispatcher doesn't change the instance status
+		if s.HasQueueBacklog() {
+			return SAPSystemHealthWarning
+		}
 		return SAPSystemHealthPassing
 	default:
 		return SAPSystemHealthUnknown
 	}
 }
+
+// HasQueueBacklog returns true if the dispatcher queues of the instance are overloaded
+// for longer than the grace period
+func (s SAPSystemInstance) HasQueueBacklog() bool {
+	return s.QueueOverloadedSince != nil && time.Since(*s.QueueOverloadedSince) >= SAPQueueBacklogGracePeriod
+}
+
+// Usage returns the percentage of the queue capacity in use
+func (q SAPInstanceQueue) Usage() int {
+	if q.Max <= 0 {
+		return 0
+	}
+
+	return q.Now * 100 / q.Max
+}
+
+func (q SAPInstanceQueue) IsOverloaded() bool {
+	return q.Usage() >= SAPQueueUsageWarningThreshold
+}
+
+// HasCriticalAlerts returns true if any of the CCMS alerts of the instance is red
+func (s SAPSystemInstance) HasCriticalAlerts() bool {
+	for _, alert := range s.Alerts {
+		if alert.Health() == SAPSystemHealthCritical {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a SAPInstanceAlert) Health() string {
+	switch a.Value {
+	case string(sapcontrol.STATECOLOR_RED):
+		return SAPSystemHealthCritical
+	case string(sapcontrol.STATECOLOR_YELLOW):
+		return SAPSystemHealthWarning
+	case string(sapcontrol.STATECOLOR_GREEN):
+		return SAPSystemHealthPassing
+	default:
+		return SAPSystemHealthUnknown
+	}
+}
+
+// AllChecks returns the HA configuration checks followed by the HA failover configuration checks
+func (c *SAPInstanceHAConfig) AllChecks() []*SAPInstanceHACheck {
+	return append(append([]*SAPInstanceHACheck{}, c.Checks...), c.FailoverChecks...)
+}
+
+func (c SAPInstanceHACheck) Health() string {
+	switch c.State {
+	case string(sapcontrol.HAVerificationStateSuccess):
+		return SAPSystemHealthPassing
+	case string(sapcontrol.HAVerificationStateWarning):
+		return SAPSystemHealthWarning
+	case string(sapcontrol.HAVerificationStateError):
+		return SAPSystemHealthCritical
+	default:
+		return SAPSystemHealthUnknown
+	}
+}
+
+// CategoryName returns a readable name of the check category, e.g. "SAP configuration"
+func (c SAPInstanceHACheck) CategoryName() string {
+	switch c.Category {
+	case string(sapcontrol.HACheckCategorySAPConfiguration):
+		return "SAP configuration"
+	case string(sapcontrol.HACheckCategorySAPState):
+		return "SAP state"
+	case string(sapcontrol.HACheckCategoryHAConfiguration):
+		return "HA configuration"
+	case string(sapcontrol.HACheckCategoryHAState):
+		return "HA state"
+	default:
+		return c.Category
+	}
+}
@@ -0,0 +1,78 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trento-project/trento/web/services"
+)
+
+func NewPackageListHandler(packagesService services.PackagesService, clustersService services.ClustersService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := c.Request.URL.Query()
+
+		packagesFilter := &services.PackagesFilter{
+			Names:        query["names"],
+			Versions:     query["versions"],
+			ClusterNames: query["clusters"],
+		}
+
+		pageNumber, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+		if err != nil {
+			pageNumber = 1
+		}
+		pageSize, err := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+		if err != nil {
+			pageSize = 10
+		}
+
+		page := &services.Page{
+			Number: pageNumber,
+			Size:   pageSize,
+		}
+
+		hostPackages, err := packagesService.GetAll(packagesFilter, page)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		count, err := packagesService.GetCount(packagesFilter)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		filterNames, err := packagesService.GetAllNames()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		// The versions are only offered for the selected packages, listing the versions
+		// of every installed package would not help finding anything
+		filterVersions, err := packagesService.GetAllVersions(packagesFilter.Names)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		filterClusters, err := clustersService.GetAllClusterNames()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		pagination := NewPagination(count, pageNumber, pageSize)
+
+		c.HTML(http.StatusOK, "packages.html.tmpl", gin.H{
+			"Packages":       hostPackages,
+			"AppliedFilters": query,
+			"FilterNames":    filterNames,
+			"FilterVersions": filterVersions,
+			"FilterClusters": filterClusters,
+			"Pagination":     pagination,
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+// ApiListPackagesHandler godoc
+// @Summary List the rpm packages installed in the hosts
+// @Description The version_mismatch field tells whether the nodes of the host cluster run different versions of the package
+// @Accept json
+// @Produce json
+// @Param names query []string false "Filter by package name"
+// @Param versions query []string false "Filter by version, with or without the release"
+// @Param clusters query []string false "Filter by cluster name"
+// @Param host_id query []string false "Filter by host id"
+// @Success 200 {object} []models.HostPackage
+// @Failure 500 {object} map[string]string
+// @Router /packages [get]
+func ApiListPackagesHandler(packagesService services.PackagesService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := c.Request.URL.Query()
+
+		hostPackages, err := packagesService.GetAll(&services.PackagesFilter{
+			Names:        query["names"],
+			Versions:     query["versions"],
+			ClusterNames: query["clusters"],
+			HostIDs:      query["host_id"],
+		}, nil)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if hostPackages == nil {
+			c.JSON(http.StatusOK, []*models.HostPackage{})
+			return
+		}
+
+		c.JSON(http.StatusOK, hostPackages)
+	}
+}
@@ -0,0 +1,73 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestApiListPackagesHandler(t *testing.T) {
+	hostPackages := []*models.HostPackage{
+		{
+			HostID:          "host_id_1",
+			Hostname:        "node01",
+			ClusterID:       "cluster_id",
+			ClusterName:     "hana_cluster",
+			Name:            "sbd",
+			Arch:            "x86_64",
+			Version:         "1.4.1+20200624.cee826a",
+			Release:         "3.11.1",
+			VersionMismatch: true,
+		},
+	}
+
+	mockPackagesService := new(services.MockPackagesService)
+	mockPackagesService.On("GetAll", &services.PackagesFilter{
+		Names:        []string{"sbd"},
+		Versions:     []string{"1.4.1+20200624.cee826a"},
+		ClusterNames: []string{"hana_cluster"},
+		HostIDs:      []string{"host_id_1"},
+	}, (*services.Page)(nil)).Return(hostPackages, nil)
+
+	deps := setupTestDependencies()
+	deps.packagesService = mockPackagesService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/packages?names=sbd&versions=1.4.1%2B20200624.cee826a&clusters=hana_cluster&host_id=host_id_1", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	expectedBody, _ := json.Marshal(hostPackages)
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, string(expectedBody), resp.Body.String())
+	mockPackagesService.AssertExpectations(t)
+}
+
+func TestApiListPackagesHandlerEmpty(t *testing.T) {
+	mockPackagesService := new(services.MockPackagesService)
+	mockPackagesService.On("GetAll", &services.PackagesFilter{}, (*services.Page)(nil)).Return(nil, nil)
+
+	deps := setupTestDependencies()
+	deps.packagesService = mockPackagesService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/packages", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, "[]", resp.Body.String())
+}
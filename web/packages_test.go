@@ -0,0 +1,75 @@
+package web
+
+import (
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestPackageListHandler(t *testing.T) {
+	packagesService := new(services.MockPackagesService)
+	clustersService := new(services.MockClustersService)
+
+	deps := setupTestDependencies()
+	deps.packagesService = packagesService
+	deps.clustersService = clustersService
+
+	packagesService.On("GetAll", &services.PackagesFilter{
+		Names: []string{"pacemaker"},
+	}, mock.Anything).Return([]*models.HostPackage{
+		{
+			HostID:          "host_id_1",
+			Hostname:        "node01",
+			ClusterID:       "cluster_id",
+			ClusterName:     "hana_cluster",
+			Name:            "pacemaker",
+			Arch:            "x86_64",
+			Version:         "2.0.4+20200616.2deceaa3a",
+			Release:         "3.9.1",
+			InstallTime:     time.Date(2021, 9, 17, 13, 41, 34, 0, time.UTC),
+			VersionMismatch: true,
+		},
+		{
+			HostID:      "host_id_2",
+			Hostname:    "node02",
+			ClusterID:   "cluster_id",
+			ClusterName: "hana_cluster",
+			Name:        "pacemaker",
+			Arch:        "x86_64",
+			Version:     "2.0.3+20200511.2b248d828",
+			Release:     "1.10",
+		},
+	}, nil)
+	packagesService.On("GetCount", mock.Anything).Return(2, nil)
+	packagesService.On("GetAllNames").Return([]string{"corosync", "pacemaker"}, nil)
+	packagesService.On("GetAllVersions", []string{"pacemaker"}).Return([]string{"2.0.3+20200511.2b248d828-1.10", "2.0.4+20200616.2deceaa3a-3.9.1"}, nil)
+	clustersService.On("GetAllClusterNames").Return([]string{"hana_cluster"}, nil)
+
+	config := setupTestConfig()
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/packages?names=pacemaker", nil)
+
+	app.webEngine.ServeHTTP(resp, req)
+	packagesService.AssertExpectations(t)
+	clustersService.AssertExpectations(t)
+
+	responseBody := minifyHtml(resp.Body.String())
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Contains(t, responseBody, "Packages")
+	assert.Contains(t, responseBody, "<option value=2.0.3+20200511.2b248d828-1.10>2.0.3+20200511.2b248d828-1.10</option>")
+	assert.Regexp(t, regexp.MustCompile("<td>pacemaker</td><td>2.0.4\\+20200616.2deceaa3a-3.9.1<span .*>Mismatch</span></td><td>x86_64</td><td><a href=/hosts/host_id_1>node01</a></td><td><a href=/clusters/cluster_id>hana_cluster</a></td><td>2021-09-17 13:41:34</td>"), responseBody)
+	assert.Regexp(t, regexp.MustCompile("<td>pacemaker</td><td>2.0.3\\+20200511.2b248d828-1.10</td><td>x86_64</td><td><a href=/hosts/host_id_2>node02</a></td><td><a href=/clusters/cluster_id>hana_cluster</a></td><td></td>"), responseBody)
+}
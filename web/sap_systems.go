@@ -129,6 +129,80 @@ func NewHANADatabaseListHandler(sapSystemsService services.SAPSystemsService) gi
 	}
 }
 
+func NewSAPVersionListHandler(sapSystemsService services.SAPSystemsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := c.Request.URL.Query()
+
+		versionsFilter := &services.SAPInstanceVersionFilter{
+			SIDs:           query["sids"],
+			KernelReleases: query["kernel_release"],
+			KernelPatches:  query["kernel_patch"],
+		}
+
+		pageNumber, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+		if err != nil {
+			pageNumber = 1
+		}
+		pageSize, err := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+		if err != nil {
+			pageSize = 10
+		}
+
+		page := &services.Page{
+			Number: pageNumber,
+			Size:   pageSize,
+		}
+
+		paginatedInstanceVersions, err := sapSystemsService.GetAllInstanceVersions(versionsFilter, page)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		instanceVersions, err := sapSystemsService.GetAllInstanceVersions(versionsFilter, nil)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		filterSIDs, err := sapSystemsService.GetAllApplicationsSIDs()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		databaseSIDs, err := sapSystemsService.GetAllDatabasesSIDs()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		filterSIDs = append(filterSIDs, databaseSIDs...)
+
+		filterKernelReleases, err := sapSystemsService.GetAllKernelReleases()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		filterKernelPatches, err := sapSystemsService.GetAllKernelPatches()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		pagination := NewPagination(len(instanceVersions), pageNumber, pageSize)
+
+		c.HTML(http.StatusOK, "sap_versions.html.tmpl", gin.H{
+			"InstanceVersions":     paginatedInstanceVersions,
+			"AppliedFilters":       query,
+			"FilterSIDs":           filterSIDs,
+			"FilterKernelReleases": filterKernelReleases,
+			"FilterKernelPatches":  filterKernelPatches,
+			"Pagination":           pagination,
+		})
+	}
+}
+
 func NewSAPResourceHandler(hostsService services.HostsService, sapSystemsService services.SAPSystemsService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/trento-project/trento/web/models"
 	"github.com/trento-project/trento/web/services"
 )
 
@@ -25,3 +26,36 @@ func ApiSAPSystemsHealthSummaryHandler(healthSummaryService services.HealthSumma
 		c.JSON(http.StatusOK, healthSummary)
 	}
 }
+
+// ApiListSAPInstanceVersionsHandler godoc
+// @Summary List the SAP kernel release and patch level of all the SAP instances
+// @Accept json
+// @Produce json
+// @Param sids query []string false "Filter by SID"
+// @Param kernel_release query []string false "Filter by kernel release"
+// @Param kernel_patch query []string false "Filter by kernel patch level"
+// @Success 200 {object} []models.SAPInstanceVersion
+// @Failure 500 {object} map[string]string
+// @Router /sapsystems/versions [get]
+func ApiListSAPInstanceVersionsHandler(sapSystemsService services.SAPSystemsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := c.Request.URL.Query()
+
+		instanceVersions, err := sapSystemsService.GetAllInstanceVersions(&services.SAPInstanceVersionFilter{
+			SIDs:           query["sids"],
+			KernelReleases: query["kernel_release"],
+			KernelPatches:  query["kernel_patch"],
+		}, nil)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if instanceVersions == nil {
+			c.JSON(http.StatusOK, []*models.SAPInstanceVersion{})
+			return
+		}
+
+		c.JSON(http.StatusOK, instanceVersions)
+	}
+}
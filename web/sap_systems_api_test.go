@@ -0,0 +1,78 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestApiListSAPInstanceVersionsHandler(t *testing.T) {
+	instanceVersions := []*models.SAPInstanceVersion{
+		{
+			SAPSystemID:    "application_id",
+			SID:            "HA1",
+			Type:           models.SAPSystemTypeApplication,
+			InstanceNumber: "00",
+			HostID:         "host_id_1",
+			Hostname:       "netweaver01",
+			KernelRelease:  "753",
+			KernelPatch:    "900",
+			Components: []*models.SAPComponentVersion{
+				{
+					Name:    "disp+work",
+					Version: "753, patch 900, changelist 2011573",
+					Time:    "2020 10 27 13:35:59",
+				},
+			},
+		},
+	}
+
+	mockSAPSystemsService := new(services.MockSAPSystemsService)
+	mockSAPSystemsService.On("GetAllInstanceVersions", &services.SAPInstanceVersionFilter{
+		SIDs:           []string{"HA1"},
+		KernelReleases: []string{"753"},
+		KernelPatches:  []string{"900"},
+	}, (*services.Page)(nil)).Return(instanceVersions, nil)
+
+	deps := setupTestDependencies()
+	deps.sapSystemsService = mockSAPSystemsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/sapsystems/versions?sids=HA1&kernel_release=753&kernel_patch=900", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	expectedBody, _ := json.Marshal(instanceVersions)
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, string(expectedBody), resp.Body.String())
+	mockSAPSystemsService.AssertExpectations(t)
+}
+
+func TestApiListSAPInstanceVersionsHandlerEmpty(t *testing.T) {
+	mockSAPSystemsService := new(services.MockSAPSystemsService)
+	mockSAPSystemsService.On("GetAllInstanceVersions", &services.SAPInstanceVersionFilter{}, (*services.Page)(nil)).Return(nil, nil)
+
+	deps := setupTestDependencies()
+	deps.sapSystemsService = mockSAPSystemsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/sapsystems/versions", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, "[]", resp.Body.String())
+}
@@ -4,6 +4,7 @@ import (
 	"net/http/httptest"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -155,9 +156,70 @@ func TestSAPDatabaseListHandler(t *testing.T) {
 	assert.Contains(t, responseBody, "HDB_WORKER")
 }
 
+func TestSAPVersionListHandler(t *testing.T) {
+	sapSystemsService := new(services.MockSAPSystemsService)
+
+	deps := setupTestDependencies()
+	deps.sapSystemsService = sapSystemsService
+	sapSystemsService.On("GetAllInstanceVersions", mock.Anything, mock.Anything).Return([]*models.SAPInstanceVersion{
+		{
+			SAPSystemID:    "application_id",
+			SID:            "HA1",
+			Type:           models.SAPSystemTypeApplication,
+			InstanceNumber: "00",
+			HostID:         "host_id_1",
+			Hostname:       "netweaver01",
+			KernelRelease:  "753",
+			KernelPatch:    "900",
+			Components: []*models.SAPComponentVersion{
+				{
+					Name:    "disp+work",
+					Version: "753, patch 900, changelist 2011573",
+					Time:    "2020 10 27 13:35:59",
+				},
+			},
+		},
+		{
+			SAPSystemID:    "database_id",
+			SID:            "PRD",
+			Type:           models.SAPSystemTypeDatabase,
+			InstanceNumber: "00",
+			HostID:         "host_id_2",
+			Hostname:       "hana01",
+			KernelRelease:  "753",
+			KernelPatch:    "800",
+			Components:     []*models.SAPComponentVersion{},
+		},
+	}, nil)
+	sapSystemsService.On("GetAllApplicationsSIDs").Return([]string{"HA1"}, nil)
+	sapSystemsService.On("GetAllDatabasesSIDs").Return([]string{"PRD"}, nil)
+	sapSystemsService.On("GetAllKernelReleases").Return([]string{"753"}, nil)
+	sapSystemsService.On("GetAllKernelPatches").Return([]string{"800", "900"}, nil)
+
+	config := setupTestConfig()
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/sapsystems/versions?kernel_release=753", nil)
+
+	app.webEngine.ServeHTTP(resp, req)
+	sapSystemsService.AssertExpectations(t)
+
+	responseBody := minifyHtml(resp.Body.String())
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Contains(t, responseBody, "SAP Versions")
+	assert.Regexp(t, regexp.MustCompile("<td><a href=/sapsystems/application_id>HA1</a></td><td>Application</td><td>00</td><td><a href=/hosts/host_id_1>netweaver01</a></td><td>753</td><td>900</td><td><span .*>disp\\+work</span></td>"), responseBody)
+	assert.Regexp(t, regexp.MustCompile("<td><a href=/databases/database_id>PRD</a></td><td>HANA Database</td><td>00</td><td><a href=/hosts/host_id_2>hana01</a></td><td>753</td><td>800</td><td></td>"), responseBody)
+}
+
 func TestSAPResourceHandler(t *testing.T) {
 	sapSystemsService := new(services.MockSAPSystemsService)
 	hostsService := new(services.MockHostsService)
+	overloadedSince := time.Date(2021, 11, 10, 10, 25, 12, 0, time.UTC)
 
 	sapSystemsService.On("GetByID", "sap_system_id").Return(&models.SAPSystem{
 		ID:   "sap_system_id",
@@ -172,6 +234,30 @@ func TestSAPResourceHandler(t *testing.T) {
 				HttpsPort:      50014,
 				Status:         "SAPControl-GREEN",
 				StartPriority:  "0.5",
+				Alerts: []*models.SAPInstanceAlert{
+					{
+						Object:      "Dialog",
+						Attribute:   "ResponseTime",
+						Value:       "SAPControl-RED",
+						Description: "Dialog response time exceeds the threshold",
+						Time:        "2021 11 10 10:25:12",
+					},
+				},
+			},
+			{
+				InstanceNumber: "02",
+				SAPHostname:    "netweaver02",
+				Features:       "ABAP|GATEWAY|ICMAN|IGS",
+				Status:         "SAPControl-GREEN",
+				Queues: []*models.SAPInstanceQueue{
+					{Type: "ABAP/NOWP", Now: 0, High: 2, Max: 14000},
+					{Type: "ABAP/DIA", Now: 12600, High: 13000, Max: 14000},
+				},
+				WorkProcesses: []*models.SAPInstanceWorkProcesses{
+					{Type: "DIA", Total: 10, Busy: 10},
+					{Type: "BTC", Total: 4, Busy: 1},
+				},
+				QueueOverloadedSince: &overloadedSince,
 			},
 		},
 	}, nil)
@@ -218,6 +304,10 @@ func TestSAPResourceHandler(t *testing.T) {
 	assert.Contains(t, responseBody, "PRD")
 	// Layout
 	assert.Regexp(t, regexp.MustCompile("<tr><td>netweaver01</td><td>00</td><td>MESSAGESERVER\\|ENQUE</td><td>50013</td><td>50014</td><td>0.5</td><td><span.*primary.*>SAPControl-GREEN</span></td></tr>"), responseBody)
+	// Alerts
+	assert.Regexp(t, regexp.MustCompile("<tr><td><i .*text-danger.*>error</i></td><td>netweaver01</td><td>00</td><td>Dialog</td><td>ResponseTime</td><td>Dialog response time exceeds the threshold</td><td>2021 11 10 10:25:12</td></tr>"), responseBody)
+	// Dispatcher
+	assert.Regexp(t, regexp.MustCompile("<tr><td><i .*text-warning.*Queues overloaded since 2021-11-10 10:25:12.*>warning</i></td><td>netweaver02</td><td>02</td><td><span .*>DIA 10/10</span><span .*>BTC 1/4</span></td><td><span .*badge-secondary.*>ABAP/NOWP 0/14000 \\(0%\\)</span><span .*badge-warning.*>ABAP/DIA 12600/14000 \\(90%\\)</span></td></tr>"), responseBody)
 	// Host
 	assert.Regexp(t, regexp.MustCompile("<tr><td>.*check_circle.*</td><td .*><a href=/hosts/netweaver01>netweaver01</a></td><td>192.168.10.10</td><td>azure</td><td><a href=/clusters/cluster_id>netweaver</a></td><td>v0</td></tr>"), responseBody)
 }
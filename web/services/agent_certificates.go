@@ -0,0 +1,238 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/trento-project/trento/internal/pki"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+)
+
+var (
+	ErrInternalCADisabled         = errors.New("the internal CA is not enabled")
+	ErrInvalidCertificateRequest  = errors.New("the certificate request is invalid")
+	ErrCertificateRequestNotFound = errors.New("no pending certificate request for the agent")
+	ErrAgentCertificateNotFound   = errors.New("no certificate request or certificate for the agent")
+	ErrAgentCertificateExists     = errors.New("the agent already has a certificate request or certificate")
+	ErrInvalidAgentCertificate    = errors.New("the agent certificate is not the one issued to the agent or it was revoked")
+)
+
+//go:generate mockery --name=AgentCertificatesService --inpackage --filename=agent_certificates_mock.go
+type AgentCertificatesService interface {
+	Request(agentID string, csr string, authenticated bool) error
+	Approve(agentID string) (*models.AgentCertificate, error)
+	Authenticate(agentID string, serialNumber string) error
+	Renew(agentID string, serialNumber string, csr string) (*models.AgentCertificate, error)
+	Revoke(agentID string) error
+	Delete(agentID string) error
+	GetByAgentID(agentID string) (*models.AgentCertificate, error)
+	GetAll() ([]*models.AgentCertificate, error)
+}
+
+type agentCertificatesService struct {
+	db       *gorm.DB
+	ca       *pki.CertificateAuthority
+	validity time.Duration
+}
+
+// NewAgentCertificatesService creates the service issuing the agent client certificates with the given CA.
+// Certificates can not be issued if the CA is nil
+func NewAgentCertificatesService(db *gorm.DB, ca *pki.CertificateAuthority, validity time.Duration) *agentCertificatesService {
+	return &agentCertificatesService{db: db, ca: ca, validity: validity}
+}
+
+// Request stores the certificate signing request of an agent, pending the approval of an administrator.
+// A previous request of the agent is only replaced if the agent proved its identity, while its issued
+// certificate is kept until the new one is approved
+func (s *agentCertificatesService) Request(agentID string, csr string, authenticated bool) error {
+	if s.ca == nil {
+		return ErrInternalCADisabled
+	}
+
+	request, err := pki.ParseCertificateRequest([]byte(csr))
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidCertificateRequest, err)
+	}
+	if request.Subject.CommonName != agentID {
+		return fmt.Errorf("%w: the request is not for agent %s", ErrInvalidCertificateRequest, agentID)
+	}
+
+	certificate := &entities.AgentCertificate{
+		AgentID:     agentID,
+		Status:      models.AgentCertificatePending,
+		Request:     csr,
+		RequestedAt: time.Now(),
+	}
+
+	if !authenticated {
+		result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(certificate)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAgentCertificateExists
+		}
+
+		return nil
+	}
+
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "agent_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "request", "requested_at"}),
+	}).Create(certificate).Error
+}
+
+// Approve signs the pending certificate signing request of an agent
+func (s *agentCertificatesService) Approve(agentID string) (*models.AgentCertificate, error) {
+	var certificate entities.AgentCertificate
+
+	err := s.db.Where("agent_id = ? AND status = ?", agentID, models.AgentCertificatePending).First(&certificate).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCertificateRequestNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issue(&certificate)
+}
+
+// Authenticate checks that the certificate with the given serial number is the last one issued
+// to the agent, and that it was not revoked
+func (s *agentCertificatesService) Authenticate(agentID string, serialNumber string) error {
+	if serialNumber == "" {
+		return ErrInvalidAgentCertificate
+	}
+
+	var count int64
+
+	err := s.db.Model(&entities.AgentCertificate{}).
+		Where("agent_id = ? AND serial_number = ? AND revoked_at IS NULL", agentID, serialNumber).
+		Count(&count).
+		Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrInvalidAgentCertificate
+	}
+
+	return nil
+}
+
+// Renew immediately signs a new certificate signing request of an agent, which must have been
+// authenticated by the certificate previously issued to it, with the given serial number
+func (s *agentCertificatesService) Renew(agentID string, serialNumber string, csr string) (*models.AgentCertificate, error) {
+	if s.ca == nil {
+		return nil, ErrInternalCADisabled
+	}
+
+	err := s.Authenticate(agentID, serialNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	certificate := &entities.AgentCertificate{
+		AgentID:     agentID,
+		Request:     csr,
+		RequestedAt: time.Now(),
+	}
+
+	return s.issue(certificate)
+}
+
+func (s *agentCertificatesService) issue(certificate *entities.AgentCertificate) (*models.AgentCertificate, error) {
+	if s.ca == nil {
+		return nil, ErrInternalCADisabled
+	}
+
+	certPEM, signed, err := s.ca.Sign([]byte(certificate.Request), certificate.AgentID, s.validity)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCertificateRequest, err)
+	}
+
+	now := time.Now()
+	certificate.Status = models.AgentCertificateIssued
+	certificate.Certificate = string(certPEM)
+	certificate.SerialNumber = signed.SerialNumber.Text(16)
+	certificate.IssuedAt = &now
+	certificate.NotAfter = &signed.NotAfter
+	certificate.RevokedAt = nil
+
+	err = s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "agent_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"status", "request", "certificate", "serial_number", "requested_at", "issued_at", "not_after", "revoked_at",
+		}),
+	}).Create(certificate).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return certificate.ToModel(), nil
+}
+
+// Revoke revokes the certificate issued to an agent, which can not use nor renew it anymore.
+// The agent has to prove its identity with its credentials to request a new one
+func (s *agentCertificatesService) Revoke(agentID string) error {
+	result := s.db.Model(&entities.AgentCertificate{}).
+		Where("agent_id = ? AND serial_number <> '' AND revoked_at IS NULL", agentID).
+		Updates(map[string]interface{}{"status": models.AgentCertificateRevoked, "revoked_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAgentCertificateNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes the certificate request and certificate of an agent, so that it can request a new one
+// without proving its identity
+func (s *agentCertificatesService) Delete(agentID string) error {
+	result := s.db.Where("agent_id = ?", agentID).Delete(&entities.AgentCertificate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAgentCertificateNotFound
+	}
+
+	return nil
+}
+
+func (s *agentCertificatesService) GetByAgentID(agentID string) (*models.AgentCertificate, error) {
+	var certificate entities.AgentCertificate
+
+	err := s.db.Where("agent_id = ?", agentID).First(&certificate).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAgentCertificateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return certificate.ToModel(), nil
+}
+
+func (s *agentCertificatesService) GetAll() ([]*models.AgentCertificate, error) {
+	var certificates []entities.AgentCertificate
+
+	err := s.db.Order("agent_id").Find(&certificates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var agentCertificates []*models.AgentCertificate
+	for _, c := range certificates {
+		agentCertificates = append(agentCertificates, c.ToModel())
+	}
+
+	return agentCertificates, nil
+}
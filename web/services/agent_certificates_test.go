@@ -0,0 +1,185 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/internal/pki"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+type AgentCertificatesServiceTestSuite struct {
+	suite.Suite
+	db                       *gorm.DB
+	tx                       *gorm.DB
+	ca                       *pki.CertificateAuthority
+	agentCertificatesService *agentCertificatesService
+}
+
+func TestAgentCertificatesServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AgentCertificatesServiceTestSuite))
+}
+
+func (suite *AgentCertificatesServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.AgentCertificate{})
+
+	ca, err := pki.NewCertificateAuthority(helpers.NewCertificateAuthorityPEM(suite.T()))
+	suite.NoError(err)
+	suite.ca = ca
+}
+
+func (suite *AgentCertificatesServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.AgentCertificate{})
+}
+
+func (suite *AgentCertificatesServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.agentCertificatesService = NewAgentCertificatesService(suite.tx, suite.ca, 24*time.Hour)
+}
+
+func (suite *AgentCertificatesServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func (suite *AgentCertificatesServiceTestSuite) TestAgentCertificatesService_RequestAndApprove() {
+	csr, _, err := pki.NewCertificateRequest("agent_1")
+	suite.NoError(err)
+
+	err = suite.agentCertificatesService.Request("agent_1", string(csr), false)
+	suite.NoError(err)
+
+	certificate, err := suite.agentCertificatesService.GetByAgentID("agent_1")
+	suite.NoError(err)
+	suite.Equal(models.AgentCertificatePending, certificate.Status)
+	suite.Empty(certificate.Certificate)
+
+	certificate, err = suite.agentCertificatesService.Approve("agent_1")
+	suite.NoError(err)
+	suite.Equal(models.AgentCertificateIssued, certificate.Status)
+	suite.NotEmpty(certificate.Certificate)
+	suite.NotEmpty(certificate.SerialNumber)
+	suite.WithinDuration(time.Now().Add(24*time.Hour), *certificate.NotAfter, time.Minute)
+
+	stored, err := suite.agentCertificatesService.GetByAgentID("agent_1")
+	suite.NoError(err)
+	suite.Equal(certificate.Certificate, stored.Certificate)
+
+	_, err = suite.agentCertificatesService.Approve("agent_1")
+	suite.ErrorIs(err, ErrCertificateRequestNotFound)
+}
+
+func (suite *AgentCertificatesServiceTestSuite) TestAgentCertificatesService_RequestInvalid() {
+	csr, _, err := pki.NewCertificateRequest("agent_2")
+	suite.NoError(err)
+
+	err = suite.agentCertificatesService.Request("agent_1", string(csr), false)
+	suite.ErrorIs(err, ErrInvalidCertificateRequest)
+
+	err = suite.agentCertificatesService.Request("agent_1", "not a csr", false)
+	suite.ErrorIs(err, ErrInvalidCertificateRequest)
+
+	_, err = suite.agentCertificatesService.GetByAgentID("agent_1")
+	suite.ErrorIs(err, ErrAgentCertificateNotFound)
+}
+
+func (suite *AgentCertificatesServiceTestSuite) TestAgentCertificatesService_RequestAgain() {
+	csr, _, err := pki.NewCertificateRequest("agent_1")
+	suite.NoError(err)
+
+	suite.NoError(suite.agentCertificatesService.Request("agent_1", string(csr), false))
+	issued, err := suite.agentCertificatesService.Approve("agent_1")
+	suite.NoError(err)
+
+	// Only the agents proving their identity replace their request
+	newCSR, _, err := pki.NewCertificateRequest("agent_1")
+	suite.NoError(err)
+
+	err = suite.agentCertificatesService.Request("agent_1", string(newCSR), false)
+	suite.ErrorIs(err, ErrAgentCertificateExists)
+
+	certificate, _ := suite.agentCertificatesService.GetByAgentID("agent_1")
+	suite.Equal(models.AgentCertificateIssued, certificate.Status)
+
+	suite.NoError(suite.agentCertificatesService.Request("agent_1", string(newCSR), true))
+
+	certificate, _ = suite.agentCertificatesService.GetByAgentID("agent_1")
+	suite.Equal(models.AgentCertificatePending, certificate.Status)
+	suite.Equal(issued.SerialNumber, certificate.SerialNumber)
+
+	// Deleted requests can be submitted again by anyone
+	suite.NoError(suite.agentCertificatesService.Delete("agent_1"))
+	suite.ErrorIs(suite.agentCertificatesService.Delete("agent_1"), ErrAgentCertificateNotFound)
+	suite.NoError(suite.agentCertificatesService.Request("agent_1", string(newCSR), false))
+}
+
+func (suite *AgentCertificatesServiceTestSuite) TestAgentCertificatesService_Renew() {
+	csr, _, err := pki.NewCertificateRequest("agent_1")
+	suite.NoError(err)
+
+	suite.NoError(suite.agentCertificatesService.Request("agent_1", string(csr), false))
+	first, err := suite.agentCertificatesService.Approve("agent_1")
+	suite.NoError(err)
+
+	second, err := suite.agentCertificatesService.Renew("agent_1", first.SerialNumber, string(csr))
+	suite.NoError(err)
+	suite.NotEqual(first.SerialNumber, second.SerialNumber)
+
+	// The replaced certificate can not be used anymore
+	_, err = suite.agentCertificatesService.Renew("agent_1", first.SerialNumber, string(csr))
+	suite.ErrorIs(err, ErrInvalidAgentCertificate)
+
+	_, err = suite.agentCertificatesService.Renew("agent_2", second.SerialNumber, string(csr))
+	suite.ErrorIs(err, ErrInvalidAgentCertificate)
+
+	certificates, err := suite.agentCertificatesService.GetAll()
+	suite.NoError(err)
+	suite.Len(certificates, 1)
+	suite.Equal(second.SerialNumber, certificates[0].SerialNumber)
+}
+
+func (suite *AgentCertificatesServiceTestSuite) TestAgentCertificatesService_Revoke() {
+	csr, _, err := pki.NewCertificateRequest("agent_1")
+	suite.NoError(err)
+
+	suite.NoError(suite.agentCertificatesService.Request("agent_1", string(csr), false))
+	suite.ErrorIs(suite.agentCertificatesService.Revoke("agent_1"), ErrAgentCertificateNotFound)
+
+	issued, err := suite.agentCertificatesService.Approve("agent_1")
+	suite.NoError(err)
+	suite.NoError(suite.agentCertificatesService.Authenticate("agent_1", issued.SerialNumber))
+
+	suite.NoError(suite.agentCertificatesService.Revoke("agent_1"))
+	suite.ErrorIs(suite.agentCertificatesService.Revoke("agent_1"), ErrAgentCertificateNotFound)
+	suite.ErrorIs(suite.agentCertificatesService.Authenticate("agent_1", issued.SerialNumber), ErrInvalidAgentCertificate)
+
+	_, err = suite.agentCertificatesService.Renew("agent_1", issued.SerialNumber, string(csr))
+	suite.ErrorIs(err, ErrInvalidAgentCertificate)
+
+	certificate, _ := suite.agentCertificatesService.GetByAgentID("agent_1")
+	suite.Equal(models.AgentCertificateRevoked, certificate.Status)
+	suite.True(certificate.IsRevoked())
+
+	// A new certificate is issued once the agent proves its identity otherwise
+	suite.NoError(suite.agentCertificatesService.Request("agent_1", string(csr), true))
+	reissued, err := suite.agentCertificatesService.Approve("agent_1")
+	suite.NoError(err)
+	suite.False(reissued.IsRevoked())
+	suite.NoError(suite.agentCertificatesService.Authenticate("agent_1", reissued.SerialNumber))
+}
+
+func (suite *AgentCertificatesServiceTestSuite) TestAgentCertificatesService_CADisabled() {
+	service := NewAgentCertificatesService(suite.tx, nil, time.Hour)
+
+	csr, _, err := pki.NewCertificateRequest("agent_1")
+	suite.NoError(err)
+
+	suite.ErrorIs(service.Request("agent_1", string(csr), false), ErrInternalCADisabled)
+	_, err = service.Renew("agent_1", "1f", string(csr))
+	suite.ErrorIs(err, ErrInternalCADisabled)
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+)
+
+const EnrollmentTokenTTL = 24 * time.Hour
+
+var (
+	ErrInvalidEnrollmentToken  = errors.New("the enrollment token is invalid, expired or already used")
+	ErrInvalidAgentCredentials = errors.New("the agent credentials are invalid or revoked")
+	ErrAgentNotEnrolled        = errors.New("the agent is not enrolled")
+	ErrAgentAlreadyEnrolled    = errors.New("the agent is already enrolled, its credentials must be deleted to enroll it again")
+)
+
+//go:generate mockery --name=AgentCredentialsService --inpackage --filename=agent_credentials_mock.go
+type AgentCredentialsService interface {
+	CreateEnrollmentToken() (*models.EnrollmentToken, error)
+	Enroll(token string, agentID string) (string, error)
+	Authenticate(agentID string, secret string) error
+	Revoke(agentID string) error
+	Delete(agentID string) error
+	GetAll() ([]*models.AgentCredentials, error)
+}
+
+type agentCredentialsService struct {
+	db *gorm.DB
+}
+
+func NewAgentCredentialsService(db *gorm.DB) *agentCredentialsService {
+	return &agentCredentialsService{db: db}
+}
+
+// CreateEnrollmentToken creates a one-time token to enroll an agent, valid for EnrollmentTokenTTL
+func (s *agentCredentialsService) CreateEnrollmentToken() (*models.EnrollmentToken, error) {
+	token, err := newRandomSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	enrollmentToken := &entities.EnrollmentToken{
+		TokenHash: hashSecret(token),
+		ExpiresAt: time.Now().Add(EnrollmentTokenTTL),
+	}
+
+	err = s.db.Create(enrollmentToken).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.EnrollmentToken{
+		Token:     token,
+		ExpiresAt: enrollmentToken.ExpiresAt,
+	}, nil
+}
+
+// Enroll exchanges an enrollment token for a new agent secret. Agents already holding credentials,
+// even revoked ones, cannot enroll again until an admin deletes them
+func (s *agentCredentialsService) Enroll(token string, agentID string) (string, error) {
+	secret, err := newRandomSecret()
+	if err != nil {
+		return "", err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		var count int64
+		err := tx.Model(&entities.AgentCredentials{}).Where("agent_id = ?", agentID).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrAgentAlreadyEnrolled
+		}
+
+		result := tx.Model(&entities.EnrollmentToken{}).
+			Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", hashSecret(token), now).
+			Updates(map[string]interface{}{"used_at": now, "used_by": agentID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidEnrollmentToken
+		}
+
+		credentials := &entities.AgentCredentials{
+			AgentID:    agentID,
+			SecretHash: hashSecret(secret),
+			EnrolledAt: now,
+		}
+
+		return tx.Create(credentials).Error
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
+
+// Authenticate checks the secret of an agent, failing if its credentials were revoked
+func (s *agentCredentialsService) Authenticate(agentID string, secret string) error {
+	var credentials entities.AgentCredentials
+
+	err := s.db.Where("agent_id = ? AND revoked_at IS NULL", agentID).First(&credentials).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvalidAgentCredentials
+	}
+	if err != nil {
+		return err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(credentials.SecretHash), []byte(hashSecret(secret))) != 1 {
+		return ErrInvalidAgentCredentials
+	}
+
+	return nil
+}
+
+// Revoke revokes the credentials of an agent, so its requests are rejected. The agent cannot enroll
+// again until its credentials are deleted
+func (s *agentCredentialsService) Revoke(agentID string) error {
+	result := s.db.Model(&entities.AgentCredentials{}).
+		Where("agent_id = ? AND revoked_at IS NULL", agentID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAgentNotEnrolled
+	}
+
+	return nil
+}
+
+// Delete deletes the credentials of an agent, either active or revoked, so that it can enroll again
+func (s *agentCredentialsService) Delete(agentID string) error {
+	result := s.db.Where("agent_id = ?", agentID).Delete(&entities.AgentCredentials{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAgentNotEnrolled
+	}
+
+	return nil
+}
+
+func (s *agentCredentialsService) GetAll() ([]*models.AgentCredentials, error) {
+	var credentials []entities.AgentCredentials
+
+	err := s.db.Order("agent_id").Find(&credentials).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var agentCredentials []*models.AgentCredentials
+	for _, c := range credentials {
+		agentCredentials = append(agentCredentials, c.ToModel())
+	}
+
+	return agentCredentials, nil
+}
+
+func newRandomSecret() (string, error) {
+	secret := make([]byte, 32)
+
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(secret), nil
+}
+
+func hashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
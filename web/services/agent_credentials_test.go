@@ -0,0 +1,146 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"gorm.io/gorm"
+)
+
+type AgentCredentialsServiceTestSuite struct {
+	suite.Suite
+	db                      *gorm.DB
+	tx                      *gorm.DB
+	agentCredentialsService *agentCredentialsService
+}
+
+func TestAgentCredentialsServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AgentCredentialsServiceTestSuite))
+}
+
+func (suite *AgentCredentialsServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.EnrollmentToken{}, &entities.AgentCredentials{})
+}
+
+func (suite *AgentCredentialsServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.EnrollmentToken{}, &entities.AgentCredentials{})
+}
+
+func (suite *AgentCredentialsServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.agentCredentialsService = NewAgentCredentialsService(suite.tx)
+}
+
+func (suite *AgentCredentialsServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func (suite *AgentCredentialsServiceTestSuite) TestAgentCredentialsService_EnrollAndAuthenticate() {
+	token, err := suite.agentCredentialsService.CreateEnrollmentToken()
+	suite.NoError(err)
+	suite.WithinDuration(time.Now().Add(EnrollmentTokenTTL), token.ExpiresAt, time.Minute)
+
+	secret, err := suite.agentCredentialsService.Enroll(token.Token, "agent_1")
+	suite.NoError(err)
+	suite.NotEmpty(secret)
+
+	suite.NoError(suite.agentCredentialsService.Authenticate("agent_1", secret))
+	suite.ErrorIs(suite.agentCredentialsService.Authenticate("agent_1", "wrong-secret"), ErrInvalidAgentCredentials)
+	suite.ErrorIs(suite.agentCredentialsService.Authenticate("agent_2", secret), ErrInvalidAgentCredentials)
+
+	var storedToken entities.EnrollmentToken
+	suite.tx.First(&storedToken)
+	suite.NotEqual(token.Token, storedToken.TokenHash)
+	suite.Equal("agent_1", storedToken.UsedBy)
+}
+
+func (suite *AgentCredentialsServiceTestSuite) TestAgentCredentialsService_TokenUsedOnce() {
+	token, err := suite.agentCredentialsService.CreateEnrollmentToken()
+	suite.NoError(err)
+
+	_, err = suite.agentCredentialsService.Enroll(token.Token, "agent_1")
+	suite.NoError(err)
+
+	_, err = suite.agentCredentialsService.Enroll(token.Token, "agent_2")
+	suite.ErrorIs(err, ErrInvalidEnrollmentToken)
+
+	_, err = suite.agentCredentialsService.Enroll("unknown-token", "agent_2")
+	suite.ErrorIs(err, ErrInvalidEnrollmentToken)
+}
+
+func (suite *AgentCredentialsServiceTestSuite) TestAgentCredentialsService_ExpiredToken() {
+	token, err := suite.agentCredentialsService.CreateEnrollmentToken()
+	suite.NoError(err)
+
+	suite.tx.Model(&entities.EnrollmentToken{}).Where("1 = 1").Update("expires_at", time.Now().Add(-time.Minute))
+
+	_, err = suite.agentCredentialsService.Enroll(token.Token, "agent_1")
+	suite.ErrorIs(err, ErrInvalidEnrollmentToken)
+}
+
+func (suite *AgentCredentialsServiceTestSuite) TestAgentCredentialsService_EnrollAgain() {
+	token, _ := suite.agentCredentialsService.CreateEnrollmentToken()
+	secret, err := suite.agentCredentialsService.Enroll(token.Token, "agent_1")
+	suite.NoError(err)
+
+	token, _ = suite.agentCredentialsService.CreateEnrollmentToken()
+	_, err = suite.agentCredentialsService.Enroll(token.Token, "agent_1")
+	suite.ErrorIs(err, ErrAgentAlreadyEnrolled)
+
+	// The existing credentials are kept and the token is not consumed
+	suite.NoError(suite.agentCredentialsService.Authenticate("agent_1", secret))
+	_, err = suite.agentCredentialsService.Enroll(token.Token, "agent_2")
+	suite.NoError(err)
+}
+
+func (suite *AgentCredentialsServiceTestSuite) TestAgentCredentialsService_RevokeAndEnrollAgain() {
+	token, _ := suite.agentCredentialsService.CreateEnrollmentToken()
+	secret, err := suite.agentCredentialsService.Enroll(token.Token, "agent_1")
+	suite.NoError(err)
+
+	suite.NoError(suite.agentCredentialsService.Revoke("agent_1"))
+	suite.ErrorIs(suite.agentCredentialsService.Authenticate("agent_1", secret), ErrInvalidAgentCredentials)
+	suite.ErrorIs(suite.agentCredentialsService.Revoke("agent_1"), ErrAgentNotEnrolled)
+
+	credentials, err := suite.agentCredentialsService.GetAll()
+	suite.NoError(err)
+	suite.Len(credentials, 1)
+	suite.NotNil(credentials[0].RevokedAt)
+
+	token, _ = suite.agentCredentialsService.CreateEnrollmentToken()
+	_, err = suite.agentCredentialsService.Enroll(token.Token, "agent_1")
+	suite.ErrorIs(err, ErrAgentAlreadyEnrolled)
+
+	credentials, _ = suite.agentCredentialsService.GetAll()
+	suite.NotNil(credentials[0].RevokedAt)
+}
+
+func (suite *AgentCredentialsServiceTestSuite) TestAgentCredentialsService_DeleteAndEnrollAgain() {
+	token, _ := suite.agentCredentialsService.CreateEnrollmentToken()
+	secret, err := suite.agentCredentialsService.Enroll(token.Token, "agent_1")
+	suite.NoError(err)
+	suite.NoError(suite.agentCredentialsService.Revoke("agent_1"))
+
+	suite.NoError(suite.agentCredentialsService.Delete("agent_1"))
+	suite.ErrorIs(suite.agentCredentialsService.Delete("agent_1"), ErrAgentNotEnrolled)
+
+	token, _ = suite.agentCredentialsService.CreateEnrollmentToken()
+	newSecret, err := suite.agentCredentialsService.Enroll(token.Token, "agent_1")
+	suite.NoError(err)
+
+	suite.NoError(suite.agentCredentialsService.Authenticate("agent_1", newSecret))
+	suite.ErrorIs(suite.agentCredentialsService.Authenticate("agent_1", secret), ErrInvalidAgentCredentials)
+
+	credentials, _ := suite.agentCredentialsService.GetAll()
+	suite.Len(credentials, 1)
+	suite.Nil(credentials[0].RevokedAt)
+}
+
+func (suite *AgentCredentialsServiceTestSuite) TestAgentCredentialsService_RevokeUnknownAgent() {
+	suite.ErrorIs(suite.agentCredentialsService.Revoke("unknown"), ErrAgentNotEnrolled)
+}
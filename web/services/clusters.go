@@ -129,6 +129,22 @@ func (s *clustersService) GetByID(clusterID string) (*models.Cluster, error) {
 		s.enrichClusterNodes(detail.Nodes, cluster.ID, cluster.Hosts)
 		s.enrichCluster(clusterModel)
 		clusterModel.Details = detail
+	case models.ClusterTypeASCSERS:
+		var clusterDetailASCSERS entities.ASCSERSClusterDetails
+
+		err := json.Unmarshal(cluster.Details, &clusterDetailASCSERS)
+		if err != nil {
+			return nil, err
+		}
+
+		detail := clusterDetailASCSERS.ToModel()
+		s.enrichClusterNodes(detail.Nodes, cluster.ID, cluster.Hosts)
+		err = s.enrichASCSERSInstances(detail.Instances, cluster.Hosts)
+		if err != nil {
+			return nil, err
+		}
+		s.enrichCluster(clusterModel)
+		clusterModel.Details = detail
 	default:
 		clusterModel.Details = nil
 	}
@@ -357,3 +373,36 @@ func (s *clustersService) enrichClusterNodes(nodes []*models.HANAClusterNode, cl
 		}
 	}
 }
+
+// enrichASCSERSInstances links the ASCS and ERS instances of a cluster to the discovered SAP systems
+// and to the hosts running them
+func (s *clustersService) enrichASCSERSInstances(instances []*models.ASCSERSInstance, hosts []*entities.Host) error {
+	var agentIDs []string
+	for _, host := range hosts {
+		agentIDs = append(agentIDs, host.AgentID)
+	}
+
+	for _, instance := range instances {
+		for _, host := range hosts {
+			if instance.Node == host.Name {
+				instance.HostID = host.AgentID
+				break
+			}
+		}
+
+		var sapSystemInstance entities.SAPSystemInstance
+		err := s.db.
+			Where("sid = ? AND instance_number = ? AND agent_id IN (?)", instance.SID, instance.InstanceNumber, agentIDs).
+			Limit(1).
+			Find(&sapSystemInstance).
+			Error
+		if err != nil {
+			return err
+		}
+
+		instance.SAPSystemID = sapSystemInstance.ID
+		instance.HAConfig = sapSystemInstance.HAConfigToModel()
+	}
+
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/trento-project/trento/test/helpers"
 	"github.com/trento-project/trento/web/entities"
 	"github.com/trento-project/trento/web/models"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -30,6 +31,7 @@ func (suite *ClustersServiceTestSuite) SetupSuite() {
 	suite.db.AutoMigrate(
 		entities.Cluster{}, entities.Host{}, models.Tag{}, models.SelectedChecks{},
 		models.ConnectionSettings{}, entities.ChecksResult{}, entities.HealthState{},
+		entities.SAPSystemInstance{},
 	)
 	loadClustersFixtures(suite.db)
 }
@@ -38,6 +40,7 @@ func (suite *ClustersServiceTestSuite) TearDownSuite() {
 	suite.db.Migrator().DropTable(
 		entities.Cluster{}, entities.Host{}, models.Tag{}, models.SelectedChecks{},
 		models.ConnectionSettings{}, entities.ChecksResult{}, entities.HealthState{},
+		entities.SAPSystemInstance{},
 	)
 }
 
@@ -291,6 +294,88 @@ func (suite *ClustersServiceTestSuite) TestClustersService_GetByID() {
 		},
 	}, cluster.Details.(*models.HANAClusterDetails))
 }
+
+func (suite *ClustersServiceTestSuite) TestClustersService_GetByID_ASCSERS() {
+	details, _ := json.Marshal(&entities.ASCSERSClusterDetails{
+		EnsaVersion: models.EnsaVersion2,
+		Nodes: []*entities.HANAClusterNode{
+			{
+				Name: "nwp01",
+			},
+		},
+		Instances: []*entities.ASCSERSInstance{
+			{
+				Type:           models.ASCSERSInstanceTypeASCS,
+				SID:            "NWP",
+				InstanceNumber: "00",
+				Node:           "nwp01",
+			},
+		},
+	})
+
+	suite.tx.Create(&entities.Cluster{
+		ID:          "ascs_ers",
+		Name:        "netweaver_cluster",
+		ClusterType: models.ClusterTypeASCSERS,
+		SID:         "NWP",
+		Details:     details,
+		Hosts: []*entities.Host{
+			{
+				AgentID:     "nwp01_id",
+				Name:        "nwp01",
+				IPAddresses: pq.StringArray{"10.80.1.10"},
+			},
+		},
+	})
+	suite.tx.Create(&entities.SAPSystemInstance{
+		ID:             "nwp_copy_system_id",
+		AgentID:        "nwp_copy_id",
+		SID:            "NWP",
+		InstanceNumber: "00",
+	})
+	suite.tx.Create(&entities.SAPSystemInstance{
+		ID:             "nwp_system_id",
+		AgentID:        "nwp01_id",
+		SID:            "NWP",
+		InstanceNumber: "00",
+		HAConfig:       datatypes.JSON(`{"active": true, "checks": [{"state": "SAPControl-HA-SUCCESS", "description": "HA software"}]}`),
+	})
+
+	suite.checksService.On("GetAggregatedChecksResultByCluster", "ascs_ers").Return(&models.AggregatedCheckData{}, nil)
+	suite.checksService.On("GetAggregatedChecksResultByHost", "ascs_ers").Return(map[string]*models.AggregatedCheckData{}, nil)
+
+	cluster, err := suite.clustersService.GetByID("ascs_ers")
+
+	suite.NoError(err)
+	suite.EqualValues(&models.ASCSERSClusterDetails{
+		EnsaVersion: models.EnsaVersion2,
+		Nodes: []*models.HANAClusterNode{
+			{
+				HostID:      "nwp01_id",
+				Name:        "nwp01",
+				Health:      models.CheckUndefined,
+				IPAddresses: []string{"10.80.1.10"},
+			},
+		},
+		Instances: []*models.ASCSERSInstance{
+			{
+				Type:           models.ASCSERSInstanceTypeASCS,
+				SID:            "NWP",
+				InstanceNumber: "00",
+				Node:           "nwp01",
+				SAPSystemID:    "nwp_system_id",
+				HostID:         "nwp01_id",
+				HAConfig: &models.SAPInstanceHAConfig{
+					Active: true,
+					Checks: []*models.SAPInstanceHACheck{
+						{State: "SAPControl-HA-SUCCESS", Description: "HA software"},
+					},
+				},
+			},
+		},
+	}, cluster.Details.(*models.ASCSERSClusterDetails))
+}
+
 func (suite *ClustersServiceTestSuite) TestClustersService_GetByID_NotFound() {
 	cluster, err := suite.clustersService.GetByID("not_there")
 
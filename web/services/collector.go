@@ -1,13 +1,20 @@
 package services
 
 import (
+	"errors"
+
+	"github.com/trento-project/trento/internal"
 	"github.com/trento-project/trento/web/datapipeline"
 	"gorm.io/gorm"
 )
 
+var ErrResyncRequired = errors.New("the unchanged payload does not match the last one collected")
+
 //go:generate mockery --name=CollectorService --inpackage --filename=collector_mock.go
 type CollectorService interface {
 	StoreEvent(dataCollected *datapipeline.DataCollectedEvent) error
+	StoreEvents(dataCollected []*datapipeline.DataCollectedEvent) error
+	ConfirmUnchanged(dataUnchanged *datapipeline.DataUnchangedEvent) error
 }
 
 type collectorService struct {
@@ -20,6 +27,8 @@ func NewCollectorService(db *gorm.DB, projectorsChannel chan *datapipeline.DataC
 }
 
 func (c *collectorService) StoreEvent(collectedData *datapipeline.DataCollectedEvent) error {
+	collectedData.PayloadHash = internal.Md5sum(string(collectedData.Payload))
+
 	if err := c.db.Create(collectedData).Error; err != nil {
 		return err
 	}
@@ -27,3 +36,53 @@ func (c *collectorService) StoreEvent(collectedData *datapipeline.DataCollectedE
 
 	return nil
 }
+
+// StoreEvents stores the events of a batch all at once, none of them is projected if any fails
+func (c *collectorService) StoreEvents(collectedData []*datapipeline.DataCollectedEvent) error {
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		for _, e := range collectedData {
+			e.PayloadHash = internal.Md5sum(string(e.Payload))
+
+			if err := tx.Create(e).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, e := range collectedData {
+		c.projectorsChannel <- e
+	}
+
+	return nil
+}
+
+// ConfirmUnchanged checks that an unchanged payload notified by an agent matches the last one collected.
+// No event is stored nor projected, as the projections are already up to date
+func (c *collectorService) ConfirmUnchanged(dataUnchanged *datapipeline.DataUnchangedEvent) error {
+	var lastEvent datapipeline.DataCollectedEvent
+
+	err := c.db.
+		Select("id", "payload_hash").
+		Where("agent_id = ? AND discovery_type = ?", dataUnchanged.AgentID, dataUnchanged.DiscoveryType).
+		Order("id DESC").
+		First(&lastEvent).
+		Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrResyncRequired
+	}
+	if err != nil {
+		return err
+	}
+
+	if lastEvent.PayloadHash != dataUnchanged.PayloadHash {
+		return ErrResyncRequired
+	}
+
+	return nil
+}
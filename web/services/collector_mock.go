@@ -12,6 +12,20 @@ type MockCollectorService struct {
 	mock.Mock
 }
 
+// ConfirmUnchanged provides a mock function with given fields: dataUnchanged
+func (_m *MockCollectorService) ConfirmUnchanged(dataUnchanged *datapipeline.DataUnchangedEvent) error {
+	ret := _m.Called(dataUnchanged)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*datapipeline.DataUnchangedEvent) error); ok {
+		r0 = rf(dataUnchanged)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // StoreEvent provides a mock function with given fields: dataCollected
 func (_m *MockCollectorService) StoreEvent(dataCollected *datapipeline.DataCollectedEvent) error {
 	ret := _m.Called(dataCollected)
@@ -25,3 +39,17 @@ func (_m *MockCollectorService) StoreEvent(dataCollected *datapipeline.DataColle
 
 	return r0
 }
+
+// StoreEvents provides a mock function with given fields: dataCollected
+func (_m *MockCollectorService) StoreEvents(dataCollected []*datapipeline.DataCollectedEvent) error {
+	ret := _m.Called(dataCollected)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]*datapipeline.DataCollectedEvent) error); ok {
+		r0 = rf(dataCollected)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
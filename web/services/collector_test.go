@@ -35,7 +35,7 @@ func (suite *CollectorServiceTestSuite) TearDownSuite() {
 func (suite *CollectorServiceTestSuite) SetupTest() {
 	suite.tx = suite.db.Begin()
 
-	ch := make(chan *datapipeline.DataCollectedEvent, 1)
+	ch := make(chan *datapipeline.DataCollectedEvent, 2)
 	suite.ch = ch
 	suite.collectorService = NewCollectorService(suite.tx, ch)
 }
@@ -59,4 +59,58 @@ func (suite *CollectorServiceTestSuite) TestCollectorService_StoreEvent() {
 	suite.EqualValues(eventFromChannel.AgentID, eventFromDB.AgentID)
 	suite.EqualValues(eventFromChannel.DiscoveryType, eventFromDB.DiscoveryType)
 	suite.EqualValues(eventFromChannel.Payload, eventFromDB.Payload)
+	suite.EqualValues("99914b932bd37a50b983c5e7c90ae93b", eventFromDB.PayloadHash)
+}
+
+func (suite *CollectorServiceTestSuite) TestCollectorService_StoreEvents() {
+	err := suite.collectorService.StoreEvents([]*datapipeline.DataCollectedEvent{
+		{
+			AgentID:       "agent_id",
+			DiscoveryType: "host_discovery",
+			Payload:       []byte("{}"),
+		},
+		{
+			AgentID:       "agent_id",
+			DiscoveryType: "cloud_discovery",
+			Payload:       []byte(`{"provider":"azure"}`),
+		},
+	})
+	suite.NoError(err)
+
+	suite.Equal("host_discovery", (<-suite.ch).DiscoveryType)
+	suite.Equal("cloud_discovery", (<-suite.ch).DiscoveryType)
+
+	var count int64
+	suite.tx.Model(&datapipeline.DataCollectedEvent{}).Count(&count)
+	suite.EqualValues(2, count)
+}
+
+func (suite *CollectorServiceTestSuite) TestCollectorService_ConfirmUnchanged() {
+	suite.collectorService.StoreEvent(&datapipeline.DataCollectedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: "test_discovery_type",
+		Payload:       []byte("{}"),
+	})
+	<-suite.ch
+
+	err := suite.collectorService.ConfirmUnchanged(&datapipeline.DataUnchangedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: "test_discovery_type",
+		PayloadHash:   "99914b932bd37a50b983c5e7c90ae93b",
+	})
+	suite.NoError(err)
+
+	err = suite.collectorService.ConfirmUnchanged(&datapipeline.DataUnchangedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: "test_discovery_type",
+		PayloadHash:   "other_hash",
+	})
+	suite.ErrorIs(err, ErrResyncRequired)
+
+	err = suite.collectorService.ConfirmUnchanged(&datapipeline.DataUnchangedEvent{
+		AgentID:       "other_agent_id",
+		DiscoveryType: "test_discovery_type",
+		PayloadHash:   "99914b932bd37a50b983c5e7c90ae93b",
+	})
+	suite.ErrorIs(err, ErrResyncRequired)
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/lib/pq"
 	log "github.com/sirupsen/logrus"
 	"github.com/trento-project/trento/internal"
+	"github.com/trento-project/trento/internal/cloud"
 	"github.com/trento-project/trento/web/entities"
 	"github.com/trento-project/trento/web/models"
 	"gorm.io/gorm"
@@ -19,6 +20,10 @@ import (
 
 const HeartbeatTreshold = internal.HeartbeatInterval * 2
 
+// AgentIdentityConflictWindow is how long an agent is considered to be reporting from the host of its last
+// heartbeats. The same agent id reported in turns by several hosts within the window is an identity conflict
+const AgentIdentityConflictWindow = 2 * time.Minute
+
 var timeSince = time.Since
 
 //go:generate mockery --name=HostsService --inpackage --filename=hosts_mock.go
@@ -29,7 +34,8 @@ type HostsService interface {
 	GetCount() (int, error)
 	GetAllSIDs() ([]string, error)
 	GetAllTags() ([]string, error)
-	Heartbeat(agentID string) error
+	Heartbeat(agentID string, agentStatus *models.AgentStatus, address string) error
+	GetIdentityConflicts() ([]*models.AgentIdentityConflict, error)
 	GetExportersState(hostname string) (map[string]string, error)
 }
 
@@ -75,6 +81,7 @@ func (s *hostsService) GetAll(filter *HostsFilter, page *Page) (models.HostList,
 		Scopes(Paginate(page)).
 		Preload("Tags").
 		Preload("Heartbeat").
+		Preload("Sightings", recentSightings).
 		Preload("SAPSystemInstances").
 		Preload("SAPSystemInstances.Host")
 
@@ -123,6 +130,7 @@ func (s *hostsService) GetByID(id string) (*models.Host, error) {
 	err := s.db.
 		Where("agent_id = ?", id).
 		Preload("Heartbeat").
+		Preload("Sightings", recentSightings).
 		Preload("SAPSystemInstances").
 		First(&host).
 		Error
@@ -138,10 +146,19 @@ func (s *hostsService) GetByID(id string) (*models.Host, error) {
 	modeledHost := host.ToModel()
 	modeledHost.Health = hostHealth
 
-	if modeledHost.CloudProvider == "azure" {
+	switch modeledHost.CloudProvider {
+	case cloud.Azure:
 		var cloudData models.AzureCloudData
 		json.Unmarshal(host.CloudData, &cloudData)
 		modeledHost.CloudData = cloudData
+	case cloud.Aws:
+		var cloudData models.AwsCloudData
+		json.Unmarshal(host.CloudData, &cloudData)
+		modeledHost.CloudData = cloudData
+	case cloud.Gcp:
+		var cloudData models.GcpCloudData
+		json.Unmarshal(host.CloudData, &cloudData)
+		modeledHost.CloudData = cloudData
 	}
 
 	return modeledHost, nil
@@ -217,17 +234,138 @@ func (s *hostsService) GetAllTags() ([]string, error) {
 	return tags, nil
 }
 
-func (s *hostsService) Heartbeat(agentID string) error {
+// Heartbeat records that the agent is alive, along with the agent status if reported.
+// Agents not reporting it reset any status previously stored.
+// The reported hostname and boot id are recorded, along with the address the heartbeat comes from, to detect
+// agents sharing the same id. Addresses alone change with NAT or several interfaces, so they only tell apart
+// the older agents, which don't report their status
+func (s *hostsService) Heartbeat(agentID string, agentStatus *models.AgentStatus, address string) error {
 	heartbeat := &entities.HostHeartbeat{
 		AgentID: agentID,
 	}
 
-	return s.db.Clauses(clause.OnConflict{
+	sighting := &entities.AgentSighting{
+		AgentID:    agentID,
+		Address:    address,
+		LastSeenAt: time.Now(),
+	}
+
+	if agentStatus != nil {
+		agentStatusBytes, err := json.Marshal(agentStatus)
+		if err != nil {
+			return err
+		}
+		heartbeat.AgentStatus = agentStatusBytes
+		sighting.Hostname = agentStatus.Hostname
+		sighting.BootID = agentStatus.BootID
+	} else {
+		sighting.Origin = address
+	}
+
+	err := s.db.Clauses(clause.OnConflict{
 		Columns: []clause.Column{
 			{Name: "agent_id"},
 		},
-		DoUpdates: clause.AssignmentColumns([]string{"updated_at"}),
+		DoUpdates: clause.AssignmentColumns([]string{"updated_at", "agent_status"}),
 	}).Create(heartbeat).Error
+	if err != nil {
+		return err
+	}
+
+	var recent []*entities.AgentSighting
+	err = recentSightings(s.db).Where("agent_id = ?", agentID).Find(&recent).Error
+	if err != nil {
+		return err
+	}
+
+	var last, current *entities.AgentSighting
+	for _, r := range recent {
+		if last == nil || r.LastSeenAt.After(last.LastSeenAt) {
+			last = r
+		}
+		if r.Hostname == sighting.Hostname && r.BootID == sighting.BootID && r.Origin == sighting.Origin {
+			current = r
+		}
+	}
+
+	// A host reporting again after another one reported the same agent id is alternating with it,
+	// which a restarted or renamed host doesn't do. It stops once the other hosts are not seen anymore
+	if current != nil && len(recent) > 1 {
+		sighting.Alternating = current.Alternating || last != current
+	}
+
+	err = s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "agent_id"}, {Name: "hostname"}, {Name: "boot_id"}, {Name: "origin"},
+		},
+		DoUpdates: clause.AssignmentColumns([]string{"address", "last_seen_at", "alternating"}),
+	}).Create(sighting).Error
+	if err != nil {
+		return err
+	}
+
+	return s.db.
+		Where("agent_id = ? AND last_seen_at < ?", agentID, time.Now().Add(-AgentIdentityConflictWindow)).
+		Delete(&entities.AgentSighting{}).
+		Error
+}
+
+// GetIdentityConflicts returns the agent ids recently reported in turns by several hosts
+func (s *hostsService) GetIdentityConflicts() ([]*models.AgentIdentityConflict, error) {
+	var sightings []*entities.AgentSighting
+
+	err := recentSightings(s.db).Find(&sightings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	sightingsByAgent := make(map[string][]*models.AgentSighting)
+	var agentIDs []string
+	for _, sighting := range sightings {
+		if _, found := sightingsByAgent[sighting.AgentID]; !found {
+			agentIDs = append(agentIDs, sighting.AgentID)
+		}
+		sightingsByAgent[sighting.AgentID] = append(sightingsByAgent[sighting.AgentID], sighting.ToModel())
+	}
+
+	var conflictingAgentIDs []string
+	for _, agentID := range agentIDs {
+		if models.IsAgentIdentityConflict(sightingsByAgent[agentID]) {
+			conflictingAgentIDs = append(conflictingAgentIDs, agentID)
+		}
+	}
+
+	if len(conflictingAgentIDs) == 0 {
+		return nil, nil
+	}
+
+	var hosts []entities.Host
+	err = s.db.Where("agent_id IN ?", conflictingAgentIDs).Find(&hosts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	hostNames := make(map[string]string)
+	for _, h := range hosts {
+		hostNames[h.AgentID] = h.Name
+	}
+
+	var conflicts []*models.AgentIdentityConflict
+	for _, agentID := range conflictingAgentIDs {
+		conflicts = append(conflicts, &models.AgentIdentityConflict{
+			AgentID:   agentID,
+			HostName:  hostNames[agentID],
+			Sightings: sightingsByAgent[agentID],
+		})
+	}
+
+	return conflicts, nil
+}
+
+func recentSightings(db *gorm.DB) *gorm.DB {
+	return db.
+		Where("last_seen_at > ?", time.Now().Add(-AgentIdentityConflictWindow)).
+		Order("agent_id, hostname, boot_id, origin")
 }
 
 func initJobsStates() map[string]string {
@@ -281,5 +419,10 @@ func computeHearbeatHealth(hearbeat *entities.HostHeartbeat) string {
 		return models.HostHealthCritical
 	}
 
+	// The agent is alive, but some of its discoveries are not reporting up to date data
+	if agentStatus := hearbeat.ToModel(); agentStatus != nil && agentStatus.HasFailingDiscoveries() {
+		return models.HostHealthWarning
+	}
+
 	return models.HostHealthPassing
 }
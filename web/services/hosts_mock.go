@@ -171,13 +171,36 @@ func (_m *MockHostsService) GetExportersState(hostname string) (map[string]strin
 	return r0, r1
 }
 
-// Heartbeat provides a mock function with given fields: agentID
-func (_m *MockHostsService) Heartbeat(agentID string) error {
-	ret := _m.Called(agentID)
+// GetIdentityConflicts provides a mock function with given fields:
+func (_m *MockHostsService) GetIdentityConflicts() ([]*models.AgentIdentityConflict, error) {
+	ret := _m.Called()
+
+	var r0 []*models.AgentIdentityConflict
+	if rf, ok := ret.Get(0).(func() []*models.AgentIdentityConflict); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.AgentIdentityConflict)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Heartbeat provides a mock function with given fields: agentID, agentStatus, address
+func (_m *MockHostsService) Heartbeat(agentID string, agentStatus *models.AgentStatus, address string) error {
+	ret := _m.Called(agentID, agentStatus, address)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(agentID)
+	if rf, ok := ret.Get(0).(func(string, *models.AgentStatus, string) error); ok {
+		r0 = rf(agentID, agentStatus, address)
 	} else {
 		r0 = ret.Error(0)
 	}
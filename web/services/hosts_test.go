@@ -10,6 +10,7 @@ import (
 	"github.com/trento-project/trento/test/helpers"
 	"github.com/trento-project/trento/web/entities"
 	"github.com/trento-project/trento/web/models"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 
 	prometheusModel "github.com/prometheus/common/model"
@@ -89,7 +90,7 @@ func TestHostsServiceTestSuite(t *testing.T) {
 func (suite *HostsServiceTestSuite) SetupSuite() {
 	suite.db = helpers.SetupTestDatabase(suite.T())
 
-	suite.db.AutoMigrate(&entities.Host{}, &entities.HostHeartbeat{}, &entities.SAPSystemInstance{}, &models.Tag{})
+	suite.db.AutoMigrate(&entities.Host{}, &entities.HostHeartbeat{}, &entities.AgentSighting{}, &entities.SAPSystemInstance{}, &models.Tag{})
 	hosts := hostsFixtures()
 	err := suite.db.Create(&hosts).Error
 	suite.NoError(err)
@@ -98,6 +99,7 @@ func (suite *HostsServiceTestSuite) SetupSuite() {
 func (suite *HostsServiceTestSuite) TearDownSuite() {
 	suite.db.Migrator().DropTable(&entities.Host{},
 		&entities.HostHeartbeat{},
+		&entities.AgentSighting{},
 		&entities.SAPSystemInstance{},
 		&models.Tag{})
 }
@@ -231,12 +233,155 @@ func (suite *HostsServiceTestSuite) TestHostsService_GetAllSIDs() {
 }
 
 func (suite *HostsServiceTestSuite) TestHostsService_Heartbeat() {
-	err := suite.hostsService.Heartbeat("1")
+	err := suite.hostsService.Heartbeat("1", nil, "10.0.0.1")
 	suite.NoError(err)
 
 	var heartbeat entities.HostHeartbeat
 	suite.tx.First(&heartbeat)
 	suite.Equal("1", heartbeat.AgentID)
+	suite.Nil(heartbeat.ToModel())
+}
+
+func (suite *HostsServiceTestSuite) TestHostsService_HeartbeatWithAgentStatus() {
+	agentStatus := &models.AgentStatus{
+		Hostname: "host1",
+		Version:  "1.0.0",
+		Uptime:   3600,
+		Discoveries: []*models.AgentDiscoveryStatus{
+			{
+				ID:                  "ha_cluster_discovery",
+				LastError:           "crm_mon failed",
+				ConsecutiveFailures: 3,
+			},
+		},
+	}
+
+	err := suite.hostsService.Heartbeat("1", agentStatus, "10.0.0.1")
+	suite.NoError(err)
+
+	var heartbeat entities.HostHeartbeat
+	suite.tx.Where("agent_id = ?", "1").First(&heartbeat)
+	suite.Equal(agentStatus, heartbeat.ToModel())
+
+	host, err := suite.hostsService.GetByID("1")
+	suite.NoError(err)
+	suite.Equal(agentStatus, host.AgentStatus)
+	suite.Len(host.AgentSightings, 1)
+	suite.Equal("host1", host.AgentSightings[0].Hostname)
+	suite.Equal("10.0.0.1", host.AgentSightings[0].Address)
+	suite.False(host.HasIdentityConflict())
+}
+
+func (suite *HostsServiceTestSuite) TestHostsService_Tuning() {
+	suite.tx.Model(&entities.SAPSystemInstance{}).
+		Where("agent_id = ?", "1").
+		Update("type", models.SAPSystemTypeDatabase)
+	suite.tx.Model(&entities.Host{}).
+		Where("agent_id = ?", "1").
+		Update("tuning", datatypes.JSON(`{"transparent_hugepages":"never","saptune":{"active":true,"solutions":["NETWEAVER"]}}`))
+
+	host, err := suite.hostsService.GetByID("1")
+	suite.NoError(err)
+	suite.Equal(&models.HostTuning{
+		TransparentHugepages: "never",
+		Saptune: &models.SaptuneState{
+			Active:    true,
+			Solutions: []string{"NETWEAVER"},
+		},
+	}, host.Tuning)
+	suite.Equal([]string{models.SAPSystemTypeDatabase}, host.SAPWorkloads())
+	suite.True(host.HasTuningMismatch())
+
+	host, err = suite.hostsService.GetByID("2")
+	suite.NoError(err)
+	suite.Nil(host.Tuning)
+	suite.False(host.HasTuningMismatch())
+}
+
+func (suite *HostsServiceTestSuite) TestHostsService_IdentityConflict() {
+	// Two hosts cloned with the same machine id, and the same host behind different addresses,
+	// which is not a conflict
+	suite.NoError(suite.hostsService.Heartbeat("1", &models.AgentStatus{Hostname: "host1"}, "10.0.0.1"))
+	suite.NoError(suite.hostsService.Heartbeat("1", &models.AgentStatus{Hostname: "host1-clone"}, "10.0.0.2"))
+	suite.NoError(suite.hostsService.Heartbeat("1", &models.AgentStatus{Hostname: "host1"}, "10.0.0.1"))
+	suite.NoError(suite.hostsService.Heartbeat("2", &models.AgentStatus{Hostname: "host2"}, "10.0.0.3"))
+	suite.NoError(suite.hostsService.Heartbeat("2", &models.AgentStatus{Hostname: "host2"}, "10.0.0.4"))
+	suite.NoError(suite.hostsService.Heartbeat("2", nil, "10.0.0.5"))
+
+	// Sightings out of the window are not a conflict
+	suite.tx.Create(&entities.AgentSighting{
+		AgentID:     "3",
+		Hostname:    "host3-old-name",
+		LastSeenAt:  time.Now().Add(-2 * AgentIdentityConflictWindow),
+		Alternating: true,
+	})
+	suite.NoError(suite.hostsService.Heartbeat("3", &models.AgentStatus{Hostname: "host3"}, "10.0.0.6"))
+
+	host, err := suite.hostsService.GetByID("1")
+	suite.NoError(err)
+	suite.True(host.HasIdentityConflict())
+	suite.Len(host.AgentSightings, 2)
+
+	host, err = suite.hostsService.GetByID("2")
+	suite.NoError(err)
+	suite.False(host.HasIdentityConflict())
+	suite.Len(host.AgentSightings, 2)
+	suite.Equal("", host.AgentSightings[0].BootID)
+	suite.Equal("10.0.0.5", host.AgentSightings[0].Address)
+	suite.Equal("10.0.0.4", host.AgentSightings[1].Address)
+
+	conflicts, err := suite.hostsService.GetIdentityConflicts()
+	suite.NoError(err)
+	suite.Len(conflicts, 1)
+	suite.Equal("1", conflicts[0].AgentID)
+	suite.Equal("host1", conflicts[0].HostName)
+	suite.Equal("host1", conflicts[0].Sightings[0].Hostname)
+	suite.Equal("10.0.0.1", conflicts[0].Sightings[0].Address)
+	suite.Equal("host1-clone", conflicts[0].Sightings[1].Hostname)
+	suite.Equal("10.0.0.2", conflicts[0].Sightings[1].Address)
+
+	var sightings int64
+	suite.tx.Model(&entities.AgentSighting{}).Where("agent_id = ?", "3").Count(&sightings)
+	suite.Equal(int64(1), sightings)
+}
+
+func (suite *HostsServiceTestSuite) TestHostsService_IdentityConflictSameHostname() {
+	// Two hosts cloned keeping the same hostname, told apart by their boot ids
+	suite.NoError(suite.hostsService.Heartbeat("1", &models.AgentStatus{Hostname: "host1", BootID: "boot-a"}, "10.0.0.1"))
+	suite.NoError(suite.hostsService.Heartbeat("1", &models.AgentStatus{Hostname: "host1", BootID: "boot-b"}, "10.0.0.2"))
+	suite.NoError(suite.hostsService.Heartbeat("1", &models.AgentStatus{Hostname: "host1", BootID: "boot-a"}, "10.0.0.1"))
+
+	// A rebooted host is reported with a new boot id, but never with the previous one again
+	suite.NoError(suite.hostsService.Heartbeat("2", &models.AgentStatus{Hostname: "host2", BootID: "boot-c"}, "10.0.0.3"))
+	suite.NoError(suite.hostsService.Heartbeat("2", &models.AgentStatus{Hostname: "host2", BootID: "boot-d"}, "10.0.0.3"))
+	suite.NoError(suite.hostsService.Heartbeat("2", &models.AgentStatus{Hostname: "host2", BootID: "boot-d"}, "10.0.0.3"))
+
+	// Older agents not reporting their status are told apart by their addresses
+	suite.NoError(suite.hostsService.Heartbeat("3", nil, "10.0.0.5"))
+	suite.NoError(suite.hostsService.Heartbeat("3", nil, "10.0.0.6"))
+	suite.NoError(suite.hostsService.Heartbeat("3", nil, "10.0.0.5"))
+
+	host, err := suite.hostsService.GetByID("1")
+	suite.NoError(err)
+	suite.True(host.HasIdentityConflict())
+	suite.Len(host.AgentSightings, 2)
+	suite.Equal("boot-a", host.AgentSightings[0].BootID)
+	suite.Equal("boot-b", host.AgentSightings[1].BootID)
+
+	host, err = suite.hostsService.GetByID("2")
+	suite.NoError(err)
+	suite.False(host.HasIdentityConflict())
+	suite.Len(host.AgentSightings, 2)
+
+	conflicts, err := suite.hostsService.GetIdentityConflicts()
+	suite.NoError(err)
+	suite.Len(conflicts, 2)
+	suite.Equal("1", conflicts[0].AgentID)
+	suite.Equal("3", conflicts[1].AgentID)
+	suite.Equal("", conflicts[1].Sightings[0].Hostname)
+	suite.Equal("", conflicts[1].Sightings[0].BootID)
+	suite.Equal("10.0.0.5", conflicts[1].Sightings[0].Address)
+	suite.Equal("10.0.0.6", conflicts[1].Sightings[1].Address)
 }
 
 func (suite *HostsServiceTestSuite) TestHostsService_computeHealth() {
@@ -252,6 +397,12 @@ func (suite *HostsServiceTestSuite) TestHostsService_computeHealth() {
 	}
 	suite.Equal(models.HostHealthCritical, computeHealth(&host))
 
+	timeSince = func(_ time.Time) time.Duration {
+		return time.Duration(0)
+	}
+	host.Heartbeat.AgentStatus = datatypes.JSON(`{"discoveries":[{"id":"ha_cluster_discovery","last_error":"crm_mon failed"}]}`)
+	suite.Equal(models.HostHealthWarning, computeHealth(&host))
+
 	host.Heartbeat = nil
 	suite.Equal(models.HostHealthUnknown, computeHealth(&host))
 }
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+//go:generate mockery --name=PackagesService --inpackage --filename=packages_mock.go
+type PackagesService interface {
+	GetAll(filter *PackagesFilter, page *Page) ([]*models.HostPackage, error)
+	GetCount(filter *PackagesFilter) (int, error)
+	GetAllNames() ([]string, error)
+	GetAllVersions(names []string) ([]string, error)
+}
+
+// PackagesFilter filters the installed packages. Versions match either the bare version
+// or the version along with the release, as in 2.0.4+20200616.2deceaa3a-3.9.1
+type PackagesFilter struct {
+	Names        []string
+	Versions     []string
+	ClusterNames []string
+	HostIDs      []string
+}
+
+type packagesService struct {
+	db *gorm.DB
+}
+
+func NewPackagesService(db *gorm.DB) *packagesService {
+	return &packagesService{db: db}
+}
+
+func (s *packagesService) GetAll(filter *PackagesFilter, page *Page) ([]*models.HostPackage, error) {
+	var packageEntities []*entities.HostPackage
+
+	err := s.filteredQuery(filter).
+		Preload("Host").
+		Scopes(Paginate(page)).
+		Order("host_packages.name, host_packages.arch, hosts.name, host_packages.agent_id, host_packages.version, host_packages.release").
+		Find(&packageEntities).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	var hostPackages []*models.HostPackage
+	for _, p := range packageEntities {
+		hostPackages = append(hostPackages, p.ToModel())
+	}
+
+	if err := s.markVersionMismatches(hostPackages); err != nil {
+		return nil, err
+	}
+
+	return hostPackages, nil
+}
+
+func (s *packagesService) GetCount(filter *PackagesFilter) (int, error) {
+	var count int64
+	err := s.filteredQuery(filter).Count(&count).Error
+
+	return int(count), err
+}
+
+func (s *packagesService) GetAllNames() ([]string, error) {
+	var names []string
+
+	err := s.db.
+		Model(&entities.HostPackage{}).
+		Distinct().
+		Order("name").
+		Pluck("name", &names).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// GetAllVersions returns the versions, along with their release, installed of the given packages
+func (s *packagesService) GetAllVersions(names []string) ([]string, error) {
+	var packageEntities []*entities.HostPackage
+
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	err := s.db.
+		Distinct("version", "release").
+		Where("name IN (?)", names).
+		Order("version, release").
+		Find(&packageEntities).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	for _, p := range packageEntities {
+		versions = append(versions, p.ToModel().FullVersion())
+	}
+
+	return versions, nil
+}
+
+func (s *packagesService) filteredQuery(filter *PackagesFilter) *gorm.DB {
+	db := s.db.
+		Model(&entities.HostPackage{}).
+		Joins("LEFT JOIN hosts ON hosts.agent_id = host_packages.agent_id")
+
+	if filter == nil {
+		return db
+	}
+
+	if len(filter.Names) > 0 {
+		db = db.Where("host_packages.name IN (?)", filter.Names)
+	}
+
+	if len(filter.Versions) > 0 {
+		db = db.Where(
+			"(host_packages.version IN (?) OR host_packages.version || '-' || host_packages.release IN (?))",
+			filter.Versions, filter.Versions)
+	}
+
+	if len(filter.ClusterNames) > 0 {
+		db = db.Where("hosts.cluster_name IN (?)", filter.ClusterNames)
+	}
+
+	if len(filter.HostIDs) > 0 {
+		db = db.Where("host_packages.agent_id IN (?)", filter.HostIDs)
+	}
+
+	return db
+}
+
+// markVersionMismatches flags the packages whose installed versions differ between the nodes
+// of the same cluster. The whole set of versions of each node is compared, so that nodes with
+// several kernels installed are not reported as long as all of them have the same ones.
+// Nodes missing the package entirely count as a set of their own
+func (s *packagesService) markVersionMismatches(hostPackages []*models.HostPackage) error {
+	var clusterIDs, names []string
+	seen := make(map[string]bool)
+
+	for _, p := range hostPackages {
+		if p.ClusterID == "" {
+			continue
+		}
+		if !seen["cluster:"+p.ClusterID] {
+			seen["cluster:"+p.ClusterID] = true
+			clusterIDs = append(clusterIDs, p.ClusterID)
+		}
+		if !seen["name:"+p.Name] {
+			seen["name:"+p.Name] = true
+			names = append(names, p.Name)
+		}
+	}
+
+	if len(clusterIDs) == 0 {
+		return nil
+	}
+
+	var clusterHosts []*entities.Host
+	err := s.db.
+		Select("agent_id", "cluster_id").
+		Where("cluster_id IN (?)", clusterIDs).
+		Find(&clusterHosts).
+		Error
+
+	if err != nil {
+		return err
+	}
+
+	var clusterPackages []*entities.HostPackage
+	err = s.db.
+		Preload("Host").
+		Joins("JOIN hosts ON hosts.agent_id = host_packages.agent_id").
+		Where("hosts.cluster_id IN (?) AND host_packages.name IN (?)", clusterIDs, names).
+		Find(&clusterPackages).
+		Error
+
+	if err != nil {
+		return err
+	}
+
+	nodeVersions := make(map[string]map[string][]string)
+	keyClusters := make(map[string]string)
+	for _, p := range clusterPackages {
+		hostPackage := p.ToModel()
+		key := packageClusterKey(hostPackage)
+		if nodeVersions[key] == nil {
+			nodeVersions[key] = make(map[string][]string)
+			keyClusters[key] = hostPackage.ClusterID
+		}
+		nodeVersions[key][hostPackage.HostID] = append(nodeVersions[key][hostPackage.HostID], hostPackage.FullVersion())
+	}
+
+	clusterNodes := make(map[string][]string)
+	for _, h := range clusterHosts {
+		clusterNodes[h.ClusterID] = append(clusterNodes[h.ClusterID], h.AgentID)
+	}
+
+	mismatches := make(map[string]bool)
+	for key, nodes := range nodeVersions {
+		versionSets := make(map[string]bool)
+		for _, versions := range nodes {
+			sort.Strings(versions)
+			versionSets[strings.Join(versions, ",")] = true
+		}
+
+		for _, agentID := range clusterNodes[keyClusters[key]] {
+			if _, installed := nodes[agentID]; !installed {
+				versionSets[""] = true
+			}
+		}
+
+		mismatches[key] = len(versionSets) > 1
+	}
+
+	for _, p := range hostPackages {
+		if p.ClusterID == "" {
+			continue
+		}
+		p.VersionMismatch = mismatches[packageClusterKey(p)]
+	}
+
+	return nil
+}
+
+func packageClusterKey(p *models.HostPackage) string {
+	return p.ClusterID + "/" + p.Name + "/" + p.Arch
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"gorm.io/gorm"
+)
+
+type PackagesServiceTestSuite struct {
+	suite.Suite
+	db              *gorm.DB
+	tx              *gorm.DB
+	packagesService *packagesService
+}
+
+func TestPackagesServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(PackagesServiceTestSuite))
+}
+
+func (suite *PackagesServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(entities.HostPackage{}, entities.Host{})
+	loadPackagesFixtures(suite.db)
+}
+
+func (suite *PackagesServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(entities.HostPackage{}, entities.Host{})
+}
+
+func (suite *PackagesServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.packagesService = NewPackagesService(suite.tx)
+}
+
+func (suite *PackagesServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func loadPackagesFixtures(db *gorm.DB) {
+	db.Create(&entities.Host{
+		AgentID:     "1",
+		Name:        "node01",
+		ClusterID:   "cluster_id",
+		ClusterName: "hana_cluster",
+	})
+	db.Create(&entities.Host{
+		AgentID:     "2",
+		Name:        "node02",
+		ClusterID:   "cluster_id",
+		ClusterName: "hana_cluster",
+	})
+	db.Create(&entities.Host{
+		AgentID: "3",
+		Name:    "standalone",
+	})
+
+	hostPackages := []entities.HostPackage{
+		{AgentID: "1", Name: "pacemaker", Arch: "x86_64", Version: "2.0.4+20200616.2deceaa3a", Release: "3.9.1"},
+		{AgentID: "2", Name: "pacemaker", Arch: "x86_64", Version: "2.0.3+20200511.2b248d828", Release: "1.10"},
+		{AgentID: "1", Name: "kernel-default", Arch: "x86_64", Version: "5.3.18", Release: "24.75.3"},
+		{AgentID: "1", Name: "kernel-default", Arch: "x86_64", Version: "5.3.18", Release: "24.78.1"},
+		{AgentID: "2", Name: "kernel-default", Arch: "x86_64", Version: "5.3.18", Release: "24.78.1"},
+		{AgentID: "2", Name: "kernel-default", Arch: "x86_64", Version: "5.3.18", Release: "24.75.3"},
+		{AgentID: "3", Name: "pacemaker", Arch: "x86_64", Version: "2.0.3+20200511.2b248d828", Release: "1.10"},
+	}
+	db.Create(&hostPackages)
+}
+
+func (suite *PackagesServiceTestSuite) TestPackagesService_GetAll() {
+	hostPackages, err := suite.packagesService.GetAll(&PackagesFilter{Names: []string{"pacemaker"}}, nil)
+	suite.NoError(err)
+	suite.Equal(3, len(hostPackages))
+
+	suite.Equal("node01", hostPackages[0].Hostname)
+	suite.Equal("hana_cluster", hostPackages[0].ClusterName)
+	suite.Equal("2.0.4+20200616.2deceaa3a-3.9.1", hostPackages[0].FullVersion())
+	suite.True(hostPackages[0].VersionMismatch)
+
+	suite.Equal("node02", hostPackages[1].Hostname)
+	suite.True(hostPackages[1].VersionMismatch)
+
+	suite.Equal("standalone", hostPackages[2].Hostname)
+	suite.Equal("", hostPackages[2].ClusterID)
+	suite.False(hostPackages[2].VersionMismatch)
+}
+
+func (suite *PackagesServiceTestSuite) TestPackagesService_GetAllMultiversion() {
+	hostPackages, err := suite.packagesService.GetAll(&PackagesFilter{Names: []string{"kernel-default"}}, nil)
+	suite.NoError(err)
+	suite.Equal(4, len(hostPackages))
+
+	for _, p := range hostPackages {
+		suite.False(p.VersionMismatch)
+	}
+}
+
+func (suite *PackagesServiceTestSuite) TestPackagesService_GetAllMissingOnNode() {
+	suite.tx.Create(&entities.Host{
+		AgentID:     "4",
+		Name:        "node04",
+		ClusterID:   "other_cluster_id",
+		ClusterName: "netweaver_cluster",
+	})
+	suite.tx.Create(&entities.Host{
+		AgentID:     "5",
+		Name:        "node05",
+		ClusterID:   "other_cluster_id",
+		ClusterName: "netweaver_cluster",
+	})
+	suite.tx.Create(&entities.HostPackage{
+		AgentID: "4", Name: "sbd", Arch: "x86_64", Version: "1.4.1+20200624.cee826a", Release: "1.6",
+	})
+
+	hostPackages, err := suite.packagesService.GetAll(&PackagesFilter{Names: []string{"sbd"}}, nil)
+	suite.NoError(err)
+	suite.Equal(1, len(hostPackages))
+	suite.Equal("node04", hostPackages[0].Hostname)
+	suite.True(hostPackages[0].VersionMismatch)
+}
+
+func (suite *PackagesServiceTestSuite) TestPackagesService_GetAllFilter() {
+	hostPackages, err := suite.packagesService.GetAll(&PackagesFilter{
+		Versions: []string{"2.0.3+20200511.2b248d828"},
+	}, nil)
+	suite.NoError(err)
+	suite.Equal(2, len(hostPackages))
+	suite.Equal("node02", hostPackages[0].Hostname)
+	suite.Equal("standalone", hostPackages[1].Hostname)
+
+	hostPackages, err = suite.packagesService.GetAll(&PackagesFilter{
+		Versions:     []string{"2.0.3+20200511.2b248d828-1.10"},
+		ClusterNames: []string{"hana_cluster"},
+	}, nil)
+	suite.NoError(err)
+	suite.Equal(1, len(hostPackages))
+	suite.Equal("node02", hostPackages[0].Hostname)
+
+	hostPackages, err = suite.packagesService.GetAll(&PackagesFilter{HostIDs: []string{"3"}}, nil)
+	suite.NoError(err)
+	suite.Equal(1, len(hostPackages))
+}
+
+func (suite *PackagesServiceTestSuite) TestPackagesService_GetAllPaginated() {
+	hostPackages, err := suite.packagesService.GetAll(nil, &Page{Number: 2, Size: 4})
+	suite.NoError(err)
+	suite.Equal(3, len(hostPackages))
+	suite.Equal("pacemaker", hostPackages[0].Name)
+	suite.True(hostPackages[0].VersionMismatch)
+}
+
+func (suite *PackagesServiceTestSuite) TestPackagesService_GetCount() {
+	count, err := suite.packagesService.GetCount(&PackagesFilter{ClusterNames: []string{"hana_cluster"}})
+	suite.NoError(err)
+	suite.Equal(6, count)
+}
+
+func (suite *PackagesServiceTestSuite) TestPackagesService_GetAllNames() {
+	names, err := suite.packagesService.GetAllNames()
+	suite.NoError(err)
+	suite.ElementsMatch([]string{"kernel-default", "pacemaker"}, names)
+}
+
+func (suite *PackagesServiceTestSuite) TestPackagesService_GetAllVersions() {
+	versions, err := suite.packagesService.GetAllVersions([]string{"pacemaker"})
+	suite.NoError(err)
+	suite.Equal([]string{"2.0.3+20200511.2b248d828-1.10", "2.0.4+20200616.2deceaa3a-3.9.1"}, versions)
+
+	versions, err = suite.packagesService.GetAllVersions(nil)
+	suite.NoError(err)
+	suite.Empty(versions)
+}
@@ -23,6 +23,9 @@ type SAPSystemsService interface {
 	GetAllDatabasesSIDs() ([]string, error)
 	GetAllApplicationsTags() ([]string, error)
 	GetAllDatabasesTags() ([]string, error)
+	GetAllInstanceVersions(filter *SAPInstanceVersionFilter, page *Page) ([]*models.SAPInstanceVersion, error)
+	GetAllKernelReleases() ([]string, error)
+	GetAllKernelPatches() ([]string, error)
 }
 
 type SAPSystemFilter struct {
@@ -30,6 +33,12 @@ type SAPSystemFilter struct {
 	SIDs []string
 }
 
+type SAPInstanceVersionFilter struct {
+	SIDs           []string
+	KernelReleases []string
+	KernelPatches  []string
+}
+
 type sapSystemsService struct {
 	db *gorm.DB
 }
@@ -163,6 +172,67 @@ func (s *sapSystemsService) GetAllDatabasesTags() ([]string, error) {
 	return tags, nil
 }
 
+func (s *sapSystemsService) GetAllInstanceVersions(filter *SAPInstanceVersionFilter, page *Page) ([]*models.SAPInstanceVersion, error) {
+	var instances entities.SAPSystemInstances
+
+	db := s.db.
+		Preload("Host").
+		Scopes(Paginate(page)).
+		Order("sid, instance_number, id, agent_id")
+
+	if filter != nil {
+		if len(filter.SIDs) > 0 {
+			db = db.Where("sid IN (?)", filter.SIDs)
+		}
+
+		if len(filter.KernelReleases) > 0 {
+			db = db.Where("kernel_release IN (?)", filter.KernelReleases)
+		}
+
+		if len(filter.KernelPatches) > 0 {
+			db = db.Where("kernel_patch IN (?)", filter.KernelPatches)
+		}
+	}
+
+	err := db.Find(&instances).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var instanceVersions []*models.SAPInstanceVersion
+	for _, instance := range instances {
+		instanceVersions = append(instanceVersions, instance.VersionToModel())
+	}
+
+	return instanceVersions, nil
+}
+
+func (s *sapSystemsService) GetAllKernelReleases() ([]string, error) {
+	return s.getAllKernelVersionValues("kernel_release")
+}
+
+func (s *sapSystemsService) GetAllKernelPatches() ([]string, error) {
+	return s.getAllKernelVersionValues("kernel_patch")
+}
+
+func (s *sapSystemsService) getAllKernelVersionValues(column string) ([]string, error) {
+	var values []string
+
+	err := s.db.
+		Model(&entities.SAPSystemInstance{}).
+		Where(fmt.Sprintf("%s <> ''", column)).
+		Distinct().
+		Order(column).
+		Pluck(column, &values).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
 func (s *sapSystemsService) getAllByType(sapSystemType string, tagResourceType string, filter *SAPSystemFilter, page *Page) (models.SAPSystemList, error) {
 	var instances entities.SAPSystemInstances
 
@@ -287,12 +357,18 @@ func (s *sapSystemsService) attachDatabase(sapSystem *models.SAPSystem) error {
 func (s *sapSystemsService) computeHealth(sapSystem *models.SAPSystem) {
 	sapSystem.Health = models.SAPSystemHealthPassing
 	for _, sapInstance := range sapSystem.GetAllInstances() {
+		instanceHealth := sapInstance.Health()
+		// A red CCMS alert makes the system critical even if the instance processes are running
+		if sapInstance.HasCriticalAlerts() {
+			instanceHealth = models.SAPSystemHealthCritical
+		}
+
 		switch {
-		case sapInstance.Health() == models.SAPSystemHealthCritical:
+		case instanceHealth == models.SAPSystemHealthCritical:
 			sapSystem.Health = models.SAPSystemHealthCritical
-		case sapSystem.Health != models.SAPSystemHealthCritical && sapInstance.Health() == models.SAPSystemHealthWarning:
+		case sapSystem.Health != models.SAPSystemHealthCritical && instanceHealth == models.SAPSystemHealthWarning:
 			sapSystem.Health = models.SAPSystemHealthWarning
-		case sapSystem.Health == models.SAPSystemHealthPassing && sapInstance.Health() == models.SAPSystemHealthUnknown:
+		case sapSystem.Health == models.SAPSystemHealthPassing && instanceHealth == models.SAPSystemHealthUnknown:
 			sapSystem.Health = models.SAPSystemHealthUnknown
 		}
 	}
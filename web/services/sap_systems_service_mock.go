@@ -150,6 +150,75 @@ func (_m *MockSAPSystemsService) GetAllDatabasesTags() ([]string, error) {
 	return r0, r1
 }
 
+// GetAllInstanceVersions provides a mock function with given fields: filter, page
+func (_m *MockSAPSystemsService) GetAllInstanceVersions(filter *SAPInstanceVersionFilter, page *Page) ([]*models.SAPInstanceVersion, error) {
+	ret := _m.Called(filter, page)
+
+	var r0 []*models.SAPInstanceVersion
+	if rf, ok := ret.Get(0).(func(*SAPInstanceVersionFilter, *Page) []*models.SAPInstanceVersion); ok {
+		r0 = rf(filter, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.SAPInstanceVersion)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*SAPInstanceVersionFilter, *Page) error); ok {
+		r1 = rf(filter, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllKernelPatches provides a mock function with given fields:
+func (_m *MockSAPSystemsService) GetAllKernelPatches() ([]string, error) {
+	ret := _m.Called()
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllKernelReleases provides a mock function with given fields:
+func (_m *MockSAPSystemsService) GetAllKernelReleases() ([]string, error) {
+	ret := _m.Called()
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetApplicationsCount provides a mock function with given fields:
 func (_m *MockSAPSystemsService) GetApplicationsCount() (int, error) {
 	ret := _m.Called()
@@ -2,6 +2,7 @@ package services
 
 import (
 	"testing"
+	"time"
 
 	"github.com/lib/pq"
 	"github.com/stretchr/testify/suite"
@@ -9,6 +10,7 @@ import (
 	"github.com/trento-project/trento/test/helpers"
 	"github.com/trento-project/trento/web/entities"
 	"github.com/trento-project/trento/web/models"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -25,6 +27,9 @@ func sapSystemsFixtures() entities.SAPSystemInstances {
 			DBHost:         "dbhost_1",
 			DBName:         "tenant",
 			DBAddress:      "192.168.1.10",
+			KernelRelease:  "753",
+			KernelPatch:    "900",
+			Versions:       datatypes.JSON(`[{"name":"disp+work","filename":"/usr/sap/HA1/D00/exe/disp+work","version":"753, patch 900","time":"2020 10 27 13:35:59"}]`),
 			Host: &entities.Host{
 				AgentID:     "1",
 				Name:        "apphost",
@@ -50,6 +55,7 @@ func sapSystemsFixtures() entities.SAPSystemInstances {
 			Tenants:                 pq.StringArray{"tenant"},
 			SystemReplication:       "Primary",
 			SystemReplicationStatus: "SOK",
+			Alerts:                  datatypes.JSON(`[{"object":"Dialog","attribute":"ResponseTime","value":"SAPControl-RED","description":"Dialog response time exceeds the threshold","time":"2021 11 10 10:25:12"}]`),
 			Host: &entities.Host{
 				AgentID:     "2",
 				Name:        "dbhost_1",
@@ -146,13 +152,15 @@ func (suite *SAPSystemsServiceTestSuite) TestSAPSystemsService_GetAllApplication
 					SID:            "HA1",
 					Type:           models.SAPSystemTypeApplication,
 					Status:         string(sapcontrol.STATECOLOR_RED),
+					KernelRelease:  "753",
+					KernelPatch:    "900",
 				},
 			},
 			AttachedDatabase: &models.SAPSystem{
 				ID:     "sap_system_2",
 				SID:    "PRD",
 				Type:   models.SAPSystemTypeDatabase,
-				Health: models.SAPSystemHealthWarning,
+				Health: models.SAPSystemHealthCritical,
 				Instances: []*models.SAPSystemInstance{
 					{
 						HostID:                  "2",
@@ -166,6 +174,15 @@ func (suite *SAPSystemsServiceTestSuite) TestSAPSystemsService_GetAllApplication
 						SID:                     "PRD",
 						Type:                    models.SAPSystemTypeDatabase,
 						Status:                  string(sapcontrol.STATECOLOR_GREEN),
+						Alerts: []*models.SAPInstanceAlert{
+							{
+								Object:      "Dialog",
+								Attribute:   "ResponseTime",
+								Value:       string(sapcontrol.STATECOLOR_RED),
+								Description: "Dialog response time exceeds the threshold",
+								Time:        "2021 11 10 10:25:12",
+							},
+						},
 					},
 					{
 						HostID:                  "3",
@@ -233,7 +250,7 @@ func (suite *SAPSystemsServiceTestSuite) TestSAPSystemsService_GetAllDatabases()
 			ID:     "sap_system_2",
 			SID:    "PRD",
 			Type:   models.SAPSystemTypeDatabase,
-			Health: models.SAPSystemHealthWarning,
+			Health: models.SAPSystemHealthCritical,
 			Instances: []*models.SAPSystemInstance{
 				{
 					Features:                "features",
@@ -247,6 +264,15 @@ func (suite *SAPSystemsServiceTestSuite) TestSAPSystemsService_GetAllDatabases()
 					SID:                     "PRD",
 					Type:                    models.SAPSystemTypeDatabase,
 					Status:                  string(sapcontrol.STATECOLOR_GREEN),
+					Alerts: []*models.SAPInstanceAlert{
+						{
+							Object:      "Dialog",
+							Attribute:   "ResponseTime",
+							Value:       string(sapcontrol.STATECOLOR_RED),
+							Description: "Dialog response time exceeds the threshold",
+							Time:        "2021 11 10 10:25:12",
+						},
+					},
 				},
 				{
 					Features:                "features",
@@ -306,3 +332,85 @@ func (suite *SAPSystemsServiceTestSuite) TestSAPSystemsService_GetAllDatabasesSI
 	suite.NoError(err)
 	suite.Equal([]string{"PRD"}, sids)
 }
+
+func (suite *SAPSystemsServiceTestSuite) TestSAPSystemsService_GetAllInstanceVersions() {
+	instanceVersions, err := suite.sapSystemsService.GetAllInstanceVersions(nil, nil)
+	suite.NoError(err)
+	suite.Equal(3, len(instanceVersions))
+
+	suite.EqualValues(&models.SAPInstanceVersion{
+		SAPSystemID:    "sap_system_1",
+		SID:            "HA1",
+		Type:           models.SAPSystemTypeApplication,
+		InstanceNumber: "00",
+		HostID:         "1",
+		Hostname:       "apphost",
+		KernelRelease:  "753",
+		KernelPatch:    "900",
+		Components: []*models.SAPComponentVersion{
+			{
+				Name:    "disp+work",
+				Version: "753, patch 900",
+				Time:    "2020 10 27 13:35:59",
+			},
+		},
+	}, instanceVersions[0])
+	suite.Equal([]*models.SAPComponentVersion{}, instanceVersions[1].Components)
+}
+
+func (suite *SAPSystemsServiceTestSuite) TestSAPSystemsService_GetAllInstanceVersions_Filter() {
+	instanceVersions, err := suite.sapSystemsService.GetAllInstanceVersions(&SAPInstanceVersionFilter{
+		KernelReleases: []string{"753"}, KernelPatches: []string{"900"},
+	}, nil)
+	suite.NoError(err)
+	suite.Equal(1, len(instanceVersions))
+	suite.Equal("HA1", instanceVersions[0].SID)
+
+	instanceVersions, err = suite.sapSystemsService.GetAllInstanceVersions(&SAPInstanceVersionFilter{
+		SIDs: []string{"PRD"},
+	}, &Page{Number: 1, Size: 1})
+	suite.NoError(err)
+	suite.Equal(1, len(instanceVersions))
+	suite.Equal("PRD", instanceVersions[0].SID)
+}
+
+func (suite *SAPSystemsServiceTestSuite) TestSAPSystemsService_GetAllKernelReleases() {
+	releases, err := suite.sapSystemsService.GetAllKernelReleases()
+	suite.NoError(err)
+	suite.Equal([]string{"753"}, releases)
+}
+
+func (suite *SAPSystemsServiceTestSuite) TestSAPSystemsService_GetAllKernelPatches() {
+	patches, err := suite.sapSystemsService.GetAllKernelPatches()
+	suite.NoError(err)
+	suite.Equal([]string{"900"}, patches)
+}
+
+func (suite *SAPSystemsServiceTestSuite) TestSAPSystemsService_computeHealth() {
+	sapSystem := &models.SAPSystem{
+		Instances: []*models.SAPSystemInstance{
+			{
+				Status: string(sapcontrol.STATECOLOR_GREEN),
+				Alerts: []*models.SAPInstanceAlert{
+					{Value: string(sapcontrol.STATECOLOR_YELLOW)},
+				},
+			},
+		},
+	}
+
+	suite.sapSystemsService.computeHealth(sapSystem)
+	suite.Equal(models.SAPSystemHealthPassing, sapSystem.Health)
+
+	overloadedSince := time.Now().Add(-models.SAPQueueBacklogGracePeriod - time.Minute)
+	sapSystem.Instances[0].QueueOverloadedSince = &overloadedSince
+
+	suite.sapSystemsService.computeHealth(sapSystem)
+	suite.Equal(models.SAPSystemHealthWarning, sapSystem.Health)
+
+	sapSystem.Instances[0].Alerts = append(sapSystem.Instances[0].Alerts, &models.SAPInstanceAlert{
+		Value: string(sapcontrol.STATECOLOR_RED),
+	})
+
+	suite.sapSystemsService.computeHealth(sapSystem)
+	suite.Equal(models.SAPSystemHealthCritical, sapSystem.Health)
+}
@@ -12,6 +12,13 @@ import (
 
 var telemetryCollectionInterval = 24 * time.Hour
 
+// telemetryStartupJitter delays the first collection by a random time up to it,
+// so the installations started at the same time don't publish at once
+var telemetryStartupJitter = time.Hour
+
+// telemetryJitter randomly spreads the collections around the collection interval
+const telemetryJitter = 0.1
+
 // Engine is the entrypoint for the telemetry extraction and publishing system.
 type Engine struct {
 	installationID    uuid.UUID
@@ -48,15 +55,15 @@ type Publisher interface {
 func (e *Engine) Start(ctx context.Context) {
 	log.Infof("Starting Telemetry Engine")
 
-	extractAndPublishFn := func() {
+	extractAndPublishFn := func() error {
 		canPublishTelemetry, err := e.premiumDetection.CanPublishTelemetry()
 		if err != nil {
 			log.Errorf("Unable to start Telemetry Engine. Error: %s", err)
-			return
+			return err
 		}
 		if !canPublishTelemetry {
 			log.Infof("Telemetry publishing is not supported by this installation")
-			return
+			return nil
 		}
 
 		for telemetryName, extractor := range *e.telemetryRegistry {
@@ -72,6 +79,8 @@ func (e *Engine) Start(ctx context.Context) {
 				log.Errorf("Error while publishing telemetry %s: %s", telemetryName, err)
 			}
 		}
+
+		return nil
 	}
 
 	internal.Repeat(
@@ -79,6 +88,8 @@ func (e *Engine) Start(ctx context.Context) {
 		extractAndPublishFn,
 		telemetryCollectionInterval,
 		ctx,
+		internal.WithStartupJitter(telemetryStartupJitter),
+		internal.WithJitter(telemetryJitter),
 	)
 }
 
@@ -27,6 +27,7 @@ func TestEngineTestSuite(t *testing.T) {
 func (suite *EngineTestSuite) SetupSuite() {
 	suite.dummyInstallationId = uuid.New()
 	telemetryCollectionInterval = 50 * time.Millisecond
+	telemetryStartupJitter = 0
 }
 
 func (suite *EngineTestSuite) SetupTest() {